@@ -0,0 +1,70 @@
+package config
+
+import (
+	"log"
+	"strings"
+	"time"
+)
+
+// ApprovalSLA 某類請假的審批時限，為 0 時不執行該步驟
+type ApprovalSLA struct {
+	RemindAfter   time.Duration // 提交後多久仍未審批時提醒審批人
+	EscalateAfter time.Duration // 提交後多久仍未審批時升級給上一級主管
+}
+
+// ApprovalSLAConfig 審批時限檢查配置
+type ApprovalSLAConfig struct {
+	Interval             time.Duration          // 檢查待審批請假的間隔，為 0 時不啟動
+	Default              ApprovalSLA            // 未單獨配置的請假類型使用的時限
+	ByLeaveType          map[string]ApprovalSLA // 按請假類型覆蓋的時限
+	AutoApprovePastStart bool                   // 開始日期已過仍未審批時是否自動核准
+	LockTTL              time.Duration          // 檢查分佈式鎖的過期時間，多副本時只有持鎖的副本執行
+}
+
+// For 返回請假類型適用的審批時限
+func (c ApprovalSLAConfig) For(leaveType string) ApprovalSLA {
+	if sla, ok := c.ByLeaveType[leaveType]; ok {
+		return sla
+	}
+	return c.Default
+}
+
+// LoadApprovalSLAConfig 從環境變量讀取審批時限配置
+func LoadApprovalSLAConfig() ApprovalSLAConfig {
+	return ApprovalSLAConfig{
+		Interval: getEnvDuration("APPROVAL_SLA_INTERVAL", 10*time.Minute),
+		Default: ApprovalSLA{
+			RemindAfter:   getEnvDuration("APPROVAL_REMIND_AFTER", 24*time.Hour),
+			EscalateAfter: getEnvDuration("APPROVAL_ESCALATE_AFTER", 72*time.Hour),
+		},
+		ByLeaveType:          parseApprovalSLAs(getEnv("APPROVAL_SLA_BY_TYPE", "")),
+		AutoApprovePastStart: getEnvBool("APPROVAL_AUTO_APPROVE_PAST_START", false),
+		LockTTL:              getEnvDuration("APPROVAL_SLA_LOCK_TTL", 5*time.Minute),
+	}
+}
+
+// parseApprovalSLAs 解析「請假類型=提醒時限/升級時限」格式、逗號分隔的配置，如 病假=4h/8h,年假=48h/120h
+// 格式錯誤的項目記錄日誌後忽略
+func parseApprovalSLAs(value string) map[string]ApprovalSLA {
+	slas := make(map[string]ApprovalSLA)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		leaveType, durations, ok := strings.Cut(item, "=")
+		remind, escalate, ok2 := strings.Cut(durations, "/")
+		if !ok || !ok2 || strings.TrimSpace(leaveType) == "" {
+			log.Printf("Invalid APPROVAL_SLA_BY_TYPE entry %q, expected type=remind/escalate", item)
+			continue
+		}
+		remindAfter, err1 := time.ParseDuration(strings.TrimSpace(remind))
+		escalateAfter, err2 := time.ParseDuration(strings.TrimSpace(escalate))
+		if err1 != nil || err2 != nil {
+			log.Printf("Invalid APPROVAL_SLA_BY_TYPE entry %q, expected type=remind/escalate", item)
+			continue
+		}
+		slas[strings.TrimSpace(leaveType)] = ApprovalSLA{RemindAfter: remindAfter, EscalateAfter: escalateAfter}
+	}
+	return slas
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"log"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 附件存儲後端
+const (
+	AttachmentStorageLocal = "local" // 本地磁盤
+	AttachmentStorageS3    = "s3"    // S3 兼容的對象存儲（AWS S3、MinIO 等）
+)
+
+// S3Config S3 兼容對象存儲的配置，以路徑形式（Endpoint/Bucket/Key）訪問對象
+type S3Config struct {
+	Endpoint  string // 如 https://s3.ap-northeast-1.amazonaws.com 或 http://minio:9000
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	Timeout   time.Duration // 單次請求超時
+}
+
+// AttachmentConfig 請假附件配置
+type AttachmentConfig struct {
+	Storage      string // 存儲後端（local/s3）
+	Dir          string // 本地存儲的目錄
+	S3           S3Config
+	MaxSize      int64          // 單個附件的大小上限（字節）
+	MaxPerLeave  int            // 每筆請假的附件數上限
+	AllowedTypes []string       // 允許的內容類型，按文件內容識別
+	Required     map[string]int // 需要附件的請假類型，請假天數超過對應值時需要
+}
+
+// Requires 判斷請假類型在請假 days 天時是否需要附件
+func (c AttachmentConfig) Requires(leaveType string, days int) bool {
+	minDays, ok := c.Required[leaveType]
+	return ok && days > minDays
+}
+
+// Allows 判斷內容類型是否允許上傳
+func (c AttachmentConfig) Allows(contentType string) bool {
+	for _, allowed := range c.AllowedTypes {
+		if allowed == contentType {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadAttachmentConfig 從環境變量讀取附件配置
+func LoadAttachmentConfig() AttachmentConfig {
+	return AttachmentConfig{
+		Storage: getEnv("ATTACHMENT_STORAGE", AttachmentStorageLocal),
+		Dir:     getEnv("ATTACHMENT_DIR", "./data/attachments"),
+		S3: S3Config{
+			Endpoint:  getEnv("ATTACHMENT_S3_ENDPOINT", ""),
+			Region:    getEnv("ATTACHMENT_S3_REGION", "us-east-1"),
+			Bucket:    getEnv("ATTACHMENT_S3_BUCKET", ""),
+			AccessKey: getEnv("ATTACHMENT_S3_ACCESS_KEY", ""),
+			SecretKey: getEnv("ATTACHMENT_S3_SECRET_KEY", ""),
+			Timeout:   getEnvDuration("ATTACHMENT_S3_TIMEOUT", time.Minute),
+		},
+		MaxSize:      int64(getEnvInt("ATTACHMENT_MAX_SIZE", 10<<20)),
+		MaxPerLeave:  getEnvInt("ATTACHMENT_MAX_PER_LEAVE", 5),
+		AllowedTypes: splitList(getEnv("ATTACHMENT_ALLOWED_TYPES", "application/pdf,image/jpeg,image/png,image/heic")),
+		Required:     parseAttachmentRequirements(getEnv("ATTACHMENT_REQUIRED", "病假>3,婚假,喪假")),
+	}
+}
+
+// splitList 解析逗號分隔的列表，忽略空白項目
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseAttachmentRequirements 解析「請假類型」或「請假類型>天數」格式、逗號分隔的配置，如 病假>3,婚假
+// 只寫請假類型時一律需要附件；格式錯誤的項目記錄日誌後忽略
+func parseAttachmentRequirements(value string) map[string]int {
+	required := make(map[string]int)
+	for _, item := range splitList(value) {
+		leaveType, days, hasDays := strings.Cut(item, ">")
+		leaveType = strings.TrimSpace(leaveType)
+		minDays := 0
+		if hasDays {
+			n, err := strconv.Atoi(strings.TrimSpace(days))
+			if err != nil || n < 0 || leaveType == "" {
+				log.Printf("Invalid ATTACHMENT_REQUIRED entry %q, expected type or type>days", item)
+				continue
+			}
+			minDays = n
+		}
+		required[leaveType] = minDays
+	}
+	return required
+}
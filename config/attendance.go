@@ -0,0 +1,21 @@
+package config
+
+import "time"
+
+// AttendanceConfig 考勤配置，應出勤時間由排班決定
+type AttendanceConfig struct {
+	LateGrace    time.Duration // 晚於上班時間多久以內不算遲到
+	EarlyGrace   time.Duration // 早於下班時間多久以內不算早退
+	MaxRange     time.Duration // 考勤摘要單次查詢的最長期間
+	MaxClockSkew time.Duration // 打卡設備上傳的打卡時間最多可晚於服務器時間多久
+}
+
+// LoadAttendanceConfig 從環境變量讀取考勤配置
+func LoadAttendanceConfig() AttendanceConfig {
+	return AttendanceConfig{
+		LateGrace:    getEnvDuration("ATTENDANCE_LATE_GRACE", 5*time.Minute),
+		EarlyGrace:   getEnvDuration("ATTENDANCE_EARLY_GRACE", 0),
+		MaxRange:     getEnvDuration("ATTENDANCE_MAX_RANGE", 92*24*time.Hour),
+		MaxClockSkew: getEnvDuration("ATTENDANCE_MAX_CLOCK_SKEW", 5*time.Minute),
+	}
+}
@@ -0,0 +1,28 @@
+package config
+
+import "time"
+
+// 緩存後端類型
+const (
+	CacheBackendRedis  = "redis"  // Redis 為主，故障時切換到進程內 LRU
+	CacheBackendMemory = "memory" // 僅使用進程內 LRU
+	CacheBackendNone   = "none"   // 不使用緩存
+)
+
+// CacheConfig 緩存層配置
+type CacheConfig struct {
+	Backend          string        // 緩存後端類型
+	LRUCapacity      int           // 進程內 LRU 最大條目數
+	BreakerThreshold int           // 連續失敗多少次後熔斷 Redis
+	BreakerCooldown  time.Duration // 熔斷後多久嘗試恢復 Redis
+}
+
+// LoadCacheConfig 從環境變量讀取緩存配置
+func LoadCacheConfig() CacheConfig {
+	return CacheConfig{
+		Backend:          getEnv("CACHE_BACKEND", CacheBackendRedis),
+		LRUCapacity:      getEnvInt("CACHE_LRU_CAPACITY", 10000),
+		BreakerThreshold: getEnvInt("CACHE_BREAKER_THRESHOLD", 5),
+		BreakerCooldown:  getEnvDuration("CACHE_BREAKER_COOLDOWN", 30*time.Second),
+	}
+}
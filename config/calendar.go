@@ -0,0 +1,21 @@
+package config
+
+import "time"
+
+// CalendarConfig 團隊行事曆與 iCalendar 訂閱配置
+type CalendarConfig struct {
+	BaseURL    string        // 訂閱連結使用的服務對外地址
+	MaxRange   time.Duration // 行事曆單次查詢的最長期間
+	FeedPast   time.Duration // 訂閱內容包含的過去期間
+	FeedFuture time.Duration // 訂閱內容包含的未來期間
+}
+
+// LoadCalendarConfig 從環境變量讀取行事曆配置
+func LoadCalendarConfig() CalendarConfig {
+	return CalendarConfig{
+		BaseURL:    getEnv("PUBLIC_BASE_URL", "http://localhost:8080"),
+		MaxRange:   getEnvDuration("CALENDAR_MAX_RANGE", 92*24*time.Hour),
+		FeedPast:   getEnvDuration("CALENDAR_FEED_PAST", 90*24*time.Hour),
+		FeedFuture: getEnvDuration("CALENDAR_FEED_FUTURE", 365*24*time.Hour),
+	}
+}
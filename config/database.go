@@ -39,11 +39,7 @@ func InitDB() {
 	}
 
 	// 自動遷移數據庫結構
-	err = DB.AutoMigrate(
-		&models.Employee{},
-		&models.Leave{},
-	)
-	if err != nil {
+	if err := AutoMigrate(DB); err != nil {
 		log.Fatal("Failed to migrate database:", err)
 	}
 
@@ -105,3 +101,45 @@ func InitDB() {
 
 	log.Println("Successfully connected to database and migrated schemas")
 }
+
+// AutoMigrate 遷移所有模型的數據庫結構
+func AutoMigrate(db *gorm.DB) error {
+	return db.AutoMigrate(
+		&models.Employee{},
+		&models.Leave{},
+		&models.WebhookSubscription{},
+		&models.WebhookDelivery{},
+		&models.OutboxMessage{},
+		&models.NotificationPreference{},
+		&models.NotificationLog{},
+		&models.Notification{},
+		&models.AuditLog{},
+		&models.LeaveActionToken{},
+		&models.ApprovalDelegation{},
+		&models.LeaveHistory{},
+		&models.Holiday{},
+		&models.CalendarFeed{},
+		&models.StaffingRule{},
+		&models.LeaveAttachment{},
+		&models.BulkLeaveJob{},
+		&models.BulkLeaveItem{},
+		&models.LeaveLedgerEntry{},
+		&models.YearEndClose{},
+		&models.YearEndCloseItem{},
+		&models.OvertimeRequest{},
+		&models.AttendancePunch{},
+		&models.AttendanceCorrection{},
+		&models.WorkScheduleTemplate{},
+		&models.ScheduleShift{},
+		&models.RosterAssignment{},
+		&models.ShiftSwapRequest{},
+		&models.ShiftOverride{},
+		&models.PayComponent{},
+		&models.PayrollRun{},
+		&models.Payslip{},
+		&models.PayrollLine{},
+		&models.InsuranceGradeTable{},
+		&models.InsuranceGrade{},
+		&models.InsuranceContribution{},
+	)
+}
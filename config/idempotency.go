@@ -0,0 +1,17 @@
+package config
+
+import "time"
+
+// IdempotencyConfig 冪等鍵配置
+type IdempotencyConfig struct {
+	TTL         time.Duration // 已完成請求的響應保存時間，期間內相同冪等鍵的重試會直接重放響應
+	InFlightTTL time.Duration // 處理中標記的過期時間，避免副本崩潰後冪等鍵永久被佔用
+}
+
+// LoadIdempotencyConfig 從環境變量讀取冪等鍵配置
+func LoadIdempotencyConfig() IdempotencyConfig {
+	return IdempotencyConfig{
+		TTL:         getEnvDuration("IDEMPOTENCY_TTL", 24*time.Hour),
+		InFlightTTL: getEnvDuration("IDEMPOTENCY_IN_FLIGHT_TTL", time.Minute),
+	}
+}
@@ -0,0 +1,53 @@
+package config
+
+import "log"
+
+// InsuranceConfig 勞保、健保與勞退的費率配置，投保薪資按分級表決定
+type InsuranceConfig struct {
+	LaborRate           float64 // 勞保費率（普通事故加就業保險）
+	LaborEmployeeShare  float64 // 勞保費由員工負擔的比例
+	LaborEmployerShare  float64 // 勞保費由雇主負擔的比例，其餘由政府負擔
+	OccupationalRate    float64 // 職災保險費率，全額由雇主負擔
+	HealthRate          float64 // 健保費率
+	HealthEmployeeShare float64 // 健保費由員工負擔的比例，按本人與眷屬人數計算
+	HealthEmployerShare float64 // 健保費由雇主負擔的比例，按本人與平均眷口數計算
+	HealthAvgDependents float64 // 雇主負擔健保費時的平均眷口數
+	HealthMaxDependents int     // 員工負擔健保費時最多計算的眷屬人數
+	PensionEmployerRate float64 // 雇主提繳勞退的比例
+}
+
+// LoadInsuranceConfig 從環境變量讀取勞健保與勞退配置，默認為 2024 年的費率
+func LoadInsuranceConfig() InsuranceConfig {
+	avgDependents := getEnvFloat("INSURANCE_HEALTH_AVG_DEPENDENTS", 0.57)
+	if avgDependents < 0 {
+		log.Printf("Invalid INSURANCE_HEALTH_AVG_DEPENDENTS %g, using 0.57", avgDependents)
+		avgDependents = 0.57
+	}
+	maxDependents := getEnvInt("INSURANCE_HEALTH_MAX_DEPENDENTS", 3)
+	if maxDependents < 0 {
+		log.Printf("Invalid INSURANCE_HEALTH_MAX_DEPENDENTS %d, using 3", maxDependents)
+		maxDependents = 3
+	}
+	return InsuranceConfig{
+		LaborRate:           getEnvRate("INSURANCE_LABOR_RATE", 0.125),
+		LaborEmployeeShare:  getEnvRate("INSURANCE_LABOR_EMPLOYEE_SHARE", 0.2),
+		LaborEmployerShare:  getEnvRate("INSURANCE_LABOR_EMPLOYER_SHARE", 0.7),
+		OccupationalRate:    getEnvRate("INSURANCE_OCCUPATIONAL_RATE", 0.0021),
+		HealthRate:          getEnvRate("INSURANCE_HEALTH_RATE", 0.0517),
+		HealthEmployeeShare: getEnvRate("INSURANCE_HEALTH_EMPLOYEE_SHARE", 0.3),
+		HealthEmployerShare: getEnvRate("INSURANCE_HEALTH_EMPLOYER_SHARE", 0.6),
+		HealthAvgDependents: avgDependents,
+		HealthMaxDependents: maxDependents,
+		PensionEmployerRate: getEnvRate("INSURANCE_PENSION_EMPLOYER_RATE", 0.06),
+	}
+}
+
+// getEnvRate 獲取 0～1 之間的比例，超出範圍時返回默認值
+func getEnvRate(key string, defaultValue float64) float64 {
+	rate := getEnvFloat(key, defaultValue)
+	if rate < 0 || rate > 1 {
+		log.Printf("Invalid %s %g, expected a rate between 0 and 1, using %g", key, rate, defaultValue)
+		return defaultValue
+	}
+	return rate
+}
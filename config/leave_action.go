@@ -0,0 +1,19 @@
+package config
+
+import "time"
+
+// LeaveActionConfig 郵件審批連結配置
+type LeaveActionConfig struct {
+	Secret  string        // 令牌簽名密鑰，多副本部署時必須一致；為空時啟動時隨機生成，重啟後已發出的連結失效
+	TTL     time.Duration // 連結有效期
+	BaseURL string        // 郵件中連結使用的服務對外地址
+}
+
+// LoadLeaveActionConfig 從環境變量讀取郵件審批連結配置
+func LoadLeaveActionConfig() LeaveActionConfig {
+	return LeaveActionConfig{
+		Secret:  getEnv("ACTION_TOKEN_SECRET", ""),
+		TTL:     getEnvDuration("ACTION_TOKEN_TTL", 72*time.Hour),
+		BaseURL: getEnv("PUBLIC_BASE_URL", "http://localhost:8080"),
+	}
+}
@@ -0,0 +1,48 @@
+package config
+
+import "time"
+
+// SMTPConfig 郵件發送配置，Host 為空時不連接郵件服務器，郵件只寫入日誌
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string // 為空時不進行 SMTP 認證
+	Password string
+	From     string        // 發件人，如 "HR System <hr@example.com>"
+	Timeout  time.Duration // 連接與發送的總超時
+}
+
+// NotificationConfig 通知發送配置
+type NotificationConfig struct {
+	SMTP         SMTPConfig
+	PollInterval time.Duration // 輪詢待發送通知的間隔
+	BatchSize    int           // 每次輪詢處理的通知數
+	MaxAttempts  int           // 最大嘗試次數，用盡後標記為發送失敗
+	BackoffBase  time.Duration // 首次重試的等待時間，之後每次翻倍
+	BackoffMax   time.Duration // 重試等待時間上限
+
+	PubSubChannel   string        // 站內通知跨副本廣播使用的 Redis 頻道
+	StreamHeartbeat time.Duration // 推送流的心跳間隔，避免代理關閉閒置連接
+}
+
+// LoadNotificationConfig 從環境變量讀取通知配置
+func LoadNotificationConfig() NotificationConfig {
+	return NotificationConfig{
+		SMTP: SMTPConfig{
+			Host:     getEnv("SMTP_HOST", ""),
+			Port:     getEnvInt("SMTP_PORT", 587),
+			Username: getEnv("SMTP_USERNAME", ""),
+			Password: getEnv("SMTP_PASSWORD", ""),
+			From:     getEnv("SMTP_FROM", "HR System <hr@example.com>"),
+			Timeout:  getEnvDuration("SMTP_TIMEOUT", 30*time.Second),
+		},
+		PollInterval: getEnvDuration("NOTIFICATION_POLL_INTERVAL", 5*time.Second),
+		BatchSize:    getEnvInt("NOTIFICATION_BATCH_SIZE", 50),
+		MaxAttempts:  getEnvInt("NOTIFICATION_MAX_ATTEMPTS", 5),
+		BackoffBase:  getEnvDuration("NOTIFICATION_BACKOFF_BASE", time.Minute),
+		BackoffMax:   getEnvDuration("NOTIFICATION_BACKOFF_MAX", time.Hour),
+
+		PubSubChannel:   getEnv("NOTIFICATION_PUBSUB_CHANNEL", "hr:notifications"),
+		StreamHeartbeat: getEnvDuration("NOTIFICATION_STREAM_HEARTBEAT", 25*time.Second),
+	}
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+// 事件出口
+const (
+	OutboxSinkWebhook      = "webhook"
+	OutboxSinkRedisStream  = "redis_stream"
+	OutboxSinkStdout       = "stdout"
+	OutboxSinkNotification = "notification"
+)
+
+// OutboxConfig 事件中繼配置
+type OutboxConfig struct {
+	Sinks        []string      // 啟用的事件出口
+	PollInterval time.Duration // 輪詢未發佈事件的間隔
+	BatchSize    int           // 每批發佈的事件數
+	LockTTL      time.Duration // 中繼分佈式鎖的過期時間，多副本時只有持鎖的副本發佈事件
+	BackoffBase  time.Duration // 發佈失敗後首次重試的等待時間，之後每次翻倍
+	BackoffMax   time.Duration // 重試等待時間上限
+	Retention    time.Duration // 已發佈事件的保留時間
+	StreamKey    string        // Redis Stream 的鍵
+	StreamMaxLen int64         // Redis Stream 的近似最大長度
+}
+
+// LoadOutboxConfig 從環境變量讀取事件中繼配置
+func LoadOutboxConfig() OutboxConfig {
+	var sinks []string
+	for _, sink := range strings.Split(getEnv("OUTBOX_SINKS", OutboxSinkWebhook+","+OutboxSinkNotification), ",") {
+		if sink = strings.TrimSpace(sink); sink != "" {
+			sinks = append(sinks, sink)
+		}
+	}
+	return OutboxConfig{
+		Sinks:        sinks,
+		PollInterval: getEnvDuration("OUTBOX_POLL_INTERVAL", time.Second),
+		BatchSize:    getEnvInt("OUTBOX_BATCH_SIZE", 100),
+		LockTTL:      getEnvDuration("OUTBOX_LOCK_TTL", 30*time.Second),
+		BackoffBase:  getEnvDuration("OUTBOX_BACKOFF_BASE", time.Second),
+		BackoffMax:   getEnvDuration("OUTBOX_BACKOFF_MAX", 5*time.Minute),
+		Retention:    getEnvDuration("OUTBOX_RETENTION", 7*24*time.Hour),
+		StreamKey:    getEnv("OUTBOX_STREAM_KEY", "hr:events"),
+		StreamMaxLen: int64(getEnvInt("OUTBOX_STREAM_MAXLEN", 100000)),
+	}
+}
@@ -0,0 +1,18 @@
+package config
+
+import "log"
+
+// OvertimeConfig 加班配置
+type OvertimeConfig struct {
+	HoursPerDay int // 加班時數轉為補休天數時每天的時數
+}
+
+// LoadOvertimeConfig 從環境變量讀取加班配置
+func LoadOvertimeConfig() OvertimeConfig {
+	hours := getEnvInt("OVERTIME_HOURS_PER_DAY", 8)
+	if hours <= 0 {
+		log.Printf("Invalid OVERTIME_HOURS_PER_DAY %d, using 8", hours)
+		hours = 8
+	}
+	return OvertimeConfig{HoursPerDay: hours}
+}
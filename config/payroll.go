@@ -0,0 +1,93 @@
+package config
+
+import (
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OvertimeTier 加班費的一段時數及其倍率
+type OvertimeTier struct {
+	UpToHours  float64 // 當天累計加班時數不超過該值的部分適用此倍率
+	Multiplier float64 // 平日每小時工資的倍數
+}
+
+// PayrollConfig 薪資計算配置
+type PayrollConfig struct {
+	DailyRateDivisor int                       // 日薪 = 月薪 / DailyRateDivisor
+	HoursPerDay      float64                   // 時薪 = 日薪 / HoursPerDay
+	UnpaidLeaveRates map[string]float64        // 按請假類型每天扣除日薪的比例，未配置的類型不扣薪
+	OvertimeRates    map[string][]OvertimeTier // 按加班日類型的加班費倍率，超過最後一段的時數按最後一段的倍率計算
+}
+
+// LoadPayrollConfig 從環境變量讀取薪資計算配置
+// 默認按勞基法：事假不給薪，病假與生理假半薪；平日加班前 2 小時 4/3 倍、之後 5/3 倍，
+// 休息日前 2 小時 4/3 倍、3～8 小時 5/3 倍、之後 8/3 倍，國定假日 8 小時內加倍發給（月薪已含當天工資，另發 1 倍）
+func LoadPayrollConfig() PayrollConfig {
+	divisor := getEnvInt("PAYROLL_DAILY_RATE_DIVISOR", 30)
+	if divisor <= 0 {
+		log.Printf("Invalid PAYROLL_DAILY_RATE_DIVISOR %d, using 30", divisor)
+		divisor = 30
+	}
+	hours := getEnvInt("PAYROLL_HOURS_PER_DAY", 8)
+	if hours <= 0 {
+		log.Printf("Invalid PAYROLL_HOURS_PER_DAY %d, using 8", hours)
+		hours = 8
+	}
+	return PayrollConfig{
+		DailyRateDivisor: divisor,
+		HoursPerDay:      float64(hours),
+		UnpaidLeaveRates: parseLeaveRates(getEnv("PAYROLL_UNPAID_LEAVE_RATES", "事假=1,病假=0.5,生理假=0.5")),
+		OvertimeRates: parseOvertimeRates(getEnv("PAYROLL_OVERTIME_RATES",
+			"weekday=2:1.34/4:1.67,rest_day=2:1.34/8:1.67/12:2.67,holiday=8:1/12:1.34")),
+	}
+}
+
+// parseLeaveRates 解析「請假類型=比例」格式、逗號分隔的配置，比例須在 0～1 之間；格式錯誤的項目記錄日誌後忽略
+func parseLeaveRates(value string) map[string]float64 {
+	rates := make(map[string]float64)
+	for _, item := range splitList(value) {
+		leaveType, raw, ok := strings.Cut(item, "=")
+		leaveType = strings.TrimSpace(leaveType)
+		rate, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if !ok || err != nil || leaveType == "" || rate < 0 || rate > 1 {
+			log.Printf("Invalid PAYROLL_UNPAID_LEAVE_RATES entry %q, expected type=rate with rate between 0 and 1", item)
+			continue
+		}
+		rates[leaveType] = rate
+	}
+	return rates
+}
+
+// parseOvertimeRates 解析「加班日類型=時數:倍率/時數:倍率」格式、逗號分隔的配置，如 weekday=2:1.34/4:1.67；
+// 格式錯誤的項目記錄日誌後忽略
+func parseOvertimeRates(value string) map[string][]OvertimeTier {
+	rates := make(map[string][]OvertimeTier)
+	for _, item := range splitList(value) {
+		dayType, raw, ok := strings.Cut(item, "=")
+		dayType = strings.TrimSpace(dayType)
+		tiers, valid := parseOvertimeTiers(raw)
+		if !ok || !valid || dayType == "" {
+			log.Printf("Invalid PAYROLL_OVERTIME_RATES entry %q, expected day_type=hours:multiplier[/hours:multiplier...]", item)
+			continue
+		}
+		rates[dayType] = tiers
+	}
+	return rates
+}
+
+func parseOvertimeTiers(value string) ([]OvertimeTier, bool) {
+	var tiers []OvertimeTier
+	for _, part := range strings.Split(value, "/") {
+		hours, multiplier, ok := strings.Cut(part, ":")
+		upTo, err1 := strconv.ParseFloat(strings.TrimSpace(hours), 64)
+		rate, err2 := strconv.ParseFloat(strings.TrimSpace(multiplier), 64)
+		if !ok || err1 != nil || err2 != nil || upTo <= 0 || rate < 0 {
+			return nil, false
+		}
+		tiers = append(tiers, OvertimeTier{UpToHours: upTo, Multiplier: rate})
+	}
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].UpToHours < tiers[j].UpToHours })
+	return tiers, len(tiers) > 0
+}
@@ -0,0 +1,31 @@
+package config
+
+import "time"
+
+// 緩存預熱範圍
+const (
+	PrewarmScopeAll       = "all"
+	PrewarmScopeEmployees = "employees"
+	PrewarmScopeLeaves    = "leaves"
+	PrewarmScopeNone      = "none"
+)
+
+// PrewarmConfig 緩存預熱配置
+type PrewarmConfig struct {
+	Interval     time.Duration // 預熱間隔
+	FullInterval time.Duration // 全量預熱間隔，其餘時間只刷新有變更的數據
+	Scope        string        // 預熱範圍
+	BatchSize    int           // 每批從數據庫讀取並寫入緩存的條數
+	LockTTL      time.Duration // 預熱分佈式鎖的過期時間，需長於單次預熱耗時
+}
+
+// LoadPrewarmConfig 從環境變量讀取預熱配置
+func LoadPrewarmConfig() PrewarmConfig {
+	return PrewarmConfig{
+		Interval:     getEnvDuration("PREWARM_INTERVAL", 30*time.Minute),
+		FullInterval: getEnvDuration("PREWARM_FULL_INTERVAL", 24*time.Hour),
+		Scope:        getEnv("PREWARM_SCOPE", PrewarmScopeAll),
+		BatchSize:    getEnvInt("PREWARM_BATCH_SIZE", 500),
+		LockTTL:      getEnvDuration("PREWARM_LOCK_TTL", 5*time.Minute),
+	}
+}
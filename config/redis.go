@@ -12,19 +12,24 @@ import (
 var RedisClient *redis.Client
 
 // 緩存過期時間
+// 實際寫入時會在此基礎上加入 ±CacheExpirationJitter 的隨機抖動，避免大量鍵同時過期
 const (
 	EmployeeCacheExpiration = 30 * time.Minute
 	LeaveCacheExpiration    = 15 * time.Minute
+	NotFoundCacheExpiration = 30 * time.Second // 不存在的ID（負緩存）只短暫緩存
+	CacheExpirationJitter   = 0.1
 )
 
 // 緩存鍵前綴
 const (
-	EmployeeKeyPrefix = "employee:"
-	LeaveKeyPrefix    = "leave:"
+	EmployeeKeyPrefix       = "employee:"
+	LeaveKeyPrefix          = "leave:"
+	EmployeeLeavesKeyPrefix = "employee_leaves:" // 員工ID -> 內嵌該員工數據的請假緩存鍵集合
 )
 
 // InitRedis 初始化 Redis 連接
-func InitRedis() {
+// 連接失敗時只記錄日誌並返回錯誤，由緩存層切換到備援後端，避免 Redis 故障導致整個服務無法啟動
+func InitRedis() error {
 	RedisClient = redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%s", getEnv("REDIS_HOST", "localhost"), getEnv("REDIS_PORT", "6379")),
 		Password: getEnv("REDIS_PASSWORD", ""), // 如果有密碼的話
@@ -37,8 +42,10 @@ func InitRedis() {
 	// 測試連接
 	_, err := RedisClient.Ping(ctx).Result()
 	if err != nil {
-		log.Fatal("Failed to connect to Redis:", err)
+		log.Printf("Failed to connect to Redis: %v", err)
+		return err
 	}
 
 	log.Println("Successfully connected to Redis")
+	return nil
 }
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"log"
+	"time"
+)
+
+// ScheduleConfig 排班配置
+type ScheduleConfig struct {
+	DefaultStart time.Duration // 沒有排班的員工週一至週五的上班時間，自零點起算
+	DefaultEnd   time.Duration // 沒有排班的員工週一至週五的下班時間，自零點起算
+	MaxRange     time.Duration // 班次單次查詢的最長期間
+}
+
+// LoadScheduleConfig 從環境變量讀取排班配置
+func LoadScheduleConfig() ScheduleConfig {
+	return ScheduleConfig{
+		DefaultStart: getEnvClock("SCHEDULE_DEFAULT_START", 9*time.Hour),
+		DefaultEnd:   getEnvClock("SCHEDULE_DEFAULT_END", 18*time.Hour),
+		MaxRange:     getEnvDuration("SCHEDULE_MAX_RANGE", 92*24*time.Hour),
+	}
+}
+
+// getEnvClock 讀取 HH:MM 格式的時刻，返回自零點起算的時長
+func getEnvClock(key string, defaultValue time.Duration) time.Duration {
+	value := getEnv(key, "")
+	if value == "" {
+		return defaultValue
+	}
+	clock, err := ParseClock(value)
+	if err != nil {
+		log.Printf("Invalid %s %q, expected HH:MM", key, value)
+		return defaultValue
+	}
+	return clock
+}
+
+// ParseClock 解析 HH:MM 格式的時刻，返回自零點起算的時長
+func ParseClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
@@ -0,0 +1,25 @@
+package config
+
+import "time"
+
+// WebhookConfig Webhook 投遞配置
+type WebhookConfig struct {
+	PollInterval time.Duration // 輪詢到期投遞記錄的間隔
+	BatchSize    int           // 每次輪詢處理的投遞記錄數
+	Timeout      time.Duration // 單次投遞的 HTTP 超時
+	MaxAttempts  int           // 最大嘗試次數，用盡後進入死信列表
+	BackoffBase  time.Duration // 首次重試的等待時間，之後每次翻倍
+	BackoffMax   time.Duration // 重試等待時間上限
+}
+
+// LoadWebhookConfig 從環境變量讀取 Webhook 配置
+func LoadWebhookConfig() WebhookConfig {
+	return WebhookConfig{
+		PollInterval: getEnvDuration("WEBHOOK_POLL_INTERVAL", 5*time.Second),
+		BatchSize:    getEnvInt("WEBHOOK_BATCH_SIZE", 50),
+		Timeout:      getEnvDuration("WEBHOOK_TIMEOUT", 10*time.Second),
+		MaxAttempts:  getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+		BackoffBase:  getEnvDuration("WEBHOOK_BACKOFF_BASE", 30*time.Second),
+		BackoffMax:   getEnvDuration("WEBHOOK_BACKOFF_MAX", 6*time.Hour),
+	}
+}
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"log"
+	"strconv"
+	"strings"
+)
+
+// 年終結算時未休假期的處理方式
+const (
+	YearEndCarryOver = "carry_over" // 遞延到次年，超過上限的部分按 Remainder 處理
+	YearEndExpire    = "expire"     // 作廢
+	YearEndPayout    = "payout"     // 按日薪折發工資
+)
+
+// YearEndPolicy 某類請假年終未休天數的處理方式
+type YearEndPolicy struct {
+	Action       string  // carry_over/expire/payout
+	MaxCarryDays float64 // 遞延天數上限，僅 carry_over 使用
+	Remainder    string  // 超過遞延上限的天數的處理方式（expire/payout），僅 carry_over 使用
+}
+
+// YearEndConfig 年終結算配置
+type YearEndConfig struct {
+	Policies         map[string]YearEndPolicy // 按請假類型的處理方式，未配置的類型不結算
+	DailyRateDivisor int                      // 日薪 = 月薪 / DailyRateDivisor
+}
+
+// LoadYearEndConfig 從環境變量讀取年終結算配置
+// 勞基法規定特休與補休期限屆滿未休完應發給工資，特休經勞雇雙方協商可遞延一年，默認折發工資
+func LoadYearEndConfig() YearEndConfig {
+	divisor := getEnvInt("YEAR_END_DAILY_RATE_DIVISOR", 30)
+	if divisor <= 0 {
+		log.Printf("Invalid YEAR_END_DAILY_RATE_DIVISOR %d, using 30", divisor)
+		divisor = 30
+	}
+	return YearEndConfig{
+		Policies:         parseYearEndPolicies(getEnv("YEAR_END_POLICIES", "年假=payout,補休=payout")),
+		DailyRateDivisor: divisor,
+	}
+}
+
+// parseYearEndPolicies 解析「請假類型=處理方式」格式、逗號分隔的配置，處理方式為
+// payout、expire 或 carry_over:上限天數[/超出部分的處理方式]，如 年假=carry_over:5/payout,補休=expire
+// 超出部分的處理方式默認為 payout；格式錯誤的項目記錄日誌後忽略
+func parseYearEndPolicies(value string) map[string]YearEndPolicy {
+	policies := make(map[string]YearEndPolicy)
+	for _, item := range splitList(value) {
+		leaveType, action, ok := strings.Cut(item, "=")
+		leaveType, action = strings.TrimSpace(leaveType), strings.TrimSpace(action)
+		policy, valid := parseYearEndPolicy(action)
+		if !ok || !valid || leaveType == "" {
+			log.Printf("Invalid YEAR_END_POLICIES entry %q, expected type=payout, type=expire or type=carry_over:days[/payout|expire]", item)
+			continue
+		}
+		policies[leaveType] = policy
+	}
+	return policies
+}
+
+func parseYearEndPolicy(action string) (YearEndPolicy, bool) {
+	switch action {
+	case YearEndExpire, YearEndPayout:
+		return YearEndPolicy{Action: action}, true
+	}
+	name, rest, ok := strings.Cut(action, ":")
+	if name != YearEndCarryOver || !ok {
+		return YearEndPolicy{}, false
+	}
+	days, remainder, hasRemainder := strings.Cut(rest, "/")
+	maxDays, err := strconv.ParseFloat(strings.TrimSpace(days), 64)
+	if err != nil || maxDays < 0 {
+		return YearEndPolicy{}, false
+	}
+	policy := YearEndPolicy{Action: YearEndCarryOver, MaxCarryDays: maxDays, Remainder: YearEndPayout}
+	if hasRemainder {
+		policy.Remainder = strings.TrimSpace(remainder)
+		if policy.Remainder != YearEndExpire && policy.Remainder != YearEndPayout {
+			return YearEndPolicy{}, false
+		}
+	}
+	return policy, true
+}
@@ -0,0 +1,109 @@
+package apperrors
+
+// 錯誤碼
+const (
+	CodeInternal         = "internal_error"
+	CodeInvalidRequest   = "invalid_request"
+	CodeInvalidDateTime  = "invalid_datetime"
+	CodeInvalidID        = "invalid_id"
+	CodeValidationFailed = "validation_failed"
+	CodeUnauthenticated  = "unauthenticated"
+
+	CodeEmployeeNotFound   = "employee_not_found"
+	CodeEmailAlreadyExists = "email_already_exists"
+	CodeManagerNotFound    = "manager_not_found"
+	CodeInvalidManager     = "invalid_manager"
+
+	CodeLeaveNotFound      = "leave_not_found"
+	CodeInvalidDateRange   = "invalid_date_range"
+	CodeInvalidStatus      = "invalid_leave_status"
+	CodeLeaveNotCancelable = "leave_not_cancellable"
+	CodeLeaveProcessed     = "leave_already_processed"
+	CodeNotApprover        = "not_leave_approver"
+	CodeNoApprover         = "no_leave_approver"
+	CodeNotLeaveOwner      = "not_leave_owner"
+	CodePrewarmQueued      = "prewarm_already_queued"
+	CodeInvalidPrewarmMode = "invalid_prewarm_mode"
+
+	CodeInvalidIdempotencyKey  = "invalid_idempotency_key"
+	CodeIdempotencyKeyReused   = "idempotency_key_reused"
+	CodeIdempotencyKeyInFlight = "idempotency_key_in_flight"
+
+	CodeWebhookNotFound  = "webhook_not_found"
+	CodeDeliveryNotFound = "webhook_delivery_not_found"
+	CodeDeliveryInFlight = "webhook_delivery_in_flight"
+	CodeInvalidEventType = "invalid_event_type"
+
+	CodeInvalidNotificationEvent = "invalid_notification_event"
+	CodeNotificationNotFound     = "notification_not_found"
+
+	CodeDelegationNotFound = "delegation_not_found"
+	CodeDelegationOverlap  = "delegation_overlap"
+	CodeInvalidDelegate    = "invalid_delegate"
+	CodeDelegationDenied   = "delegation_access_denied"
+
+	CodeInvalidActionToken = "invalid_action_token"
+	CodeActionTokenExpired = "action_token_expired"
+	CodeActionTokenUsed    = "action_token_used"
+
+	CodeInvalidDate          = "invalid_date"
+	CodeInvalidCalendarScope = "invalid_calendar_scope"
+	CodeCalendarRangeTooLong = "calendar_range_too_long"
+	CodeCalendarFeedNotFound = "calendar_feed_not_found"
+	CodeHolidayNotFound      = "holiday_not_found"
+	CodeHolidayExists        = "holiday_already_exists"
+
+	CodeStaffingRuleNotFound = "staffing_rule_not_found"
+	CodeInvalidStaffingRule  = "invalid_staffing_rule"
+	CodeStaffingRuleViolated = "staffing_rule_violated"
+
+	CodeAttachmentNotFound       = "attachment_not_found"
+	CodeAttachmentRequired       = "attachment_required"
+	CodeAttachmentTooLarge       = "attachment_too_large"
+	CodeAttachmentTypeNotAllowed = "attachment_type_not_allowed"
+	CodeAttachmentLimitReached   = "attachment_limit_reached"
+	CodeAttachmentAccessDenied   = "attachment_access_denied"
+	CodeAttachmentLeaveClosed    = "attachment_leave_closed"
+
+	CodeBulkLeaveJobNotFound   = "bulk_leave_job_not_found"
+	CodeInvalidBulkLeaveTarget = "invalid_bulk_leave_target"
+	CodeBulkLeaveNoEmployees   = "bulk_leave_no_employees"
+
+	CodeYearEndCloseNotFound = "year_end_close_not_found"
+	CodeYearAlreadyClosed    = "year_already_closed"
+	CodeInvalidCloseYear     = "invalid_close_year"
+
+	CodeOvertimeNotFound   = "overtime_not_found"
+	CodeOvertimeExists     = "overtime_already_exists"
+	CodeOvertimeNotPending = "overtime_not_pending"
+
+	CodeCorrectionNotFound     = "attendance_correction_not_found"
+	CodeCorrectionNotPending   = "attendance_correction_not_pending"
+	CodeInvalidPunchTime       = "invalid_punch_time"
+	CodeAttendanceRangeTooLong = "attendance_range_too_long"
+
+	CodeScheduleTemplateNotFound = "schedule_template_not_found"
+	CodeScheduleTemplateExists   = "schedule_template_already_exists"
+	CodeScheduleTemplateInUse    = "schedule_template_in_use"
+	CodeInvalidScheduleTemplate  = "invalid_schedule_template"
+	CodeRosterNotFound           = "roster_assignment_not_found"
+	CodeRosterOverlap            = "roster_assignment_overlap"
+	CodeScheduleRangeTooLong     = "schedule_range_too_long"
+	CodeShiftSwapNotFound        = "shift_swap_not_found"
+	CodeShiftSwapNotAllowed      = "shift_swap_not_allowed"
+	CodeInvalidShiftSwap         = "invalid_shift_swap"
+	CodeShiftSwapStatus          = "shift_swap_invalid_status"
+
+	CodePayrollRunNotFound   = "payroll_run_not_found"
+	CodePayrollRunExists     = "payroll_run_already_exists"
+	CodePayrollRunLocked     = "payroll_run_locked"
+	CodeInvalidPayrollMonth  = "invalid_payroll_month"
+	CodePayComponentNotFound = "pay_component_not_found"
+	CodeInvalidPayComponent  = "invalid_pay_component"
+	CodePayslipNotFound      = "payslip_not_found"
+
+	CodeGradeTableNotFound = "insurance_grade_table_not_found"
+	CodeGradeTableExists   = "insurance_grade_table_already_exists"
+	CodeGradeTableInUse    = "insurance_grade_table_in_use"
+	CodeInvalidGradeTable  = "invalid_insurance_grade_table"
+)
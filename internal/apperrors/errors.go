@@ -0,0 +1,151 @@
+package apperrors
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+)
+
+// Kind 錯誤類型，決定返回的 HTTP 狀態碼
+type Kind string
+
+const (
+	KindNotFound      Kind = "not_found"
+	KindConflict      Kind = "conflict"
+	KindValidation    Kind = "validation"
+	KindUnauthorized  Kind = "unauthorized"
+	KindForbidden     Kind = "forbidden"
+	KindUnprocessable Kind = "unprocessable"
+	KindInternal      Kind = "internal"
+)
+
+// mysqlDuplicateEntry MySQL 唯一鍵衝突的錯誤碼
+const mysqlDuplicateEntry = 1062
+
+// FieldError 單個字段的校驗錯誤
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Error 業務錯誤，由 repositories 與 services 返回，錯誤中間件據此渲染響應
+type Error struct {
+	Kind    Kind
+	Code    string       // 機器可讀的錯誤碼，如 employee_not_found
+	Message string       // 面向用戶的錯誤訊息
+	Fields  []FieldError // 字段級錯誤，僅校驗錯誤使用
+	Err     error        // 原始錯誤，不會返回給客戶端
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// HTTPStatus 返回錯誤類型對應的 HTTP 狀態碼
+func (e *Error) HTTPStatus() int {
+	switch e.Kind {
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindUnprocessable:
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// Wrap 為錯誤附加原始錯誤
+func (e *Error) Wrap(err error) *Error {
+	e.Err = err
+	return e
+}
+
+func NotFound(code, message string) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message}
+}
+
+func Conflict(code, message string) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: message}
+}
+
+func Validation(code, message string, fields ...FieldError) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: message, Fields: fields}
+}
+
+// Unauthorized 無法識別當前用戶
+func Unauthorized(code, message string) *Error {
+	return &Error{Kind: KindUnauthorized, Code: code, Message: message}
+}
+
+func Forbidden(code, message string) *Error {
+	return &Error{Kind: KindForbidden, Code: code, Message: message}
+}
+
+// Unprocessable 請求格式正確但無法處理，如冪等鍵被用於不同的請求
+func Unprocessable(code, message string) *Error {
+	return &Error{Kind: KindUnprocessable, Code: code, Message: message}
+}
+
+// Internal 包裝未預期的錯誤，原始錯誤只記錄在日誌中
+func Internal(err error) *Error {
+	return &Error{Kind: KindInternal, Code: CodeInternal, Message: "Internal server error", Err: err}
+}
+
+// Field 創建字段錯誤
+func Field(field, code, message string) FieldError {
+	return FieldError{Field: field, Code: code, Message: message}
+}
+
+// From 將任意錯誤轉換為 *Error，非業務錯誤視為內部錯誤
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Internal(err)
+}
+
+// IsKind 判斷錯誤是否為指定類型
+func IsKind(err error, kind Kind) bool {
+	var appErr *Error
+	return errors.As(err, &appErr) && appErr.Kind == kind
+}
+
+// IsNotFound 判斷錯誤是否表示資源不存在
+func IsNotFound(err error) bool {
+	return IsKind(err, KindNotFound) || errors.Is(err, gorm.ErrRecordNotFound)
+}
+
+// FromDB 將數據庫錯誤轉換為業務錯誤
+// 記錄不存在轉為 NotFound，唯一鍵衝突轉為 Conflict，其餘為 Internal
+func FromDB(err error, notFound *Error, conflict *Error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) && notFound != nil {
+		return notFound.Wrap(err)
+	}
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry && conflict != nil {
+		return conflict.Wrap(err)
+	}
+	return Internal(err)
+}
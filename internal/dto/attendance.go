@@ -0,0 +1,68 @@
+package dto
+
+import (
+	"time"
+
+	"hr-system/internal/models"
+)
+
+// PunchRequest 員工自行打卡的請求體，打卡時間以伺服器時間為準
+type PunchRequest struct {
+	Kind     string `json:"kind" binding:"required,oneof=clock_in clock_out"`
+	Source   string `json:"source" binding:"omitempty,oneof=web mobile"`
+	Location string `json:"location" binding:"max=200"`
+}
+
+// ToModel 轉換為打卡記錄，來源未指定時為 web
+func (r *PunchRequest) ToModel(employeeID uint, ip string) *models.AttendancePunch {
+	source := r.Source
+	if source == "" {
+		source = models.PunchSourceWeb
+	}
+	return &models.AttendancePunch{
+		EmployeeID: employeeID,
+		Kind:       r.Kind,
+		Source:     source,
+		IP:         ip,
+		Location:   r.Location,
+	}
+}
+
+// DevicePunchRequest 打卡機等設備上傳打卡記錄的請求體
+type DevicePunchRequest struct {
+	EmployeeID uint      `json:"employee_id" binding:"required"`
+	Kind       string    `json:"kind" binding:"required,oneof=clock_in clock_out"`
+	Time       time.Time `json:"time" binding:"required"`
+	IP         string    `json:"ip" binding:"omitempty,ip"`
+	Location   string    `json:"location" binding:"max=200"`
+}
+
+// ToModel 轉換為來源為 device 的打卡記錄
+func (r *DevicePunchRequest) ToModel() *models.AttendancePunch {
+	return &models.AttendancePunch{
+		EmployeeID: r.EmployeeID,
+		Kind:       r.Kind,
+		Time:       r.Time,
+		Source:     models.PunchSourceDevice,
+		IP:         r.IP,
+		Location:   r.Location,
+	}
+}
+
+// CreateCorrectionRequest 提交補卡申請的請求體
+type CreateCorrectionRequest struct {
+	Kind   string    `json:"kind" binding:"required,oneof=clock_in clock_out"`
+	Time   time.Time `json:"time" binding:"required,not_future"`
+	Reason string    `json:"reason" binding:"required,max=500"`
+}
+
+// ToModel 轉換為補卡申請模型
+func (r *CreateCorrectionRequest) ToModel(employeeID uint) *models.AttendanceCorrection {
+	return &models.AttendanceCorrection{
+		EmployeeID: employeeID,
+		Kind:       r.Kind,
+		Time:       r.Time,
+		Reason:     r.Reason,
+		Status:     models.LeaveStatusPending,
+	}
+}
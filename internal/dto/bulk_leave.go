@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"time"
+
+	"hr-system/internal/models"
+)
+
+// CreateBulkLeaveRequest 批量請假的請求體，對象為部門時需要 department，為指定員工時需要 employee_ids
+type CreateBulkLeaveRequest struct {
+	Target         string    `json:"target" binding:"required,oneof=all department employees"`
+	Department     string    `json:"department" binding:"max=50"`
+	EmployeeIDs    []uint    `json:"employee_ids" binding:"omitempty,max=5000,dive,gt=0"`
+	StartDate      time.Time `json:"start_date" binding:"required"`
+	EndDate        time.Time `json:"end_date" binding:"required,gtefield=StartDate,max_leave_span"`
+	LeaveType      string    `json:"leave_type" binding:"required,bulk_leave_type"`
+	Reason         string    `json:"reason" binding:"max=500"`
+	ConflictPolicy string    `json:"conflict_policy" binding:"omitempty,oneof=skip abort"`
+}
+
+// ToModel 轉換為批量請假任務模型
+func (r *CreateBulkLeaveRequest) ToModel() *models.BulkLeaveJob {
+	return &models.BulkLeaveJob{
+		Target:         r.Target,
+		Department:     r.Department,
+		StartDate:      r.StartDate,
+		EndDate:        r.EndDate,
+		LeaveType:      r.LeaveType,
+		Reason:         r.Reason,
+		ConflictPolicy: r.ConflictPolicy,
+	}
+}
@@ -0,0 +1,40 @@
+package dto
+
+import (
+	"time"
+
+	"hr-system/internal/models"
+)
+
+// CreateCalendarFeedRequest 創建行事曆訂閱的請求體
+type CreateCalendarFeedRequest struct {
+	Scope string `json:"scope" binding:"required,oneof=self team department"`
+}
+
+// CreateHolidayRequest 創建公司假日的請求體
+type CreateHolidayRequest struct {
+	Date string `json:"date" binding:"required,date"`
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// ToModel 轉換為假日模型，日期按服務所在時區解析
+func (r *CreateHolidayRequest) ToModel() *models.Holiday {
+	date, _ := time.ParseInLocation(DateLayout, r.Date, time.Local)
+	return &models.Holiday{Date: date, Name: r.Name}
+}
+
+// CalendarDayResponse 行事曆中一天的響應
+type CalendarDayResponse struct {
+	Date    string          `json:"date"`
+	Holiday string          `json:"holiday,omitempty"` // 假日名稱
+	Leaves  []LeaveResponse `json:"leaves"`            // 當天請假中的已核准與待審批請假
+}
+
+// NewCalendarDayResponse 以指定語系構建行事曆中一天的響應
+func NewCalendarDayResponse(date time.Time, holiday string, leaves []models.Leave, locale string) CalendarDayResponse {
+	return CalendarDayResponse{
+		Date:    date.Format(DateLayout),
+		Holiday: holiday,
+		Leaves:  NewLeaveResponses(leaves, locale),
+	}
+}
@@ -0,0 +1,26 @@
+package dto
+
+import (
+	"time"
+
+	"hr-system/internal/models"
+)
+
+// CreateDelegationRequest 指定審批代理的請求體
+type CreateDelegationRequest struct {
+	DelegateID uint      `json:"delegate_id" binding:"required"`
+	StartDate  time.Time `json:"start_date" binding:"required"`
+	EndDate    time.Time `json:"end_date" binding:"required,gtefield=StartDate"`
+	Reason     string    `json:"reason" binding:"max=255"`
+}
+
+// ToModel 轉換為審批代理模型
+func (r *CreateDelegationRequest) ToModel(managerID uint) *models.ApprovalDelegation {
+	return &models.ApprovalDelegation{
+		ManagerID:  managerID,
+		DelegateID: r.DelegateID,
+		StartDate:  r.StartDate,
+		EndDate:    r.EndDate,
+		Reason:     r.Reason,
+	}
+}
@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"time"
+
+	"hr-system/internal/models"
+)
+
+// EmployeeRequest 新增/更新員工的請求體
+type EmployeeRequest struct {
+	Name                 string     `json:"name" binding:"required,max=100"`
+	Email                string     `json:"email" binding:"required,email,max=100"`
+	Phone                string     `json:"phone" binding:"omitempty,max=20,tw_phone"`
+	Position             string     `json:"position" binding:"max=50"`
+	Department           string     `json:"department" binding:"max=50"`
+	Level                int        `json:"level" binding:"gte=0,lte=20"`
+	Salary               float64    `json:"salary" binding:"gte=0,lte=10000000"`
+	HireDate             time.Time  `json:"hire_date" binding:"omitempty,not_future"`
+	TerminationDate      *time.Time `json:"termination_date"`
+	HealthDependents     int        `json:"health_dependents" binding:"gte=0,lte=20"`
+	PensionVoluntaryRate float64    `json:"pension_voluntary_rate" binding:"gte=0,lte=6"`
+	Address              string     `json:"address" binding:"max=200"`
+	EmergencyContact     string     `json:"emergency_contact" binding:"max=100"`
+	Status               string     `json:"status" binding:"omitempty,employee_status"`
+	ManagerID            *uint      `json:"manager_id" binding:"omitempty,gt=0"`
+	Locale               string     `json:"locale" binding:"omitempty,oneof=zh-TW en"`
+}
+
+// ToModel 轉換為員工模型，未指定狀態時默認為在職
+func (r *EmployeeRequest) ToModel() *models.Employee {
+	status := r.Status
+	if status == "" {
+		status = models.EmployeeStatusActive
+	}
+	return &models.Employee{
+		Name:                 r.Name,
+		Email:                r.Email,
+		Phone:                r.Phone,
+		Position:             r.Position,
+		Department:           r.Department,
+		Level:                r.Level,
+		Salary:               r.Salary,
+		HireDate:             r.HireDate,
+		TerminationDate:      r.TerminationDate,
+		HealthDependents:     r.HealthDependents,
+		PensionVoluntaryRate: r.PensionVoluntaryRate,
+		Address:              r.Address,
+		EmergencyContact:     r.EmergencyContact,
+		Status:               status,
+		ManagerID:            r.ManagerID,
+		Locale:               r.Locale,
+	}
+}
@@ -0,0 +1,26 @@
+package dto
+
+import (
+	"time"
+
+	"hr-system/internal/models"
+)
+
+// ImportGradeTableRequest 上傳投保薪資分級表的表單字段，級距以 CSV 文件在 file 字段上傳
+type ImportGradeTableRequest struct {
+	Kind          string `json:"kind" form:"kind" binding:"required,oneof=labor health pension"`
+	Version       string `json:"version" form:"version" binding:"required,max=50"`
+	EffectiveFrom string `json:"effective_from" form:"effective_from" binding:"required,date"`
+	Description   string `json:"description" form:"description" binding:"max=500"`
+}
+
+// ToModel 轉換為分級表模型，生效日期按服務所在時區解析
+func (r *ImportGradeTableRequest) ToModel() *models.InsuranceGradeTable {
+	effectiveFrom, _ := time.ParseInLocation(DateLayout, r.EffectiveFrom, time.Local)
+	return &models.InsuranceGradeTable{
+		Kind:          r.Kind,
+		Version:       r.Version,
+		EffectiveFrom: effectiveFrom,
+		Description:   r.Description,
+	}
+}
@@ -0,0 +1,34 @@
+package dto
+
+import (
+	"time"
+
+	"hr-system/internal/models"
+)
+
+// CreateLeaveRequest 新增請假的請求體，新建的請假一律為待審批狀態
+type CreateLeaveRequest struct {
+	EmployeeID uint      `json:"employee_id" binding:"required"`
+	StartDate  time.Time `json:"start_date" binding:"required"`
+	EndDate    time.Time `json:"end_date" binding:"required,gtefield=StartDate,max_leave_span"`
+	LeaveType  string    `json:"leave_type" binding:"required,leave_type"`
+	Reason     string    `json:"reason" binding:"max=500"`
+}
+
+// ToModel 轉換為請假模型
+func (r *CreateLeaveRequest) ToModel() *models.Leave {
+	return &models.Leave{
+		EmployeeID: r.EmployeeID,
+		StartDate:  r.StartDate,
+		EndDate:    r.EndDate,
+		LeaveType:  r.LeaveType,
+		Reason:     r.Reason,
+		Status:     models.LeaveStatusPending,
+	}
+}
+
+// UpdateLeaveStatusRequest 審批請假的請求體
+type UpdateLeaveStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=approved rejected"`
+	Remark string `json:"remark" binding:"max=500"`
+}
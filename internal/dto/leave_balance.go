@@ -0,0 +1,31 @@
+package dto
+
+import "hr-system/internal/models"
+
+// LeaveLedgerEntryRequest 人工寫入額度流水的請求體，發放的天數必須為正數，調整可正可負
+type LeaveLedgerEntryRequest struct {
+	EmployeeID uint    `json:"employee_id" binding:"required,gt=0"`
+	Year       int     `json:"year" binding:"required,gte=1900,lte=9999"`
+	LeaveType  string  `json:"leave_type" binding:"required,leave_type"`
+	Kind       string  `json:"kind" binding:"required,oneof=grant adjustment"`
+	Days       float64 `json:"days" binding:"required,gte=-366,lte=366"`
+	Remark     string  `json:"remark" binding:"max=500"`
+}
+
+// ToModel 轉換為額度流水模型
+func (r *LeaveLedgerEntryRequest) ToModel() *models.LeaveLedgerEntry {
+	return &models.LeaveLedgerEntry{
+		EmployeeID: r.EmployeeID,
+		Year:       r.Year,
+		LeaveType:  r.LeaveType,
+		Kind:       r.Kind,
+		Days:       r.Days,
+		Remark:     r.Remark,
+	}
+}
+
+// YearEndCloseRequest 年終結算的請求體，preview 為 true 時只計算不寫入
+type YearEndCloseRequest struct {
+	Year    int  `json:"year" binding:"required,gte=1900,lte=9999"`
+	Preview bool `json:"preview"`
+}
@@ -0,0 +1,27 @@
+package dto
+
+import "hr-system/internal/models"
+
+// NotificationPreferenceItem 單一事件類型的通知偏好
+type NotificationPreferenceItem struct {
+	EventType string `json:"event_type" binding:"required,notification_event"`
+	Email     *bool  `json:"email" binding:"required"`
+}
+
+// NotificationPreferencesRequest 更新通知偏好的請求體，未列出的事件類型保持不變
+type NotificationPreferencesRequest struct {
+	Preferences []NotificationPreferenceItem `json:"preferences" binding:"required,min=1,dive"`
+}
+
+// ToModels 轉換為通知偏好模型
+func (r *NotificationPreferencesRequest) ToModels(employeeID uint) []models.NotificationPreference {
+	prefs := make([]models.NotificationPreference, 0, len(r.Preferences))
+	for _, item := range r.Preferences {
+		prefs = append(prefs, models.NotificationPreference{
+			EmployeeID: employeeID,
+			EventType:  item.EventType,
+			Email:      *item.Email,
+		})
+	}
+	return prefs
+}
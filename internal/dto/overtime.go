@@ -0,0 +1,29 @@
+package dto
+
+import (
+	"time"
+
+	"hr-system/internal/models"
+)
+
+// CreateOvertimeRequest 提交加班申請的請求體，加班日類型由服務按日期判斷
+type CreateOvertimeRequest struct {
+	EmployeeID   uint    `json:"employee_id" binding:"required"`
+	Date         string  `json:"date" binding:"required,date"`
+	Hours        float64 `json:"hours" binding:"required,gt=0,lte=12"`
+	Reason       string  `json:"reason" binding:"max=500"`
+	Compensation string  `json:"compensation" binding:"required,oneof=pay comp_time"`
+}
+
+// ToModel 轉換為加班申請模型，日期按服務所在時區解析
+func (r *CreateOvertimeRequest) ToModel() *models.OvertimeRequest {
+	date, _ := time.ParseInLocation(DateLayout, r.Date, time.Local)
+	return &models.OvertimeRequest{
+		EmployeeID:   r.EmployeeID,
+		Date:         date,
+		Hours:        r.Hours,
+		Reason:       r.Reason,
+		Compensation: r.Compensation,
+		Status:       models.LeaveStatusPending,
+	}
+}
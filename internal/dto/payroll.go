@@ -0,0 +1,42 @@
+package dto
+
+import "hr-system/internal/models"
+
+// PayComponentRequest 新增/更新薪資項目的請求體，employee_id 與 department 均為空時適用於全體員工
+type PayComponentRequest struct {
+	Name        string  `json:"name" binding:"required,max=100"`
+	Kind        string  `json:"kind" binding:"required,oneof=allowance deduction"`
+	Amount      float64 `json:"amount" binding:"required,gt=0"`
+	EmployeeID  *uint   `json:"employee_id"`
+	Department  string  `json:"department" binding:"max=50"`
+	StartMonth  string  `json:"start_month" binding:"omitempty,month"`
+	EndMonth    string  `json:"end_month" binding:"omitempty,month"`
+	ProRate     bool    `json:"pro_rate"`
+	Active      *bool   `json:"active"`
+	Description string  `json:"description" binding:"max=500"`
+}
+
+// ToModel 轉換為薪資項目模型，未指定 active 時默認啟用
+func (r *PayComponentRequest) ToModel() *models.PayComponent {
+	active := true
+	if r.Active != nil {
+		active = *r.Active
+	}
+	return &models.PayComponent{
+		Name:        r.Name,
+		Kind:        r.Kind,
+		Amount:      r.Amount,
+		EmployeeID:  r.EmployeeID,
+		Department:  r.Department,
+		StartMonth:  r.StartMonth,
+		EndMonth:    r.EndMonth,
+		ProRate:     r.ProRate,
+		Active:      active,
+		Description: r.Description,
+	}
+}
+
+// CreatePayrollRunRequest 創建薪資批次的請求體
+type CreatePayrollRunRequest struct {
+	Month string `json:"month" binding:"required,month"`
+}
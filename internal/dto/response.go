@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"hr-system/internal/i18n"
+	"hr-system/internal/models"
+)
+
+// EmployeeResponse 員工響應，附帶當前語系的部門與狀態名稱
+type EmployeeResponse struct {
+	*models.Employee
+	DepartmentLabel string `json:"department_label,omitempty"`
+	StatusLabel     string `json:"status_label"`
+}
+
+// NewEmployeeResponse 以指定語系構建員工響應
+func NewEmployeeResponse(employee *models.Employee, locale string) EmployeeResponse {
+	resp := EmployeeResponse{
+		Employee:    employee,
+		StatusLabel: i18n.Label(locale, "employee_status", employee.Status),
+	}
+	if employee.Department != "" {
+		resp.DepartmentLabel = i18n.Label(locale, "department", employee.Department)
+	}
+	return resp
+}
+
+// NewEmployeeResponses 以指定語系構建員工列表響應
+func NewEmployeeResponses(employees []models.Employee, locale string) []EmployeeResponse {
+	result := make([]EmployeeResponse, 0, len(employees))
+	for i := range employees {
+		result = append(result, NewEmployeeResponse(&employees[i], locale))
+	}
+	return result
+}
+
+// LeaveResponse 請假響應，附帶當前語系的假別與審批狀態名稱
+type LeaveResponse struct {
+	*models.Leave
+	LeaveTypeLabel   string                    `json:"leave_type_label"`
+	StatusLabel      string                    `json:"status_label"`
+	StaffingWarnings []models.StaffingCoverage `json:"staffing_warnings,omitempty"` // 提交時不滿足的 warn 人力規則
+}
+
+// NewLeaveResponse 以指定語系構建請假響應
+func NewLeaveResponse(leave *models.Leave, locale string) LeaveResponse {
+	return LeaveResponse{
+		Leave:          leave,
+		LeaveTypeLabel: i18n.Label(locale, "leave_type", leave.LeaveType),
+		StatusLabel:    i18n.Label(locale, "leave_status", leave.Status),
+	}
+}
+
+// NewLeaveResponses 以指定語系構建請假列表響應
+func NewLeaveResponses(leaves []models.Leave, locale string) []LeaveResponse {
+	result := make([]LeaveResponse, 0, len(leaves))
+	for i := range leaves {
+		result = append(result, NewLeaveResponse(&leaves[i], locale))
+	}
+	return result
+}
@@ -0,0 +1,109 @@
+package dto
+
+import (
+	"time"
+
+	"hr-system/internal/models"
+)
+
+// ScheduleTemplateRequest 新增/更新班表模板的請求體，固定工時與兼職的 day_index 0~6 為週一至週日
+type ScheduleTemplateRequest struct {
+	Name         string                 `json:"name" binding:"required,max=100"`
+	Kind         string                 `json:"kind" binding:"required,oneof=fixed rotating part_time"`
+	CycleDays    int                    `json:"cycle_days" binding:"gte=0,lte=56"`
+	SkipHolidays *bool                  `json:"skip_holidays"`
+	Description  string                 `json:"description" binding:"max=500"`
+	Shifts       []ScheduleShiftRequest `json:"shifts" binding:"required,min=1,max=56,dive"`
+}
+
+// ScheduleShiftRequest 班表模板中的一個班次，下班時間早於上班時間時表示跨夜
+type ScheduleShiftRequest struct {
+	DayIndex     int    `json:"day_index" binding:"gte=0,lt=56"`
+	Start        string `json:"start" binding:"required,clock"`
+	End          string `json:"end" binding:"required,clock"`
+	BreakMinutes int    `json:"break_minutes" binding:"gte=0"`
+}
+
+// ToModel 轉換為班表模板模型，未指定 skip_holidays 時輪班默認在公司假日照常上班，其他類型默認不上班
+func (r *ScheduleTemplateRequest) ToModel() *models.WorkScheduleTemplate {
+	skipHolidays := r.Kind != models.ScheduleKindRotating
+	if r.SkipHolidays != nil {
+		skipHolidays = *r.SkipHolidays
+	}
+	shifts := make([]models.ScheduleShift, len(r.Shifts))
+	for i, shift := range r.Shifts {
+		shifts[i] = models.ScheduleShift{
+			DayIndex:     shift.DayIndex,
+			Start:        shift.Start,
+			End:          shift.End,
+			BreakMinutes: shift.BreakMinutes,
+		}
+	}
+	return &models.WorkScheduleTemplate{
+		Name:         r.Name,
+		Kind:         r.Kind,
+		CycleDays:    r.CycleDays,
+		SkipHolidays: skipHolidays,
+		Description:  r.Description,
+		Shifts:       shifts,
+	}
+}
+
+// RosterAssignmentRequest 為員工排班的請求體
+type RosterAssignmentRequest struct {
+	EmployeeID  uint   `json:"employee_id" binding:"required"`
+	TemplateID  uint   `json:"template_id" binding:"required"`
+	StartDate   string `json:"start_date" binding:"required,date"`
+	EndDate     string `json:"end_date" binding:"omitempty,date"`
+	CycleOffset int    `json:"cycle_offset" binding:"gte=0"`
+}
+
+// ToModel 轉換為排班模型，日期按服務所在時區解析
+func (r *RosterAssignmentRequest) ToModel(createdByID *uint) *models.RosterAssignment {
+	startDate, _ := time.ParseInLocation(DateLayout, r.StartDate, time.Local)
+	return &models.RosterAssignment{
+		EmployeeID:  r.EmployeeID,
+		TemplateID:  r.TemplateID,
+		StartDate:   startDate,
+		EndDate:     parseOptionalDate(r.EndDate),
+		CycleOffset: r.CycleOffset,
+		CreatedByID: createdByID,
+	}
+}
+
+// CreateShiftSwapRequest 提交換班申請的請求體，未指定 counterpart_date 時為同一天互換班次
+type CreateShiftSwapRequest struct {
+	CounterpartID   uint   `json:"counterpart_id" binding:"required"`
+	Date            string `json:"date" binding:"required,date"`
+	CounterpartDate string `json:"counterpart_date" binding:"omitempty,date"`
+	Reason          string `json:"reason" binding:"max=500"`
+}
+
+// ToModel 轉換為換班申請模型，日期按服務所在時區解析
+func (r *CreateShiftSwapRequest) ToModel(requesterID uint) *models.ShiftSwapRequest {
+	date, _ := time.ParseInLocation(DateLayout, r.Date, time.Local)
+	swap := &models.ShiftSwapRequest{
+		RequesterID:   requesterID,
+		CounterpartID: r.CounterpartID,
+		Date:          date,
+		Reason:        r.Reason,
+		Status:        models.ShiftSwapPending,
+	}
+	if counterpartDate := parseOptionalDate(r.CounterpartDate); counterpartDate != nil {
+		swap.CounterpartDate = *counterpartDate
+	}
+	return swap
+}
+
+// RespondShiftSwapRequest 對方回覆換班申請的請求體
+type RespondShiftSwapRequest struct {
+	Accept *bool `json:"accept" binding:"required"`
+}
+
+// ScheduledResponse 員工在某一時間是否在班
+type ScheduledResponse struct {
+	EmployeeID uint                   `json:"employee_id"`
+	At         time.Time              `json:"at"`
+	Scheduled  bool                   `json:"scheduled"`
+	Shift      *models.ScheduledShift `json:"shift,omitempty"` // 正在進行的班次
+}
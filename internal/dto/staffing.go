@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"time"
+
+	"hr-system/internal/models"
+)
+
+// StaffingRuleRequest 新增/更新人力規則的請求體，department 與 manager_id 只能指定其一
+type StaffingRuleRequest struct {
+	Name           string  `json:"name" binding:"required,max=100"`
+	Department     string  `json:"department" binding:"max=50"`
+	ManagerID      *uint   `json:"manager_id" binding:"omitempty,gt=0"`
+	MinPresent     int     `json:"min_present" binding:"gte=0"`
+	MaxAbsentRatio float64 `json:"max_absent_ratio" binding:"gte=0,lte=1"`
+	StartDate      string  `json:"start_date" binding:"omitempty,date"`
+	EndDate        string  `json:"end_date" binding:"omitempty,date"`
+	Enforcement    string  `json:"enforcement" binding:"required,oneof=warn block"`
+	Active         *bool   `json:"active"`
+}
+
+// ToModel 轉換為人力規則模型，日期按服務所在時區解析，未指定 active 時默認啟用
+func (r *StaffingRuleRequest) ToModel() *models.StaffingRule {
+	active := true
+	if r.Active != nil {
+		active = *r.Active
+	}
+	return &models.StaffingRule{
+		Name:           r.Name,
+		Department:     r.Department,
+		ManagerID:      r.ManagerID,
+		MinPresent:     r.MinPresent,
+		MaxAbsentRatio: r.MaxAbsentRatio,
+		StartDate:      parseOptionalDate(r.StartDate),
+		EndDate:        parseOptionalDate(r.EndDate),
+		Enforcement:    r.Enforcement,
+		Active:         active,
+	}
+}
+
+// parseOptionalDate 解析已校驗的 YYYY-MM-DD 日期，為空時返回空
+func parseOptionalDate(value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+	date, _ := time.ParseInLocation(DateLayout, value, time.Local)
+	return &date
+}
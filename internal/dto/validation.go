@@ -0,0 +1,191 @@
+package dto
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/i18n"
+	"hr-system/internal/models"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// DateLayout 只有日期的字段與查詢參數使用的格式
+const DateLayout = "2006-01-02"
+
+// ClockLayout 班次上下班時間使用的格式
+const ClockLayout = "15:04"
+
+// MonthLayout 薪資月份使用的格式
+const MonthLayout = "2006-01"
+
+// maxLeaveSpan 單次請假的最長跨度
+const maxLeaveSpan = 366 * 24 * time.Hour
+
+// twPhonePattern 台灣電話號碼：手機（09xx-xxx-xxx）、市話（0x-xxxxxxxx，可帶分機），亦接受 +886 開頭
+var twPhonePattern = regexp.MustCompile(`^(?:0|\+886-?)(?:9\d{2}-?\d{3}-?\d{3}|[2-8]\d{0,2}-?\d{6,8}(?:#\d{1,6})?)$`)
+
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+
+	// 字段錯誤使用 JSON 字段名
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+
+	v.RegisterValidation("tw_phone", func(fl validator.FieldLevel) bool {
+		return twPhonePattern.MatchString(fl.Field().String())
+	})
+	v.RegisterValidation("not_future", func(fl validator.FieldLevel) bool {
+		t, ok := fl.Field().Interface().(time.Time)
+		return ok && !t.After(time.Now())
+	})
+	v.RegisterValidation("employee_status", func(fl validator.FieldLevel) bool {
+		return contains(models.EmployeeStatuses, fl.Field().String())
+	})
+	v.RegisterValidation("leave_type", func(fl validator.FieldLevel) bool {
+		return contains(models.LeaveTypes, fl.Field().String())
+	})
+	v.RegisterValidation("bulk_leave_type", func(fl validator.FieldLevel) bool {
+		return contains(models.BulkLeaveTypes, fl.Field().String())
+	})
+	v.RegisterValidation("webhook_event", func(fl validator.FieldLevel) bool {
+		value := fl.Field().String()
+		return value == models.EventAll || contains(models.EventTypes, value)
+	})
+	v.RegisterValidation("notification_event", func(fl validator.FieldLevel) bool {
+		return contains(models.NotificationEventTypes, fl.Field().String())
+	})
+	v.RegisterValidation("date", func(fl validator.FieldLevel) bool {
+		_, err := time.Parse(DateLayout, fl.Field().String())
+		return err == nil
+	})
+	v.RegisterValidation("clock", func(fl validator.FieldLevel) bool {
+		_, err := time.Parse(ClockLayout, fl.Field().String())
+		return err == nil
+	})
+	v.RegisterValidation("month", func(fl validator.FieldLevel) bool {
+		_, err := time.Parse(MonthLayout, fl.Field().String())
+		return err == nil
+	})
+	v.RegisterValidation("max_leave_span", func(fl validator.FieldLevel) bool {
+		end, ok := fl.Field().Interface().(time.Time)
+		if !ok {
+			return false
+		}
+		start, ok := fl.Parent().FieldByName("StartDate").Interface().(time.Time)
+		return ok && end.Sub(start) <= maxLeaveSpan
+	})
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// BindError 將請求綁定錯誤轉換為帶字段錯誤列表的校驗錯誤，字段訊息以指定語系生成
+func BindError(err error, locale string) error {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]apperrors.FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, apperrors.Field(fieldPath(fe), fe.Tag(), fieldMessage(locale, fe)))
+		}
+		return apperrors.Validation(apperrors.CodeValidationFailed, "Request validation failed", fields...)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		msg := i18n.Tf(locale, "validation.type", i18n.Vars{"field": fieldLabel(locale, typeErr.Field)})
+		return apperrors.Validation(apperrors.CodeValidationFailed, "Request validation failed",
+			apperrors.Field(typeErr.Field, "type", msg))
+	}
+
+	var timeErr *time.ParseError
+	if errors.As(err, &timeErr) {
+		return apperrors.Validation(apperrors.CodeInvalidDateTime, err.Error())
+	}
+
+	return apperrors.Validation(apperrors.CodeInvalidRequest, err.Error())
+}
+
+// fieldPath 返回字段在請求體中的路徑，如 preferences[0].email
+func fieldPath(fe validator.FieldError) string {
+	if _, path, ok := strings.Cut(fe.Namespace(), "."); ok {
+		return path
+	}
+	return fe.Field()
+}
+
+// fieldMessage 生成單個字段錯誤的本地化訊息
+func fieldMessage(locale string, fe validator.FieldError) string {
+	key := fe.Tag()
+	// 字串長度與數值大小使用不同的措辭
+	if (key == "max" || key == "min") && fe.Kind() == reflect.String {
+		key += "_len"
+	}
+
+	param := fe.Param()
+	switch fe.Tag() {
+	case "employee_status":
+		param = strings.Join(models.EmployeeStatuses, ", ")
+	case "leave_type":
+		param = strings.Join(models.LeaveTypes, ", ")
+	case "bulk_leave_type":
+		param = strings.Join(models.BulkLeaveTypes, ", ")
+	case "webhook_event":
+		param = strings.Join(append([]string{models.EventAll}, models.EventTypes...), ", ")
+	case "notification_event":
+		param = strings.Join(models.NotificationEventTypes, ", ")
+	case "oneof":
+		param = strings.Join(strings.Fields(param), ", ")
+	case "gtefield":
+		param = fieldLabel(locale, jsonFieldName(fe.Param()))
+	}
+
+	if _, ok := i18n.Lookup(locale, "validation."+key); !ok {
+		key = "invalid"
+	}
+	return i18n.Tf(locale, "validation."+key, i18n.Vars{"field": fieldLabel(locale, fe.Field()), "param": param})
+}
+
+// fieldLabel 返回字段的顯示名稱，數組元素（如 event_types[0]）使用數組字段的名稱
+func fieldLabel(locale, field string) string {
+	name, index, _ := strings.Cut(field, "[")
+	if index != "" {
+		return i18n.Label(locale, "field", name) + "[" + index
+	}
+	return i18n.Label(locale, "field", field)
+}
+
+// jsonFieldName 將結構體字段名（如 StartDate）轉為 JSON 字段名（start_date）
+func jsonFieldName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
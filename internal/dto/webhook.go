@@ -0,0 +1,33 @@
+package dto
+
+import "hr-system/internal/models"
+
+// WebhookSubscriptionRequest 新增/更新 Webhook 訂閱的請求體
+type WebhookSubscriptionRequest struct {
+	URL         string   `json:"url" binding:"required,url,max=500"`
+	EventTypes  []string `json:"event_types" binding:"omitempty,dive,webhook_event"`
+	Description string   `json:"description" binding:"max=200"`
+	Secret      string   `json:"secret" binding:"omitempty,min=16,max=100"`
+	Active      *bool    `json:"active"`
+}
+
+// ToModel 轉換為訂閱模型，未指定 active 時默認啟用
+func (r *WebhookSubscriptionRequest) ToModel() *models.WebhookSubscription {
+	active := true
+	if r.Active != nil {
+		active = *r.Active
+	}
+	return &models.WebhookSubscription{
+		URL:         r.URL,
+		EventTypes:  r.EventTypes,
+		Description: r.Description,
+		Secret:      r.Secret,
+		Active:      active,
+	}
+}
+
+// WebhookSubscriptionCreated 創建訂閱的響應，密鑰只在此時返回一次
+type WebhookSubscriptionCreated struct {
+	*models.WebhookSubscription
+	Secret string `json:"secret"`
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/dto"
+	"hr-system/internal/i18n"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+	"hr-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ApprovalServiceInterface 定義審批代理服務接口
+type ApprovalServiceInterface interface {
+	CreateDelegation(delegation *models.ApprovalDelegation) error
+	ListDelegations(managerID uint) ([]models.ApprovalDelegation, error)
+	DeleteDelegation(managerID, id uint) error
+	GetLeaveApprover(leaveID uint) (*services.Approver, error)
+	PendingApprovals(approverID uint) ([]models.Leave, error)
+}
+
+type ApprovalHandler struct {
+	approvalService ApprovalServiceInterface
+}
+
+func NewApprovalHandler(approvalService ApprovalServiceInterface) *ApprovalHandler {
+	return &ApprovalHandler{
+		approvalService: approvalService,
+	}
+}
+
+// CreateDelegation 主管指定期間內的審批代理人，路由須經過 RequireIdentity，只能為自己指定
+func (h *ApprovalHandler) CreateDelegation(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+	if middleware.GetEmployeeID(c) != uint(id) {
+		c.Error(errDelegationDenied())
+		return
+	}
+
+	var req dto.CreateDelegationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
+		return
+	}
+
+	delegation := req.ToModel(uint(id))
+	if err := h.approvalService.CreateDelegation(delegation); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, delegation)
+}
+
+// ListDelegations 獲取主管的審批代理
+func (h *ApprovalHandler) ListDelegations(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	delegations, err := h.approvalService.ListDelegations(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if delegations == nil {
+		delegations = []models.ApprovalDelegation{}
+	}
+	c.JSON(http.StatusOK, delegations)
+}
+
+// DeleteDelegation 刪除審批代理，路由須經過 RequireIdentity，只能刪除自己的代理
+func (h *ApprovalHandler) DeleteDelegation(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+	if middleware.GetEmployeeID(c) != uint(id) {
+		c.Error(errDelegationDenied())
+		return
+	}
+	delegationID, err := strconv.ParseUint(c.Param("delegation_id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	if err := h.approvalService.DeleteDelegation(uint(id), uint(delegationID)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(middleware.GetLocale(c), "message.delegation_deleted")})
+}
+
+// GetLeaveApprover 獲取請假當前的審批人，主管不在時為代理人或上一級主管
+func (h *ApprovalHandler) GetLeaveApprover(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	approver, err := h.approvalService.GetLeaveApprover(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, approver)
+}
+
+// ListMyApprovals 獲取當前員工可以審批的待審批請假，包括代理與代上級審批的
+func (h *ApprovalHandler) ListMyApprovals(c *gin.Context) {
+	leaves, err := h.approvalService.PendingApprovals(middleware.GetEmployeeID(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.NewLeaveResponses(leaves, middleware.GetLocale(c)))
+}
+
+func errDelegationDenied() error {
+	return apperrors.Forbidden(apperrors.CodeDelegationDenied, "You can only manage your own approval delegations")
+}
@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+	"hr-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockApprovalService 模擬審批代理服務
+type MockApprovalService struct {
+	mock.Mock
+}
+
+func (m *MockApprovalService) CreateDelegation(delegation *models.ApprovalDelegation) error {
+	args := m.Called(delegation)
+	return args.Error(0)
+}
+
+func (m *MockApprovalService) ListDelegations(managerID uint) ([]models.ApprovalDelegation, error) {
+	args := m.Called(managerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ApprovalDelegation), args.Error(1)
+}
+
+func (m *MockApprovalService) DeleteDelegation(managerID, id uint) error {
+	args := m.Called(managerID, id)
+	return args.Error(0)
+}
+
+func (m *MockApprovalService) GetLeaveApprover(leaveID uint) (*services.Approver, error) {
+	args := m.Called(leaveID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.Approver), args.Error(1)
+}
+
+func (m *MockApprovalService) PendingApprovals(approverID uint) ([]models.Leave, error) {
+	args := m.Called(approverID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Leave), args.Error(1)
+}
+
+// 確保 MockApprovalService 實現了 ApprovalServiceInterface
+var _ ApprovalServiceInterface = (*MockApprovalService)(nil)
+
+func setupApprovalTestRouter(handler *ApprovalHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.Locale(), middleware.ErrorHandler(), middleware.Identity())
+
+	r.POST("/api/employees/:id/delegations", middleware.RequireIdentity(), handler.CreateDelegation)
+	r.GET("/api/employees/:id/delegations", handler.ListDelegations)
+	r.DELETE("/api/employees/:id/delegations/:delegation_id", middleware.RequireIdentity(), handler.DeleteDelegation)
+	r.GET("/api/leaves/:id/approver", handler.GetLeaveApprover)
+	r.GET("/api/me/approvals", middleware.RequireIdentity(), handler.ListMyApprovals)
+	return r
+}
+
+func TestCreateDelegation(t *testing.T) {
+	mockService := &MockApprovalService{}
+	router := setupApprovalTestRouter(NewApprovalHandler(mockService))
+
+	tests := []struct {
+		name       string
+		body       string
+		caller     string
+		anonymous  bool
+		mockSetup  func()
+		wantStatus int
+		wantField  string
+		wantCode   string
+	}{
+		{
+			name: "成功指定代理人",
+			body: `{"delegate_id":3,"start_date":"2024-03-01T00:00:00+08:00","end_date":"2024-03-08T00:00:00+08:00","reason":"出差"}`,
+			mockSetup: func() {
+				mockService.On("CreateDelegation", mock.MatchedBy(func(d *models.ApprovalDelegation) bool {
+					return d.ManagerID == 2 && d.DelegateID == 3 && d.Reason == "出差"
+				})).Return(nil).Once()
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "缺少代理人",
+			body:       `{"start_date":"2024-03-01T00:00:00+08:00","end_date":"2024-03-08T00:00:00+08:00"}`,
+			mockSetup:  func() {},
+			wantStatus: http.StatusBadRequest,
+			wantField:  "delegate_id",
+		},
+		{
+			name:       "結束早於開始",
+			body:       `{"delegate_id":3,"start_date":"2024-03-08T00:00:00+08:00","end_date":"2024-03-01T00:00:00+08:00"}`,
+			mockSetup:  func() {},
+			wantStatus: http.StatusBadRequest,
+			wantField:  "end_date",
+		},
+		{
+			name: "代理期間重疊",
+			body: `{"delegate_id":4,"start_date":"2024-03-05T00:00:00+08:00","end_date":"2024-03-10T00:00:00+08:00"}`,
+			mockSetup: func() {
+				mockService.On("CreateDelegation", mock.MatchedBy(func(d *models.ApprovalDelegation) bool {
+					return d.DelegateID == 4
+				})).Return(apperrors.Conflict(apperrors.CodeDelegationOverlap, "The manager already has a delegation in this period")).Once()
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "為其他主管指定代理人",
+			body:       `{"delegate_id":3,"start_date":"2024-03-01T00:00:00+08:00","end_date":"2024-03-08T00:00:00+08:00"}`,
+			caller:     "3",
+			mockSetup:  func() {},
+			wantStatus: http.StatusForbidden,
+			wantCode:   apperrors.CodeDelegationDenied,
+		},
+		{
+			name:       "缺少員工身份",
+			body:       `{"delegate_id":3,"start_date":"2024-03-01T00:00:00+08:00","end_date":"2024-03-08T00:00:00+08:00"}`,
+			anonymous:  true,
+			mockSetup:  func() {},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   apperrors.CodeUnauthenticated,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/employees/2/delegations", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if caller := tt.caller; !tt.anonymous {
+				if caller == "" {
+					caller = "2"
+				}
+				req.Header.Set(middleware.EmployeeIDHeader, caller)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var resp middleware.ErrorResponse
+			if tt.wantField != "" {
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				if assert.Len(t, resp.Error.Fields, 1) {
+					assert.Equal(t, tt.wantField, resp.Error.Fields[0].Field)
+				}
+			}
+			if tt.wantCode != "" {
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, tt.wantCode, resp.Error.Code)
+			}
+		})
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestDeleteDelegation(t *testing.T) {
+	mockService := &MockApprovalService{}
+	router := setupApprovalTestRouter(NewApprovalHandler(mockService))
+
+	mockService.On("DeleteDelegation", uint(2), uint(5)).Return(nil)
+	mockService.On("DeleteDelegation", uint(2), uint(6)).Return(apperrors.NotFound(apperrors.CodeDelegationNotFound, "Delegation not found"))
+
+	tests := []struct {
+		name       string
+		path       string
+		caller     string
+		wantStatus int
+	}{
+		{name: "刪除自己的代理", path: "/api/employees/2/delegations/5", caller: "2", wantStatus: http.StatusOK},
+		{name: "代理不存在", path: "/api/employees/2/delegations/6", caller: "2", wantStatus: http.StatusNotFound},
+		{name: "無效的ID", path: "/api/employees/2/delegations/abc", caller: "2", wantStatus: http.StatusBadRequest},
+		{name: "刪除其他主管的代理", path: "/api/employees/2/delegations/5", caller: "3", wantStatus: http.StatusForbidden},
+		{name: "缺少員工身份", path: "/api/employees/2/delegations/5", wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodDelete, tt.path, nil)
+			if tt.caller != "" {
+				req.Header.Set(middleware.EmployeeIDHeader, tt.caller)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+	mockService.AssertNumberOfCalls(t, "DeleteDelegation", 2)
+}
+
+func TestGetLeaveApprover(t *testing.T) {
+	mockService := &MockApprovalService{}
+	router := setupApprovalTestRouter(NewApprovalHandler(mockService))
+
+	mockService.On("GetLeaveApprover", uint(1)).Return(&services.Approver{
+		ApproverID:         5,
+		OriginalApproverID: 2,
+		Reason:             services.ApproverReasonManagerOnLeave,
+	}, nil)
+	mockService.On("GetLeaveApprover", uint(2)).Return(nil, apperrors.NotFound(apperrors.CodeNoApprover, "Employee has no approver"))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/leaves/1/approver", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var approver services.Approver
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &approver))
+	assert.Equal(t, uint(5), approver.ApproverID)
+	assert.Equal(t, uint(2), approver.OriginalApproverID)
+	assert.Equal(t, services.ApproverReasonManagerOnLeave, approver.Reason)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/leaves/2/approver", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestListMyApprovals(t *testing.T) {
+	mockService := &MockApprovalService{}
+	router := setupApprovalTestRouter(NewApprovalHandler(mockService))
+
+	mockService.On("PendingApprovals", uint(3)).Return([]models.Leave{
+		{EmployeeID: 1, LeaveType: models.LeaveTypeAnnual, Status: models.LeaveStatusPending,
+			StartDate: time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC), EndDate: time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC)},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/me/approvals", nil)
+	req.Header.Set("X-Employee-ID", "3")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var leaves []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &leaves))
+	assert.Len(t, leaves, 1)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/me/approvals", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockService.AssertExpectations(t)
+}
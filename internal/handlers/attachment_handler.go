@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/i18n"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+	"hr-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead 上傳請求中表單邊界與其他字段允許佔用的額外字節數
+const multipartOverhead = 1 << 20
+
+// AttachmentServiceInterface 定義請假附件服務接口
+type AttachmentServiceInterface interface {
+	Upload(leaveID, uploaderID uint, upload services.AttachmentUpload) (*models.LeaveAttachment, error)
+	List(leaveID, requesterID uint) ([]models.LeaveAttachment, error)
+	Open(leaveID, id, requesterID uint) (*models.LeaveAttachment, io.ReadCloser, error)
+	Delete(leaveID, id, requesterID uint) error
+}
+
+type AttachmentHandler struct {
+	attachmentService AttachmentServiceInterface
+	maxSize           int64 // 單個附件的大小上限，超出的請求不會被完整讀取
+}
+
+func NewAttachmentHandler(attachmentService AttachmentServiceInterface, maxSize int64) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentService: attachmentService,
+		maxSize:           maxSize,
+	}
+}
+
+// UploadAttachment 以 multipart/form-data 的 file 字段為請假上傳附件
+func (h *AttachmentHandler) UploadAttachment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxSize+multipartOverhead)
+	file, err := c.FormFile("file")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		msg := fmt.Sprintf("File must not exceed %d bytes", h.maxSize)
+		c.Error(apperrors.Validation(apperrors.CodeAttachmentTooLarge, msg,
+			apperrors.Field("file", apperrors.CodeAttachmentTooLarge, msg)))
+		return
+	}
+	if err != nil {
+		c.Error(apperrors.Validation(apperrors.CodeValidationFailed, "File is required",
+			apperrors.Field("file", "required", fieldMessage(c, "required", "file", ""))))
+		return
+	}
+	content, err := file.Open()
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	defer content.Close()
+
+	attachment, err := h.attachmentService.Upload(uint(id), middleware.GetEmployeeID(c), services.AttachmentUpload{
+		FileName: file.Filename,
+		Size:     file.Size,
+		Content:  content,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, attachment)
+}
+
+// ListAttachments 獲取請假的附件
+func (h *AttachmentHandler) ListAttachments(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	attachments, err := h.attachmentService.List(uint(id), middleware.GetEmployeeID(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if attachments == nil {
+		attachments = []models.LeaveAttachment{}
+	}
+	c.JSON(http.StatusOK, attachments)
+}
+
+// DownloadAttachment 下載請假附件，一律以附件形式下載，不在瀏覽器中直接打開
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+	attachmentID, err := strconv.ParseUint(c.Param("attachment_id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	attachment, content, err := h.attachmentService.Open(uint(id), uint(attachmentID), middleware.GetEmployeeID(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+		"Cache-Control":          "private, no-store",
+	})
+}
+
+// DeleteAttachment 刪除待審批請假的附件
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+	attachmentID, err := strconv.ParseUint(c.Param("attachment_id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	if err := h.attachmentService.Delete(uint(id), uint(attachmentID), middleware.GetEmployeeID(c)); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(middleware.GetLocale(c), "message.attachment_deleted")})
+}
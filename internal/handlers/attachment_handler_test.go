@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+	"hr-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAttachmentService 模擬請假附件服務
+type MockAttachmentService struct {
+	mock.Mock
+}
+
+func (m *MockAttachmentService) Upload(leaveID, uploaderID uint, upload services.AttachmentUpload) (*models.LeaveAttachment, error) {
+	args := m.Called(leaveID, uploaderID, upload)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.LeaveAttachment), args.Error(1)
+}
+
+func (m *MockAttachmentService) List(leaveID, requesterID uint) ([]models.LeaveAttachment, error) {
+	args := m.Called(leaveID, requesterID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.LeaveAttachment), args.Error(1)
+}
+
+func (m *MockAttachmentService) Open(leaveID, id, requesterID uint) (*models.LeaveAttachment, io.ReadCloser, error) {
+	args := m.Called(leaveID, id, requesterID)
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).(*models.LeaveAttachment), args.Get(1).(io.ReadCloser), args.Error(2)
+}
+
+func (m *MockAttachmentService) Delete(leaveID, id, requesterID uint) error {
+	args := m.Called(leaveID, id, requesterID)
+	return args.Error(0)
+}
+
+// 確保 MockAttachmentService 實現了 AttachmentServiceInterface
+var _ AttachmentServiceInterface = (*MockAttachmentService)(nil)
+
+func setupAttachmentTestRouter(handler *AttachmentHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.Locale(), middleware.ErrorHandler(), middleware.Identity())
+
+	r.POST("/api/leaves/:id/attachments", middleware.RequireIdentity(), handler.UploadAttachment)
+	r.GET("/api/leaves/:id/attachments", middleware.RequireIdentity(), handler.ListAttachments)
+	r.GET("/api/leaves/:id/attachments/:attachment_id", middleware.RequireIdentity(), handler.DownloadAttachment)
+	r.DELETE("/api/leaves/:id/attachments/:attachment_id", middleware.RequireIdentity(), handler.DeleteAttachment)
+	return r
+}
+
+// newUploadRequest 創建以 multipart/form-data 上傳 content 的請求，field 為空時不附帶文件
+func newUploadRequest(t *testing.T, url, field, fileName, content string) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if field != "" {
+		part, err := writer.CreateFormFile(field, fileName)
+		assert.NoError(t, err)
+		part.Write([]byte(content))
+	}
+	writer.Close()
+	req := httptest.NewRequest(http.MethodPost, url, &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestUploadAttachment(t *testing.T) {
+	mockService := &MockAttachmentService{}
+	router := setupAttachmentTestRouter(NewAttachmentHandler(mockService, 1024))
+
+	mockService.On("Upload", uint(1), uint(3), mock.MatchedBy(func(upload services.AttachmentUpload) bool {
+		content, _ := io.ReadAll(upload.Content)
+		return upload.FileName == "診斷證明.pdf" && upload.Size == 8 && string(content) == "%PDF-1.4"
+	})).Return(&models.LeaveAttachment{ID: 5, LeaveID: 1, FileName: "診斷證明.pdf", ContentType: "application/pdf", Size: 8, StorageKey: "leaves/1/abc.pdf"}, nil).Once()
+	mockService.On("Upload", uint(2), uint(3), mock.Anything).Return(nil,
+		apperrors.Validation(apperrors.CodeAttachmentTypeNotAllowed, "File type is not allowed")).Once()
+
+	req := newUploadRequest(t, "/api/leaves/1/attachments", "file", "診斷證明.pdf", "%PDF-1.4")
+	req.Header.Set(middleware.EmployeeIDHeader, "3")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"content_type":"application/pdf"`)
+	assert.NotContains(t, w.Body.String(), "leaves/1/abc.pdf")
+
+	tests := []struct {
+		name       string
+		url        string
+		field      string
+		content    string
+		employee   string
+		wantStatus int
+		wantCode   string
+	}{
+		{name: "不支持的類型", url: "/api/leaves/2/attachments", field: "file", content: "MZ", employee: "3", wantStatus: http.StatusBadRequest, wantCode: apperrors.CodeAttachmentTypeNotAllowed},
+		{name: "缺少文件", url: "/api/leaves/1/attachments", employee: "3", wantStatus: http.StatusBadRequest, wantCode: apperrors.CodeValidationFailed},
+		{name: "文件過大", url: "/api/leaves/1/attachments", field: "file", content: strings.Repeat("x", 2<<20), employee: "3", wantStatus: http.StatusBadRequest, wantCode: apperrors.CodeAttachmentTooLarge},
+		{name: "未識別身份", url: "/api/leaves/1/attachments", field: "file", content: "%PDF-1.4", wantStatus: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newUploadRequest(t, tt.url, tt.field, "a.bin", tt.content)
+			if tt.employee != "" {
+				req.Header.Set(middleware.EmployeeIDHeader, tt.employee)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantCode != "" {
+				var resp middleware.ErrorResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, tt.wantCode, resp.Error.Code)
+			}
+		})
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestDownloadAttachment(t *testing.T) {
+	mockService := &MockAttachmentService{}
+	router := setupAttachmentTestRouter(NewAttachmentHandler(mockService, 1024))
+
+	mockService.On("Open", uint(1), uint(5), uint(2)).Return(
+		&models.LeaveAttachment{ID: 5, LeaveID: 1, FileName: "診斷證明.pdf", ContentType: "application/pdf", Size: 8},
+		io.NopCloser(strings.NewReader("%PDF-1.4")), nil).Once()
+	mockService.On("Open", uint(1), uint(5), uint(9)).Return(nil, nil,
+		apperrors.Forbidden(apperrors.CodeAttachmentAccessDenied, "You are not allowed to access attachments of this leave")).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/leaves/1/attachments/5", nil)
+	req.Header.Set(middleware.EmployeeIDHeader, "2")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+	assert.Equal(t, "attachment; filename*=utf-8''%E8%A8%BA%E6%96%B7%E8%AD%89%E6%98%8E.pdf", w.Header().Get("Content-Disposition"))
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "%PDF-1.4", w.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/api/leaves/1/attachments/5", nil)
+	req.Header.Set(middleware.EmployeeIDHeader, "9")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/leaves/1/attachments/abc", nil)
+	req.Header.Set(middleware.EmployeeIDHeader, "2")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestDeleteAttachment(t *testing.T) {
+	mockService := &MockAttachmentService{}
+	router := setupAttachmentTestRouter(NewAttachmentHandler(mockService, 1024))
+
+	mockService.On("Delete", uint(1), uint(5), uint(3)).Return(nil).Once()
+	mockService.On("Delete", uint(2), uint(6), uint(3)).Return(
+		apperrors.Conflict(apperrors.CodeAttachmentLeaveClosed, "Attachments can only be removed while the leave is pending")).Once()
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/leaves/1/attachments/5", nil)
+	req.Header.Set(middleware.EmployeeIDHeader, "3")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/leaves/2/attachments/6", nil)
+	req.Header.Set(middleware.EmployeeIDHeader, "3")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockService.AssertExpectations(t)
+}
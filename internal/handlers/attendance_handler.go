@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/dto"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AttendanceServiceInterface 定義考勤服務接口
+type AttendanceServiceInterface interface {
+	Punch(punch *models.AttendancePunch) error
+	EmployeeAttendance(employeeID uint, from, to time.Time) ([]models.AttendanceDay, error)
+	Anomalies(date time.Time, department string) ([]models.AttendanceDay, error)
+	CreateCorrection(correction *models.AttendanceCorrection) error
+	ListCorrections(employeeID uint, status string) ([]models.AttendanceCorrection, error)
+	GetCorrection(id uint) (*models.AttendanceCorrection, error)
+	UpdateCorrectionStatus(id uint, status, remark string, approverID uint) (*models.AttendanceCorrection, error)
+}
+
+type AttendanceHandler struct {
+	attendanceService AttendanceServiceInterface
+}
+
+func NewAttendanceHandler(attendanceService AttendanceServiceInterface) *AttendanceHandler {
+	return &AttendanceHandler{
+		attendanceService: attendanceService,
+	}
+}
+
+// Punch 當前員工打卡，記錄請求的來源IP
+func (h *AttendanceHandler) Punch(c *gin.Context) {
+	var req dto.PunchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
+		return
+	}
+
+	punch := req.ToModel(middleware.GetEmployeeID(c), c.ClientIP())
+	if err := h.attendanceService.Punch(punch); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, punch)
+}
+
+// ImportPunch 上傳打卡機等設備的打卡記錄
+func (h *AttendanceHandler) ImportPunch(c *gin.Context) {
+	var req dto.DevicePunchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
+		return
+	}
+
+	punch := req.ToModel()
+	if err := h.attendanceService.Punch(punch); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, punch)
+}
+
+// GetEmployeeAttendance 獲取員工每天的考勤摘要，期間（from/to，包含兩端）默認為本月初至今天
+func (h *AttendanceHandler) GetEmployeeAttendance(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	from, err := queryDate(c, "from", today.AddDate(0, 0, 1-today.Day()))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	to, err := queryDate(c, "to", today)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	days, err := h.attendanceService.EmployeeAttendance(uint(id), from, to)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, days)
+}
+
+// ListAnomalies 獲取某天（date，默認今天）有考勤異常的員工，可按部門（department）篩選
+func (h *AttendanceHandler) ListAnomalies(c *gin.Context) {
+	now := time.Now()
+	date, err := queryDate(c, "date", time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	days, err := h.attendanceService.Anomalies(date, c.Query("department"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, days)
+}
+
+// CreateCorrection 當前員工提交補卡申請
+func (h *AttendanceHandler) CreateCorrection(c *gin.Context) {
+	var req dto.CreateCorrectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
+		return
+	}
+
+	correction := req.ToModel(middleware.GetEmployeeID(c))
+	if err := h.attendanceService.CreateCorrection(correction); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, correction)
+}
+
+// ListCorrections 獲取補卡申請，可按員工（employee_id）與狀態（status）篩選
+func (h *AttendanceHandler) ListCorrections(c *gin.Context) {
+	var employeeID uint
+	if raw := c.Query("employee_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil || id == 0 {
+			c.Error(apperrors.Validation(apperrors.CodeValidationFailed, "Invalid employee ID",
+				apperrors.Field("employee_id", "gt", fieldMessage(c, "gt", "employee_id", "0"))))
+			return
+		}
+		employeeID = uint(id)
+	}
+	status := c.Query("status")
+	if status != "" && !containsString(models.LeaveStatuses, status) {
+		statuses := strings.Join(models.LeaveStatuses, ", ")
+		c.Error(apperrors.Validation(apperrors.CodeValidationFailed, "Invalid status",
+			apperrors.Field("status", "oneof", fieldMessage(c, "oneof", "status", statuses))))
+		return
+	}
+
+	corrections, err := h.attendanceService.ListCorrections(employeeID, status)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if corrections == nil {
+		corrections = []models.AttendanceCorrection{}
+	}
+	c.JSON(http.StatusOK, corrections)
+}
+
+// GetCorrection 獲取補卡申請
+func (h *AttendanceHandler) GetCorrection(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	correction, err := h.attendanceService.GetCorrection(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, correction)
+}
+
+// UpdateCorrectionStatus 審批補卡申請，請求體與審批請假相同
+func (h *AttendanceHandler) UpdateCorrectionStatus(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	var req dto.UpdateLeaveStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
+		return
+	}
+
+	correction, err := h.attendanceService.UpdateCorrectionStatus(uint(id), req.Status, req.Remark, middleware.GetEmployeeID(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, correction)
+}
@@ -0,0 +1,303 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockAttendanceService 模擬考勤服務
+type MockAttendanceService struct {
+	mock.Mock
+}
+
+func (m *MockAttendanceService) Punch(punch *models.AttendancePunch) error {
+	args := m.Called(punch)
+	return args.Error(0)
+}
+
+func (m *MockAttendanceService) EmployeeAttendance(employeeID uint, from, to time.Time) ([]models.AttendanceDay, error) {
+	args := m.Called(employeeID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AttendanceDay), args.Error(1)
+}
+
+func (m *MockAttendanceService) Anomalies(date time.Time, department string) ([]models.AttendanceDay, error) {
+	args := m.Called(date, department)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AttendanceDay), args.Error(1)
+}
+
+func (m *MockAttendanceService) CreateCorrection(correction *models.AttendanceCorrection) error {
+	args := m.Called(correction)
+	return args.Error(0)
+}
+
+func (m *MockAttendanceService) ListCorrections(employeeID uint, status string) ([]models.AttendanceCorrection, error) {
+	args := m.Called(employeeID, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.AttendanceCorrection), args.Error(1)
+}
+
+func (m *MockAttendanceService) GetCorrection(id uint) (*models.AttendanceCorrection, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AttendanceCorrection), args.Error(1)
+}
+
+func (m *MockAttendanceService) UpdateCorrectionStatus(id uint, status, remark string, approverID uint) (*models.AttendanceCorrection, error) {
+	args := m.Called(id, status, remark, approverID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.AttendanceCorrection), args.Error(1)
+}
+
+// 確保 MockAttendanceService 實現了 AttendanceServiceInterface
+var _ AttendanceServiceInterface = (*MockAttendanceService)(nil)
+
+func setupAttendanceTestRouter(handler *AttendanceHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.Locale(), middleware.ErrorHandler(), middleware.Identity())
+
+	r.POST("/api/attendance/punches", middleware.RequireIdentity(), handler.Punch)
+	r.POST("/api/admin/attendance/punches", handler.ImportPunch)
+	r.GET("/api/employees/:id/attendance", handler.GetEmployeeAttendance)
+	r.GET("/api/attendance/anomalies", handler.ListAnomalies)
+	r.POST("/api/attendance/corrections", middleware.RequireIdentity(), handler.CreateCorrection)
+	r.GET("/api/attendance/corrections", handler.ListCorrections)
+	r.GET("/api/attendance/corrections/:id", handler.GetCorrection)
+	r.PUT("/api/attendance/corrections/:id/status", middleware.RequireIdentity(), handler.UpdateCorrectionStatus)
+	return r
+}
+
+func TestPunch(t *testing.T) {
+	mockService := &MockAttendanceService{}
+	router := setupAttendanceTestRouter(NewAttendanceHandler(mockService))
+
+	mockService.On("Punch", mock.MatchedBy(func(punch *models.AttendancePunch) bool {
+		return punch.EmployeeID == 3 && punch.Kind == models.PunchClockIn &&
+			punch.Source == models.PunchSourceWeb && punch.IP != "" && punch.Time.IsZero()
+	})).Return(nil).Once()
+
+	tests := []struct {
+		name       string
+		employeeID string
+		body       string
+		wantStatus int
+	}{
+		{name: "成功打卡", employeeID: "3", body: `{"kind":"clock_in"}`, wantStatus: http.StatusCreated},
+		{name: "未識別身份", body: `{"kind":"clock_in"}`, wantStatus: http.StatusUnauthorized},
+		{name: "無效的打卡類型", employeeID: "3", body: `{"kind":"lunch"}`, wantStatus: http.StatusBadRequest},
+		{name: "不能自選設備來源", employeeID: "3", body: `{"kind":"clock_out","source":"device"}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/attendance/punches", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.employeeID != "" {
+				req.Header.Set(middleware.EmployeeIDHeader, tt.employeeID)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestImportPunch(t *testing.T) {
+	mockService := &MockAttendanceService{}
+	router := setupAttendanceTestRouter(NewAttendanceHandler(mockService))
+
+	mockService.On("Punch", mock.MatchedBy(func(punch *models.AttendancePunch) bool {
+		return punch.EmployeeID == 3 && punch.Source == models.PunchSourceDevice && punch.Location == "1F 大門"
+	})).Return(nil).Once()
+	mockService.On("Punch", mock.MatchedBy(func(punch *models.AttendancePunch) bool {
+		return punch.EmployeeID == 4
+	})).Return(apperrors.Validation(apperrors.CodeInvalidPunchTime, "Punch time must not be in the future")).Once()
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "成功上傳", body: `{"employee_id":3,"kind":"clock_in","time":"2024-06-03T08:55:00+08:00","location":"1F 大門","ip":"10.0.0.8"}`, wantStatus: http.StatusCreated},
+		{name: "時間晚於當前時間", body: `{"employee_id":4,"kind":"clock_in","time":"2099-06-03T08:55:00+08:00"}`, wantStatus: http.StatusBadRequest},
+		{name: "缺少時間", body: `{"employee_id":3,"kind":"clock_in"}`, wantStatus: http.StatusBadRequest},
+		{name: "無效的IP", body: `{"employee_id":3,"kind":"clock_in","time":"2024-06-03T08:55:00+08:00","ip":"gate-1"}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/attendance/punches", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestGetEmployeeAttendance(t *testing.T) {
+	mockService := &MockAttendanceService{}
+	router := setupAttendanceTestRouter(NewAttendanceHandler(mockService))
+
+	from := time.Date(2024, time.June, 3, 0, 0, 0, 0, time.Local)
+	to := time.Date(2024, time.June, 4, 0, 0, 0, 0, time.Local)
+	mockService.On("EmployeeAttendance", uint(3), from, to).Return([]models.AttendanceDay{
+		{EmployeeID: 3, Date: "2024-06-03", WorkDay: true, Anomalies: []string{models.AttendanceLate}},
+		{EmployeeID: 3, Date: "2024-06-04", WorkDay: true, Anomalies: []string{models.AttendanceAbsent}},
+	}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/employees/3/attendance?from=2024-06-03&to=2024-06-04", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var days []models.AttendanceDay
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &days))
+	assert.Len(t, days, 2)
+	assert.Equal(t, []string{models.AttendanceAbsent}, days[1].Anomalies)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/employees/3/attendance?from=2024/06/03", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestListAttendanceAnomalies(t *testing.T) {
+	mockService := &MockAttendanceService{}
+	router := setupAttendanceTestRouter(NewAttendanceHandler(mockService))
+
+	date := time.Date(2024, time.June, 3, 0, 0, 0, 0, time.Local)
+	mockService.On("Anomalies", date, "研發部").Return([]models.AttendanceDay{
+		{EmployeeID: 3, Date: "2024-06-03", WorkDay: true, Anomalies: []string{models.AttendanceMissingClockOut}},
+	}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/attendance/anomalies?date=2024-06-03&department=研發部", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), models.AttendanceMissingClockOut)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateCorrection(t *testing.T) {
+	mockService := &MockAttendanceService{}
+	router := setupAttendanceTestRouter(NewAttendanceHandler(mockService))
+
+	mockService.On("CreateCorrection", mock.MatchedBy(func(correction *models.AttendanceCorrection) bool {
+		return correction.EmployeeID == 3 && correction.Kind == models.PunchClockOut &&
+			correction.Status == models.LeaveStatusPending
+	})).Run(func(args mock.Arguments) {
+		args.Get(0).(*models.AttendanceCorrection).ID = 7
+	}).Return(nil).Once()
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "成功提交", body: `{"kind":"clock_out","time":"2024-06-03T18:05:00+08:00","reason":"忘記打卡"}`, wantStatus: http.StatusCreated},
+		{name: "缺少原因", body: `{"kind":"clock_out","time":"2024-06-03T18:05:00+08:00"}`, wantStatus: http.StatusBadRequest},
+		{name: "時間晚於當前時間", body: `{"kind":"clock_out","time":"2099-06-03T18:05:00+08:00","reason":"忘記打卡"}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/attendance/corrections", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(middleware.EmployeeIDHeader, "3")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestListCorrections(t *testing.T) {
+	mockService := &MockAttendanceService{}
+	router := setupAttendanceTestRouter(NewAttendanceHandler(mockService))
+
+	mockService.On("ListCorrections", uint(3), models.LeaveStatusPending).Return(nil, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/attendance/corrections?employee_id=3&status=pending", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "[]", w.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/api/attendance/corrections?employee_id=abc", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestUpdateCorrectionStatus(t *testing.T) {
+	mockService := &MockAttendanceService{}
+	router := setupAttendanceTestRouter(NewAttendanceHandler(mockService))
+
+	approverID := uint(9)
+	mockService.On("UpdateCorrectionStatus", uint(7), "approved", "", approverID).Return(&models.AttendanceCorrection{
+		EmployeeID: 3,
+		Kind:       models.PunchClockOut,
+		Status:     models.LeaveStatusApproved,
+		ApproverID: &approverID,
+	}, nil).Once()
+	mockService.On("UpdateCorrectionStatus", uint(8), "rejected", "時間不符", approverID).Return(nil,
+		apperrors.Conflict(apperrors.CodeCorrectionNotPending, "Only pending attendance corrections can be approved or rejected")).Once()
+	mockService.On("GetCorrection", uint(99)).Return(nil,
+		apperrors.NotFound(apperrors.CodeCorrectionNotFound, "Attendance correction not found")).Once()
+
+	req := httptest.NewRequest(http.MethodPut, "/api/attendance/corrections/7/status", bytes.NewBufferString(`{"status":"approved"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.EmployeeIDHeader, "9")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req = httptest.NewRequest(http.MethodPut, "/api/attendance/corrections/8/status", bytes.NewBufferString(`{"status":"rejected","remark":"時間不符"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.EmployeeIDHeader, "9")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var resp middleware.ErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, apperrors.CodeCorrectionNotPending, resp.Error.Code)
+
+	// 缺少審批人身份時不調用服務
+	req = httptest.NewRequest(http.MethodPut, "/api/attendance/corrections/7/status", bytes.NewBufferString(`{"status":"approved"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/attendance/corrections/99", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
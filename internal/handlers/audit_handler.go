@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAuditLogLimit = 100
+	maxAuditLogLimit     = 500
+)
+
+// AuditServiceInterface 定義審計記錄服務接口
+type AuditServiceInterface interface {
+	ListAuditLogs(entityType string, entityID uint, limit int) ([]models.AuditLog, error)
+}
+
+type AuditHandler struct {
+	auditService AuditServiceInterface
+}
+
+func NewAuditHandler(auditService AuditServiceInterface) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// ListAuditLogs 查詢審計記錄，可按 entity_type 與 entity_id 篩選
+func (h *AuditHandler) ListAuditLogs(c *gin.Context) {
+	var entityID uint64
+	if raw := c.Query("entity_id"); raw != "" {
+		var err error
+		entityID, err = strconv.ParseUint(raw, 10, 32)
+		if err != nil || entityID == 0 {
+			c.Error(apperrors.Validation(apperrors.CodeValidationFailed, "Invalid entity ID",
+				apperrors.Field("entity_id", "gt", fieldMessage(c, "gt", "entity_id", "0"))))
+			return
+		}
+	}
+
+	limit, err := queryLimit(c, defaultAuditLogLimit, maxAuditLogLimit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	logs, err := h.auditService.ListAuditLogs(c.Query("entity_type"), uint(entityID), limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if logs == nil {
+		logs = []models.AuditLog{}
+	}
+	c.JSON(http.StatusOK, logs)
+}
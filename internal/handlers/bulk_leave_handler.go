@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"hr-system/internal/dto"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BulkLeaveServiceInterface 定義批量請假服務接口
+type BulkLeaveServiceInterface interface {
+	Create(job *models.BulkLeaveJob, employeeIDs []uint) error
+	GetJob(id uint) (*models.BulkLeaveJob, error)
+	ListJobs() ([]models.BulkLeaveJob, error)
+}
+
+type BulkLeaveHandler struct {
+	bulkLeaveService BulkLeaveServiceInterface
+}
+
+func NewBulkLeaveHandler(bulkLeaveService BulkLeaveServiceInterface) *BulkLeaveHandler {
+	return &BulkLeaveHandler{
+		bulkLeaveService: bulkLeaveService,
+	}
+}
+
+// CreateBulkLeave 為一批員工創建已核准的請假，返回任務及每個員工的處理結果
+// 因重疊請假中止的任務同樣返回 201，以 status 區分
+func (h *BulkLeaveHandler) CreateBulkLeave(c *gin.Context) {
+	var req dto.CreateBulkLeaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
+		return
+	}
+
+	job := req.ToModel()
+	job.CreatedByID = currentEmployee(c)
+	if err := h.bulkLeaveService.Create(job, req.EmployeeIDs); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, job)
+}
+
+// ListBulkLeaves 獲取所有批量請假任務
+func (h *BulkLeaveHandler) ListBulkLeaves(c *gin.Context) {
+	jobs, err := h.bulkLeaveService.ListJobs()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if jobs == nil {
+		jobs = []models.BulkLeaveJob{}
+	}
+	c.JSON(http.StatusOK, jobs)
+}
+
+// GetBulkLeave 獲取批量請假任務及每個員工的處理結果
+func (h *BulkLeaveHandler) GetBulkLeave(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	job, err := h.bulkLeaveService.GetJob(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockBulkLeaveService 模擬批量請假服務
+type MockBulkLeaveService struct {
+	mock.Mock
+}
+
+func (m *MockBulkLeaveService) Create(job *models.BulkLeaveJob, employeeIDs []uint) error {
+	args := m.Called(job, employeeIDs)
+	return args.Error(0)
+}
+
+func (m *MockBulkLeaveService) GetJob(id uint) (*models.BulkLeaveJob, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BulkLeaveJob), args.Error(1)
+}
+
+func (m *MockBulkLeaveService) ListJobs() ([]models.BulkLeaveJob, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.BulkLeaveJob), args.Error(1)
+}
+
+// 確保 MockBulkLeaveService 實現了 BulkLeaveServiceInterface
+var _ BulkLeaveServiceInterface = (*MockBulkLeaveService)(nil)
+
+func setupBulkLeaveTestRouter(handler *BulkLeaveHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.Locale(), middleware.ErrorHandler(), middleware.Identity())
+
+	r.POST("/api/admin/bulk-leaves", handler.CreateBulkLeave)
+	r.GET("/api/admin/bulk-leaves", handler.ListBulkLeaves)
+	r.GET("/api/admin/bulk-leaves/:id", handler.GetBulkLeave)
+	return r
+}
+
+func TestCreateBulkLeave(t *testing.T) {
+	mockService := &MockBulkLeaveService{}
+	router := setupBulkLeaveTestRouter(NewBulkLeaveHandler(mockService))
+
+	mockService.On("Create", mock.MatchedBy(func(job *models.BulkLeaveJob) bool {
+		return job.Target == models.BulkLeaveTargetEmployees && job.LeaveType == models.LeaveTypeClosure &&
+			job.CreatedByID != nil && *job.CreatedByID == 9
+	}), []uint{1, 2, 3}).Run(func(args mock.Arguments) {
+		job := args.Get(0).(*models.BulkLeaveJob)
+		leaveID := uint(100)
+		job.ID = 7
+		job.Status = models.BulkLeaveJobCompleted
+		job.CreatedCount, job.SkippedCount = 1, 2
+		job.Items = []models.BulkLeaveItem{
+			{JobID: 7, EmployeeID: 1, Outcome: models.BulkLeaveOutcomeCreated, LeaveID: &leaveID},
+			{JobID: 7, EmployeeID: 2, Outcome: models.BulkLeaveOutcomeConflict, ConflictLeaveIDs: []uint{42}},
+			{JobID: 7, EmployeeID: 3, Outcome: models.BulkLeaveOutcomeNotFound},
+		}
+	}).Return(nil).Once()
+	mockService.On("Create", mock.MatchedBy(func(job *models.BulkLeaveJob) bool {
+		return job.Target == models.BulkLeaveTargetDepartment && job.Department == ""
+	}), []uint(nil)).Return(apperrors.Validation(apperrors.CodeInvalidBulkLeaveTarget, "Department target needs a department")).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/bulk-leaves", bytes.NewBufferString(
+		`{"target":"employees","employee_ids":[1,2,3],"start_date":"2024-07-25T00:00:00+08:00","end_date":"2024-07-25T00:00:00+08:00","leave_type":"停班","reason":"颱風停班"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.EmployeeIDHeader, "9")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var job models.BulkLeaveJob
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+	assert.Equal(t, 1, job.CreatedCount)
+	if assert.Len(t, job.Items, 3) {
+		assert.Equal(t, []uint{42}, job.Items[1].ConflictLeaveIDs)
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "部門未指定", body: `{"target":"department","start_date":"2024-07-25T00:00:00+08:00","end_date":"2024-07-25T00:00:00+08:00","leave_type":"停班"}`, wantStatus: http.StatusBadRequest},
+		{name: "無效的對象", body: `{"target":"team","start_date":"2024-07-25T00:00:00+08:00","end_date":"2024-07-25T00:00:00+08:00","leave_type":"停班"}`, wantStatus: http.StatusBadRequest},
+		{name: "無效的請假類型", body: `{"target":"all","start_date":"2024-07-25T00:00:00+08:00","end_date":"2024-07-25T00:00:00+08:00","leave_type":"颱風假"}`, wantStatus: http.StatusBadRequest},
+		{name: "無效的重疊處理方式", body: `{"target":"all","start_date":"2024-07-25T00:00:00+08:00","end_date":"2024-07-25T00:00:00+08:00","leave_type":"停班","conflict_policy":"replace"}`, wantStatus: http.StatusBadRequest},
+		{name: "結束日期早於開始日期", body: `{"target":"all","start_date":"2024-07-25T00:00:00+08:00","end_date":"2024-07-24T00:00:00+08:00","leave_type":"停班"}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/bulk-leaves", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestGetBulkLeave(t *testing.T) {
+	mockService := &MockBulkLeaveService{}
+	router := setupBulkLeaveTestRouter(NewBulkLeaveHandler(mockService))
+
+	mockService.On("GetJob", uint(7)).Return(&models.BulkLeaveJob{ID: 7, Status: models.BulkLeaveJobAborted}, nil).Once()
+	mockService.On("GetJob", uint(8)).Return(nil, apperrors.NotFound(apperrors.CodeBulkLeaveJobNotFound, "Bulk leave job not found")).Once()
+	mockService.On("ListJobs").Return(nil, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/bulk-leaves/7", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"aborted"`)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/admin/bulk-leaves/8", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	var resp middleware.ErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, apperrors.CodeBulkLeaveJobNotFound, resp.Error.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/admin/bulk-leaves", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "[]", w.Body.String())
+	mockService.AssertExpectations(t)
+}
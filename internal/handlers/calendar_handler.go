@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/dto"
+	"hr-system/internal/i18n"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+	"hr-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultCalendarDays 未指定結束日期時行事曆顯示的天數
+const defaultCalendarDays = 7
+
+// CalendarServiceInterface 定義行事曆服務接口
+type CalendarServiceInterface interface {
+	TeamCalendar(query services.CalendarQuery) ([]services.CalendarDay, error)
+	CreateFeed(ownerID uint, scope string) (*services.CalendarFeedSubscription, error)
+	ListFeeds(ownerID uint) ([]models.CalendarFeed, error)
+	DeleteFeed(ownerID, id uint) error
+	RenderFeed(token string) ([]byte, error)
+	CreateHoliday(holiday *models.Holiday) error
+	ListHolidays(from, to time.Time) ([]models.Holiday, error)
+	DeleteHoliday(id uint) error
+}
+
+type CalendarHandler struct {
+	calendarService CalendarServiceInterface
+}
+
+func NewCalendarHandler(calendarService CalendarServiceInterface) *CalendarHandler {
+	return &CalendarHandler{
+		calendarService: calendarService,
+	}
+}
+
+// GetTeamCalendar 按天列出部門（department）或主管團隊（manager_id）的請假與假日
+// from 默認為今天，to 默認為 from 之後第 6 天
+func (h *CalendarHandler) GetTeamCalendar(c *gin.Context) {
+	query := services.CalendarQuery{Department: c.Query("department")}
+	if raw := c.Query("manager_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil || id == 0 {
+			c.Error(apperrors.Validation(apperrors.CodeValidationFailed, "Invalid manager ID",
+				apperrors.Field("manager_id", "gt", fieldMessage(c, "gt", "manager_id", "0"))))
+			return
+		}
+		managerID := uint(id)
+		query.ManagerID = &managerID
+	}
+
+	now := time.Now()
+	from, err := queryDate(c, "from", time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	to, err := queryDate(c, "to", from.AddDate(0, 0, defaultCalendarDays-1))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	query.From, query.To = from, to
+
+	days, err := h.calendarService.TeamCalendar(query)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	locale := middleware.GetLocale(c)
+	result := make([]dto.CalendarDayResponse, 0, len(days))
+	for _, day := range days {
+		result = append(result, dto.NewCalendarDayResponse(day.Date, day.Holiday, day.Leaves, locale))
+	}
+	c.JSON(http.StatusOK, gin.H{"from": from.Format(dto.DateLayout), "to": to.Format(dto.DateLayout), "days": result})
+}
+
+// CreateMyFeed 為當前員工創建 iCalendar 訂閱，響應中的 url 只返回這一次
+func (h *CalendarHandler) CreateMyFeed(c *gin.Context) {
+	var req dto.CreateCalendarFeedRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
+		return
+	}
+
+	feed, err := h.calendarService.CreateFeed(middleware.GetEmployeeID(c), req.Scope)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, feed)
+}
+
+// ListMyFeeds 獲取當前員工的 iCalendar 訂閱
+func (h *CalendarHandler) ListMyFeeds(c *gin.Context) {
+	feeds, err := h.calendarService.ListFeeds(middleware.GetEmployeeID(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if feeds == nil {
+		feeds = []models.CalendarFeed{}
+	}
+	c.JSON(http.StatusOK, feeds)
+}
+
+// DeleteMyFeed 撤銷當前員工的 iCalendar 訂閱
+func (h *CalendarHandler) DeleteMyFeed(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	if err := h.calendarService.DeleteFeed(middleware.GetEmployeeID(c), uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(middleware.GetLocale(c), "message.calendar_feed_deleted")})
+}
+
+// GetFeed 以連結中的令牌返回 iCalendar 訂閱內容，供日曆客戶端定期讀取，不需要登入
+func (h *CalendarHandler) GetFeed(c *gin.Context) {
+	token, ok := strings.CutSuffix(c.Param("file"), ".ics")
+	if !ok || token == "" {
+		c.Error(apperrors.NotFound(apperrors.CodeCalendarFeedNotFound, "Calendar feed not found"))
+		return
+	}
+
+	content, err := h.calendarService.RenderFeed(token)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.Header("Cache-Control", "private, max-age=900")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", content)
+}
+
+// CreateHoliday 創建公司假日
+func (h *CalendarHandler) CreateHoliday(c *gin.Context) {
+	var req dto.CreateHolidayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
+		return
+	}
+
+	holiday := req.ToModel()
+	if err := h.calendarService.CreateHoliday(holiday); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, holiday)
+}
+
+// ListHolidays 獲取 from 至 to 內的公司假日，默認為今年
+func (h *CalendarHandler) ListHolidays(c *gin.Context) {
+	year := time.Now().Year()
+	from, err := queryDate(c, "from", time.Date(year, time.January, 1, 0, 0, 0, 0, time.Local))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	to, err := queryDate(c, "to", time.Date(year, time.December, 31, 0, 0, 0, 0, time.Local))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	holidays, err := h.calendarService.ListHolidays(from, to)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if holidays == nil {
+		holidays = []models.Holiday{}
+	}
+	c.JSON(http.StatusOK, holidays)
+}
+
+// DeleteHoliday 刪除公司假日
+func (h *CalendarHandler) DeleteHoliday(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	if err := h.calendarService.DeleteHoliday(uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(middleware.GetLocale(c), "message.holiday_deleted")})
+}
+
+// queryDate 解析 YYYY-MM-DD 格式的日期查詢參數，未指定時使用默認值
+func queryDate(c *gin.Context, name string, defaultDate time.Time) (time.Time, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return defaultDate, nil
+	}
+	date, err := time.ParseInLocation(dto.DateLayout, raw, time.Local)
+	if err != nil {
+		return time.Time{}, apperrors.Validation(apperrors.CodeInvalidDate, "Invalid date",
+			apperrors.Field(name, "date", fieldMessage(c, "date", name, "")))
+	}
+	return date, nil
+}
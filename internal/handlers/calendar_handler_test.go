@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+	"hr-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockCalendarService 模擬行事曆服務
+type MockCalendarService struct {
+	mock.Mock
+}
+
+func (m *MockCalendarService) TeamCalendar(query services.CalendarQuery) ([]services.CalendarDay, error) {
+	args := m.Called(query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]services.CalendarDay), args.Error(1)
+}
+
+func (m *MockCalendarService) CreateFeed(ownerID uint, scope string) (*services.CalendarFeedSubscription, error) {
+	args := m.Called(ownerID, scope)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.CalendarFeedSubscription), args.Error(1)
+}
+
+func (m *MockCalendarService) ListFeeds(ownerID uint) ([]models.CalendarFeed, error) {
+	args := m.Called(ownerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.CalendarFeed), args.Error(1)
+}
+
+func (m *MockCalendarService) DeleteFeed(ownerID, id uint) error {
+	args := m.Called(ownerID, id)
+	return args.Error(0)
+}
+
+func (m *MockCalendarService) RenderFeed(token string) ([]byte, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockCalendarService) CreateHoliday(holiday *models.Holiday) error {
+	args := m.Called(holiday)
+	return args.Error(0)
+}
+
+func (m *MockCalendarService) ListHolidays(from, to time.Time) ([]models.Holiday, error) {
+	args := m.Called(from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Holiday), args.Error(1)
+}
+
+func (m *MockCalendarService) DeleteHoliday(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// 確保 MockCalendarService 實現了 CalendarServiceInterface
+var _ CalendarServiceInterface = (*MockCalendarService)(nil)
+
+func setupCalendarTestRouter(handler *CalendarHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.Locale(), middleware.ErrorHandler(), middleware.Identity())
+
+	r.GET("/calendar/feeds/:file", handler.GetFeed)
+	r.GET("/api/calendar", handler.GetTeamCalendar)
+	r.POST("/api/me/calendar-feeds", middleware.RequireIdentity(), handler.CreateMyFeed)
+	r.GET("/api/me/calendar-feeds", middleware.RequireIdentity(), handler.ListMyFeeds)
+	r.DELETE("/api/me/calendar-feeds/:id", middleware.RequireIdentity(), handler.DeleteMyFeed)
+	r.POST("/api/admin/holidays", handler.CreateHoliday)
+	r.GET("/api/admin/holidays", handler.ListHolidays)
+	r.DELETE("/api/admin/holidays/:id", handler.DeleteHoliday)
+	return r
+}
+
+func TestGetTeamCalendar(t *testing.T) {
+	mockService := &MockCalendarService{}
+	router := setupCalendarTestRouter(NewCalendarHandler(mockService))
+
+	from := time.Date(2024, 6, 10, 0, 0, 0, 0, time.Local)
+	to := time.Date(2024, 6, 11, 0, 0, 0, 0, time.Local)
+	managerID := uint(2)
+	leave := models.Leave{EmployeeID: 3, LeaveType: models.LeaveTypeAnnual, Status: models.LeaveStatusApproved}
+	leave.ID = 7
+	mockService.On("TeamCalendar", services.CalendarQuery{ManagerID: &managerID, From: from, To: to}).Return([]services.CalendarDay{
+		{Date: from, Holiday: "端午節", Leaves: []models.Leave{leave}},
+		{Date: to, Leaves: []models.Leave{}},
+	}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/calendar?manager_id=2&from=2024-06-10&to=2024-06-11&lang=en", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+		Days []struct {
+			Date    string `json:"date"`
+			Holiday string `json:"holiday"`
+			Leaves  []struct {
+				ID             uint   `json:"ID"`
+				LeaveTypeLabel string `json:"leave_type_label"`
+			} `json:"leaves"`
+		} `json:"days"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "2024-06-10", resp.From)
+	assert.Equal(t, "2024-06-11", resp.To)
+	if assert.Len(t, resp.Days, 2) {
+		assert.Equal(t, "端午節", resp.Days[0].Holiday)
+		if assert.Len(t, resp.Days[0].Leaves, 1) {
+			assert.Equal(t, uint(7), resp.Days[0].Leaves[0].ID)
+			assert.Equal(t, "Annual leave", resp.Days[0].Leaves[0].LeaveTypeLabel)
+		}
+		assert.NotNil(t, resp.Days[1].Leaves)
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		wantField string
+	}{
+		{name: "日期格式錯誤", query: "department=研發部&from=2024/06/10", wantField: "from"},
+		{name: "無效的主管ID", query: "manager_id=abc", wantField: "manager_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/calendar?"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var resp middleware.ErrorResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			if assert.Len(t, resp.Error.Fields, 1) {
+				assert.Equal(t, tt.wantField, resp.Error.Fields[0].Field)
+			}
+		})
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateMyFeed(t *testing.T) {
+	mockService := &MockCalendarService{}
+	router := setupCalendarTestRouter(NewCalendarHandler(mockService))
+
+	mockService.On("CreateFeed", uint(2), models.CalendarFeedScopeTeam).Return(&services.CalendarFeedSubscription{
+		CalendarFeed: models.CalendarFeed{ID: 1, OwnerID: 2, Scope: models.CalendarFeedScopeTeam},
+		URL:          "http://localhost:8080/calendar/feeds/abc.ics",
+	}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/me/calendar-feeds", bytes.NewBufferString(`{"scope":"team"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.EmployeeIDHeader, "2")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"url":"http://localhost:8080/calendar/feeds/abc.ics"`)
+	assert.NotContains(t, w.Body.String(), "token_hash")
+
+	req = httptest.NewRequest(http.MethodPost, "/api/me/calendar-feeds", bytes.NewBufferString(`{"scope":"company"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.EmployeeIDHeader, "2")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/me/calendar-feeds", bytes.NewBufferString(`{"scope":"self"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetFeed(t *testing.T) {
+	mockService := &MockCalendarService{}
+	router := setupCalendarTestRouter(NewCalendarHandler(mockService))
+
+	mockService.On("RenderFeed", "abc").Return([]byte("BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"), nil).Once()
+	mockService.On("RenderFeed", "revoked").Return(nil, apperrors.NotFound(apperrors.CodeCalendarFeedNotFound, "Calendar feed not found")).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/calendar/feeds/abc.ics", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/calendar; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), "BEGIN:VCALENDAR")
+
+	req = httptest.NewRequest(http.MethodGet, "/calendar/feeds/revoked.ics", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/calendar/feeds/abc", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateHoliday(t *testing.T) {
+	mockService := &MockCalendarService{}
+	router := setupCalendarTestRouter(NewCalendarHandler(mockService))
+
+	mockService.On("CreateHoliday", mock.MatchedBy(func(h *models.Holiday) bool {
+		return h.Name == "端午節" && h.Date.Equal(time.Date(2024, 6, 10, 0, 0, 0, 0, time.Local))
+	})).Return(nil).Once()
+	mockService.On("CreateHoliday", mock.MatchedBy(func(h *models.Holiday) bool {
+		return h.Name == "重複"
+	})).Return(apperrors.Conflict(apperrors.CodeHolidayExists, "A holiday already exists on this date")).Once()
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "成功創建假日", body: `{"date":"2024-06-10","name":"端午節"}`, wantStatus: http.StatusCreated},
+		{name: "日期已有假日", body: `{"date":"2024-06-10","name":"重複"}`, wantStatus: http.StatusConflict},
+		{name: "日期格式錯誤", body: `{"date":"2024-06-10T00:00:00Z","name":"端午節"}`, wantStatus: http.StatusBadRequest},
+		{name: "缺少名稱", body: `{"date":"2024-06-10"}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/holidays", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+	mockService.AssertExpectations(t)
+}
@@ -4,6 +4,9 @@ import (
 	"net/http"
 	"strconv"
 
+	"hr-system/internal/dto"
+	"hr-system/internal/i18n"
+	"hr-system/internal/middleware"
 	"hr-system/internal/models"
 
 	"github.com/gin-gonic/gin"
@@ -30,91 +33,84 @@ func NewEmployeeHandler(employeeService EmployeeServiceInterface) *EmployeeHandl
 
 // CreateEmployee 創建員工
 func (h *EmployeeHandler) CreateEmployee(c *gin.Context) {
-	var employee models.Employee
-	if err := c.ShouldBindJSON(&employee); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req dto.EmployeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
 		return
 	}
 
-	// 驗證必填字段
-	if employee.Name == "" || employee.Email == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Name and email are required"})
+	employee := req.ToModel()
+	if err := h.employeeService.CreateEmployee(employee); err != nil {
+		c.Error(err)
 		return
 	}
 
-	if err := h.employeeService.CreateEmployee(&employee); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-
-	c.JSON(http.StatusCreated, employee)
+	c.JSON(http.StatusCreated, dto.NewEmployeeResponse(employee, middleware.GetLocale(c)))
 }
 
 // GetEmployee 獲取員工信息
 func (h *EmployeeHandler) GetEmployee(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		c.Error(errInvalidID())
 		return
 	}
 
 	employee, err := h.employeeService.GetEmployee(uint(id))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, employee)
+	c.JSON(http.StatusOK, dto.NewEmployeeResponse(employee, middleware.GetLocale(c)))
 }
 
 // ListEmployees 獲取員工列表
 func (h *EmployeeHandler) ListEmployees(c *gin.Context) {
 	employees, err := h.employeeService.ListEmployees()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
-	if employees == nil {
-		employees = []models.Employee{}
-	}
-	c.JSON(http.StatusOK, employees)
+	c.JSON(http.StatusOK, dto.NewEmployeeResponses(employees, middleware.GetLocale(c)))
 }
 
 // UpdateEmployee 更新員工信息
 func (h *EmployeeHandler) UpdateEmployee(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		c.Error(errInvalidID())
 		return
 	}
 
-	var employee models.Employee
-	if err := c.ShouldBindJSON(&employee); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req dto.EmployeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
 		return
 	}
 
+	employee := req.ToModel()
 	employee.ID = uint(id)
-	if err := h.employeeService.UpdateEmployee(&employee); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := h.employeeService.UpdateEmployee(employee); err != nil {
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, employee)
+	c.JSON(http.StatusOK, dto.NewEmployeeResponse(employee, middleware.GetLocale(c)))
 }
 
 // DeleteEmployee 刪除員工
 func (h *EmployeeHandler) DeleteEmployee(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		c.Error(errInvalidID())
 		return
 	}
 
 	if err := h.employeeService.DeleteEmployee(uint(id)); err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Employee not found"})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Employee deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(middleware.GetLocale(c), "message.employee_deleted")})
 }
@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"hr-system/internal/apperrors"
+	"hr-system/internal/middleware"
 	"hr-system/internal/models"
 
 	"github.com/gin-gonic/gin"
@@ -61,6 +64,7 @@ var _ EmployeeServiceInterface = (*MockEmployeeService)(nil)
 func setupTestRouter(handler *EmployeeHandler) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
+	r.Use(middleware.RequestID(), middleware.Locale(), middleware.ErrorHandler())
 
 	api := r.Group("/api")
 	{
@@ -95,7 +99,7 @@ func TestCreateEmployee(t *testing.T) {
 			payload: models.Employee{
 				Name:             "測試員工",
 				Email:            "test@example.com",
-				Phone:            "13800138000",
+				Phone:            "0912345678",
 				Position:         "工程師",
 				Department:       "技術部",
 				Level:            1,
@@ -161,10 +165,18 @@ func TestGetEmployee(t *testing.T) {
 			name: "員工不存在",
 			id:   "999",
 			mockSetup: func() {
-				mockService.On("GetEmployee", uint(999)).Return(nil, assert.AnError)
+				mockService.On("GetEmployee", uint(999)).Return(nil, apperrors.NotFound(apperrors.CodeEmployeeNotFound, "Employee not found"))
 			},
 			wantStatus: http.StatusNotFound,
 		},
+		{
+			name: "數據庫錯誤",
+			id:   "500",
+			mockSetup: func() {
+				mockService.On("GetEmployee", uint(500)).Return(nil, assert.AnError)
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
 		{
 			name:       "無效的ID",
 			id:         "invalid",
@@ -309,10 +321,18 @@ func TestDeleteEmployee(t *testing.T) {
 			name: "員工不存在",
 			id:   "999",
 			mockSetup: func() {
-				mockService.On("DeleteEmployee", uint(999)).Return(assert.AnError)
+				mockService.On("DeleteEmployee", uint(999)).Return(apperrors.NotFound(apperrors.CodeEmployeeNotFound, "Employee not found"))
 			},
 			wantStatus: http.StatusNotFound,
 		},
+		{
+			name: "數據庫錯誤",
+			id:   "500",
+			mockSetup: func() {
+				mockService.On("DeleteEmployee", uint(500)).Return(assert.AnError)
+			},
+			wantStatus: http.StatusInternalServerError,
+		},
 		{
 			name:       "無效的ID",
 			id:         "invalid",
@@ -333,3 +353,121 @@ func TestDeleteEmployee(t *testing.T) {
 		})
 	}
 }
+
+func TestCreateEmployeeErrorEnvelope(t *testing.T) {
+	mockService := &MockEmployeeService{}
+	handler := NewEmployeeHandler(mockService)
+	router := setupTestRouter(handler)
+
+	mockService.On("CreateEmployee", mock.AnythingOfType("*models.Employee")).
+		Return(apperrors.Conflict(apperrors.CodeEmailAlreadyExists, "Email already exists"))
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		query          string
+		wantMessage    string
+		wantLanguage   string
+	}{
+		{
+			name:         "默認繁體中文",
+			wantMessage:  "該電子郵件已被使用",
+			wantLanguage: "zh-TW",
+		},
+		{
+			name:           "依 Accept-Language 選擇英文",
+			acceptLanguage: "en-US,en;q=0.9,zh-TW;q=0.8",
+			wantMessage:    "Email already exists",
+			wantLanguage:   "en",
+		},
+		{
+			name:           "查詢參數優先於 Accept-Language",
+			acceptLanguage: "en",
+			query:          "?lang=zh-TW",
+			wantMessage:    "該電子郵件已被使用",
+			wantLanguage:   "zh-TW",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(models.Employee{Name: "測試員工", Email: "test@example.com"})
+			req := httptest.NewRequest(http.MethodPost, "/api/employees"+tt.query, bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(middleware.RequestIDHeader, "req-123")
+			if tt.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusConflict, w.Code)
+			assert.Equal(t, tt.wantLanguage, w.Header().Get("Content-Language"))
+
+			var resp middleware.ErrorResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, apperrors.CodeEmailAlreadyExists, resp.Error.Code)
+			assert.Equal(t, tt.wantMessage, resp.Error.Message)
+			assert.Equal(t, "req-123", resp.Error.RequestID)
+		})
+	}
+}
+
+func TestCreateEmployeeValidation(t *testing.T) {
+	mockService := &MockEmployeeService{}
+	handler := NewEmployeeHandler(mockService)
+	router := setupTestRouter(handler)
+
+	future := time.Now().AddDate(0, 1, 0).Format(time.RFC3339)
+
+	tests := []struct {
+		name      string
+		body      string
+		wantField string
+		wantCode  string
+	}{
+		{"無效的郵箱", `{"name":"測試","email":"not-an-email"}`, "email", "email"},
+		{"負數薪資", `{"name":"測試","email":"a@example.com","salary":-1}`, "salary", "gte"},
+		{"未來的入職日期", `{"name":"測試","email":"a@example.com","hire_date":"` + future + `"}`, "hire_date", "not_future"},
+		{"未知的狀態", `{"name":"測試","email":"a@example.com","status":"retired"}`, "status", "employee_status"},
+		{"非台灣電話", `{"name":"測試","email":"a@example.com","phone":"13800138000"}`, "phone", "tw_phone"},
+		{"過長的電話", `{"name":"測試","email":"a@example.com","phone":"` + strings.Repeat("9", 500) + `"}`, "phone", "max"},
+		{"錯誤的類型", `{"name":"測試","email":"a@example.com","level":"senior"}`, "level", "type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/employees", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var resp middleware.ErrorResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, apperrors.CodeValidationFailed, resp.Error.Code)
+			if assert.Len(t, resp.Error.Fields, 1) {
+				assert.Equal(t, tt.wantField, resp.Error.Fields[0].Field)
+				assert.Equal(t, tt.wantCode, resp.Error.Fields[0].Code)
+			}
+		})
+	}
+
+	t.Run("依 Accept-Language 返回英文訊息", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/api/employees", strings.NewReader(`{"email":"a@example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept-Language", "en-US,en;q=0.9,zh-TW;q=0.8")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var resp middleware.ErrorResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		if assert.Len(t, resp.Error.Fields, 1) {
+			assert.Equal(t, "Name is required", resp.Error.Fields[0].Message)
+		}
+	})
+}
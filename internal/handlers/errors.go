@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"strconv"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/i18n"
+	"hr-system/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errInvalidID 路徑中的ID無效
+func errInvalidID() error {
+	return apperrors.Validation(apperrors.CodeInvalidID, "Invalid ID",
+		apperrors.Field("id", apperrors.CodeInvalidID, "ID must be a positive integer"))
+}
+
+// errInvalidRequest 請求體無法解析
+func errInvalidRequest(err error) error {
+	return apperrors.Validation(apperrors.CodeInvalidRequest, err.Error())
+}
+
+// queryLimit 解析查詢參數 limit，未指定時使用默認值
+func queryLimit(c *gin.Context, defaultLimit, maxLimit int) (int, error) {
+	raw := c.Query("limit")
+	if raw == "" {
+		return defaultLimit, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit <= 0 || limit > maxLimit {
+		return 0, apperrors.Validation(apperrors.CodeValidationFailed, "Invalid limit",
+			apperrors.Field("limit", "lte", fieldMessage(c, "lte", "limit", strconv.Itoa(maxLimit))))
+	}
+	return limit, nil
+}
+
+// queryPage 解析分頁參數 page（從 1 開始）與 page_size，未指定時使用默認值
+func queryPage(c *gin.Context, defaultSize, maxSize int) (page, pageSize int, err error) {
+	page, pageSize = 1, defaultSize
+	if raw := c.Query("page"); raw != "" {
+		page, err = strconv.Atoi(raw)
+		if err != nil || page <= 0 {
+			return 0, 0, apperrors.Validation(apperrors.CodeValidationFailed, "Invalid page",
+				apperrors.Field("page", "gte", fieldMessage(c, "gte", "page", "1")))
+		}
+	}
+	if raw := c.Query("page_size"); raw != "" {
+		pageSize, err = strconv.Atoi(raw)
+		if err != nil || pageSize <= 0 || pageSize > maxSize {
+			return 0, 0, apperrors.Validation(apperrors.CodeValidationFailed, "Invalid page size",
+				apperrors.Field("page_size", "lte", fieldMessage(c, "lte", "page_size", strconv.Itoa(maxSize))))
+		}
+	}
+	return page, pageSize, nil
+}
+
+// currentEmployee 返回網關識別的當前員工ID，未識別時為空
+func currentEmployee(c *gin.Context) *uint {
+	if id := middleware.GetEmployeeID(c); id != 0 {
+		return &id
+	}
+	return nil
+}
+
+// fieldMessage 以當前語系生成字段校驗訊息
+func fieldMessage(c *gin.Context, code, field, param string) string {
+	locale := middleware.GetLocale(c)
+	return i18n.Tf(locale, "validation."+code, i18n.Vars{"field": i18n.T(locale, "field."+field), "param": param})
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"hr-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthServiceInterface 定義健康檢查服務接口
+type HealthServiceInterface interface {
+	Check(ctx context.Context) services.HealthReport
+}
+
+type HealthHandler struct {
+	healthService HealthServiceInterface
+}
+
+func NewHealthHandler(healthService HealthServiceInterface) *HealthHandler {
+	return &HealthHandler{
+		healthService: healthService,
+	}
+}
+
+// GetHealth 返回數據庫與緩存後端的健康狀態
+// 緩存降級時仍返回 200，只有數據庫不可用時返回 503
+func (h *HealthHandler) GetHealth(c *gin.Context) {
+	report := h.healthService.Check(c.Request.Context())
+	if report.Status == services.HealthStatusDown {
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"hr-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockHealthService 模擬健康檢查服務
+type MockHealthService struct {
+	mock.Mock
+}
+
+func (m *MockHealthService) Check(ctx context.Context) services.HealthReport {
+	args := m.Called()
+	return args.Get(0).(services.HealthReport)
+}
+
+// 確保 MockHealthService 實現了 HealthServiceInterface
+var _ HealthServiceInterface = (*MockHealthService)(nil)
+
+func TestGetHealth(t *testing.T) {
+	tests := []struct {
+		name        string
+		report      services.HealthReport
+		wantStatus  int
+		wantBackend string
+	}{
+		{
+			name: "Redis 正常",
+			report: services.HealthReport{
+				Status:   services.HealthStatusOK,
+				Database: services.HealthStatusOK,
+				Cache:    services.CacheHealth{Backend: "redis", Healthy: true, Breaker: services.BreakerClosed},
+			},
+			wantStatus:  http.StatusOK,
+			wantBackend: "redis",
+		},
+		{
+			name: "Redis 熔斷，使用進程內緩存",
+			report: services.HealthReport{
+				Status:   services.HealthStatusDegraded,
+				Database: services.HealthStatusOK,
+				Cache:    services.CacheHealth{Backend: "memory", Healthy: false, Breaker: services.BreakerOpen},
+			},
+			wantStatus:  http.StatusOK,
+			wantBackend: "memory",
+		},
+		{
+			name: "數據庫不可用",
+			report: services.HealthReport{
+				Status:   services.HealthStatusDown,
+				Database: services.HealthStatusDown,
+				Cache:    services.CacheHealth{Backend: "redis", Healthy: true},
+			},
+			wantStatus:  http.StatusServiceUnavailable,
+			wantBackend: "redis",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockHealthService{}
+			mockService.On("Check").Return(tt.report)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/health", NewHealthHandler(mockService).GetHealth)
+
+			req := httptest.NewRequest(http.MethodGet, "/health", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+
+			var body services.HealthReport
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.wantBackend, body.Cache.Backend)
+		})
+	}
+}
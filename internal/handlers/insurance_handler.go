@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/dto"
+	"hr-system/internal/i18n"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxGradeTableSize 分級表 CSV 文件的大小上限
+const maxGradeTableSize = 1 << 20
+
+// InsuranceServiceInterface 定義勞健保與勞退服務接口
+type InsuranceServiceInterface interface {
+	ImportTable(table *models.InsuranceGradeTable, content io.Reader) error
+	GetTable(id uint) (*models.InsuranceGradeTable, error)
+	ListTables(kind string) ([]models.InsuranceGradeTable, error)
+	DeleteTable(id uint) error
+	EmployeeGrades(employeeID uint, month string) ([]models.InsuredGrade, error)
+	Reconcile(runID uint) (*models.InsuranceReconciliation, error)
+}
+
+type InsuranceHandler struct {
+	insuranceService InsuranceServiceInterface
+}
+
+func NewInsuranceHandler(insuranceService InsuranceServiceInterface) *InsuranceHandler {
+	return &InsuranceHandler{
+		insuranceService: insuranceService,
+	}
+}
+
+// ImportTable 以 multipart/form-data 上傳分級表，級距為 file 字段中的 CSV 文件
+func (h *InsuranceHandler) ImportTable(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxGradeTableSize+multipartOverhead)
+	var req dto.ImportGradeTableRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
+		return
+	}
+	file, err := c.FormFile("file")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) || (err == nil && file.Size > maxGradeTableSize) {
+		msg := fmt.Sprintf("File must not exceed %d bytes", maxGradeTableSize)
+		c.Error(apperrors.Validation(apperrors.CodeInvalidGradeTable, msg,
+			apperrors.Field("file", apperrors.CodeInvalidGradeTable, msg)))
+		return
+	}
+	if err != nil {
+		c.Error(apperrors.Validation(apperrors.CodeValidationFailed, "File is required",
+			apperrors.Field("file", "required", fieldMessage(c, "required", "file", ""))))
+		return
+	}
+	content, err := file.Open()
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+	defer content.Close()
+
+	table := req.ToModel()
+	if err := h.insuranceService.ImportTable(table, content); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, table)
+}
+
+// ListTables 獲取分級表，可按保險類型篩選
+func (h *InsuranceHandler) ListTables(c *gin.Context) {
+	kind := c.Query("kind")
+	if kind != "" && !containsString(models.InsuranceKinds, kind) {
+		kinds := strings.Join(models.InsuranceKinds, ", ")
+		c.Error(apperrors.Validation(apperrors.CodeValidationFailed, "Invalid kind",
+			apperrors.Field("kind", "oneof", fieldMessage(c, "oneof", "kind", kinds))))
+		return
+	}
+
+	tables, err := h.insuranceService.ListTables(kind)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if tables == nil {
+		tables = []models.InsuranceGradeTable{}
+	}
+	c.JSON(http.StatusOK, tables)
+}
+
+// GetTable 獲取分級表及其級距
+func (h *InsuranceHandler) GetTable(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	table, err := h.insuranceService.GetTable(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, table)
+}
+
+// DeleteTable 刪除沒有薪資單使用的分級表
+func (h *InsuranceHandler) DeleteTable(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	if err := h.insuranceService.DeleteTable(uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(middleware.GetLocale(c), "message.insurance_grade_table_deleted")})
+}
+
+// GetEmployeeGrades 獲取員工當前月薪在某月份（默認本月）的投保級距
+func (h *InsuranceHandler) GetEmployeeGrades(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	grades, err := h.insuranceService.EmployeeGrades(uint(id), c.DefaultQuery("month", time.Now().Format(dto.MonthLayout)))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, grades)
+}
+
+// Reconcile 核對薪資批次的保費
+func (h *InsuranceHandler) Reconcile(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	report, err := h.insuranceService.Reconcile(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockInsuranceService 模擬勞健保與勞退服務
+type MockInsuranceService struct {
+	mock.Mock
+}
+
+func (m *MockInsuranceService) ImportTable(table *models.InsuranceGradeTable, content io.Reader) error {
+	args := m.Called(table, content)
+	return args.Error(0)
+}
+
+func (m *MockInsuranceService) GetTable(id uint) (*models.InsuranceGradeTable, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.InsuranceGradeTable), args.Error(1)
+}
+
+func (m *MockInsuranceService) ListTables(kind string) ([]models.InsuranceGradeTable, error) {
+	args := m.Called(kind)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.InsuranceGradeTable), args.Error(1)
+}
+
+func (m *MockInsuranceService) DeleteTable(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockInsuranceService) EmployeeGrades(employeeID uint, month string) ([]models.InsuredGrade, error) {
+	args := m.Called(employeeID, month)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.InsuredGrade), args.Error(1)
+}
+
+func (m *MockInsuranceService) Reconcile(runID uint) (*models.InsuranceReconciliation, error) {
+	args := m.Called(runID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.InsuranceReconciliation), args.Error(1)
+}
+
+// 確保 MockInsuranceService 實現了 InsuranceServiceInterface
+var _ InsuranceServiceInterface = (*MockInsuranceService)(nil)
+
+func setupInsuranceTestRouter(handler *InsuranceHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.Locale(), middleware.ErrorHandler(), middleware.Identity())
+
+	r.POST("/api/admin/insurance-grade-tables", handler.ImportTable)
+	r.GET("/api/admin/insurance-grade-tables", handler.ListTables)
+	r.GET("/api/admin/insurance-grade-tables/:id", handler.GetTable)
+	r.DELETE("/api/admin/insurance-grade-tables/:id", handler.DeleteTable)
+	r.GET("/api/employees/:id/insurance-grades", handler.GetEmployeeGrades)
+	r.GET("/api/admin/payroll-runs/:id/insurance-reconciliation", handler.Reconcile)
+	return r
+}
+
+// newGradeTableRequest 創建以 multipart/form-data 上傳分級表的請求，csv 為空時不附帶文件
+func newGradeTableRequest(t *testing.T, fields map[string]string, csv string) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for name, value := range fields {
+		assert.NoError(t, writer.WriteField(name, value))
+	}
+	if csv != "" {
+		part, err := writer.CreateFormFile("file", "grades.csv")
+		assert.NoError(t, err)
+		part.Write([]byte(csv))
+	}
+	writer.Close()
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/insurance-grade-tables", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestImportGradeTable(t *testing.T) {
+	mockService := &MockInsuranceService{}
+	router := setupInsuranceTestRouter(NewInsuranceHandler(mockService))
+
+	mockService.On("ImportTable", mock.MatchedBy(func(table *models.InsuranceGradeTable) bool {
+		return table.Kind == models.InsuranceLabor && table.Version == "2024" && table.EffectiveFrom.Month() == 1
+	}), mock.MatchedBy(func(content io.Reader) bool {
+		data, _ := io.ReadAll(content)
+		return strings.HasPrefix(string(data), "grade,insured_salary")
+	})).Return(nil).Once()
+	mockService.On("ImportTable", mock.MatchedBy(func(table *models.InsuranceGradeTable) bool {
+		return table.Kind == models.InsuranceHealth
+	}), mock.Anything).Return(apperrors.Validation(apperrors.CodeInvalidGradeTable,
+		"Line 3: grades and insured salaries must be increasing")).Once()
+
+	labor := map[string]string{"kind": "labor", "version": "2024", "effective_from": "2024-01-01"}
+	req := newGradeTableRequest(t, labor, "grade,insured_salary\n1,27470\n2,27600\n")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	tests := []struct {
+		name       string
+		fields     map[string]string
+		csv        string
+		wantStatus int
+		wantCode   string
+	}{
+		{
+			name:       "級距不遞增",
+			fields:     map[string]string{"kind": "health", "version": "2024", "effective_from": "2024-01-01"},
+			csv:        "1,27470\n2,26400\n",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   apperrors.CodeInvalidGradeTable,
+		},
+		{name: "缺少文件", fields: labor, wantStatus: http.StatusBadRequest, wantCode: apperrors.CodeValidationFailed},
+		{
+			name:       "無效的類型",
+			fields:     map[string]string{"kind": "accident", "version": "2024", "effective_from": "2024-01-01"},
+			csv:        "1,27470\n",
+			wantStatus: http.StatusBadRequest,
+			wantCode:   apperrors.CodeValidationFailed,
+		},
+		{name: "文件過大", fields: labor, csv: strings.Repeat("1,27470\n", 1<<17+1), wantStatus: http.StatusBadRequest, wantCode: apperrors.CodeInvalidGradeTable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newGradeTableRequest(t, tt.fields, tt.csv)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var resp middleware.ErrorResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.wantCode, resp.Error.Code)
+		})
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestListAndDeleteGradeTables(t *testing.T) {
+	mockService := &MockInsuranceService{}
+	router := setupInsuranceTestRouter(NewInsuranceHandler(mockService))
+
+	mockService.On("ListTables", models.InsuranceHealth).Return(nil, nil).Once()
+	mockService.On("DeleteTable", uint(2)).Return(
+		apperrors.Conflict(apperrors.CodeGradeTableInUse, "The grade table is used by payslips")).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/insurance-grade-tables?kind=health", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[]`, w.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/api/admin/insurance-grade-tables?kind=accident", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/admin/insurance-grade-tables/2", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetEmployeeGrades(t *testing.T) {
+	mockService := &MockInsuranceService{}
+	router := setupInsuranceTestRouter(NewInsuranceHandler(mockService))
+
+	mockService.On("EmployeeGrades", uint(3), "2024-07").Return([]models.InsuredGrade{
+		{Kind: models.InsuranceLabor, TableID: 1, Version: "2024", Grade: 9, InsuredSalary: 48200},
+		{Kind: models.InsuranceHealth, TableID: 2, Version: "2024", Grade: 22, InsuredSalary: 48200},
+	}, nil).Once()
+	mockService.On("EmployeeGrades", uint(3), "2024-13").Return(nil,
+		apperrors.Validation(apperrors.CodeInvalidPayrollMonth, "Month must be in YYYY-MM format")).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/employees/3/insurance-grades?month=2024-07", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var grades []models.InsuredGrade
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &grades))
+	assert.Len(t, grades, 2)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/employees/3/insurance-grades?month=2024-13", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestReconcileInsurance(t *testing.T) {
+	mockService := &MockInsuranceService{}
+	router := setupInsuranceTestRouter(NewInsuranceHandler(mockService))
+
+	mockService.On("Reconcile", uint(1)).Return(&models.InsuranceReconciliation{
+		RunID: 1, Month: "2024-07", Status: models.PayrollRunLocked, MismatchCount: 1,
+		Items: []models.InsuranceReconciliationItem{
+			{EmployeeID: 3, Kind: models.InsuranceHealth, Grade: 22, ExpectedGrade: 22,
+				EmployeeAmount: 748, Deducted: 748, ExpectedEmployee: 1496, EmployerAmount: 2345, ExpectedEmployer: 2345},
+		},
+	}, nil).Once()
+	mockService.On("Reconcile", uint(9)).Return(nil,
+		apperrors.NotFound(apperrors.CodePayrollRunNotFound, "Payroll run not found")).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/payroll-runs/1/insurance-reconciliation", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var report models.InsuranceReconciliation
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, 1, report.MismatchCount)
+	assert.False(t, report.Items[0].Matched)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/admin/payroll-runs/9/insurance-reconciliation", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
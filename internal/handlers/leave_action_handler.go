@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"bytes"
+	_ "embed"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/i18n"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+	"hr-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxLeaveActionRemark 郵件審批時備註的最大長度
+const maxLeaveActionRemark = 500
+
+//go:embed templates/leave_action.html
+var leaveActionPage string
+
+// leaveActionTemplate 郵件審批的確認頁與結果頁，t 函數在渲染時按語系替換
+var leaveActionTemplate = template.Must(template.New("leave_action").
+	Funcs(template.FuncMap{"t": func(key string) string { return key }}).
+	Parse(leaveActionPage))
+
+// LeaveActionServiceInterface 定義郵件審批服務接口
+type LeaveActionServiceInterface interface {
+	Inspect(token string) (*services.LeaveActionPreview, error)
+	Execute(token, remark string, meta services.RequestMeta) (*services.LeaveActionPreview, error)
+}
+
+type LeaveActionHandler struct {
+	leaveActionService LeaveActionServiceInterface
+}
+
+func NewLeaveActionHandler(leaveActionService LeaveActionServiceInterface) *LeaveActionHandler {
+	return &LeaveActionHandler{
+		leaveActionService: leaveActionService,
+	}
+}
+
+type leaveActionPageData struct {
+	Locale    string
+	Title     string
+	Message   string
+	Token     string // 非空時顯示確認表單
+	Action    string
+	Submit    string
+	Leave     *models.Leave
+	LeaveType string
+}
+
+// ShowLeaveAction 郵件連結的落地頁，驗證令牌並顯示確認表單；只查看不會使用令牌，
+// 避免郵件客戶端或安全掃描預先打開連結時誤審批
+func (h *LeaveActionHandler) ShowLeaveAction(c *gin.Context) {
+	token := c.Query("token")
+	preview, err := h.leaveActionService.Inspect(token)
+	if err != nil {
+		renderLeaveActionError(c, err)
+		return
+	}
+
+	locale := middleware.GetLocale(c)
+	renderLeaveActionPage(c, http.StatusOK, leaveActionPageData{
+		Title:     i18n.T(locale, "page.leave_action."+preview.Action+"_title"),
+		Token:     token,
+		Action:    preview.Action,
+		Submit:    i18n.T(locale, "page.leave_action."+preview.Action+"_submit"),
+		Leave:     preview.Leave,
+		LeaveType: i18n.Label(locale, "leave_type", preview.Leave.LeaveType),
+	})
+}
+
+// SubmitLeaveAction 提交確認表單，使用令牌完成審批
+func (h *LeaveActionHandler) SubmitLeaveAction(c *gin.Context) {
+	remark := strings.TrimSpace(c.PostForm("remark"))
+	if utf8.RuneCountInString(remark) > maxLeaveActionRemark {
+		renderLeaveActionError(c, apperrors.Validation(apperrors.CodeValidationFailed, "Remark is too long",
+			apperrors.Field("remark", "max", fieldMessage(c, "max_len", "remark", "500"))))
+		return
+	}
+
+	preview, err := h.leaveActionService.Execute(c.PostForm("token"), remark, services.RequestMeta{
+		RequestID: middleware.GetRequestID(c),
+		IP:        c.ClientIP(),
+	})
+	if err != nil {
+		renderLeaveActionError(c, err)
+		return
+	}
+
+	locale := middleware.GetLocale(c)
+	renderLeaveActionPage(c, http.StatusOK, leaveActionPageData{
+		Title:     i18n.T(locale, "page.leave_action."+preview.Action+"_title"),
+		Message:   i18n.T(locale, "page.leave_action."+preview.Leave.Status),
+		Leave:     preview.Leave,
+		LeaveType: i18n.Label(locale, "leave_type", preview.Leave.LeaveType),
+	})
+}
+
+// renderLeaveActionError 以頁面形式顯示錯誤，訊息按錯誤碼翻譯
+func renderLeaveActionError(c *gin.Context, err error) {
+	appErr := apperrors.From(err)
+	if appErr.Kind == apperrors.KindInternal {
+		log.Printf("[%s] %s %s: %v", middleware.GetRequestID(c), c.Request.Method, c.Request.URL.Path, appErr.Err)
+	}
+
+	locale := middleware.GetLocale(c)
+	message, ok := i18n.Lookup(locale, "error."+appErr.Code)
+	if !ok {
+		message = appErr.Message
+	}
+	if len(appErr.Fields) > 0 {
+		message = appErr.Fields[0].Message
+	}
+	renderLeaveActionPage(c, appErr.HTTPStatus(), leaveActionPageData{
+		Title:   i18n.T(locale, "page.leave_action.error_title"),
+		Message: message,
+	})
+}
+
+func renderLeaveActionPage(c *gin.Context, status int, data leaveActionPageData) {
+	locale := middleware.GetLocale(c)
+	data.Locale = locale
+
+	tmpl, err := leaveActionTemplate.Clone()
+	if err == nil {
+		tmpl.Funcs(template.FuncMap{"t": func(key string) string { return i18n.T(locale, key) }})
+	}
+	var buf bytes.Buffer
+	if err == nil {
+		err = tmpl.Execute(&buf, data)
+	}
+	if err != nil {
+		c.Error(apperrors.Internal(err))
+		return
+	}
+
+	// 頁面包含令牌，不緩存、不通過 Referer 洩漏、不允許被嵌入
+	c.Header("Cache-Control", "no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("X-Frame-Options", "DENY")
+	c.Data(status, "text/html; charset=utf-8", buf.Bytes())
+}
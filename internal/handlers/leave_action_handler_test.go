@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+	"hr-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockLeaveActionService 模擬郵件審批服務
+type MockLeaveActionService struct {
+	mock.Mock
+}
+
+func (m *MockLeaveActionService) Inspect(token string) (*services.LeaveActionPreview, error) {
+	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.LeaveActionPreview), args.Error(1)
+}
+
+func (m *MockLeaveActionService) Execute(token, remark string, meta services.RequestMeta) (*services.LeaveActionPreview, error) {
+	args := m.Called(token, remark, meta)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.LeaveActionPreview), args.Error(1)
+}
+
+// 確保 MockLeaveActionService 實現了 LeaveActionServiceInterface
+var _ LeaveActionServiceInterface = (*MockLeaveActionService)(nil)
+
+func setupLeaveActionTestRouter(handler *LeaveActionHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.Locale(), middleware.ErrorHandler())
+
+	r.GET("/leave-actions", handler.ShowLeaveAction)
+	r.POST("/leave-actions", handler.SubmitLeaveAction)
+	return r
+}
+
+func testLeaveActionPreview(status string) *services.LeaveActionPreview {
+	return &services.LeaveActionPreview{
+		TokenID: "abc",
+		Action:  models.LeaveActionApprove,
+		Leave: &models.Leave{
+			EmployeeID: 1,
+			Employee:   models.Employee{Name: "王小明"},
+			LeaveType:  "annual",
+			StartDate:  time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC),
+			EndDate:    time.Date(2024, 3, 1, 18, 0, 0, 0, time.UTC),
+			Status:     status,
+		},
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+}
+
+func TestShowLeaveAction(t *testing.T) {
+	mockService := &MockLeaveActionService{}
+	router := setupLeaveActionTestRouter(NewLeaveActionHandler(mockService))
+
+	mockService.On("Inspect", "good").Return(testLeaveActionPreview("pending"), nil)
+	mockService.On("Inspect", "expired").Return(nil, apperrors.Forbidden(apperrors.CodeActionTokenExpired, "Action token has expired"))
+
+	req := httptest.NewRequest(http.MethodGet, "/leave-actions?token=good&lang=zh-TW", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	assert.Equal(t, "no-referrer", w.Header().Get("Referrer-Policy"))
+	body := w.Body.String()
+	assert.Contains(t, body, `<form method="post" action="/leave-actions">`)
+	assert.Contains(t, body, `name="token" value="good"`)
+	assert.Contains(t, body, "確認核准")
+	assert.Contains(t, body, "王小明")
+
+	req = httptest.NewRequest(http.MethodGet, "/leave-actions?token=expired&lang=zh-TW", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, w.Body.String(), "此連結已過期")
+	assert.NotContains(t, w.Body.String(), "<form")
+}
+
+func TestSubmitLeaveAction(t *testing.T) {
+	mockService := &MockLeaveActionService{}
+	router := setupLeaveActionTestRouter(NewLeaveActionHandler(mockService))
+
+	tests := []struct {
+		name       string
+		form       url.Values
+		mockSetup  func()
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name: "核准成功",
+			form: url.Values{"token": {"good"}, "remark": {" 同意 "}},
+			mockSetup: func() {
+				mockService.On("Execute", "good", "同意", mock.AnythingOfType("services.RequestMeta")).
+					Return(testLeaveActionPreview("approved"), nil).Once()
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   "已核准此請假申請。",
+		},
+		{
+			name: "連結已使用",
+			form: url.Values{"token": {"used"}},
+			mockSetup: func() {
+				mockService.On("Execute", "used", "", mock.AnythingOfType("services.RequestMeta")).
+					Return(nil, apperrors.Conflict(apperrors.CodeActionTokenUsed, "Action token has already been used")).Once()
+			},
+			wantStatus: http.StatusConflict,
+			wantBody:   "此連結已使用過",
+		},
+		{
+			name: "請假已被處理",
+			form: url.Values{"token": {"late"}},
+			mockSetup: func() {
+				mockService.On("Execute", "late", "", mock.AnythingOfType("services.RequestMeta")).
+					Return(nil, apperrors.Conflict(apperrors.CodeLeaveProcessed, "Leave has already been processed")).Once()
+			},
+			wantStatus: http.StatusConflict,
+			wantBody:   "此請假申請已處理",
+		},
+		{
+			name:       "備註過長",
+			form:       url.Values{"token": {"good"}, "remark": {strings.Repeat("長", 501)}},
+			mockSetup:  func() {},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest(http.MethodPost, "/leave-actions?lang=zh-TW", strings.NewReader(tt.form.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+			if tt.wantBody != "" {
+				assert.Contains(t, w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/dto"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LeaveBalanceServiceInterface 定義假期額度與年終結算服務接口
+type LeaveBalanceServiceInterface interface {
+	AddEntry(entry *models.LeaveLedgerEntry) error
+	ListEntries(employeeID uint, year int) ([]models.LeaveLedgerEntry, error)
+	Balances(employeeID uint, year int) ([]models.LeaveBalance, error)
+	PreviewClose(year int) (*models.YearEndClose, error)
+	CommitClose(year int, actorID *uint) (*models.YearEndClose, bool, error)
+	GetClose(year int) (*models.YearEndClose, error)
+	ListCloses() ([]models.YearEndClose, error)
+	PayoutReport(year int) (*models.YearEndPayoutReport, error)
+}
+
+type LeaveBalanceHandler struct {
+	balanceService LeaveBalanceServiceInterface
+}
+
+func NewLeaveBalanceHandler(balanceService LeaveBalanceServiceInterface) *LeaveBalanceHandler {
+	return &LeaveBalanceHandler{
+		balanceService: balanceService,
+	}
+}
+
+// GetBalances 獲取員工某年度各類請假的額度，年度由 year 參數指定，默認為今年
+func (h *LeaveBalanceHandler) GetBalances(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+	year, err := parseYear(c, c.DefaultQuery("year", strconv.Itoa(time.Now().Year())))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	balances, err := h.balanceService.Balances(uint(id), year)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, balances)
+}
+
+// ListEntries 獲取員工某年度的額度流水，年度由 year 參數指定，默認為今年
+func (h *LeaveBalanceHandler) ListEntries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+	year, err := parseYear(c, c.DefaultQuery("year", strconv.Itoa(time.Now().Year())))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	entries, err := h.balanceService.ListEntries(uint(id), year)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if entries == nil {
+		entries = []models.LeaveLedgerEntry{}
+	}
+	c.JSON(http.StatusOK, entries)
+}
+
+// AddEntry 人工發放或調整假期額度
+func (h *LeaveBalanceHandler) AddEntry(c *gin.Context) {
+	var req dto.LeaveLedgerEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
+		return
+	}
+	if req.Kind == models.LeaveLedgerGrant && req.Days < 0 {
+		c.Error(apperrors.Validation(apperrors.CodeValidationFailed, "Granted days must be positive",
+			apperrors.Field("days", "gt", fieldMessage(c, "gt", "days", "0"))))
+		return
+	}
+
+	entry := req.ToModel()
+	entry.ActorID = currentEmployee(c)
+	if err := h.balanceService.AddEntry(entry); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, entry)
+}
+
+// CloseYear 預覽或執行年終結算；已結算的年度重複提交時返回已有的結算與 200
+func (h *LeaveBalanceHandler) CloseYear(c *gin.Context) {
+	var req dto.YearEndCloseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
+		return
+	}
+
+	if req.Preview {
+		record, err := h.balanceService.PreviewClose(req.Year)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, record)
+		return
+	}
+
+	record, created, err := h.balanceService.CommitClose(req.Year, currentEmployee(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, record)
+}
+
+// ListCloses 獲取所有年終結算
+func (h *LeaveBalanceHandler) ListCloses(c *gin.Context) {
+	closes, err := h.balanceService.ListCloses()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if closes == nil {
+		closes = []models.YearEndClose{}
+	}
+	c.JSON(http.StatusOK, closes)
+}
+
+// GetClose 獲取某年度的年終結算及每個員工的結算結果
+func (h *LeaveBalanceHandler) GetClose(c *gin.Context) {
+	year, err := parseYear(c, c.Param("year"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	record, err := h.balanceService.GetClose(year)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, record)
+}
+
+// GetPayoutReport 獲取某年度年終結算的折發工資報表
+func (h *LeaveBalanceHandler) GetPayoutReport(c *gin.Context) {
+	year, err := parseYear(c, c.Param("year"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	report, err := h.balanceService.PayoutReport(year)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// parseYear 解析四位數的年度
+func parseYear(c *gin.Context, raw string) (int, error) {
+	year, err := strconv.Atoi(raw)
+	if err != nil || year < 1900 || year > 9999 {
+		return 0, apperrors.Validation(apperrors.CodeValidationFailed, "Invalid year",
+			apperrors.Field("year", "invalid", fieldMessage(c, "invalid", "year", "")))
+	}
+	return year, nil
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockLeaveBalanceService 模擬假期額度服務
+type MockLeaveBalanceService struct {
+	mock.Mock
+}
+
+func (m *MockLeaveBalanceService) AddEntry(entry *models.LeaveLedgerEntry) error {
+	args := m.Called(entry)
+	return args.Error(0)
+}
+
+func (m *MockLeaveBalanceService) ListEntries(employeeID uint, year int) ([]models.LeaveLedgerEntry, error) {
+	args := m.Called(employeeID, year)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.LeaveLedgerEntry), args.Error(1)
+}
+
+func (m *MockLeaveBalanceService) Balances(employeeID uint, year int) ([]models.LeaveBalance, error) {
+	args := m.Called(employeeID, year)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.LeaveBalance), args.Error(1)
+}
+
+func (m *MockLeaveBalanceService) PreviewClose(year int) (*models.YearEndClose, error) {
+	args := m.Called(year)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.YearEndClose), args.Error(1)
+}
+
+func (m *MockLeaveBalanceService) CommitClose(year int, actorID *uint) (*models.YearEndClose, bool, error) {
+	args := m.Called(year, actorID)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).(*models.YearEndClose), args.Bool(1), args.Error(2)
+}
+
+func (m *MockLeaveBalanceService) GetClose(year int) (*models.YearEndClose, error) {
+	args := m.Called(year)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.YearEndClose), args.Error(1)
+}
+
+func (m *MockLeaveBalanceService) ListCloses() ([]models.YearEndClose, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.YearEndClose), args.Error(1)
+}
+
+func (m *MockLeaveBalanceService) PayoutReport(year int) (*models.YearEndPayoutReport, error) {
+	args := m.Called(year)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.YearEndPayoutReport), args.Error(1)
+}
+
+// 確保 MockLeaveBalanceService 實現了 LeaveBalanceServiceInterface
+var _ LeaveBalanceServiceInterface = (*MockLeaveBalanceService)(nil)
+
+func setupLeaveBalanceTestRouter(handler *LeaveBalanceHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.Locale(), middleware.ErrorHandler(), middleware.Identity())
+
+	r.GET("/api/employees/:id/leave-balances", handler.GetBalances)
+	r.GET("/api/employees/:id/leave-ledger", handler.ListEntries)
+	r.POST("/api/admin/leave-ledger", handler.AddEntry)
+	r.POST("/api/admin/year-end-closes", handler.CloseYear)
+	r.GET("/api/admin/year-end-closes", handler.ListCloses)
+	r.GET("/api/admin/year-end-closes/:year", handler.GetClose)
+	r.GET("/api/admin/year-end-closes/:year/payouts", handler.GetPayoutReport)
+	return r
+}
+
+func TestGetLeaveBalances(t *testing.T) {
+	mockService := &MockLeaveBalanceService{}
+	router := setupLeaveBalanceTestRouter(NewLeaveBalanceHandler(mockService))
+
+	mockService.On("Balances", uint(1), 2024).Return([]models.LeaveBalance{
+		{LeaveType: models.LeaveTypeAnnual, Year: 2024, Entitled: 14, Used: 5, Remaining: 9},
+	}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/employees/1/leave-balances?year=2024", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var balances []models.LeaveBalance
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &balances))
+	if assert.Len(t, balances, 1) {
+		assert.Equal(t, 9.0, balances[0].Remaining)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/employees/1/leave-balances?year=24", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestAddLeaveLedgerEntry(t *testing.T) {
+	mockService := &MockLeaveBalanceService{}
+	router := setupLeaveBalanceTestRouter(NewLeaveBalanceHandler(mockService))
+
+	mockService.On("AddEntry", mock.MatchedBy(func(entry *models.LeaveLedgerEntry) bool {
+		return entry.EmployeeID == 1 && entry.Kind == models.LeaveLedgerGrant && entry.Days == 14 &&
+			entry.ActorID != nil && *entry.ActorID == 9
+	})).Return(nil).Once()
+	mockService.On("AddEntry", mock.MatchedBy(func(entry *models.LeaveLedgerEntry) bool {
+		return entry.Year == 2023
+	})).Return(apperrors.Conflict(apperrors.CodeYearAlreadyClosed, "The year has already been closed")).Once()
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "發放特休", body: `{"employee_id":1,"year":2024,"leave_type":"年假","kind":"grant","days":14}`, wantStatus: http.StatusCreated},
+		{name: "已結算的年度", body: `{"employee_id":1,"year":2023,"leave_type":"年假","kind":"adjustment","days":-1}`, wantStatus: http.StatusConflict},
+		{name: "發放負數天數", body: `{"employee_id":1,"year":2024,"leave_type":"年假","kind":"grant","days":-2}`, wantStatus: http.StatusBadRequest},
+		{name: "結算流水不能人工寫入", body: `{"employee_id":1,"year":2024,"leave_type":"年假","kind":"paid_out","days":-2}`, wantStatus: http.StatusBadRequest},
+		{name: "天數為 0", body: `{"employee_id":1,"year":2024,"leave_type":"年假","kind":"adjustment","days":0}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/leave-ledger", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(middleware.EmployeeIDHeader, "9")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestCloseYear(t *testing.T) {
+	mockService := &MockLeaveBalanceService{}
+	router := setupLeaveBalanceTestRouter(NewLeaveBalanceHandler(mockService))
+
+	preview := &models.YearEndClose{Year: 2024, PaidDays: 3, PayoutAmount: 4500, Items: []models.YearEndCloseItem{
+		{EmployeeID: 1, LeaveType: models.LeaveTypeAnnual, Unused: 8, CarriedOver: 5, PaidOut: 3, DailyRate: 1500, Amount: 4500},
+	}}
+	committed := *preview
+	committed.ID = 3
+	mockService.On("PreviewClose", 2024).Return(preview, nil).Once()
+	mockService.On("CommitClose", 2024, (*uint)(nil)).Return(&committed, true, nil).Once()
+	mockService.On("CommitClose", 2024, (*uint)(nil)).Return(&committed, false, nil).Once()
+	mockService.On("CommitClose", 2099, (*uint)(nil)).Return(nil, false,
+		apperrors.Validation(apperrors.CodeInvalidCloseYear, "Year must not be in the future")).Once()
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "預覽", body: `{"year":2024,"preview":true}`, wantStatus: http.StatusOK},
+		{name: "執行結算", body: `{"year":2024}`, wantStatus: http.StatusCreated},
+		{name: "重複執行返回已有結算", body: `{"year":2024}`, wantStatus: http.StatusOK},
+		{name: "未來的年度", body: `{"year":2099}`, wantStatus: http.StatusBadRequest},
+		{name: "缺少年度", body: `{"preview":true}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/year-end-closes", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus < http.StatusBadRequest {
+				var record models.YearEndClose
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &record))
+				assert.Equal(t, 4500.0, record.PayoutAmount)
+			}
+		})
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestGetPayoutReport(t *testing.T) {
+	mockService := &MockLeaveBalanceService{}
+	router := setupLeaveBalanceTestRouter(NewLeaveBalanceHandler(mockService))
+
+	mockService.On("PayoutReport", 2024).Return(&models.YearEndPayoutReport{
+		Year: 2024, TotalDays: 3, TotalAmount: 4500,
+		Items: []models.YearEndCloseItem{{EmployeeID: 1, EmployeeName: "王小明", PaidOut: 3, DailyRate: 1500, Amount: 4500}},
+	}, nil).Once()
+	mockService.On("PayoutReport", 2023).Return(nil,
+		apperrors.NotFound(apperrors.CodeYearEndCloseNotFound, "Year-end close not found")).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/year-end-closes/2024/payouts", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"total_amount":4500`)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/admin/year-end-closes/2023/payouts", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	var resp middleware.ErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, apperrors.CodeYearEndCloseNotFound, resp.Error.Code)
+	mockService.AssertExpectations(t)
+}
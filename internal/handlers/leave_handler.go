@@ -4,6 +4,9 @@ import (
 	"net/http"
 	"strconv"
 
+	"hr-system/internal/dto"
+	"hr-system/internal/i18n"
+	"hr-system/internal/middleware"
 	"hr-system/internal/models"
 
 	"github.com/gin-gonic/gin"
@@ -11,11 +14,13 @@ import (
 
 // LeaveServiceInterface 定義請假服務接口
 type LeaveServiceInterface interface {
-	CreateLeave(leave *models.Leave) error
+	CreateLeave(leave *models.Leave) ([]models.StaffingCoverage, error)
 	GetLeave(id uint) (*models.Leave, error)
 	ListLeaves() ([]models.Leave, error)
-	UpdateLeaveStatus(id uint, status string, remark string) error
+	UpdateLeaveStatus(id uint, status string, remark string, approverID uint) ([]models.StaffingCoverage, error)
+	CancelLeave(id uint, actorID uint) error
 	DeleteLeave(id uint) error
+	GetLeaveHistory(id uint) ([]models.LeaveHistory, error)
 }
 
 type LeaveHandler struct {
@@ -30,93 +35,123 @@ func NewLeaveHandler(leaveService LeaveServiceInterface) *LeaveHandler {
 
 // CreateLeave 創建請假記錄
 func (h *LeaveHandler) CreateLeave(c *gin.Context) {
-	var leave models.Leave
-	if err := c.ShouldBindJSON(&leave); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var req dto.CreateLeaveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
 		return
 	}
 
-	// 驗證必填字段
-	if leave.EmployeeID == 0 || leave.LeaveType == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Employee ID and leave type are required"})
-		return
-	}
-
-	if err := h.leaveService.CreateLeave(&leave); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	leave := req.ToModel()
+	warnings, err := h.leaveService.CreateLeave(leave)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, leave)
+	resp := dto.NewLeaveResponse(leave, middleware.GetLocale(c))
+	resp.StaffingWarnings = warnings
+	c.JSON(http.StatusCreated, resp)
 }
 
 // GetLeave 獲取請假記錄
 func (h *LeaveHandler) GetLeave(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		c.Error(errInvalidID())
 		return
 	}
 
 	leave, err := h.leaveService.GetLeave(uint(id))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Leave record not found"})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, leave)
+	c.JSON(http.StatusOK, dto.NewLeaveResponse(leave, middleware.GetLocale(c)))
 }
 
 // ListLeaves 獲取請假記錄列表
 func (h *LeaveHandler) ListLeaves(c *gin.Context) {
 	leaves, err := h.leaveService.ListLeaves()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
-	if leaves == nil {
-		leaves = []models.Leave{}
-	}
-	c.JSON(http.StatusOK, leaves)
+	c.JSON(http.StatusOK, dto.NewLeaveResponses(leaves, middleware.GetLocale(c)))
 }
 
-// UpdateLeaveStatus 更新請假狀態
+// UpdateLeaveStatus 更新請假狀態，審批人為網關識別的當前員工，需在 RequireIdentity 之後使用
 func (h *LeaveHandler) UpdateLeaveStatus(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		c.Error(errInvalidID())
 		return
 	}
 
-	var status struct {
-		Status string `json:"status"`
-		Remark string `json:"remark"`
-	}
+	var status dto.UpdateLeaveStatusRequest
 	if err := c.ShouldBindJSON(&status); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
 		return
 	}
 
-	if err := h.leaveService.UpdateLeaveStatus(uint(id), status.Status, status.Remark); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	warnings, err := h.leaveService.UpdateLeaveStatus(uint(id), status.Status, status.Remark, middleware.GetEmployeeID(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp := gin.H{"message": i18n.T(middleware.GetLocale(c), "message.leave_status_updated")}
+	if len(warnings) > 0 {
+		resp["staffing_warnings"] = warnings
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// CancelLeave 取消請假，路由須經過 RequireIdentity，只有申請人或審批人可以取消
+func (h *LeaveHandler) CancelLeave(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	if err := h.leaveService.CancelLeave(uint(id), middleware.GetEmployeeID(c)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(middleware.GetLocale(c), "message.leave_cancelled")})
+}
+
+// GetLeaveHistory 獲取請假的審批歷程，包括提醒、升級與自動核准
+func (h *LeaveHandler) GetLeaveHistory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	history, err := h.leaveService.GetLeaveHistory(uint(id))
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Leave status updated successfully"})
+	c.JSON(http.StatusOK, history)
 }
 
 // DeleteLeave 刪除請假記錄
 func (h *LeaveHandler) DeleteLeave(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID"})
+		c.Error(errInvalidID())
 		return
 	}
 
 	if err := h.leaveService.DeleteLeave(uint(id)); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Leave record deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(middleware.GetLocale(c), "message.leave_deleted")})
 }
@@ -8,7 +8,11 @@ import (
 	"testing"
 	"time"
 
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/middleware"
 	"hr-system/internal/models"
+	"hr-system/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -21,9 +25,12 @@ type MockLeaveService struct {
 	mock.Mock
 }
 
-func (m *MockLeaveService) CreateLeave(leave *models.Leave) error {
+func (m *MockLeaveService) CreateLeave(leave *models.Leave) ([]models.StaffingCoverage, error) {
 	args := m.Called(leave)
-	return args.Error(0)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.StaffingCoverage), args.Error(1)
 }
 
 func (m *MockLeaveService) GetLeave(id uint) (*models.Leave, error) {
@@ -45,8 +52,16 @@ func (m *MockLeaveService) ListLeaves() ([]models.Leave, error) {
 	return args.Get(0).([]models.Leave), nil
 }
 
-func (m *MockLeaveService) UpdateLeaveStatus(id uint, status string, remark string) error {
-	args := m.Called(id, status, remark)
+func (m *MockLeaveService) UpdateLeaveStatus(id uint, status string, remark string, approverID uint) ([]models.StaffingCoverage, error) {
+	args := m.Called(id, status, remark, approverID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.StaffingCoverage), args.Error(1)
+}
+
+func (m *MockLeaveService) CancelLeave(id uint, actorID uint) error {
+	args := m.Called(id, actorID)
 	return args.Error(0)
 }
 
@@ -55,12 +70,21 @@ func (m *MockLeaveService) DeleteLeave(id uint) error {
 	return args.Error(0)
 }
 
+func (m *MockLeaveService) GetLeaveHistory(id uint) ([]models.LeaveHistory, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.LeaveHistory), args.Error(1)
+}
+
 // 確保 MockLeaveService 實現了 LeaveServiceInterface
 var _ LeaveServiceInterface = (*MockLeaveService)(nil)
 
 func setupLeaveTestRouter(handler *LeaveHandler) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.Default()
+	r.Use(middleware.RequestID(), middleware.Locale(), middleware.ErrorHandler(), middleware.Identity())
 
 	api := r.Group("/api")
 	{
@@ -69,7 +93,9 @@ func setupLeaveTestRouter(handler *LeaveHandler) *gin.Engine {
 			leaves.POST("", handler.CreateLeave)
 			leaves.GET("", handler.ListLeaves)
 			leaves.GET("/:id", handler.GetLeave)
-			leaves.PUT("/:id/status", handler.UpdateLeaveStatus)
+			leaves.PUT("/:id/status", middleware.RequireIdentity(), handler.UpdateLeaveStatus)
+			leaves.PUT("/:id/cancel", middleware.RequireIdentity(), handler.CancelLeave)
+			leaves.GET("/:id/history", handler.GetLeaveHistory)
 			leaves.DELETE("/:id", handler.DeleteLeave)
 		}
 	}
@@ -101,7 +127,7 @@ func TestCreateLeave(t *testing.T) {
 				Reason:     "休息",
 			},
 			mockSetup: func() {
-				mockService.On("CreateLeave", mock.AnythingOfType("*models.Leave")).Return(nil)
+				mockService.On("CreateLeave", mock.AnythingOfType("*models.Leave")).Return(nil, nil)
 			},
 			wantStatus: http.StatusCreated,
 		},
@@ -162,7 +188,7 @@ func TestGetLeave(t *testing.T) {
 			name: "請假記錄不存在",
 			id:   "999",
 			mockSetup: func() {
-				mockService.On("GetLeave", uint(999)).Return(nil, assert.AnError)
+				mockService.On("GetLeave", uint(999)).Return(nil, apperrors.NotFound(apperrors.CodeLeaveNotFound, "Leave record not found"))
 			},
 			wantStatus: http.StatusNotFound,
 		},
@@ -249,25 +275,62 @@ func TestUpdateLeaveStatus(t *testing.T) {
 	tests := []struct {
 		name       string
 		id         string
+		approver   string
 		payload    map[string]string
 		mockSetup  func()
 		wantStatus int
 	}{
 		{
-			name: "成功更新請假狀態",
-			id:   "1",
+			name:     "成功更新請假狀態",
+			id:       "1",
+			approver: "3",
 			payload: map[string]string{
 				"status": "approved",
 				"remark": "同意",
 			},
 			mockSetup: func() {
-				mockService.On("UpdateLeaveStatus", uint(1), "approved", "同意").Return(nil)
+				mockService.On("UpdateLeaveStatus", uint(1), "approved", "同意", uint(3)).Return(nil, nil)
 			},
 			wantStatus: http.StatusOK,
 		},
+		{
+			name:     "記錄網關識別的審批人",
+			id:       "2",
+			approver: "3",
+			payload: map[string]string{
+				"status": "rejected",
+				"remark": "人力不足",
+			},
+			mockSetup: func() {
+				mockService.On("UpdateLeaveStatus", uint(2), "rejected", "人力不足", uint(3)).Return(nil, nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "缺少審批人",
+			id:   "4",
+			payload: map[string]string{
+				"status": "approved",
+			},
+			mockSetup:  func() {},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:     "非當前審批人",
+			id:       "3",
+			approver: "9",
+			payload: map[string]string{
+				"status": "approved",
+			},
+			mockSetup: func() {
+				mockService.On("UpdateLeaveStatus", uint(3), "approved", "", uint(9)).Return(nil, apperrors.Forbidden(apperrors.CodeNotApprover, "You are not the current approver of this leave request"))
+			},
+			wantStatus: http.StatusForbidden,
+		},
 		{
 			name:       "無效的ID",
 			id:         "invalid",
+			approver:   "3",
 			payload:    map[string]string{},
 			mockSetup:  func() {},
 			wantStatus: http.StatusBadRequest,
@@ -281,6 +344,9 @@ func TestUpdateLeaveStatus(t *testing.T) {
 			body, _ := json.Marshal(tt.payload)
 			req := httptest.NewRequest(http.MethodPut, "/api/leaves/"+tt.id+"/status", bytes.NewBuffer(body))
 			req.Header.Set("Content-Type", "application/json")
+			if tt.approver != "" {
+				req.Header.Set(middleware.EmployeeIDHeader, tt.approver)
+			}
 
 			w := httptest.NewRecorder()
 			router.ServeHTTP(w, req)
@@ -290,6 +356,75 @@ func TestUpdateLeaveStatus(t *testing.T) {
 	}
 }
 
+func TestCancelLeave(t *testing.T) {
+	mockService := &MockLeaveService{}
+	handler := NewLeaveHandler(mockService)
+	router := setupLeaveTestRouter(handler)
+
+	tests := []struct {
+		name       string
+		id         string
+		anonymous  bool
+		mockSetup  func()
+		wantStatus int
+	}{
+		{
+			name: "成功取消請假",
+			id:   "1",
+			mockSetup: func() {
+				mockService.On("CancelLeave", uint(1), uint(3)).Return(nil)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name: "不是申請人或審批人",
+			id:   "3",
+			mockSetup: func() {
+				mockService.On("CancelLeave", uint(3), uint(3)).
+					Return(apperrors.Forbidden(apperrors.CodeNotLeaveOwner, "Only the applicant or the approver can cancel this leave request"))
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "缺少員工身份",
+			id:         "1",
+			anonymous:  true,
+			mockSetup:  func() {},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "已駁回的請假不能取消",
+			id:   "2",
+			mockSetup: func() {
+				mockService.On("CancelLeave", uint(2), uint(3)).
+					Return(apperrors.Conflict(apperrors.CodeLeaveNotCancelable, "Only pending or approved leave can be cancelled"))
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "無效的ID",
+			id:         "invalid",
+			mockSetup:  func() {},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest(http.MethodPut, "/api/leaves/"+tt.id+"/cancel", nil)
+			if !tt.anonymous {
+				req.Header.Set(middleware.EmployeeIDHeader, "3")
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
 func TestDeleteLeave(t *testing.T) {
 	mockService := &MockLeaveService{}
 	handler := NewLeaveHandler(mockService)
@@ -313,7 +448,15 @@ func TestDeleteLeave(t *testing.T) {
 			name: "請假記錄不存在",
 			id:   "999",
 			mockSetup: func() {
-				mockService.On("DeleteLeave", uint(999)).Return(assert.AnError)
+				mockService.On("DeleteLeave", uint(999)).Return(apperrors.NotFound(apperrors.CodeLeaveNotFound, "Leave record not found"))
+			},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "數據庫錯誤",
+			id:   "500",
+			mockSetup: func() {
+				mockService.On("DeleteLeave", uint(500)).Return(assert.AnError)
 			},
 			wantStatus: http.StatusInternalServerError,
 		},
@@ -337,3 +480,151 @@ func TestDeleteLeave(t *testing.T) {
 		})
 	}
 }
+
+func TestGetLeaveHistory(t *testing.T) {
+	mockService := &MockLeaveService{}
+	handler := NewLeaveHandler(mockService)
+	router := setupLeaveTestRouter(handler)
+
+	manager, director := uint(2), uint(3)
+	mockService.On("GetLeaveHistory", uint(1)).Return([]models.LeaveHistory{
+		{ID: 1, LeaveID: 1, Action: models.LeaveHistorySubmitted},
+		{ID: 2, LeaveID: 1, Action: models.LeaveHistoryEscalated, ApproverID: &director, PreviousApproverID: &manager},
+	}, nil)
+	mockService.On("GetLeaveHistory", uint(999)).Return(nil, apperrors.NotFound(apperrors.CodeLeaveNotFound, "Leave record not found"))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/leaves/1/history", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var history []models.LeaveHistory
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+	if assert.Len(t, history, 2) {
+		assert.Equal(t, models.LeaveHistoryEscalated, history[1].Action)
+		assert.Equal(t, &director, history[1].ApproverID)
+		assert.Equal(t, &manager, history[1].PreviousApproverID)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/leaves/999/history", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/leaves/invalid/history", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCreateLeaveValidation(t *testing.T) {
+	mockService := &MockLeaveService{}
+	handler := NewLeaveHandler(mockService)
+	router := setupLeaveTestRouter(handler)
+
+	tests := []struct {
+		name      string
+		body      string
+		wantField string
+		wantCode  string
+	}{
+		{
+			name:      "未知的請假類型",
+			body:      `{"employee_id":1,"leave_type":"旅遊假","start_date":"2024-04-01T00:00:00Z","end_date":"2024-04-02T00:00:00Z"}`,
+			wantField: "leave_type",
+			wantCode:  "leave_type",
+		},
+		{
+			name:      "結束日期早於開始日期",
+			body:      `{"employee_id":1,"leave_type":"年假","start_date":"2024-04-02T00:00:00Z","end_date":"2024-04-01T00:00:00Z"}`,
+			wantField: "end_date",
+			wantCode:  "gtefield",
+		},
+		{
+			name:      "跨度超過一年",
+			body:      `{"employee_id":1,"leave_type":"事假","start_date":"2024-01-01T00:00:00Z","end_date":"2025-06-01T00:00:00Z"}`,
+			wantField: "end_date",
+			wantCode:  "max_leave_span",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/leaves", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+
+			var resp middleware.ErrorResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			if assert.Len(t, resp.Error.Fields, 1) {
+				assert.Equal(t, tt.wantField, resp.Error.Fields[0].Field)
+				assert.Equal(t, tt.wantCode, resp.Error.Fields[0].Code)
+			}
+		})
+	}
+}
+
+func TestCreateLeaveIdempotency(t *testing.T) {
+	mockService := &MockLeaveService{}
+	handler := NewLeaveHandler(mockService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RequestID(), middleware.Locale(), middleware.ErrorHandler())
+	idempotency := middleware.Idempotency(services.NewMemoryIdempotencyStore(), config.IdempotencyConfig{
+		TTL:         time.Hour,
+		InFlightTTL: time.Minute,
+	})
+	router.POST("/api/leaves", idempotency, handler.CreateLeave)
+
+	send := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/leaves", bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.IdempotencyKeyHeader, key)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	body := `{"employee_id":1,"leave_type":"年假","start_date":"2024-04-01T00:00:00Z","end_date":"2024-04-02T00:00:00Z"}`
+	mockService.On("CreateLeave", mock.AnythingOfType("*models.Leave")).Return(nil, nil).Once()
+
+	first := send("key-1", body)
+	assert.Equal(t, http.StatusCreated, first.Code)
+	assert.Empty(t, first.Header().Get(middleware.IdempotentReplayedHeader))
+
+	t.Run("相同請求重放已保存的響應", func(t *testing.T) {
+		// 字段順序與空白不同仍視為相同請求
+		retry := send("key-1", `{"leave_type":"年假", "employee_id":1, "start_date":"2024-04-01T00:00:00Z","end_date":"2024-04-02T00:00:00Z"}`)
+		assert.Equal(t, http.StatusCreated, retry.Code)
+		assert.Equal(t, "true", retry.Header().Get(middleware.IdempotentReplayedHeader))
+		assert.Equal(t, first.Body.String(), retry.Body.String())
+		mockService.AssertNumberOfCalls(t, "CreateLeave", 1)
+	})
+
+	t.Run("相同冪等鍵但請求體不同", func(t *testing.T) {
+		w := send("key-1", `{"employee_id":2,"leave_type":"年假","start_date":"2024-04-01T00:00:00Z","end_date":"2024-04-02T00:00:00Z"}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+		var resp middleware.ErrorResponse
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, apperrors.CodeIdempotencyKeyReused, resp.Error.Code)
+	})
+
+	t.Run("服務器錯誤後允許使用相同冪等鍵重試", func(t *testing.T) {
+		mockService.On("CreateLeave", mock.AnythingOfType("*models.Leave")).Return(nil, assert.AnError).Once()
+		assert.Equal(t, http.StatusInternalServerError, send("key-2", body).Code)
+
+		mockService.On("CreateLeave", mock.AnythingOfType("*models.Leave")).Return(nil, nil).Once()
+		w := send("key-2", body)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Empty(t, w.Header().Get(middleware.IdempotentReplayedHeader))
+	})
+
+	t.Run("空的冪等鍵", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, send("", body).Code)
+	})
+}
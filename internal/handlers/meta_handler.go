@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"hr-system/internal/i18n"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EnumOption 枚舉值及其在當前語系下的顯示名稱
+type EnumOption struct {
+	Value string `json:"value"`
+	Label string `json:"label"`
+}
+
+type MetaHandler struct{}
+
+func NewMetaHandler() *MetaHandler {
+	return &MetaHandler{}
+}
+
+// GetEnums 返回前端下拉選單使用的枚舉值與本地化名稱
+func (h *MetaHandler) GetEnums(c *gin.Context) {
+	locale := middleware.GetLocale(c)
+	c.JSON(http.StatusOK, gin.H{
+		"locale":                 locale,
+		"leave_types":            enumOptions(locale, "leave_type", models.LeaveTypes),
+		"bulk_leave_types":       enumOptions(locale, "leave_type", models.BulkLeaveTypes),
+		"leave_statuses":         enumOptions(locale, "leave_status", models.LeaveStatuses),
+		"employee_statuses":      enumOptions(locale, "employee_status", models.EmployeeStatuses),
+		"overtime_day_types":     enumOptions(locale, "overtime_day_type", models.OvertimeDayTypes),
+		"overtime_compensations": enumOptions(locale, "overtime_compensation", models.OvertimeCompensations),
+		"attendance_anomalies":   enumOptions(locale, "attendance_anomaly", models.AttendanceAnomalies),
+		"schedule_kinds":         enumOptions(locale, "schedule_kind", models.ScheduleKinds),
+		"shift_swap_statuses":    enumOptions(locale, "shift_swap_status", models.ShiftSwapStatuses),
+		"pay_component_kinds":    enumOptions(locale, "pay_component_kind", models.PayComponentKinds),
+		"payroll_run_statuses":   enumOptions(locale, "payroll_run_status", models.PayrollRunStatuses),
+		"insurance_kinds":        enumOptions(locale, "insurance_kind", models.InsuranceKinds),
+		"departments":            enumOptions(locale, "department", catalogValues("department")),
+		"locales":                enumOptions(locale, "locale", i18n.Supported),
+	})
+}
+
+func enumOptions(locale, kind string, values []string) []EnumOption {
+	options := make([]EnumOption, 0, len(values))
+	for _, v := range values {
+		options = append(options, EnumOption{Value: v, Label: i18n.Label(locale, kind, v)})
+	}
+	return options
+}
+
+// catalogValues 從默認語系目錄中列出某類枚舉的所有值
+func catalogValues(kind string) []string {
+	var values []string
+	for _, key := range i18n.Keys(i18n.Default) {
+		if value, ok := strings.CutPrefix(key, kind+"."); ok {
+			values = append(values, value)
+		}
+	}
+	return values
+}
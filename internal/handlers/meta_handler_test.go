@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"hr-system/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetEnums(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.Locale(), middleware.ErrorHandler())
+	r.GET("/api/meta/enums", NewMetaHandler().GetEnums)
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		wantLocale     string
+		wantLabel      string
+	}{
+		{name: "默認繁體中文", wantLocale: "zh-TW", wantLabel: "年假"},
+		{name: "英文", acceptLanguage: "en", wantLocale: "en", wantLabel: "Annual leave"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/meta/enums", nil)
+			req.Header.Set("Accept-Language", tt.acceptLanguage)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			var resp struct {
+				Locale      string       `json:"locale"`
+				LeaveTypes  []EnumOption `json:"leave_types"`
+				Departments []EnumOption `json:"departments"`
+			}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.wantLocale, resp.Locale)
+			if assert.NotEmpty(t, resp.LeaveTypes) {
+				assert.Equal(t, "年假", resp.LeaveTypes[0].Value)
+				assert.Equal(t, tt.wantLabel, resp.LeaveTypes[0].Label)
+			}
+			assert.NotEmpty(t, resp.Departments)
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/dto"
+	"hr-system/internal/i18n"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+	"hr-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultNotificationLogLimit = 100
+	maxNotificationLogLimit     = 500
+
+	defaultNotificationPageSize = 20
+	maxNotificationPageSize     = 100
+)
+
+// NotificationServiceInterface 定義通知服務接口
+type NotificationServiceInterface interface {
+	GetPreferences(employeeID uint) ([]models.NotificationPreference, error)
+	UpdatePreferences(employeeID uint, prefs []models.NotificationPreference) ([]models.NotificationPreference, error)
+	ListLogs(recipientID uint, status string, limit int) ([]models.NotificationLog, error)
+	ListNotifications(recipientID uint, unreadOnly bool, page, pageSize int) (*services.NotificationPage, error)
+	NotificationsAfter(recipientID, afterID uint) ([]models.Notification, error)
+	MarkRead(recipientID, id uint) error
+	MarkAllRead(recipientID uint) (int64, error)
+	Subscribe(recipientID uint) (<-chan models.Notification, func())
+}
+
+type NotificationHandler struct {
+	notificationService NotificationServiceInterface
+	heartbeat           time.Duration // 推送流的心跳間隔
+}
+
+func NewNotificationHandler(notificationService NotificationServiceInterface, heartbeat time.Duration) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		heartbeat:           heartbeat,
+	}
+}
+
+// GetPreferences 獲取員工的通知偏好
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	prefs, err := h.notificationService.GetPreferences(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preferences": prefs})
+}
+
+// UpdatePreferences 更新員工的通知偏好
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	var req dto.NotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
+		return
+	}
+
+	prefs, err := h.notificationService.UpdatePreferences(uint(id), req.ToModels(uint(id)))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     i18n.T(middleware.GetLocale(c), "message.notification_preferences_updated"),
+		"preferences": prefs,
+	})
+}
+
+// ListLogs 查詢通知發送記錄，可用 employee_id 與 status 篩選
+func (h *NotificationHandler) ListLogs(c *gin.Context) {
+	var recipientID uint64
+	if raw := c.Query("employee_id"); raw != "" {
+		var err error
+		recipientID, err = strconv.ParseUint(raw, 10, 32)
+		if err != nil || recipientID == 0 {
+			c.Error(apperrors.Validation(apperrors.CodeValidationFailed, "Invalid employee ID",
+				apperrors.Field("employee_id", "gt", fieldMessage(c, "gt", "employee_id", "0"))))
+			return
+		}
+	}
+
+	status := c.Query("status")
+	if status != "" && !containsString(models.NotificationStatuses, status) {
+		c.Error(apperrors.Validation(apperrors.CodeValidationFailed, "Invalid notification status",
+			apperrors.Field("status", "oneof", fieldMessage(c, "oneof", "status", strings.Join(models.NotificationStatuses, ", ")))))
+		return
+	}
+
+	limit, err := queryLimit(c, defaultNotificationLogLimit, maxNotificationLogLimit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	logs, err := h.notificationService.ListLogs(uint(recipientID), status, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if logs == nil {
+		logs = []models.NotificationLog{}
+	}
+	c.JSON(http.StatusOK, logs)
+}
+
+// ListMyNotifications 分頁獲取當前員工的站內通知，unread=true 時只返回未讀通知
+func (h *NotificationHandler) ListMyNotifications(c *gin.Context) {
+	page, pageSize, err := queryPage(c, defaultNotificationPageSize, maxNotificationPageSize)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	unreadOnly, err := strconv.ParseBool(c.DefaultQuery("unread", "false"))
+	if err != nil {
+		c.Error(apperrors.Validation(apperrors.CodeValidationFailed, "Invalid unread filter",
+			apperrors.Field("unread", "type", fieldMessage(c, "type", "unread", ""))))
+		return
+	}
+
+	result, err := h.notificationService.ListNotifications(middleware.GetEmployeeID(c), unreadOnly, page, pageSize)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// MarkNotificationRead 將當前員工的一條站內通知標記為已讀
+func (h *NotificationHandler) MarkNotificationRead(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	if err := h.notificationService.MarkRead(middleware.GetEmployeeID(c), uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(middleware.GetLocale(c), "message.notification_read")})
+}
+
+// MarkAllNotificationsRead 將當前員工的所有站內通知標記為已讀
+func (h *NotificationHandler) MarkAllNotificationsRead(c *gin.Context) {
+	count, err := h.notificationService.MarkAllRead(middleware.GetEmployeeID(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"message": i18n.T(middleware.GetLocale(c), "message.notifications_read"),
+		"count":   count,
+	})
+}
+
+// StreamNotifications 以 Server-Sent Events 推送當前員工的新站內通知
+// 每條通知為一個 notification 事件，事件ID為通知ID；瀏覽器重連時帶上 Last-Event-ID，
+// 服務端會先補發斷線期間的通知。空閒時定期發送註釋行作為心跳
+func (h *NotificationHandler) StreamNotifications(c *gin.Context) {
+	recipientID := middleware.GetEmployeeID(c)
+
+	// 先訂閱再補發，避免補發與訂閱之間產生的通知丟失；重複的通知按ID跳過
+	notifications, unsubscribe := h.notificationService.Subscribe(recipientID)
+	defer unsubscribe()
+
+	var lastID uint
+	var replay []models.Notification
+	if raw := c.GetHeader("Last-Event-ID"); raw != "" {
+		if id, err := strconv.ParseUint(raw, 10, 32); err == nil {
+			lastID = uint(id)
+			replay, err = h.notificationService.NotificationsAfter(recipientID, lastID)
+			if err != nil {
+				c.Error(err)
+				return
+			}
+		}
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // 關閉 nginx 的響應緩衝
+	c.Status(http.StatusOK)
+	fmt.Fprint(c.Writer, "retry: 5000\n\n")
+	for i := range replay {
+		if !writeNotificationEvent(c, &replay[i], &lastID) {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case n := <-notifications:
+			if !writeNotificationEvent(c, &n, &lastID) {
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+// writeNotificationEvent 寫入一個 notification 事件，已發送過的通知跳過；寫入失敗（連接已斷開）時返回 false
+func writeNotificationEvent(c *gin.Context, n *models.Notification, lastID *uint) bool {
+	if n.ID <= *lastID {
+		return true
+	}
+	data, err := json.Marshal(n)
+	if err != nil {
+		log.Printf("Failed to encode notification %d: %v", n.ID, err)
+		return true
+	}
+	if _, err := fmt.Fprintf(c.Writer, "id: %d\nevent: notification\ndata: %s\n\n", n.ID, data); err != nil {
+		return false
+	}
+	*lastID = n.ID
+	return true
+}
@@ -0,0 +1,373 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+	"hr-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockNotificationService 模擬通知服務
+type MockNotificationService struct {
+	mock.Mock
+}
+
+func (m *MockNotificationService) GetPreferences(employeeID uint) ([]models.NotificationPreference, error) {
+	args := m.Called(employeeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.NotificationPreference), args.Error(1)
+}
+
+func (m *MockNotificationService) UpdatePreferences(employeeID uint, prefs []models.NotificationPreference) ([]models.NotificationPreference, error) {
+	args := m.Called(employeeID, prefs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.NotificationPreference), args.Error(1)
+}
+
+func (m *MockNotificationService) ListLogs(recipientID uint, status string, limit int) ([]models.NotificationLog, error) {
+	args := m.Called(recipientID, status, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.NotificationLog), args.Error(1)
+}
+
+func (m *MockNotificationService) ListNotifications(recipientID uint, unreadOnly bool, page, pageSize int) (*services.NotificationPage, error) {
+	args := m.Called(recipientID, unreadOnly, page, pageSize)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*services.NotificationPage), args.Error(1)
+}
+
+func (m *MockNotificationService) NotificationsAfter(recipientID, afterID uint) ([]models.Notification, error) {
+	args := m.Called(recipientID, afterID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Notification), args.Error(1)
+}
+
+func (m *MockNotificationService) MarkRead(recipientID, id uint) error {
+	args := m.Called(recipientID, id)
+	return args.Error(0)
+}
+
+func (m *MockNotificationService) MarkAllRead(recipientID uint) (int64, error) {
+	args := m.Called(recipientID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockNotificationService) Subscribe(recipientID uint) (<-chan models.Notification, func()) {
+	args := m.Called(recipientID)
+	return args.Get(0).(chan models.Notification), func() {}
+}
+
+// 確保 MockNotificationService 實現了 NotificationServiceInterface
+var _ NotificationServiceInterface = (*MockNotificationService)(nil)
+
+func setupNotificationTestRouter(handler *NotificationHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.Locale(), middleware.ErrorHandler(), middleware.Identity())
+
+	r.GET("/api/employees/:id/notification-preferences", handler.GetPreferences)
+	r.PUT("/api/employees/:id/notification-preferences", handler.UpdatePreferences)
+	r.GET("/api/admin/notifications/logs", handler.ListLogs)
+
+	me := r.Group("/api/me", middleware.RequireIdentity())
+	{
+		me.GET("/notifications", handler.ListMyNotifications)
+		me.GET("/notifications/stream", handler.StreamNotifications)
+		me.PUT("/notifications/read-all", handler.MarkAllNotificationsRead)
+		me.PUT("/notifications/:id/read", handler.MarkNotificationRead)
+	}
+	return r
+}
+
+func TestGetNotificationPreferences(t *testing.T) {
+	mockService := &MockNotificationService{}
+	router := setupNotificationTestRouter(NewNotificationHandler(mockService, time.Minute))
+
+	mockService.On("GetPreferences", uint(1)).Return([]models.NotificationPreference{
+		{EmployeeID: 1, EventType: models.EventLeaveSubmitted, Email: true},
+		{EmployeeID: 1, EventType: models.EventLeaveApproved, Email: false},
+	}, nil)
+	mockService.On("GetPreferences", uint(999)).Return(nil, apperrors.NotFound(apperrors.CodeEmployeeNotFound, "Employee not found"))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/employees/1/notification-preferences", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Preferences []models.NotificationPreference `json:"preferences"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Preferences, 2)
+	assert.False(t, resp.Preferences[1].Email)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/employees/999/notification-preferences", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestUpdateNotificationPreferences(t *testing.T) {
+	mockService := &MockNotificationService{}
+	router := setupNotificationTestRouter(NewNotificationHandler(mockService, time.Minute))
+
+	tests := []struct {
+		name       string
+		body       string
+		mockSetup  func()
+		wantStatus int
+		wantField  string
+	}{
+		{
+			name: "關閉核准通知",
+			body: `{"preferences":[{"event_type":"leave.approved","email":false}]}`,
+			mockSetup: func() {
+				mockService.On("UpdatePreferences", uint(1), []models.NotificationPreference{
+					{EmployeeID: 1, EventType: models.EventLeaveApproved, Email: false},
+				}).Return([]models.NotificationPreference{
+					{EmployeeID: 1, EventType: models.EventLeaveApproved, Email: false},
+				}, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "不支持通知的事件類型",
+			body:       `{"preferences":[{"event_type":"employee.hired","email":false}]}`,
+			mockSetup:  func() {},
+			wantStatus: http.StatusBadRequest,
+			wantField:  "preferences[0].event_type",
+		},
+		{
+			name:       "缺少 email",
+			body:       `{"preferences":[{"event_type":"leave.approved"}]}`,
+			mockSetup:  func() {},
+			wantStatus: http.StatusBadRequest,
+			wantField:  "preferences[0].email",
+		},
+		{
+			name:       "空列表",
+			body:       `{"preferences":[]}`,
+			mockSetup:  func() {},
+			wantStatus: http.StatusBadRequest,
+			wantField:  "preferences",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest(http.MethodPut, "/api/employees/1/notification-preferences", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantField != "" {
+				var resp middleware.ErrorResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				if assert.Len(t, resp.Error.Fields, 1) {
+					assert.Equal(t, tt.wantField, resp.Error.Fields[0].Field)
+				}
+			}
+		})
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestListNotificationLogs(t *testing.T) {
+	mockService := &MockNotificationService{}
+	router := setupNotificationTestRouter(NewNotificationHandler(mockService, time.Minute))
+
+	tests := []struct {
+		name       string
+		query      string
+		mockSetup  func()
+		wantStatus int
+	}{
+		{
+			name:  "按收件人與狀態篩選",
+			query: "?employee_id=3&status=failed",
+			mockSetup: func() {
+				mockService.On("ListLogs", uint(3), models.NotificationStatusFailed, 100).
+					Return([]models.NotificationLog{{EventType: models.EventLeaveApproved, RecipientID: 3, Status: models.NotificationStatusFailed}}, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "無效的狀態",
+			query:      "?status=bounced",
+			mockSetup:  func() {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "無效的員工ID",
+			query:      "?employee_id=abc",
+			mockSetup:  func() {},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/notifications/logs"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestListMyNotifications(t *testing.T) {
+	mockService := &MockNotificationService{}
+	router := setupNotificationTestRouter(NewNotificationHandler(mockService, time.Minute))
+
+	tests := []struct {
+		name       string
+		query      string
+		employeeID string
+		mockSetup  func()
+		wantStatus int
+	}{
+		{
+			name:       "第二頁的未讀通知",
+			query:      "?page=2&page_size=10&unread=true",
+			employeeID: "7",
+			mockSetup: func() {
+				mockService.On("ListNotifications", uint(7), true, 2, 10).Return(&services.NotificationPage{
+					Items:    []models.Notification{{RecipientID: 7, EventType: models.EventLeaveApproved, Title: "請假申請已核准"}},
+					Total:    11,
+					Unread:   11,
+					Page:     2,
+					PageSize: 10,
+				}, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "每頁筆數超過上限",
+			query:      "?page_size=1000",
+			employeeID: "7",
+			mockSetup:  func() {},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "未識別當前員工",
+			query:      "",
+			employeeID: "",
+			mockSetup:  func() {},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/me/notifications"+tt.query, nil)
+			if tt.employeeID != "" {
+				req.Header.Set(middleware.EmployeeIDHeader, tt.employeeID)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestMarkNotificationRead(t *testing.T) {
+	mockService := &MockNotificationService{}
+	router := setupNotificationTestRouter(NewNotificationHandler(mockService, time.Minute))
+
+	mockService.On("MarkRead", uint(7), uint(42)).Return(nil)
+	mockService.On("MarkRead", uint(7), uint(43)).Return(apperrors.NotFound(apperrors.CodeNotificationNotFound, "Notification not found"))
+	mockService.On("MarkAllRead", uint(7)).Return(int64(3), nil)
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+	}{
+		{"標記單條通知", "/api/me/notifications/42/read", http.StatusOK},
+		{"通知不存在或屬於其他員工", "/api/me/notifications/43/read", http.StatusNotFound},
+		{"無效的ID", "/api/me/notifications/abc/read", http.StatusBadRequest},
+		{"全部標記為已讀", "/api/me/notifications/read-all", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, tt.path, nil)
+			req.Header.Set(middleware.EmployeeIDHeader, "7")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestStreamNotifications(t *testing.T) {
+	mockService := &MockNotificationService{}
+	router := setupNotificationTestRouter(NewNotificationHandler(mockService, time.Minute))
+
+	live := make(chan models.Notification, 1)
+	mockService.On("Subscribe", uint(7)).Return(live)
+	missed := models.Notification{RecipientID: 7, Title: "請假申請已駁回"}
+	missed.ID = 41
+	mockService.On("NotificationsAfter", uint(7), uint(40)).Return([]models.Notification{missed}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest(http.MethodGet, "/api/me/notifications/stream", nil).WithContext(ctx)
+	req.Header.Set(middleware.EmployeeIDHeader, "7")
+	req.Header.Set("Last-Event-ID", "40")
+	w := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		router.ServeHTTP(w, req)
+		close(done)
+	}()
+
+	approved := models.Notification{RecipientID: 7, Title: "請假申請已核准"}
+	approved.ID = 42
+	live <- missed // 補發過的通知不會重複推送
+	live <- approved
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	<-done
+
+	body := w.Body.String()
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Equal(t, 1, strings.Count(body, "id: 41\n"))
+	assert.Contains(t, body, "id: 42\nevent: notification\ndata: ")
+	assert.Less(t, strings.Index(body, "id: 41"), strings.Index(body, "id: 42"))
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"hr-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OutboxRelayInterface 定義事件中繼接口
+type OutboxRelayInterface interface {
+	Status(ctx context.Context) services.OutboxStatus
+}
+
+type OutboxHandler struct {
+	outboxRelay OutboxRelayInterface
+}
+
+func NewOutboxHandler(outboxRelay OutboxRelayInterface) *OutboxHandler {
+	return &OutboxHandler{
+		outboxRelay: outboxRelay,
+	}
+}
+
+// GetOutboxStatus 獲取事件中繼狀態與未發佈事件的積壓情況
+func (h *OutboxHandler) GetOutboxStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, h.outboxRelay.Status(c.Request.Context()))
+}
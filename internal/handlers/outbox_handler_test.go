@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"hr-system/internal/repositories"
+	"hr-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockOutboxRelay 模擬事件中繼
+type MockOutboxRelay struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRelay) Status(ctx context.Context) services.OutboxStatus {
+	args := m.Called()
+	return args.Get(0).(services.OutboxStatus)
+}
+
+func TestGetOutboxStatus(t *testing.T) {
+	mockRelay := &MockOutboxRelay{}
+	mockRelay.On("Status").Return(services.OutboxStatus{
+		Sinks:     []string{"webhook", "stdout"},
+		Stats:     &repositories.OutboxStats{Pending: 3, Failing: 1},
+		Published: 42,
+	})
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/api/admin/outbox", NewOutboxHandler(mockRelay).GetOutboxStatus)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/outbox", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var resp services.OutboxStatus
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []string{"webhook", "stdout"}, resp.Sinks)
+	assert.Equal(t, int64(3), resp.Stats.Pending)
+	assert.Equal(t, int64(42), resp.Published)
+}
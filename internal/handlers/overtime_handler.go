@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/dto"
+	"hr-system/internal/i18n"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+	"hr-system/internal/repositories"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OvertimeServiceInterface 定義加班服務接口
+type OvertimeServiceInterface interface {
+	Create(overtime *models.OvertimeRequest) error
+	Get(id uint) (*models.OvertimeRequest, error)
+	List(filter repositories.OvertimeFilter) ([]models.OvertimeRequest, error)
+	UpdateStatus(id uint, status, remark string, approverID uint) (*models.OvertimeRequest, error)
+	Cancel(id uint) error
+}
+
+type OvertimeHandler struct {
+	overtimeService OvertimeServiceInterface
+}
+
+func NewOvertimeHandler(overtimeService OvertimeServiceInterface) *OvertimeHandler {
+	return &OvertimeHandler{
+		overtimeService: overtimeService,
+	}
+}
+
+// CreateOvertime 提交加班申請
+func (h *OvertimeHandler) CreateOvertime(c *gin.Context) {
+	var req dto.CreateOvertimeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
+		return
+	}
+
+	overtime := req.ToModel()
+	if err := h.overtimeService.Create(overtime); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, overtime)
+}
+
+// ListOvertime 獲取加班申請，可按員工（employee_id）、狀態（status）與加班日期（from/to，包含兩端）篩選
+func (h *OvertimeHandler) ListOvertime(c *gin.Context) {
+	var filter repositories.OvertimeFilter
+	if raw := c.Query("employee_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil || id == 0 {
+			c.Error(apperrors.Validation(apperrors.CodeValidationFailed, "Invalid employee ID",
+				apperrors.Field("employee_id", "gt", fieldMessage(c, "gt", "employee_id", "0"))))
+			return
+		}
+		filter.EmployeeID = uint(id)
+	}
+	if filter.Status = c.Query("status"); filter.Status != "" && !containsString(models.LeaveStatuses, filter.Status) {
+		statuses := strings.Join(models.LeaveStatuses, ", ")
+		c.Error(apperrors.Validation(apperrors.CodeValidationFailed, "Invalid status",
+			apperrors.Field("status", "oneof", fieldMessage(c, "oneof", "status", statuses))))
+		return
+	}
+	from, err := queryDate(c, "from", time.Time{})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	to, err := queryDate(c, "to", time.Time{})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	filter.From = from
+	if !to.IsZero() {
+		filter.To = to.AddDate(0, 0, 1)
+	}
+
+	overtimes, err := h.overtimeService.List(filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if overtimes == nil {
+		overtimes = []models.OvertimeRequest{}
+	}
+	c.JSON(http.StatusOK, overtimes)
+}
+
+// GetOvertime 獲取加班申請
+func (h *OvertimeHandler) GetOvertime(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	overtime, err := h.overtimeService.Get(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, overtime)
+}
+
+// UpdateOvertimeStatus 審批加班申請，請求體與審批請假相同，路由須經過 RequireIdentity
+func (h *OvertimeHandler) UpdateOvertimeStatus(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	var req dto.UpdateLeaveStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
+		return
+	}
+
+	overtime, err := h.overtimeService.UpdateStatus(uint(id), req.Status, req.Remark, middleware.GetEmployeeID(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, overtime)
+}
+
+// CancelOvertime 取消待審批的加班申請
+func (h *OvertimeHandler) CancelOvertime(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	if err := h.overtimeService.Cancel(uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(middleware.GetLocale(c), "message.overtime_cancelled")})
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+	"hr-system/internal/repositories"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockOvertimeService 模擬加班服務
+type MockOvertimeService struct {
+	mock.Mock
+}
+
+func (m *MockOvertimeService) Create(overtime *models.OvertimeRequest) error {
+	args := m.Called(overtime)
+	return args.Error(0)
+}
+
+func (m *MockOvertimeService) Get(id uint) (*models.OvertimeRequest, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OvertimeRequest), args.Error(1)
+}
+
+func (m *MockOvertimeService) List(filter repositories.OvertimeFilter) ([]models.OvertimeRequest, error) {
+	args := m.Called(filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.OvertimeRequest), args.Error(1)
+}
+
+func (m *MockOvertimeService) UpdateStatus(id uint, status, remark string, approverID uint) (*models.OvertimeRequest, error) {
+	args := m.Called(id, status, remark, approverID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.OvertimeRequest), args.Error(1)
+}
+
+func (m *MockOvertimeService) Cancel(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+// 確保 MockOvertimeService 實現了 OvertimeServiceInterface
+var _ OvertimeServiceInterface = (*MockOvertimeService)(nil)
+
+func setupOvertimeTestRouter(handler *OvertimeHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.Locale(), middleware.ErrorHandler(), middleware.Identity())
+
+	r.POST("/api/overtime", handler.CreateOvertime)
+	r.GET("/api/overtime", handler.ListOvertime)
+	r.GET("/api/overtime/:id", handler.GetOvertime)
+	r.PUT("/api/overtime/:id/status", middleware.RequireIdentity(), handler.UpdateOvertimeStatus)
+	r.PUT("/api/overtime/:id/cancel", handler.CancelOvertime)
+	return r
+}
+
+func TestCreateOvertime(t *testing.T) {
+	mockService := &MockOvertimeService{}
+	router := setupOvertimeTestRouter(NewOvertimeHandler(mockService))
+
+	mockService.On("Create", mock.MatchedBy(func(overtime *models.OvertimeRequest) bool {
+		return overtime.EmployeeID == 1 && overtime.Hours == 3 && overtime.Date.Day() == 15 &&
+			overtime.Compensation == models.OvertimeCompensationCompTime
+	})).Run(func(args mock.Arguments) {
+		overtime := args.Get(0).(*models.OvertimeRequest)
+		overtime.ID = 5
+		overtime.DayType = models.OvertimeDayRestDay
+	}).Return(nil).Once()
+	mockService.On("Create", mock.MatchedBy(func(overtime *models.OvertimeRequest) bool {
+		return overtime.EmployeeID == 2
+	})).Return(apperrors.Conflict(apperrors.CodeOvertimeExists, "An overtime request already exists for this date")).Once()
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "成功提交", body: `{"employee_id":1,"date":"2024-06-15","hours":3,"reason":"版本上線","compensation":"comp_time"}`, wantStatus: http.StatusCreated},
+		{name: "同日已有申請", body: `{"employee_id":2,"date":"2024-06-15","hours":2,"compensation":"pay"}`, wantStatus: http.StatusConflict},
+		{name: "時數超過上限", body: `{"employee_id":1,"date":"2024-06-15","hours":13,"compensation":"pay"}`, wantStatus: http.StatusBadRequest},
+		{name: "無效的補償方式", body: `{"employee_id":1,"date":"2024-06-15","hours":2,"compensation":"cash"}`, wantStatus: http.StatusBadRequest},
+		{name: "日期格式錯誤", body: `{"employee_id":1,"date":"2024/06/15","hours":2,"compensation":"pay"}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/overtime", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestListOvertime(t *testing.T) {
+	mockService := &MockOvertimeService{}
+	router := setupOvertimeTestRouter(NewOvertimeHandler(mockService))
+
+	from := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.Local)
+	mockService.On("List", repositories.OvertimeFilter{
+		EmployeeID: 1,
+		Status:     models.LeaveStatusApproved,
+		From:       from,
+		To:         from.AddDate(0, 1, 0),
+	}).Return(nil, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/overtime?employee_id=1&status=approved&from=2024-06-01&to=2024-06-30", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "[]", w.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/api/overtime?status=done", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestUpdateOvertimeStatus(t *testing.T) {
+	mockService := &MockOvertimeService{}
+	router := setupOvertimeTestRouter(NewOvertimeHandler(mockService))
+
+	expiresAt := time.Date(2024, time.December, 31, 0, 0, 0, 0, time.Local)
+	mockService.On("UpdateStatus", uint(5), "approved", "", uint(9)).Return(&models.OvertimeRequest{
+		EmployeeID:    1,
+		Status:        models.LeaveStatusApproved,
+		Compensation:  models.OvertimeCompensationCompTime,
+		CompDays:      0.375,
+		CompExpiresAt: &expiresAt,
+	}, nil).Once()
+	mockService.On("UpdateStatus", uint(6), "approved", "", uint(9)).Return(nil,
+		apperrors.Forbidden(apperrors.CodeNotApprover, "You are not the current approver of this leave request")).Once()
+	mockService.On("Cancel", uint(5)).Return(apperrors.Conflict(apperrors.CodeOvertimeNotPending, "Only pending overtime requests can be cancelled")).Once()
+
+	req := httptest.NewRequest(http.MethodPut, "/api/overtime/5/status", bytes.NewBufferString(`{"status":"approved"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.EmployeeIDHeader, "9")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var overtime models.OvertimeRequest
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &overtime))
+	assert.Equal(t, 0.375, overtime.CompDays)
+
+	req = httptest.NewRequest(http.MethodPut, "/api/overtime/6/status", bytes.NewBufferString(`{"status":"approved"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.EmployeeIDHeader, "9")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// 缺少審批人身份時不調用服務
+	req = httptest.NewRequest(http.MethodPut, "/api/overtime/5/status", bytes.NewBufferString(`{"status":"approved"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req = httptest.NewRequest(http.MethodPut, "/api/overtime/5/cancel", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var resp middleware.ErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, apperrors.CodeOvertimeNotPending, resp.Error.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"hr-system/internal/dto"
+	"hr-system/internal/i18n"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PayrollServiceInterface 定義薪資服務接口
+type PayrollServiceInterface interface {
+	CreateComponent(component *models.PayComponent) error
+	GetComponent(id uint) (*models.PayComponent, error)
+	ListComponents() ([]models.PayComponent, error)
+	UpdateComponent(id uint, component *models.PayComponent) (*models.PayComponent, error)
+	DeleteComponent(id uint) error
+	CreateRun(month string, actorID *uint) (*models.PayrollRun, error)
+	Recalculate(id uint) (*models.PayrollRun, error)
+	Lock(id uint, actorID *uint) (*models.PayrollRun, error)
+	DeleteRun(id uint) error
+	GetRun(id uint) (*models.PayrollRun, error)
+	ListRuns() ([]models.PayrollRun, error)
+	GetPayslip(runID, employeeID uint) (*models.Payslip, error)
+	ListEmployeePayslips(employeeID uint) ([]models.Payslip, error)
+}
+
+type PayrollHandler struct {
+	payrollService PayrollServiceInterface
+}
+
+func NewPayrollHandler(payrollService PayrollServiceInterface) *PayrollHandler {
+	return &PayrollHandler{
+		payrollService: payrollService,
+	}
+}
+
+// CreateComponent 創建薪資項目
+func (h *PayrollHandler) CreateComponent(c *gin.Context) {
+	var req dto.PayComponentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
+		return
+	}
+
+	component := req.ToModel()
+	if err := h.payrollService.CreateComponent(component); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, component)
+}
+
+// ListComponents 獲取所有薪資項目
+func (h *PayrollHandler) ListComponents(c *gin.Context) {
+	components, err := h.payrollService.ListComponents()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if components == nil {
+		components = []models.PayComponent{}
+	}
+	c.JSON(http.StatusOK, components)
+}
+
+// GetComponent 獲取薪資項目
+func (h *PayrollHandler) GetComponent(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	component, err := h.payrollService.GetComponent(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, component)
+}
+
+// UpdateComponent 更新薪資項目
+func (h *PayrollHandler) UpdateComponent(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	var req dto.PayComponentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
+		return
+	}
+
+	component, err := h.payrollService.UpdateComponent(uint(id), req.ToModel())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, component)
+}
+
+// DeleteComponent 刪除薪資項目
+func (h *PayrollHandler) DeleteComponent(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	if err := h.payrollService.DeleteComponent(uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(middleware.GetLocale(c), "message.pay_component_deleted")})
+}
+
+// CreateRun 創建某月份的薪資批次並計算薪資單
+func (h *PayrollHandler) CreateRun(c *gin.Context) {
+	var req dto.CreatePayrollRunRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
+		return
+	}
+
+	run, err := h.payrollService.CreateRun(req.Month, currentEmployee(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, run)
+}
+
+// ListRuns 獲取所有薪資批次
+func (h *PayrollHandler) ListRuns(c *gin.Context) {
+	runs, err := h.payrollService.ListRuns()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if runs == nil {
+		runs = []models.PayrollRun{}
+	}
+	c.JSON(http.StatusOK, runs)
+}
+
+// GetRun 獲取薪資批次及其薪資單
+func (h *PayrollHandler) GetRun(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	run, err := h.payrollService.GetRun(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, run)
+}
+
+// RecalculateRun 重新計算草稿薪資批次
+func (h *PayrollHandler) RecalculateRun(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	run, err := h.payrollService.Recalculate(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, run)
+}
+
+// LockRun 鎖定草稿薪資批次
+func (h *PayrollHandler) LockRun(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	run, err := h.payrollService.Lock(uint(id), currentEmployee(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, run)
+}
+
+// DeleteRun 刪除草稿薪資批次
+func (h *PayrollHandler) DeleteRun(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	if err := h.payrollService.DeleteRun(uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(middleware.GetLocale(c), "message.payroll_run_deleted")})
+}
+
+// GetPayslip 獲取員工在薪資批次中的薪資單及明細
+func (h *PayrollHandler) GetPayslip(c *gin.Context) {
+	runID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+	employeeID, err := strconv.ParseUint(c.Param("employee_id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	payslip, err := h.payrollService.GetPayslip(uint(runID), uint(employeeID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, payslip)
+}
+
+// ListEmployeePayslips 獲取員工在已鎖定薪資批次中的薪資單
+func (h *PayrollHandler) ListEmployeePayslips(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	payslips, err := h.payrollService.ListEmployeePayslips(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if payslips == nil {
+		payslips = []models.Payslip{}
+	}
+	c.JSON(http.StatusOK, payslips)
+}
@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockPayrollService 模擬薪資服務
+type MockPayrollService struct {
+	mock.Mock
+}
+
+func (m *MockPayrollService) CreateComponent(component *models.PayComponent) error {
+	args := m.Called(component)
+	return args.Error(0)
+}
+
+func (m *MockPayrollService) GetComponent(id uint) (*models.PayComponent, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PayComponent), args.Error(1)
+}
+
+func (m *MockPayrollService) ListComponents() ([]models.PayComponent, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.PayComponent), args.Error(1)
+}
+
+func (m *MockPayrollService) UpdateComponent(id uint, component *models.PayComponent) (*models.PayComponent, error) {
+	args := m.Called(id, component)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PayComponent), args.Error(1)
+}
+
+func (m *MockPayrollService) DeleteComponent(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockPayrollService) CreateRun(month string, actorID *uint) (*models.PayrollRun, error) {
+	args := m.Called(month, actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PayrollRun), args.Error(1)
+}
+
+func (m *MockPayrollService) Recalculate(id uint) (*models.PayrollRun, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PayrollRun), args.Error(1)
+}
+
+func (m *MockPayrollService) Lock(id uint, actorID *uint) (*models.PayrollRun, error) {
+	args := m.Called(id, actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PayrollRun), args.Error(1)
+}
+
+func (m *MockPayrollService) DeleteRun(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockPayrollService) GetRun(id uint) (*models.PayrollRun, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.PayrollRun), args.Error(1)
+}
+
+func (m *MockPayrollService) ListRuns() ([]models.PayrollRun, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.PayrollRun), args.Error(1)
+}
+
+func (m *MockPayrollService) GetPayslip(runID, employeeID uint) (*models.Payslip, error) {
+	args := m.Called(runID, employeeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Payslip), args.Error(1)
+}
+
+func (m *MockPayrollService) ListEmployeePayslips(employeeID uint) ([]models.Payslip, error) {
+	args := m.Called(employeeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.Payslip), args.Error(1)
+}
+
+// 確保 MockPayrollService 實現了 PayrollServiceInterface
+var _ PayrollServiceInterface = (*MockPayrollService)(nil)
+
+func setupPayrollTestRouter(handler *PayrollHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.Locale(), middleware.ErrorHandler(), middleware.Identity())
+
+	r.POST("/api/admin/pay-components", handler.CreateComponent)
+	r.GET("/api/admin/pay-components", handler.ListComponents)
+	r.GET("/api/admin/pay-components/:id", handler.GetComponent)
+	r.PUT("/api/admin/pay-components/:id", handler.UpdateComponent)
+	r.DELETE("/api/admin/pay-components/:id", handler.DeleteComponent)
+	r.POST("/api/admin/payroll-runs", handler.CreateRun)
+	r.GET("/api/admin/payroll-runs", handler.ListRuns)
+	r.GET("/api/admin/payroll-runs/:id", handler.GetRun)
+	r.DELETE("/api/admin/payroll-runs/:id", handler.DeleteRun)
+	r.POST("/api/admin/payroll-runs/:id/recalculate", handler.RecalculateRun)
+	r.POST("/api/admin/payroll-runs/:id/lock", handler.LockRun)
+	r.GET("/api/admin/payroll-runs/:id/payslips/:employee_id", handler.GetPayslip)
+	r.GET("/api/employees/:id/payslips", handler.ListEmployeePayslips)
+	return r
+}
+
+func TestCreatePayComponent(t *testing.T) {
+	mockService := &MockPayrollService{}
+	router := setupPayrollTestRouter(NewPayrollHandler(mockService))
+
+	mockService.On("CreateComponent", mock.MatchedBy(func(component *models.PayComponent) bool {
+		return component.Name == "伙食津貼" && component.Kind == models.PayComponentAllowance &&
+			component.Amount == 2400 && component.Active && component.ProRate && component.EmployeeID == nil
+	})).Return(nil).Once()
+	mockService.On("CreateComponent", mock.MatchedBy(func(component *models.PayComponent) bool {
+		return component.Name == "借支扣回"
+	})).Return(apperrors.Validation(apperrors.CodeInvalidPayComponent, "Department must be empty when employee_id is set")).Once()
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "成功創建津貼", body: `{"name":"伙食津貼","kind":"allowance","amount":2400,"pro_rate":true}`, wantStatus: http.StatusCreated},
+		{name: "同時指定員工與部門", body: `{"name":"借支扣回","kind":"deduction","amount":1000,"employee_id":3,"department":"研發部"}`, wantStatus: http.StatusBadRequest},
+		{name: "無效的類型", body: `{"name":"獎金","kind":"bonus","amount":1000}`, wantStatus: http.StatusBadRequest},
+		{name: "金額必須為正數", body: `{"name":"伙食津貼","kind":"allowance","amount":0}`, wantStatus: http.StatusBadRequest},
+		{name: "月份格式錯誤", body: `{"name":"伙食津貼","kind":"allowance","amount":2400,"start_month":"2024/07"}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/pay-components", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestUpdateAndDeletePayComponent(t *testing.T) {
+	mockService := &MockPayrollService{}
+	router := setupPayrollTestRouter(NewPayrollHandler(mockService))
+
+	mockService.On("UpdateComponent", uint(2), mock.MatchedBy(func(component *models.PayComponent) bool {
+		return component.Kind == models.PayComponentDeduction && !component.Active && component.EndMonth == "2024-12"
+	})).Return(&models.PayComponent{Name: "停車費", Kind: models.PayComponentDeduction}, nil).Once()
+	mockService.On("DeleteComponent", uint(2)).Return(nil).Once()
+	mockService.On("DeleteComponent", uint(9)).Return(
+		apperrors.NotFound(apperrors.CodePayComponentNotFound, "Pay component not found")).Once()
+
+	req := httptest.NewRequest(http.MethodPut, "/api/admin/pay-components/2",
+		bytes.NewBufferString(`{"name":"停車費","kind":"deduction","amount":1500,"end_month":"2024-12","active":false}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/admin/pay-components/2", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/admin/pay-components/9", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreatePayrollRun(t *testing.T) {
+	mockService := &MockPayrollService{}
+	router := setupPayrollTestRouter(NewPayrollHandler(mockService))
+
+	actor := uint(1)
+	mockService.On("CreateRun", "2024-07", &actor).Return(&models.PayrollRun{
+		ID: 1, Month: "2024-07", Status: models.PayrollRunDraft, EmployeeCount: 2, GrossTotal: 96000, NetTotal: 94000,
+	}, nil).Once()
+	mockService.On("CreateRun", "2024-06", &actor).Return(nil,
+		apperrors.Conflict(apperrors.CodePayrollRunExists, "A payroll run for this month already exists")).Once()
+	mockService.On("CreateRun", "2099-01", &actor).Return(nil,
+		apperrors.Validation(apperrors.CodeInvalidPayrollMonth, "Month must not be in the future")).Once()
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "成功創建", body: `{"month":"2024-07"}`, wantStatus: http.StatusCreated},
+		{name: "月份已有批次", body: `{"month":"2024-06"}`, wantStatus: http.StatusConflict},
+		{name: "未來的月份", body: `{"month":"2099-01"}`, wantStatus: http.StatusBadRequest},
+		{name: "月份格式錯誤", body: `{"month":"2024-13"}`, wantStatus: http.StatusBadRequest},
+		{name: "缺少月份", body: `{}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/payroll-runs", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(middleware.EmployeeIDHeader, "1")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestRecalculateAndLockPayrollRun(t *testing.T) {
+	mockService := &MockPayrollService{}
+	router := setupPayrollTestRouter(NewPayrollHandler(mockService))
+
+	actor := uint(1)
+	mockService.On("Recalculate", uint(1)).Return(&models.PayrollRun{ID: 1, Status: models.PayrollRunDraft}, nil).Once()
+	mockService.On("Lock", uint(1), &actor).Return(&models.PayrollRun{ID: 1, Status: models.PayrollRunLocked}, nil).Once()
+	mockService.On("Recalculate", uint(1)).Return(nil,
+		apperrors.Conflict(apperrors.CodePayrollRunLocked, "The payroll run has been locked")).Once()
+	mockService.On("DeleteRun", uint(1)).Return(
+		apperrors.Conflict(apperrors.CodePayrollRunLocked, "The payroll run has been locked")).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/payroll-runs/1/recalculate", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/admin/payroll-runs/1/lock", nil)
+	req.Header.Set(middleware.EmployeeIDHeader, "1")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var run models.PayrollRun
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &run))
+	assert.Equal(t, models.PayrollRunLocked, run.Status)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/admin/payroll-runs/1/recalculate", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/admin/payroll-runs/1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/api/admin/payroll-runs/abc/lock", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetPayslip(t *testing.T) {
+	mockService := &MockPayrollService{}
+	router := setupPayrollTestRouter(NewPayrollHandler(mockService))
+
+	mockService.On("GetPayslip", uint(1), uint(3)).Return(&models.Payslip{
+		RunID: 1, EmployeeID: 3, Gross: 48000, Deductions: 1600, Net: 46400,
+		Lines: []models.PayrollLine{
+			{Code: models.PayrollCodeBaseSalary, Kind: models.PayrollLineEarning, Amount: 48000},
+			{Code: models.PayrollCodeUnpaidLeave, Kind: models.PayrollLineDeduction, Quantity: 1, Amount: 1600},
+		},
+	}, nil).Once()
+	mockService.On("GetPayslip", uint(1), uint(9)).Return(nil,
+		apperrors.NotFound(apperrors.CodePayslipNotFound, "Payslip not found")).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/payroll-runs/1/payslips/3", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var payslip models.Payslip
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &payslip))
+	assert.Len(t, payslip.Lines, 2)
+	assert.Equal(t, float64(46400), payslip.Net)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/admin/payroll-runs/1/payslips/9", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestListEmployeePayslips(t *testing.T) {
+	mockService := &MockPayrollService{}
+	router := setupPayrollTestRouter(NewPayrollHandler(mockService))
+
+	mockService.On("ListEmployeePayslips", uint(3)).Return(nil, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/employees/3/payslips", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[]`, w.Body.String())
+	mockService.AssertExpectations(t)
+}
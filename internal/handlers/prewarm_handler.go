@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/i18n"
+	"hr-system/internal/middleware"
+	"hr-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PrewarmServiceInterface 定義緩存預熱服務接口
+type PrewarmServiceInterface interface {
+	Status(ctx context.Context) services.PrewarmStatus
+	Trigger(mode string) bool
+}
+
+type PrewarmHandler struct {
+	prewarmService PrewarmServiceInterface
+}
+
+func NewPrewarmHandler(prewarmService PrewarmServiceInterface) *PrewarmHandler {
+	return &PrewarmHandler{
+		prewarmService: prewarmService,
+	}
+}
+
+// GetPrewarmStatus 獲取緩存預熱配置與最近一次執行情況
+func (h *PrewarmHandler) GetPrewarmStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, h.prewarmService.Status(c.Request.Context()))
+}
+
+// TriggerPrewarm 立即觸發一次緩存預熱
+func (h *PrewarmHandler) TriggerPrewarm(c *gin.Context) {
+	var req struct {
+		Mode string `json:"mode"` // full/incremental，為空時自動選擇
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(errInvalidRequest(err))
+			return
+		}
+	}
+	if req.Mode != "" && req.Mode != services.PrewarmModeFull && req.Mode != services.PrewarmModeIncremental {
+		c.Error(apperrors.Validation(apperrors.CodeInvalidPrewarmMode, "Invalid mode",
+			apperrors.Field("mode", apperrors.CodeInvalidPrewarmMode, "Mode must be full or incremental")))
+		return
+	}
+
+	if !h.prewarmService.Trigger(req.Mode) {
+		c.Error(apperrors.Conflict(apperrors.CodePrewarmQueued, "A prewarm run is already queued"))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": i18n.T(middleware.GetLocale(c), "message.prewarm_queued")})
+}
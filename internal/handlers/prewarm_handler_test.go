@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"hr-system/internal/middleware"
+	"hr-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockPrewarmService 模擬緩存預熱服務
+type MockPrewarmService struct {
+	mock.Mock
+}
+
+func (m *MockPrewarmService) Status(ctx context.Context) services.PrewarmStatus {
+	args := m.Called()
+	return args.Get(0).(services.PrewarmStatus)
+}
+
+func (m *MockPrewarmService) Trigger(mode string) bool {
+	args := m.Called(mode)
+	return args.Bool(0)
+}
+
+// 確保 MockPrewarmService 實現了 PrewarmServiceInterface
+var _ PrewarmServiceInterface = (*MockPrewarmService)(nil)
+
+func setupPrewarmTestRouter(handler *PrewarmHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.Default()
+	r.Use(middleware.RequestID(), middleware.Locale(), middleware.ErrorHandler())
+	r.GET("/api/admin/cache/prewarm", handler.GetPrewarmStatus)
+	r.POST("/api/admin/cache/prewarm", handler.TriggerPrewarm)
+	return r
+}
+
+func TestGetPrewarmStatus(t *testing.T) {
+	mockService := &MockPrewarmService{}
+	mockService.On("Status").Return(services.PrewarmStatus{Interval: "30m0s", Scope: "all", BatchSize: 500})
+	router := setupPrewarmTestRouter(NewPrewarmHandler(mockService))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/cache/prewarm", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"batch_size":500`)
+}
+
+func TestTriggerPrewarm(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		mockSetup  func(m *MockPrewarmService)
+		wantStatus int
+	}{
+		{
+			name: "自動選擇模式",
+			body: "",
+			mockSetup: func(m *MockPrewarmService) {
+				m.On("Trigger", "").Return(true)
+			},
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name: "全量預熱",
+			body: `{"mode":"full"}`,
+			mockSetup: func(m *MockPrewarmService) {
+				m.On("Trigger", services.PrewarmModeFull).Return(true)
+			},
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name: "已有待執行的預熱",
+			body: `{"mode":"incremental"}`,
+			mockSetup: func(m *MockPrewarmService) {
+				m.On("Trigger", services.PrewarmModeIncremental).Return(false)
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "無效的模式",
+			body:       `{"mode":"partial"}`,
+			mockSetup:  func(m *MockPrewarmService) {},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockPrewarmService{}
+			tt.mockSetup(mockService)
+			router := setupPrewarmTestRouter(NewPrewarmHandler(mockService))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/cache/prewarm", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,357 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/dto"
+	"hr-system/internal/i18n"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ScheduleServiceInterface 定義排班服務接口
+type ScheduleServiceInterface interface {
+	CreateTemplate(template *models.WorkScheduleTemplate) error
+	GetTemplate(id uint) (*models.WorkScheduleTemplate, error)
+	ListTemplates() ([]models.WorkScheduleTemplate, error)
+	UpdateTemplate(template *models.WorkScheduleTemplate) error
+	DeleteTemplate(id uint) error
+	AssignRoster(roster *models.RosterAssignment) error
+	ListRosters(employeeID uint) ([]models.RosterAssignment, error)
+	DeleteRoster(id uint) error
+	Shifts(employeeID uint, from, to time.Time) ([]models.ScheduledShift, error)
+	ShiftAt(employeeID uint, at time.Time) (*models.ScheduledShift, error)
+	CreateSwap(swap *models.ShiftSwapRequest) error
+	GetSwap(id uint) (*models.ShiftSwapRequest, error)
+	ListSwaps(employeeID uint, status string) ([]models.ShiftSwapRequest, error)
+	RespondSwap(id, employeeID uint, accept bool) (*models.ShiftSwapRequest, error)
+	CancelSwap(id, employeeID uint) (*models.ShiftSwapRequest, error)
+	UpdateSwapStatus(id uint, status, remark string, approverID uint) (*models.ShiftSwapRequest, error)
+}
+
+type ScheduleHandler struct {
+	scheduleService ScheduleServiceInterface
+}
+
+func NewScheduleHandler(scheduleService ScheduleServiceInterface) *ScheduleHandler {
+	return &ScheduleHandler{
+		scheduleService: scheduleService,
+	}
+}
+
+// CreateTemplate 創建班表模板
+func (h *ScheduleHandler) CreateTemplate(c *gin.Context) {
+	var req dto.ScheduleTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
+		return
+	}
+
+	template := req.ToModel()
+	if err := h.scheduleService.CreateTemplate(template); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, template)
+}
+
+// ListTemplates 獲取所有班表模板
+func (h *ScheduleHandler) ListTemplates(c *gin.Context) {
+	templates, err := h.scheduleService.ListTemplates()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if templates == nil {
+		templates = []models.WorkScheduleTemplate{}
+	}
+	c.JSON(http.StatusOK, templates)
+}
+
+// GetTemplate 獲取班表模板
+func (h *ScheduleHandler) GetTemplate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	template, err := h.scheduleService.GetTemplate(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+// UpdateTemplate 更新班表模板，班次整體替換
+func (h *ScheduleHandler) UpdateTemplate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	var req dto.ScheduleTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
+		return
+	}
+
+	template := req.ToModel()
+	template.ID = uint(id)
+	if err := h.scheduleService.UpdateTemplate(template); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+// DeleteTemplate 刪除沒有排班使用的班表模板
+func (h *ScheduleHandler) DeleteTemplate(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	if err := h.scheduleService.DeleteTemplate(uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(middleware.GetLocale(c), "message.schedule_template_deleted")})
+}
+
+// AssignRoster 為員工排班
+func (h *ScheduleHandler) AssignRoster(c *gin.Context) {
+	var req dto.RosterAssignmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
+		return
+	}
+
+	roster := req.ToModel(currentEmployee(c))
+	if err := h.scheduleService.AssignRoster(roster); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, roster)
+}
+
+// ListRosters 獲取員工的排班
+func (h *ScheduleHandler) ListRosters(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	rosters, err := h.scheduleService.ListRosters(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if rosters == nil {
+		rosters = []models.RosterAssignment{}
+	}
+	c.JSON(http.StatusOK, rosters)
+}
+
+// DeleteRoster 刪除排班
+func (h *ScheduleHandler) DeleteRoster(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	if err := h.scheduleService.DeleteRoster(uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(middleware.GetLocale(c), "message.roster_assignment_deleted")})
+}
+
+// GetShifts 獲取員工排定的班次，期間（from/to，包含兩端）默認為今天起 7 天
+func (h *ScheduleHandler) GetShifts(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	from, err := queryDate(c, "from", today)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	to, err := queryDate(c, "to", from.AddDate(0, 0, 6))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	shifts, err := h.scheduleService.Shifts(uint(id), from, to)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, shifts)
+}
+
+// GetScheduled 查詢員工在某一時間（at，RFC 3339，默認當前時間）是否在班
+func (h *ScheduleHandler) GetScheduled(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	at := time.Now()
+	if raw := c.Query("at"); raw != "" {
+		at, err = time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.Error(apperrors.Validation(apperrors.CodeInvalidDateTime, "Invalid time",
+				apperrors.Field("at", "datetime", fieldMessage(c, "datetime", "at", ""))))
+			return
+		}
+	}
+
+	shift, err := h.scheduleService.ShiftAt(uint(id), at)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.ScheduledResponse{EmployeeID: uint(id), At: at, Scheduled: shift != nil, Shift: shift})
+}
+
+// CreateSwap 當前員工提交換班申請
+func (h *ScheduleHandler) CreateSwap(c *gin.Context) {
+	var req dto.CreateShiftSwapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
+		return
+	}
+
+	swap := req.ToModel(middleware.GetEmployeeID(c))
+	if err := h.scheduleService.CreateSwap(swap); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, swap)
+}
+
+// ListSwaps 獲取換班申請，可按員工（employee_id，申請人或對方）與狀態（status）篩選
+func (h *ScheduleHandler) ListSwaps(c *gin.Context) {
+	var employeeID uint
+	if raw := c.Query("employee_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil || id == 0 {
+			c.Error(apperrors.Validation(apperrors.CodeValidationFailed, "Invalid employee ID",
+				apperrors.Field("employee_id", "gt", fieldMessage(c, "gt", "employee_id", "0"))))
+			return
+		}
+		employeeID = uint(id)
+	}
+	status := c.Query("status")
+	if status != "" && !containsString(models.ShiftSwapStatuses, status) {
+		statuses := strings.Join(models.ShiftSwapStatuses, ", ")
+		c.Error(apperrors.Validation(apperrors.CodeValidationFailed, "Invalid status",
+			apperrors.Field("status", "oneof", fieldMessage(c, "oneof", "status", statuses))))
+		return
+	}
+
+	swaps, err := h.scheduleService.ListSwaps(employeeID, status)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if swaps == nil {
+		swaps = []models.ShiftSwapRequest{}
+	}
+	c.JSON(http.StatusOK, swaps)
+}
+
+// GetSwap 獲取換班申請
+func (h *ScheduleHandler) GetSwap(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	swap, err := h.scheduleService.GetSwap(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, swap)
+}
+
+// RespondSwap 當前員工作為對方同意或拒絕換班申請
+func (h *ScheduleHandler) RespondSwap(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	var req dto.RespondShiftSwapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
+		return
+	}
+
+	swap, err := h.scheduleService.RespondSwap(uint(id), middleware.GetEmployeeID(c), *req.Accept)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, swap)
+}
+
+// CancelSwap 當前員工取消自己尚未審批的換班申請
+func (h *ScheduleHandler) CancelSwap(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	swap, err := h.scheduleService.CancelSwap(uint(id), middleware.GetEmployeeID(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, swap)
+}
+
+// UpdateSwapStatus 審批對方已同意的換班申請，請求體與審批請假相同
+func (h *ScheduleHandler) UpdateSwapStatus(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	var req dto.UpdateLeaveStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
+		return
+	}
+
+	swap, err := h.scheduleService.UpdateSwapStatus(uint(id), req.Status, req.Remark, middleware.GetEmployeeID(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, swap)
+}
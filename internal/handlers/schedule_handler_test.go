@@ -0,0 +1,436 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/dto"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockScheduleService 模擬排班服務
+type MockScheduleService struct {
+	mock.Mock
+}
+
+func (m *MockScheduleService) CreateTemplate(template *models.WorkScheduleTemplate) error {
+	args := m.Called(template)
+	return args.Error(0)
+}
+
+func (m *MockScheduleService) GetTemplate(id uint) (*models.WorkScheduleTemplate, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WorkScheduleTemplate), args.Error(1)
+}
+
+func (m *MockScheduleService) ListTemplates() ([]models.WorkScheduleTemplate, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.WorkScheduleTemplate), args.Error(1)
+}
+
+func (m *MockScheduleService) UpdateTemplate(template *models.WorkScheduleTemplate) error {
+	args := m.Called(template)
+	return args.Error(0)
+}
+
+func (m *MockScheduleService) DeleteTemplate(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockScheduleService) AssignRoster(roster *models.RosterAssignment) error {
+	args := m.Called(roster)
+	return args.Error(0)
+}
+
+func (m *MockScheduleService) ListRosters(employeeID uint) ([]models.RosterAssignment, error) {
+	args := m.Called(employeeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.RosterAssignment), args.Error(1)
+}
+
+func (m *MockScheduleService) DeleteRoster(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockScheduleService) Shifts(employeeID uint, from, to time.Time) ([]models.ScheduledShift, error) {
+	args := m.Called(employeeID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ScheduledShift), args.Error(1)
+}
+
+func (m *MockScheduleService) ShiftAt(employeeID uint, at time.Time) (*models.ScheduledShift, error) {
+	args := m.Called(employeeID, at)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ScheduledShift), args.Error(1)
+}
+
+func (m *MockScheduleService) CreateSwap(swap *models.ShiftSwapRequest) error {
+	args := m.Called(swap)
+	return args.Error(0)
+}
+
+func (m *MockScheduleService) GetSwap(id uint) (*models.ShiftSwapRequest, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ShiftSwapRequest), args.Error(1)
+}
+
+func (m *MockScheduleService) ListSwaps(employeeID uint, status string) ([]models.ShiftSwapRequest, error) {
+	args := m.Called(employeeID, status)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ShiftSwapRequest), args.Error(1)
+}
+
+func (m *MockScheduleService) RespondSwap(id, employeeID uint, accept bool) (*models.ShiftSwapRequest, error) {
+	args := m.Called(id, employeeID, accept)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ShiftSwapRequest), args.Error(1)
+}
+
+func (m *MockScheduleService) CancelSwap(id, employeeID uint) (*models.ShiftSwapRequest, error) {
+	args := m.Called(id, employeeID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ShiftSwapRequest), args.Error(1)
+}
+
+func (m *MockScheduleService) UpdateSwapStatus(id uint, status, remark string, approverID uint) (*models.ShiftSwapRequest, error) {
+	args := m.Called(id, status, remark, approverID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ShiftSwapRequest), args.Error(1)
+}
+
+// 確保 MockScheduleService 實現了 ScheduleServiceInterface
+var _ ScheduleServiceInterface = (*MockScheduleService)(nil)
+
+func setupScheduleTestRouter(handler *ScheduleHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.Locale(), middleware.ErrorHandler(), middleware.Identity())
+
+	r.POST("/api/admin/schedule-templates", handler.CreateTemplate)
+	r.GET("/api/admin/schedule-templates", handler.ListTemplates)
+	r.GET("/api/admin/schedule-templates/:id", handler.GetTemplate)
+	r.PUT("/api/admin/schedule-templates/:id", handler.UpdateTemplate)
+	r.DELETE("/api/admin/schedule-templates/:id", handler.DeleteTemplate)
+	r.POST("/api/admin/rosters", handler.AssignRoster)
+	r.DELETE("/api/admin/rosters/:id", handler.DeleteRoster)
+	r.GET("/api/employees/:id/rosters", handler.ListRosters)
+	r.GET("/api/employees/:id/shifts", handler.GetShifts)
+	r.GET("/api/employees/:id/scheduled", handler.GetScheduled)
+	r.POST("/api/shift-swaps", middleware.RequireIdentity(), handler.CreateSwap)
+	r.GET("/api/shift-swaps", handler.ListSwaps)
+	r.GET("/api/shift-swaps/:id", handler.GetSwap)
+	r.PUT("/api/shift-swaps/:id/respond", middleware.RequireIdentity(), handler.RespondSwap)
+	r.PUT("/api/shift-swaps/:id/cancel", middleware.RequireIdentity(), handler.CancelSwap)
+	r.PUT("/api/shift-swaps/:id/status", middleware.RequireIdentity(), handler.UpdateSwapStatus)
+	return r
+}
+
+func TestCreateScheduleTemplate(t *testing.T) {
+	mockService := &MockScheduleService{}
+	router := setupScheduleTestRouter(NewScheduleHandler(mockService))
+
+	mockService.On("CreateTemplate", mock.MatchedBy(func(template *models.WorkScheduleTemplate) bool {
+		return template.Name == "四班二輪" && template.Kind == models.ScheduleKindRotating && !template.SkipHolidays &&
+			len(template.Shifts) == 2 && template.Shifts[1].End == "06:00"
+	})).Return(nil).Once()
+	mockService.On("CreateTemplate", mock.MatchedBy(func(template *models.WorkScheduleTemplate) bool {
+		return template.Name == "辦公室" && template.SkipHolidays
+	})).Return(apperrors.Conflict(apperrors.CodeScheduleTemplateExists, "A schedule template with this name already exists")).Once()
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{
+			name:       "成功創建輪班模板",
+			body:       `{"name":"四班二輪","kind":"rotating","cycle_days":6,"shifts":[{"day_index":0,"start":"08:00","end":"20:00","break_minutes":60},{"day_index":1,"start":"20:00","end":"06:00"}]}`,
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "名稱重複",
+			body:       `{"name":"辦公室","kind":"fixed","shifts":[{"day_index":0,"start":"09:00","end":"18:00"}]}`,
+			wantStatus: http.StatusConflict,
+		},
+		{name: "無效的類型", body: `{"name":"夜班","kind":"night","shifts":[{"day_index":0,"start":"22:00","end":"06:00"}]}`, wantStatus: http.StatusBadRequest},
+		{name: "時間格式錯誤", body: `{"name":"夜班","kind":"fixed","shifts":[{"day_index":0,"start":"9:00pm","end":"06:00"}]}`, wantStatus: http.StatusBadRequest},
+		{name: "缺少班次", body: `{"name":"夜班","kind":"fixed","shifts":[]}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/schedule-templates", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestUpdateAndDeleteScheduleTemplate(t *testing.T) {
+	mockService := &MockScheduleService{}
+	router := setupScheduleTestRouter(NewScheduleHandler(mockService))
+
+	mockService.On("UpdateTemplate", mock.MatchedBy(func(template *models.WorkScheduleTemplate) bool {
+		return template.ID == 2 && template.Kind == models.ScheduleKindPartTime
+	})).Return(nil).Once()
+	mockService.On("DeleteTemplate", uint(2)).Return(
+		apperrors.Conflict(apperrors.CodeScheduleTemplateInUse, "The schedule template is used by roster assignments")).Once()
+	mockService.On("DeleteTemplate", uint(3)).Return(nil).Once()
+
+	req := httptest.NewRequest(http.MethodPut, "/api/admin/schedule-templates/2",
+		bytes.NewBufferString(`{"name":"週末兼職","kind":"part_time","shifts":[{"day_index":5,"start":"10:00","end":"16:00"}]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/admin/schedule-templates/2", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/admin/schedule-templates/3", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestAssignRoster(t *testing.T) {
+	mockService := &MockScheduleService{}
+	router := setupScheduleTestRouter(NewScheduleHandler(mockService))
+
+	createdBy := uint(1)
+	mockService.On("AssignRoster", mock.MatchedBy(func(roster *models.RosterAssignment) bool {
+		return roster.EmployeeID == 3 && roster.TemplateID == 2 && roster.StartDate.Day() == 1 &&
+			roster.EndDate == nil && roster.CycleOffset == 2 && *roster.CreatedByID == createdBy
+	})).Return(nil).Once()
+	mockService.On("AssignRoster", mock.MatchedBy(func(roster *models.RosterAssignment) bool {
+		return roster.EmployeeID == 4
+	})).Return(apperrors.Conflict(apperrors.CodeRosterOverlap, "The employee already has a roster assignment in this period")).Once()
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "成功排班", body: `{"employee_id":3,"template_id":2,"start_date":"2024-07-01","cycle_offset":2}`, wantStatus: http.StatusCreated},
+		{name: "期間重疊", body: `{"employee_id":4,"template_id":2,"start_date":"2024-07-01","end_date":"2024-12-31"}`, wantStatus: http.StatusConflict},
+		{name: "缺少模板", body: `{"employee_id":3,"start_date":"2024-07-01"}`, wantStatus: http.StatusBadRequest},
+		{name: "日期格式錯誤", body: `{"employee_id":3,"template_id":2,"start_date":"2024/07/01"}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/rosters", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(middleware.EmployeeIDHeader, "1")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestGetShifts(t *testing.T) {
+	mockService := &MockScheduleService{}
+	router := setupScheduleTestRouter(NewScheduleHandler(mockService))
+
+	from := time.Date(2024, time.July, 1, 0, 0, 0, 0, time.Local)
+	mockService.On("Shifts", uint(3), from, from.AddDate(0, 0, 6)).Return([]models.ScheduledShift{
+		{EmployeeID: 3, Date: "2024-07-01", Start: from.Add(20 * time.Hour), End: from.Add(30 * time.Hour), Source: models.ShiftSourceRoster},
+	}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/employees/3/shifts?from=2024-07-01", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var shifts []models.ScheduledShift
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &shifts))
+	assert.Len(t, shifts, 1)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetScheduled(t *testing.T) {
+	mockService := &MockScheduleService{}
+	router := setupScheduleTestRouter(NewScheduleHandler(mockService))
+
+	day := time.Date(2024, time.July, 1, 0, 0, 0, 0, time.FixedZone("", 8*3600))
+	onShift := day.Add(26 * time.Hour)
+	offShift := day.Add(12 * time.Hour)
+	mockService.On("ShiftAt", uint(3), mock.MatchedBy(func(at time.Time) bool { return at.Equal(onShift) })).Return(&models.ScheduledShift{
+		EmployeeID: 3, Date: "2024-07-01", Start: day.Add(20 * time.Hour), End: day.Add(30 * time.Hour), Source: models.ShiftSourceRoster,
+	}, nil).Once()
+	mockService.On("ShiftAt", uint(3), mock.MatchedBy(func(at time.Time) bool { return at.Equal(offShift) })).Return(nil, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/employees/3/scheduled?at=2024-07-02T02:00:00%2B08:00", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp dto.ScheduledResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.True(t, resp.Scheduled)
+	assert.Equal(t, "2024-07-01", resp.Shift.Date)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/employees/3/scheduled?at=2024-07-01T12:00:00%2B08:00", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	resp = dto.ScheduledResponse{}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.False(t, resp.Scheduled)
+	assert.Nil(t, resp.Shift)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/employees/3/scheduled?at=2024-07-01", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestCreateShiftSwap(t *testing.T) {
+	mockService := &MockScheduleService{}
+	router := setupScheduleTestRouter(NewScheduleHandler(mockService))
+
+	mockService.On("CreateSwap", mock.MatchedBy(func(swap *models.ShiftSwapRequest) bool {
+		return swap.RequesterID == 3 && swap.CounterpartID == 4 && swap.Date.Day() == 8 &&
+			swap.CounterpartDate.Day() == 10 && swap.Status == models.ShiftSwapPending
+	})).Return(nil).Once()
+	mockService.On("CreateSwap", mock.MatchedBy(func(swap *models.ShiftSwapRequest) bool {
+		return swap.CounterpartID == 5
+	})).Return(apperrors.Validation(apperrors.CodeInvalidShiftSwap, "The counterpart has no shift on this date")).Once()
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "成功提交", body: `{"counterpart_id":4,"date":"2024-07-08","counterpart_date":"2024-07-10","reason":"家中有事"}`, wantStatus: http.StatusCreated},
+		{name: "對方當天沒有班次", body: `{"counterpart_id":5,"date":"2024-07-08"}`, wantStatus: http.StatusBadRequest},
+		{name: "缺少對象", body: `{"date":"2024-07-08"}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/shift-swaps", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(middleware.EmployeeIDHeader, "3")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestShiftSwapWorkflow(t *testing.T) {
+	mockService := &MockScheduleService{}
+	router := setupScheduleTestRouter(NewScheduleHandler(mockService))
+
+	mockService.On("RespondSwap", uint(6), uint(4), true).Return(&models.ShiftSwapRequest{Status: models.ShiftSwapAccepted}, nil).Once()
+	mockService.On("RespondSwap", uint(6), uint(5), false).Return(nil,
+		apperrors.Forbidden(apperrors.CodeShiftSwapNotAllowed, "Only the counterpart can respond to this shift swap")).Once()
+	mockService.On("UpdateSwapStatus", uint(6), "approved", "", uint(2)).Return(&models.ShiftSwapRequest{Status: models.ShiftSwapApproved}, nil).Once()
+	mockService.On("CancelSwap", uint(6), uint(3)).Return(nil,
+		apperrors.Conflict(apperrors.CodeShiftSwapStatus, "The shift swap can no longer be changed in its current status")).Once()
+
+	req := httptest.NewRequest(http.MethodPut, "/api/shift-swaps/6/respond", bytes.NewBufferString(`{"accept":true}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.EmployeeIDHeader, "4")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req = httptest.NewRequest(http.MethodPut, "/api/shift-swaps/6/respond", bytes.NewBufferString(`{"accept":false}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.EmployeeIDHeader, "5")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	req = httptest.NewRequest(http.MethodPut, "/api/shift-swaps/6/respond", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.EmployeeIDHeader, "4")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req = httptest.NewRequest(http.MethodPut, "/api/shift-swaps/6/status", bytes.NewBufferString(`{"status":"approved"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.EmployeeIDHeader, "2")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// 缺少審批人身份時不調用服務
+	req = httptest.NewRequest(http.MethodPut, "/api/shift-swaps/6/status", bytes.NewBufferString(`{"status":"approved"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req = httptest.NewRequest(http.MethodPut, "/api/shift-swaps/6/cancel", nil)
+	req.Header.Set(middleware.EmployeeIDHeader, "3")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var resp middleware.ErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, apperrors.CodeShiftSwapStatus, resp.Error.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestListShiftSwaps(t *testing.T) {
+	mockService := &MockScheduleService{}
+	router := setupScheduleTestRouter(NewScheduleHandler(mockService))
+
+	mockService.On("ListSwaps", uint(3), models.ShiftSwapAccepted).Return(nil, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/shift-swaps?employee_id=3&status=accepted", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "[]", w.Body.String())
+
+	req = httptest.NewRequest(http.MethodGet, "/api/shift-swaps?status=done", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"hr-system/internal/dto"
+	"hr-system/internal/i18n"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StaffingServiceInterface 定義人力規則服務接口
+type StaffingServiceInterface interface {
+	CreateRule(rule *models.StaffingRule) error
+	GetRule(id uint) (*models.StaffingRule, error)
+	ListRules() ([]models.StaffingRule, error)
+	UpdateRule(rule *models.StaffingRule) error
+	DeleteRule(id uint) error
+	LeaveCoverage(leaveID uint) ([]models.StaffingCoverage, error)
+}
+
+type StaffingHandler struct {
+	staffingService StaffingServiceInterface
+}
+
+func NewStaffingHandler(staffingService StaffingServiceInterface) *StaffingHandler {
+	return &StaffingHandler{
+		staffingService: staffingService,
+	}
+}
+
+// CreateRule 創建人力規則
+func (h *StaffingHandler) CreateRule(c *gin.Context) {
+	var req dto.StaffingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
+		return
+	}
+
+	rule := req.ToModel()
+	if err := h.staffingService.CreateRule(rule); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusCreated, rule)
+}
+
+// ListRules 獲取所有人力規則
+func (h *StaffingHandler) ListRules(c *gin.Context) {
+	rules, err := h.staffingService.ListRules()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if rules == nil {
+		rules = []models.StaffingRule{}
+	}
+	c.JSON(http.StatusOK, rules)
+}
+
+// GetRule 獲取人力規則
+func (h *StaffingHandler) GetRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	rule, err := h.staffingService.GetRule(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// UpdateRule 更新人力規則
+func (h *StaffingHandler) UpdateRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	var req dto.StaffingRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
+		return
+	}
+
+	rule := req.ToModel()
+	rule.ID = uint(id)
+	if err := h.staffingService.UpdateRule(rule); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteRule 刪除人力規則
+func (h *StaffingHandler) DeleteRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	if err := h.staffingService.DeleteRule(uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(middleware.GetLocale(c), "message.staffing_rule_deleted")})
+}
+
+// GetLeaveCoverage 獲取請假核准後各適用人力規則每天的在崗人數，供審批人參考
+func (h *StaffingHandler) GetLeaveCoverage(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	coverage, err := h.staffingService.LeaveCoverage(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, coverage)
+}
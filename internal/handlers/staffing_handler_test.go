@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockStaffingService 模擬人力規則服務
+type MockStaffingService struct {
+	mock.Mock
+}
+
+func (m *MockStaffingService) CreateRule(rule *models.StaffingRule) error {
+	args := m.Called(rule)
+	return args.Error(0)
+}
+
+func (m *MockStaffingService) GetRule(id uint) (*models.StaffingRule, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.StaffingRule), args.Error(1)
+}
+
+func (m *MockStaffingService) ListRules() ([]models.StaffingRule, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.StaffingRule), args.Error(1)
+}
+
+func (m *MockStaffingService) UpdateRule(rule *models.StaffingRule) error {
+	args := m.Called(rule)
+	return args.Error(0)
+}
+
+func (m *MockStaffingService) DeleteRule(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockStaffingService) LeaveCoverage(leaveID uint) ([]models.StaffingCoverage, error) {
+	args := m.Called(leaveID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.StaffingCoverage), args.Error(1)
+}
+
+// 確保 MockStaffingService 實現了 StaffingServiceInterface
+var _ StaffingServiceInterface = (*MockStaffingService)(nil)
+
+func setupStaffingTestRouter(handler *StaffingHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.Locale(), middleware.ErrorHandler())
+
+	r.GET("/api/leaves/:id/coverage", handler.GetLeaveCoverage)
+	r.POST("/api/admin/staffing-rules", handler.CreateRule)
+	r.GET("/api/admin/staffing-rules", handler.ListRules)
+	r.PUT("/api/admin/staffing-rules/:id", handler.UpdateRule)
+	r.DELETE("/api/admin/staffing-rules/:id", handler.DeleteRule)
+	return r
+}
+
+func TestCreateStaffingRule(t *testing.T) {
+	mockService := &MockStaffingService{}
+	router := setupStaffingTestRouter(NewStaffingHandler(mockService))
+
+	mockService.On("CreateRule", mock.MatchedBy(func(rule *models.StaffingRule) bool {
+		return rule.Department == "研發部" && rule.MinPresent == 3 && rule.Active &&
+			rule.Enforcement == models.StaffingEnforcementBlock && rule.StartDate != nil && rule.StartDate.Day() == 20
+	})).Return(nil).Once()
+	mockService.On("CreateRule", mock.MatchedBy(func(rule *models.StaffingRule) bool {
+		return rule.Department == "" && rule.ManagerID == nil
+	})).Return(apperrors.Validation(apperrors.CodeInvalidStaffingRule, "Specify exactly one of department or manager_id")).Once()
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "成功創建規則", body: `{"name":"年底在崗","department":"研發部","min_present":3,"start_date":"2024-12-20","end_date":"2024-12-31","enforcement":"block"}`, wantStatus: http.StatusCreated},
+		{name: "未指定適用範圍", body: `{"name":"無範圍","max_absent_ratio":0.5,"enforcement":"warn"}`, wantStatus: http.StatusBadRequest},
+		{name: "佔比超出範圍", body: `{"name":"佔比","department":"研發部","max_absent_ratio":1.5,"enforcement":"warn"}`, wantStatus: http.StatusBadRequest},
+		{name: "無效的處理方式", body: `{"name":"方式","department":"研發部","min_present":1,"enforcement":"deny"}`, wantStatus: http.StatusBadRequest},
+		{name: "日期格式錯誤", body: `{"name":"日期","department":"研發部","min_present":1,"start_date":"2024/12/20","enforcement":"warn"}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/staffing-rules", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestGetLeaveCoverage(t *testing.T) {
+	mockService := &MockStaffingService{}
+	router := setupStaffingTestRouter(NewStaffingHandler(mockService))
+
+	mockService.On("LeaveCoverage", uint(5)).Return([]models.StaffingCoverage{{
+		RuleID:      1,
+		RuleName:    "研發部最少在崗",
+		Enforcement: models.StaffingEnforcementWarn,
+		Days: []models.StaffingDay{
+			{Date: "2024-06-10", Headcount: 5, Absent: 3, Present: 2, Satisfied: false},
+		},
+	}}, nil).Once()
+	mockService.On("LeaveCoverage", uint(6)).Return(nil, apperrors.NotFound(apperrors.CodeLeaveNotFound, "Leave record not found")).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/leaves/5/coverage", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var coverage []models.StaffingCoverage
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &coverage))
+	if assert.Len(t, coverage, 1) && assert.Len(t, coverage[0].Days, 1) {
+		assert.Equal(t, 2, coverage[0].Days[0].Present)
+		assert.False(t, coverage[0].Satisfied)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/leaves/6/coverage", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestUpdateLeaveStatusStaffingRules(t *testing.T) {
+	mockService := &MockLeaveService{}
+	router := setupLeaveTestRouter(NewLeaveHandler(mockService))
+
+	mockService.On("UpdateLeaveStatus", uint(1), "approved", "", uint(3)).Return([]models.StaffingCoverage{{
+		RuleID:      2,
+		RuleName:    "團隊半數在崗",
+		Enforcement: models.StaffingEnforcementWarn,
+	}}, nil).Once()
+	mockService.On("UpdateLeaveStatus", uint(2), "approved", "", uint(3)).Return(nil,
+		apperrors.Conflict(apperrors.CodeStaffingRuleViolated, `The leave would violate staffing rule "年底在崗"`)).Once()
+
+	req := httptest.NewRequest(http.MethodPut, "/api/leaves/1/status", bytes.NewBufferString(`{"status":"approved"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.EmployeeIDHeader, "3")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"staffing_warnings":[{"rule_id":2`)
+
+	req = httptest.NewRequest(http.MethodPut, "/api/leaves/2/status", bytes.NewBufferString(`{"status":"approved"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.EmployeeIDHeader, "3")
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var resp middleware.ErrorResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, apperrors.CodeStaffingRuleViolated, resp.Error.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/dto"
+	"hr-system/internal/i18n"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultDeliveryLimit = 100
+	maxDeliveryLimit     = 500
+)
+
+// WebhookServiceInterface 定義 Webhook 服務接口
+type WebhookServiceInterface interface {
+	CreateSubscription(sub *models.WebhookSubscription) error
+	GetSubscription(id uint) (*models.WebhookSubscription, error)
+	ListSubscriptions() ([]models.WebhookSubscription, error)
+	UpdateSubscription(sub *models.WebhookSubscription) error
+	DeleteSubscription(id uint) error
+	ListDeliveries(status string, subscriptionID uint, limit int) ([]models.WebhookDelivery, error)
+	Redeliver(id uint) (*models.WebhookDelivery, error)
+}
+
+type WebhookHandler struct {
+	webhookService WebhookServiceInterface
+}
+
+func NewWebhookHandler(webhookService WebhookServiceInterface) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// CreateSubscription 創建訂閱，響應中包含簽名密鑰
+func (h *WebhookHandler) CreateSubscription(c *gin.Context) {
+	var req dto.WebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
+		return
+	}
+
+	sub := req.ToModel()
+	if err := h.webhookService.CreateSubscription(sub); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.WebhookSubscriptionCreated{WebhookSubscription: sub, Secret: sub.Secret})
+}
+
+// ListSubscriptions 獲取訂閱列表
+func (h *WebhookHandler) ListSubscriptions(c *gin.Context) {
+	subs, err := h.webhookService.ListSubscriptions()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if subs == nil {
+		subs = []models.WebhookSubscription{}
+	}
+	c.JSON(http.StatusOK, subs)
+}
+
+// GetSubscription 獲取訂閱
+func (h *WebhookHandler) GetSubscription(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	sub, err := h.webhookService.GetSubscription(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sub)
+}
+
+// UpdateSubscription 更新訂閱，未傳入 secret 時保留原密鑰
+func (h *WebhookHandler) UpdateSubscription(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	var req dto.WebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(dto.BindError(err, middleware.GetLocale(c)))
+		return
+	}
+
+	sub := req.ToModel()
+	sub.ID = uint(id)
+	if err := h.webhookService.UpdateSubscription(sub); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, sub)
+}
+
+// DeleteSubscription 刪除訂閱
+func (h *WebhookHandler) DeleteSubscription(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	if err := h.webhookService.DeleteSubscription(uint(id)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": i18n.T(middleware.GetLocale(c), "message.webhook_deleted")})
+}
+
+// ListSubscriptionDeliveries 獲取訂閱的投遞記錄，可用 status 篩選
+func (h *WebhookHandler) ListSubscriptionDeliveries(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	status := c.Query("status")
+	if status != "" && !containsString(models.DeliveryStatuses, status) {
+		c.Error(apperrors.Validation(apperrors.CodeValidationFailed, "Invalid delivery status",
+			apperrors.Field("status", "oneof", fieldMessage(c, "oneof", "status", strings.Join(models.DeliveryStatuses, ", ")))))
+		return
+	}
+	h.listDeliveries(c, status, uint(id))
+}
+
+// ListDeadLetters 獲取重試用盡的投遞記錄
+func (h *WebhookHandler) ListDeadLetters(c *gin.Context) {
+	h.listDeliveries(c, models.DeliveryStatusDead, 0)
+}
+
+func (h *WebhookHandler) listDeliveries(c *gin.Context, status string, subscriptionID uint) {
+	limit, err := queryLimit(c, defaultDeliveryLimit, maxDeliveryLimit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	deliveries, err := h.webhookService.ListDeliveries(status, subscriptionID, limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if deliveries == nil {
+		deliveries = []models.WebhookDelivery{}
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// Redeliver 手動重新投遞一條記錄
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.Error(errInvalidID())
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(uint(id))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, delivery)
+}
@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/middleware"
+	"hr-system/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockWebhookService 模擬 Webhook 服務
+type MockWebhookService struct {
+	mock.Mock
+}
+
+func (m *MockWebhookService) CreateSubscription(sub *models.WebhookSubscription) error {
+	args := m.Called(sub)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) GetSubscription(id uint) (*models.WebhookSubscription, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookService) ListSubscriptions() ([]models.WebhookSubscription, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockWebhookService) UpdateSubscription(sub *models.WebhookSubscription) error {
+	args := m.Called(sub)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) DeleteSubscription(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockWebhookService) ListDeliveries(status string, subscriptionID uint, limit int) ([]models.WebhookDelivery, error) {
+	args := m.Called(status, subscriptionID, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookService) Redeliver(id uint) (*models.WebhookDelivery, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WebhookDelivery), args.Error(1)
+}
+
+// 確保 MockWebhookService 實現了 WebhookServiceInterface
+var _ WebhookServiceInterface = (*MockWebhookService)(nil)
+
+func setupWebhookTestRouter(handler *WebhookHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.Locale(), middleware.ErrorHandler())
+
+	webhooks := r.Group("/api/admin/webhooks")
+	{
+		webhooks.POST("", handler.CreateSubscription)
+		webhooks.GET("", handler.ListSubscriptions)
+		webhooks.GET("/dead-letters", handler.ListDeadLetters)
+		webhooks.POST("/deliveries/:id/redeliver", handler.Redeliver)
+		webhooks.GET("/:id", handler.GetSubscription)
+		webhooks.PUT("/:id", handler.UpdateSubscription)
+		webhooks.DELETE("/:id", handler.DeleteSubscription)
+		webhooks.GET("/:id/deliveries", handler.ListSubscriptionDeliveries)
+	}
+	return r
+}
+
+func TestCreateWebhookSubscription(t *testing.T) {
+	mockService := &MockWebhookService{}
+	router := setupWebhookTestRouter(NewWebhookHandler(mockService))
+
+	tests := []struct {
+		name       string
+		body       string
+		mockSetup  func()
+		wantStatus int
+		wantField  string
+	}{
+		{
+			name: "成功創建訂閱並返回密鑰",
+			body: `{"url":"https://payroll.example.com/hooks","event_types":["employee.hired","leave.approved"]}`,
+			mockSetup: func() {
+				mockService.On("CreateSubscription", mock.MatchedBy(func(sub *models.WebhookSubscription) bool {
+					return sub.Active && len(sub.EventTypes) == 2
+				})).Run(func(args mock.Arguments) {
+					args.Get(0).(*models.WebhookSubscription).Secret = "whsec_test"
+				}).Return(nil).Once()
+			},
+			wantStatus: http.StatusCreated,
+		},
+		{
+			name:       "不支持的事件類型",
+			body:       `{"url":"https://payroll.example.com/hooks","event_types":["employee.promoted"]}`,
+			mockSetup:  func() {},
+			wantStatus: http.StatusBadRequest,
+			wantField:  "event_types[0]",
+		},
+		{
+			name:       "無效的網址",
+			body:       `{"url":"not a url"}`,
+			mockSetup:  func() {},
+			wantStatus: http.StatusBadRequest,
+			wantField:  "url",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/webhooks", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusCreated {
+				var resp map[string]interface{}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, "whsec_test", resp["secret"])
+			}
+			if tt.wantField != "" {
+				var resp middleware.ErrorResponse
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				if assert.Len(t, resp.Error.Fields, 1) {
+					assert.Equal(t, tt.wantField, resp.Error.Fields[0].Field)
+				}
+			}
+		})
+	}
+}
+
+func TestGetWebhookSubscriptionHidesSecret(t *testing.T) {
+	mockService := &MockWebhookService{}
+	router := setupWebhookTestRouter(NewWebhookHandler(mockService))
+
+	sub := &models.WebhookSubscription{URL: "https://it.example.com/hooks", Secret: "whsec_hidden", Active: true}
+	sub.ID = 1
+	mockService.On("GetSubscription", uint(1)).Return(sub, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/admin/webhooks/1", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "whsec_hidden")
+}
+
+func TestListDeadLetters(t *testing.T) {
+	mockService := &MockWebhookService{}
+	router := setupWebhookTestRouter(NewWebhookHandler(mockService))
+
+	tests := []struct {
+		name       string
+		query      string
+		mockSetup  func()
+		wantStatus int
+	}{
+		{
+			name:  "默認筆數",
+			query: "",
+			mockSetup: func() {
+				mockService.On("ListDeliveries", models.DeliveryStatusDead, uint(0), 100).
+					Return([]models.WebhookDelivery{{EventType: models.EventLeaveApproved, Status: models.DeliveryStatusDead}}, nil).Once()
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "筆數超過上限",
+			query:      "?limit=1000",
+			mockSetup:  func() {},
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/webhooks/dead-letters"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestRedeliverWebhook(t *testing.T) {
+	mockService := &MockWebhookService{}
+	router := setupWebhookTestRouter(NewWebhookHandler(mockService))
+
+	tests := []struct {
+		name       string
+		id         string
+		mockSetup  func()
+		wantStatus int
+	}{
+		{
+			name: "成功重新投遞",
+			id:   "1",
+			mockSetup: func() {
+				mockService.On("Redeliver", uint(1)).Return(&models.WebhookDelivery{Status: models.DeliveryStatusPending}, nil)
+			},
+			wantStatus: http.StatusAccepted,
+		},
+		{
+			name: "投遞記錄正在等待投遞",
+			id:   "2",
+			mockSetup: func() {
+				mockService.On("Redeliver", uint(2)).Return(nil, apperrors.Conflict(apperrors.CodeDeliveryInFlight, "Delivery is already scheduled"))
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name: "投遞記錄不存在",
+			id:   "999",
+			mockSetup: func() {
+				mockService.On("Redeliver", uint(999)).Return(nil, apperrors.NotFound(apperrors.CodeDeliveryNotFound, "Webhook delivery not found"))
+			},
+			wantStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/webhooks/deliveries/"+tt.id+"/redeliver", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
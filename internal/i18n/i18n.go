@@ -0,0 +1,158 @@
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 支持的語系
+const (
+	ZhTW = "zh-TW"
+	En   = "en"
+
+	// Default 默認語系，也是其他語系缺少翻譯時的後備語系
+	Default = ZhTW
+)
+
+// Supported 所有支持的語系
+var Supported = []string{ZhTW, En}
+
+// Vars 訊息模板中的變量，模板以 {name} 引用
+type Vars map[string]string
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// catalogs 語系 -> 訊息鍵 -> 訊息模板
+var catalogs = loadCatalogs()
+
+func loadCatalogs() map[string]map[string]string {
+	result := make(map[string]map[string]string, len(Supported))
+	for _, locale := range Supported {
+		data, err := localeFS.ReadFile(path.Join("locales", locale+".json"))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: missing catalog for %s: %v", locale, err))
+		}
+		var catalog map[string]string
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			panic(fmt.Sprintf("i18n: invalid catalog for %s: %v", locale, err))
+		}
+		result[locale] = catalog
+	}
+	return result
+}
+
+// Lookup 查找訊息模板，當前語系缺少時回退到默認語系
+func Lookup(locale, key string) (string, bool) {
+	if msg, ok := catalogs[locale][key]; ok {
+		return msg, true
+	}
+	msg, ok := catalogs[Default][key]
+	return msg, ok
+}
+
+// T 翻譯訊息，找不到時返回鍵本身
+func T(locale, key string) string {
+	return Tf(locale, key, nil)
+}
+
+// Tf 翻譯訊息並替換模板變量
+func Tf(locale, key string, vars Vars) string {
+	msg, ok := Lookup(locale, key)
+	if !ok {
+		return key
+	}
+	return format(msg, vars)
+}
+
+// Label 返回枚舉值的顯示名稱，如 Label("en", "leave_type", "年假")，找不到時返回原值
+func Label(locale, kind, value string) string {
+	if msg, ok := Lookup(locale, kind+"."+value); ok {
+		return msg
+	}
+	return value
+}
+
+// Keys 返回語系目錄中的所有鍵（已排序）
+func Keys(locale string) []string {
+	keys := make([]string, 0, len(catalogs[locale]))
+	for key := range catalogs[locale] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func format(msg string, vars Vars) string {
+	if len(vars) == 0 {
+		return msg
+	}
+	pairs := make([]string, 0, len(vars)*2)
+	for name, value := range vars {
+		pairs = append(pairs, "{"+name+"}", value)
+	}
+	return strings.NewReplacer(pairs...).Replace(msg)
+}
+
+// Normalize 將語言標籤對應到支持的語系，如 en-US -> en、zh-Hant-TW -> zh-TW
+func Normalize(tag string) (string, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	switch {
+	case tag == "":
+		return "", false
+	case strings.HasPrefix(tag, "zh"):
+		return ZhTW, true
+	case strings.HasPrefix(tag, "en"):
+		return En, true
+	}
+	return "", false
+}
+
+// Negotiate 依 Accept-Language 的權重選出支持的語系，沒有匹配時返回默認語系
+func Negotiate(acceptLanguage string) string {
+	type candidate struct {
+		tag string
+		q   float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		candidates = append(candidates, candidate{tag: tag, q: q})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	for _, c := range candidates {
+		if c.q <= 0 {
+			continue
+		}
+		if locale, ok := Normalize(c.tag); ok {
+			return locale
+		}
+	}
+	return Default
+}
+
+// Preferred 返回用戶保存的偏好語系，未設置或不支持時返回默認語系
+func Preferred(preference string) string {
+	if locale, ok := Normalize(preference); ok {
+		return locale
+	}
+	return Default
+}
+
+// Notification 生成通知的標題與正文，event 如 leave_approved
+func Notification(locale, event string, vars Vars) (title, body string) {
+	prefix := "notification." + event
+	return Tf(locale, prefix+".title", vars), strings.TrimSpace(Tf(locale, prefix+".body", vars))
+}
@@ -0,0 +1,143 @@
+package i18n
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"testing"
+
+	"hr-system/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var placeholderPattern = regexp.MustCompile(`\{[a-z_]+\}`)
+
+func placeholders(msg string) []string {
+	found := placeholderPattern.FindAllString(msg, -1)
+	sort.Strings(found)
+	return found
+}
+
+// TestCatalogsHaveSameKeys 所有語系的目錄必須包含相同的鍵與模板變量
+func TestCatalogsHaveSameKeys(t *testing.T) {
+	reference := catalogs[Default]
+	for _, locale := range Supported {
+		catalog := catalogs[locale]
+		for key, msg := range reference {
+			translated, ok := catalog[key]
+			if !assert.Truef(t, ok, "%s: missing key %q", locale, key) {
+				continue
+			}
+			assert.NotEmptyf(t, translated, "%s: empty message for %q", locale, key)
+			assert.Equalf(t, placeholders(msg), placeholders(translated), "%s: placeholders differ for %q", locale, key)
+		}
+		for key := range catalog {
+			_, ok := reference[key]
+			assert.Truef(t, ok, "%s: key %q is missing from %s", locale, key, Default)
+		}
+	}
+}
+
+// TestCatalogsHaveNoDuplicateKeys 目錄中重複的鍵會被後者靜默覆蓋
+func TestCatalogsHaveNoDuplicateKeys(t *testing.T) {
+	for _, locale := range Supported {
+		data, err := localeFS.ReadFile(path.Join("locales", locale+".json"))
+		if !assert.NoError(t, err) {
+			continue
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		seen := make(map[string]bool)
+		_, _ = decoder.Token() // {
+		for decoder.More() {
+			token, err := decoder.Token()
+			if !assert.NoError(t, err) {
+				break
+			}
+			key := token.(string)
+			assert.Falsef(t, seen[key], "%s: duplicate key %q", locale, key)
+			seen[key] = true
+			var value string
+			assert.NoError(t, decoder.Decode(&value))
+		}
+	}
+}
+
+// TestErrorCodesTranslated 每個錯誤碼都必須有對應的翻譯
+func TestErrorCodesTranslated(t *testing.T) {
+	file, err := parser.ParseFile(token.NewFileSet(), "../apperrors/codes.go", nil, 0)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var codes []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.ValueSpec)
+		if !ok {
+			return true
+		}
+		for _, value := range spec.Values {
+			if lit, ok := value.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				code, _ := strconv.Unquote(lit.Value)
+				codes = append(codes, code)
+			}
+		}
+		return true
+	})
+	assert.NotEmpty(t, codes)
+
+	for _, locale := range Supported {
+		for _, code := range codes {
+			_, ok := catalogs[locale]["error."+code]
+			assert.Truef(t, ok, "%s: missing translation for error code %q", locale, code)
+		}
+	}
+}
+
+// TestEnumLabelsTranslated 每個枚舉值都必須有顯示名稱
+func TestEnumLabelsTranslated(t *testing.T) {
+	enums := map[string][]string{
+		"leave_type":      models.LeaveTypes,
+		"leave_status":    models.LeaveStatuses,
+		"employee_status": models.EmployeeStatuses,
+		"locale":          Supported,
+	}
+	for _, locale := range Supported {
+		for kind, values := range enums {
+			for _, value := range values {
+				_, ok := catalogs[locale][kind+"."+value]
+				assert.Truef(t, ok, "%s: missing label for %s %q", locale, kind, value)
+			}
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ZhTW},
+		{"en-US,en;q=0.9", En},
+		{"zh-Hant-TW", ZhTW},
+		{"fr-FR,en;q=0.5,zh-TW;q=0.8", ZhTW},
+		{"ja, en;q=0.1", En},
+		{"en;q=0", ZhTW},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Negotiate(tt.header), tt.header)
+	}
+}
+
+func TestTf(t *testing.T) {
+	assert.Equal(t, "Name is required", Tf(En, "validation.required", Vars{"field": T(En, "field.name")}))
+	assert.Equal(t, "Annual leave", Label(En, "leave_type", models.LeaveTypeAnnual))
+	assert.Equal(t, "未知", Label(En, "leave_type", "未知"))
+	assert.Equal(t, "no.such.key", T(En, "no.such.key"))
+}
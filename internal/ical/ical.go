@@ -0,0 +1,97 @@
+// Package ical 生成 RFC 5545 iCalendar 訂閱內容
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxLineOctets 每行最多的字節數（不含換行），超過時折行
+const maxLineOctets = 75
+
+// Calendar 行事曆
+type Calendar struct {
+	ProdID string // 產生者標識，如 -//HR System//Leave Calendar//EN
+	Name   string // 日曆客戶端顯示的名稱
+	Events []Event
+}
+
+// Event 全天事件，Start 與 End 只取日期部分，End 當天包含在內
+type Event struct {
+	UID         string
+	Stamp       time.Time // 事件最近一次更新的時間
+	Start       time.Time
+	End         time.Time
+	Summary     string
+	Description string
+	Tentative   bool // 未確定的事件，如待審批的請假
+	Free        bool // 不佔用忙碌時間，如他人的請假
+}
+
+// Encode 將行事曆編碼為 text/calendar 內容
+func Encode(cal *Calendar) []byte {
+	var buf bytes.Buffer
+	w := func(name, value string) {
+		writeLine(&buf, name+":"+value)
+	}
+
+	w("BEGIN", "VCALENDAR")
+	w("VERSION", "2.0")
+	w("PRODID", cal.ProdID)
+	w("CALSCALE", "GREGORIAN")
+	w("METHOD", "PUBLISH")
+	if cal.Name != "" {
+		w("X-WR-CALNAME", escape(cal.Name))
+	}
+	for _, event := range cal.Events {
+		w("BEGIN", "VEVENT")
+		w("UID", event.UID)
+		w("DTSTAMP", event.Stamp.UTC().Format("20060102T150405Z"))
+		w("DTSTART;VALUE=DATE", event.Start.Format("20060102"))
+		// DTEND 不包含在事件內，取結束日期的下一天
+		end := time.Date(event.End.Year(), event.End.Month(), event.End.Day(), 0, 0, 0, 0, event.End.Location()).AddDate(0, 0, 1)
+		w("DTEND;VALUE=DATE", end.Format("20060102"))
+		w("SUMMARY", escape(event.Summary))
+		if event.Description != "" {
+			w("DESCRIPTION", escape(event.Description))
+		}
+		status := "CONFIRMED"
+		if event.Tentative {
+			status = "TENTATIVE"
+		}
+		w("STATUS", status)
+		transp := "OPAQUE"
+		if event.Free {
+			transp = "TRANSPARENT"
+		}
+		w("TRANSP", transp)
+		w("END", "VEVENT")
+	}
+	w("END", "VCALENDAR")
+	return buf.Bytes()
+}
+
+// escape 轉義 TEXT 類型值中的特殊字符
+func escape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
+
+// writeLine 寫入一行內容，超過 75 字節時在 UTF-8 字符邊界折行，續行以空格開頭
+func writeLine(buf *bytes.Buffer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		buf.WriteString(line[:cut])
+		buf.WriteString("\r\n ")
+		line = line[cut:]
+		// 續行開頭的空格佔一個字節
+		limit = maxLineOctets - 1
+	}
+	buf.WriteString(line)
+	buf.WriteString("\r\n")
+}
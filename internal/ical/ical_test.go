@@ -0,0 +1,53 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeAllDayEvent(t *testing.T) {
+	loc := time.FixedZone("CST", 8*3600)
+	out := string(Encode(&Calendar{
+		ProdID: "-//HR System//Leave Calendar//EN",
+		Name:   "研發部請假",
+		Events: []Event{{
+			UID:       "leave-1@hr-system",
+			Stamp:     time.Date(2024, 5, 20, 9, 30, 0, 0, loc),
+			Start:     time.Date(2024, 6, 1, 0, 0, 0, 0, loc),
+			End:       time.Date(2024, 6, 3, 0, 0, 0, 0, loc),
+			Summary:   "王小明 - 年假, 家庭旅遊; 第一天",
+			Tentative: true,
+			Free:      true,
+		}},
+	}))
+
+	assert.True(t, strings.HasPrefix(out, "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"))
+	assert.True(t, strings.HasSuffix(out, "END:VCALENDAR\r\n"))
+	assert.Contains(t, out, "DTSTAMP:20240520T013000Z\r\n")
+	assert.Contains(t, out, "DTSTART;VALUE=DATE:20240601\r\n")
+	// DTEND 不包含在內，為結束日期的下一天
+	assert.Contains(t, out, "DTEND;VALUE=DATE:20240604\r\n")
+	assert.Contains(t, out, `SUMMARY:王小明 - 年假\, 家庭旅遊\; 第一天`)
+	assert.Contains(t, out, "STATUS:TENTATIVE\r\n")
+	assert.Contains(t, out, "TRANSP:TRANSPARENT\r\n")
+}
+
+func TestLongLinesAreFoldedOnRuneBoundaries(t *testing.T) {
+	summary := strings.Repeat("請假", 30)
+	out := string(Encode(&Calendar{ProdID: "-//test//EN", Events: []Event{{Summary: summary}}}))
+
+	var unfolded strings.Builder
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), maxLineOctets, line)
+		assert.True(t, strings.ToValidUTF8(line, "") == line, "line split inside a UTF-8 sequence: %q", line)
+		if strings.HasPrefix(line, " ") {
+			unfolded.WriteString(line[1:])
+			continue
+		}
+		unfolded.WriteString("\n" + line)
+	}
+	assert.Contains(t, unfolded.String(), "SUMMARY:"+summary)
+}
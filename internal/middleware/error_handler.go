@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorBody 標準錯誤響應
+type ErrorBody struct {
+	Code      string                 `json:"code"`
+	Message   string                 `json:"message"`
+	Fields    []apperrors.FieldError `json:"fields,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"`
+}
+
+// ErrorResponse 錯誤響應信封
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorHandler 將處理器通過 c.Error 記錄的錯誤渲染為標準錯誤響應
+// 只渲染最後一個錯誤；處理器已經寫入響應時不再處理
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		RenderError(c, c.Errors.Last().Err)
+	}
+}
+
+// Recovery 捕獲 panic 並以標準錯誤響應返回 500
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
+		RenderError(c, apperrors.Internal(fmt.Errorf("panic: %v", recovered)))
+	})
+}
+
+// RenderError 立即以標準錯誤響應結束請求
+// 錯誤訊息依錯誤碼從當前語系的訊息目錄中翻譯，目錄中沒有對應的錯誤碼時保留原訊息
+func RenderError(c *gin.Context, err error) {
+	appErr := apperrors.From(err)
+	requestID := GetRequestID(c)
+	if appErr.Kind == apperrors.KindInternal {
+		log.Printf("[%s] %s %s: %v", requestID, c.Request.Method, c.Request.URL.Path, appErr.Err)
+	}
+
+	locale := GetLocale(c)
+	var fields []apperrors.FieldError
+	for _, field := range appErr.Fields {
+		field.Message = translateError(locale, field.Code, field.Message)
+		fields = append(fields, field)
+	}
+
+	c.AbortWithStatusJSON(appErr.HTTPStatus(), ErrorResponse{
+		Error: ErrorBody{
+			Code:      appErr.Code,
+			Message:   translateError(locale, appErr.Code, appErr.Message),
+			Fields:    fields,
+			RequestID: requestID,
+		},
+	})
+}
+
+func translateError(locale, code, fallback string) string {
+	if msg, ok := i18n.Lookup(locale, "error."+code); ok {
+		return msg
+	}
+	return fallback
+}
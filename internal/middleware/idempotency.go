@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// IdempotencyKeyHeader 客戶端傳入冪等鍵的請求頭
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader 標記響應是重放的已保存響應
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+// Idempotency 為創建類接口提供冪等鍵支持
+// 帶有 Idempotency-Key 的請求會保存請求摘要與響應：相同的重試直接重放響應，
+// 相同冪等鍵但請求體不同時返回 422，前一個請求仍在處理中時返回 409。
+// 5xx 響應不會保存，客戶端可以使用相同的冪等鍵重試。
+func Idempotency(store services.IdempotencyStore, cfg config.IdempotencyConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if _, ok := c.Request.Header[http.CanonicalHeaderKey(IdempotencyKeyHeader)]; !ok {
+			c.Next()
+			return
+		}
+		if key == "" || len(key) > maxIdempotencyKeyLength {
+			RenderError(c, apperrors.Validation(apperrors.CodeInvalidIdempotencyKey, "Invalid Idempotency-Key header"))
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			RenderError(c, apperrors.Validation(apperrors.CodeInvalidRequest, err.Error()))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		// 冪等鍵的作用域為調用方、請求方法與路徑，不同調用方或不同接口可以使用相同的鍵
+		scopedKey := idempotencyCaller(c) + ":" + c.Request.Method + ":" + c.Request.URL.Path + ":" + key
+		hash := requestHash(c.Request.Method, c.Request.URL.Path, body)
+
+		ctx := c.Request.Context()
+		existing, err := store.Reserve(ctx, scopedKey, hash, cfg.InFlightTTL)
+		if err != nil {
+			// 存儲不可用時放行請求，可用性優先於去重
+			log.Printf("[%s] idempotency store unavailable, processing without key: %v", GetRequestID(c), err)
+			c.Next()
+			return
+		}
+		if existing != nil {
+			replayOrReject(c, existing, hash)
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		completed := false
+		defer func() {
+			// 處理失敗（5xx 或 panic）時釋放冪等鍵，允許客戶端重試
+			if !completed {
+				releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				if err := store.Release(releaseCtx, scopedKey); err != nil {
+					log.Printf("[%s] failed to release idempotency key: %v", GetRequestID(c), err)
+				}
+			}
+		}()
+
+		c.Next()
+
+		// 錯誤通常由外層的 ErrorHandler 渲染，這裡提前渲染以便保存錯誤響應
+		if len(c.Errors) > 0 && !c.Writer.Written() {
+			RenderError(c, c.Errors.Last().Err)
+		}
+
+		status := c.Writer.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+		record := &services.IdempotencyRecord{
+			RequestHash: hash,
+			Completed:   true,
+			StatusCode:  status,
+			ContentType: c.Writer.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+			CreatedAt:   time.Now(),
+		}
+		if err := store.Complete(ctx, scopedKey, record, cfg.TTL); err != nil {
+			log.Printf("[%s] failed to save idempotent response: %v", GetRequestID(c), err)
+			return
+		}
+		completed = true
+	}
+}
+
+// idempotencyCaller 返回冪等鍵所屬的調用方，已識別的員工按員工ID區分，匿名請求按客戶端地址區分
+// 需在 Identity 之後使用
+func idempotencyCaller(c *gin.Context) string {
+	if id := GetEmployeeID(c); id != 0 {
+		return "employee:" + strconv.FormatUint(uint64(id), 10)
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// replayOrReject 處理冪等鍵已存在的請求
+func replayOrReject(c *gin.Context, record *services.IdempotencyRecord, hash string) {
+	switch {
+	case record.RequestHash != hash:
+		RenderError(c, apperrors.Unprocessable(apperrors.CodeIdempotencyKeyReused,
+			"Idempotency-Key was already used with a different request body"))
+	case !record.Completed:
+		RenderError(c, apperrors.Conflict(apperrors.CodeIdempotencyKeyInFlight,
+			"A request with the same Idempotency-Key is still being processed"))
+	default:
+		c.Header(IdempotentReplayedHeader, "true")
+		c.Data(record.StatusCode, record.ContentType, record.Body)
+		c.Abort()
+	}
+}
+
+// requestHash 計算請求摘要，JSON 請求體先規範化，忽略空白與字段順序的差異
+func requestHash(method, path string, body []byte) string {
+	normalized := body
+	var payload interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if decoder.Decode(&payload) == nil {
+		if data, err := json.Marshal(payload); err == nil {
+			normalized = data
+		}
+	}
+
+	h := sha256.New()
+	h.Write([]byte(method + " " + path + "\n"))
+	h.Write(normalized)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// responseRecorder 在寫出響應的同時保存響應體
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
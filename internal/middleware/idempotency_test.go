@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupIdempotencyTestRouter 創建的接口返回調用方與請求體，並記錄實際處理的次數
+func setupIdempotencyTestRouter(calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(RequestID(), Locale(), ErrorHandler(), Identity())
+
+	cfg := config.IdempotencyConfig{TTL: time.Hour, InFlightTTL: time.Minute}
+	r.POST("/api/leaves", Idempotency(services.NewMemoryIdempotencyStore(), cfg), func(c *gin.Context) {
+		*calls++
+		body, _ := io.ReadAll(c.Request.Body)
+		c.JSON(http.StatusCreated, gin.H{"caller": GetEmployeeID(c), "body": string(body)})
+	})
+	return r
+}
+
+func newIdempotentRequest(employeeID, remoteAddr, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/api/leaves", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(IdempotencyKeyHeader, "same-key")
+	if employeeID != "" {
+		req.Header.Set(EmployeeIDHeader, employeeID)
+	}
+	if remoteAddr != "" {
+		req.RemoteAddr = remoteAddr
+	}
+	return req
+}
+
+func TestIdempotencyScopedByCaller(t *testing.T) {
+	calls := 0
+	router := setupIdempotencyTestRouter(&calls)
+
+	tests := []struct {
+		name         string
+		employeeID   string
+		remoteAddr   string
+		body         string
+		wantStatus   int
+		wantCaller   uint
+		wantReplayed bool
+		wantCalls    int
+	}{
+		{name: "員工 1 首次請求", employeeID: "1", body: `{"days": 1}`, wantStatus: http.StatusCreated, wantCaller: 1, wantCalls: 1},
+		{name: "員工 2 使用相同冪等鍵與不同請求體", employeeID: "2", body: `{"days": 2}`, wantStatus: http.StatusCreated, wantCaller: 2, wantCalls: 2},
+		{name: "員工 2 使用相同冪等鍵與員工 1 的請求體", employeeID: "2", body: `{"days": 1}`, wantStatus: http.StatusUnprocessableEntity, wantCalls: 2},
+		{name: "員工 1 重試", employeeID: "1", body: `{"days":1}`, wantStatus: http.StatusCreated, wantCaller: 1, wantReplayed: true, wantCalls: 2},
+		{name: "匿名請求", remoteAddr: "10.0.0.1:1234", body: `{"days": 1}`, wantStatus: http.StatusCreated, wantCalls: 3},
+		{name: "其他地址的匿名請求", remoteAddr: "10.0.0.2:1234", body: `{"days": 1}`, wantStatus: http.StatusCreated, wantCalls: 4},
+		{name: "相同地址的匿名重試", remoteAddr: "10.0.0.1:5678", body: `{"days": 1}`, wantStatus: http.StatusCreated, wantReplayed: true, wantCalls: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, newIdempotentRequest(tt.employeeID, tt.remoteAddr, tt.body))
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantCalls, calls)
+			if tt.wantReplayed {
+				assert.Equal(t, "true", w.Header().Get(IdempotentReplayedHeader))
+			} else {
+				assert.Empty(t, w.Header().Get(IdempotentReplayedHeader))
+			}
+			if tt.wantStatus != http.StatusCreated {
+				var resp ErrorResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+				assert.Equal(t, apperrors.CodeIdempotencyKeyReused, resp.Error.Code)
+				return
+			}
+			var resp struct {
+				Caller uint `json:"caller"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.wantCaller, resp.Caller)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"strconv"
+
+	"hr-system/internal/apperrors"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// EmployeeIDHeader 當前員工ID的請求頭，由企業入口的單點登錄網關在認證後設置
+	EmployeeIDHeader = "X-Employee-ID"
+	// employeeIDKey 當前員工ID在 gin.Context 中的鍵
+	employeeIDKey = "current_employee_id"
+)
+
+// Identity 識別當前員工，沒有該請求頭的請求視為匿名
+// 服務部署在網關之後，網關負責認證並覆蓋客戶端傳入的同名請求頭
+func Identity() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw := c.GetHeader(EmployeeIDHeader)
+		if raw == "" {
+			c.Next()
+			return
+		}
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil || id == 0 {
+			RenderError(c, errUnauthenticated())
+			return
+		}
+		c.Set(employeeIDKey, uint(id))
+		c.Next()
+	}
+}
+
+// RequireIdentity 要求請求已識別當前員工，用於 /api/me 等以當前用戶為範圍的接口，需在 Identity 之後使用
+func RequireIdentity() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if GetEmployeeID(c) == 0 {
+			RenderError(c, errUnauthenticated())
+			return
+		}
+		c.Next()
+	}
+}
+
+func errUnauthenticated() error {
+	return apperrors.Unauthorized(apperrors.CodeUnauthenticated, "Unable to identify the current user")
+}
+
+// GetEmployeeID 獲取當前員工ID，未經過 Identity 中間件時返回 0
+func GetEmployeeID(c *gin.Context) uint {
+	id, _ := c.Get(employeeIDKey)
+	employeeID, _ := id.(uint)
+	return employeeID
+}
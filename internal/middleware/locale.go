@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"hr-system/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// LocaleQueryParam 明確指定語系的查詢參數，如 ?lang=en
+	LocaleQueryParam = "lang"
+	// LocaleCookie 入口網站保存用戶語系偏好的 Cookie
+	LocaleCookie = "hr_locale"
+	// localeKey 語系在 gin.Context 中的鍵
+	localeKey = "locale"
+)
+
+// Locale 決定本次請求使用的語系
+// 優先順序：查詢參數 lang > 用戶偏好 Cookie > Accept-Language > 默認語系
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale, ok := i18n.Normalize(c.Query(LocaleQueryParam))
+		if !ok {
+			if cookie, err := c.Cookie(LocaleCookie); err == nil {
+				locale, ok = i18n.Normalize(cookie)
+			}
+		}
+		if !ok {
+			locale = i18n.Negotiate(c.GetHeader("Accept-Language"))
+		}
+
+		c.Set(localeKey, locale)
+		c.Header("Content-Language", locale)
+		c.Next()
+	}
+}
+
+// GetLocale 獲取當前請求的語系，未經過 Locale 中間件時依 Accept-Language 決定
+func GetLocale(c *gin.Context) string {
+	if locale := c.GetString(localeKey); locale != "" {
+		return locale
+	}
+	return i18n.Negotiate(c.GetHeader("Accept-Language"))
+}
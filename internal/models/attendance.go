@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 打卡類型
+const (
+	PunchClockIn  = "clock_in"  // 上班打卡
+	PunchClockOut = "clock_out" // 下班打卡
+)
+
+// 打卡來源
+const (
+	PunchSourceWeb        = "web"        // 網頁
+	PunchSourceMobile     = "mobile"     // 手機 App
+	PunchSourceDevice     = "device"     // 打卡機等設備上傳
+	PunchSourceCorrection = "correction" // 補卡申請核准後產生
+)
+
+// 考勤異常
+const (
+	AttendanceLate            = "late"              // 遲到
+	AttendanceEarlyLeave      = "early_leave"       // 早退
+	AttendanceMissingClockIn  = "missing_clock_in"  // 有下班打卡但缺上班打卡
+	AttendanceMissingClockOut = "missing_clock_out" // 有上班打卡但缺下班打卡
+	AttendanceAbsent          = "absent"            // 應出勤但沒有打卡也沒有已核准的請假
+)
+
+// AttendanceAnomalies 所有考勤異常
+var AttendanceAnomalies = []string{AttendanceLate, AttendanceEarlyLeave, AttendanceMissingClockIn, AttendanceMissingClockOut, AttendanceAbsent}
+
+// AttendancePunch 打卡記錄，只增不改
+type AttendancePunch struct {
+	ID           uint      `gorm:"primarykey" json:"id"`
+	CreatedAt    time.Time `json:"created_at"`
+	EmployeeID   uint      `gorm:"not null;index:idx_punch_employee_time" json:"employee_id"`
+	Kind         string    `gorm:"type:varchar(20);not null" json:"kind"`              // clock_in/clock_out
+	Time         time.Time `gorm:"not null;index:idx_punch_employee_time" json:"time"` // 打卡時間
+	Source       string    `gorm:"type:varchar(20);not null" json:"source"`            // web/mobile/device/correction
+	IP           string    `gorm:"type:varchar(45)" json:"ip,omitempty"`               // 打卡的來源IP
+	Location     string    `gorm:"type:varchar(200)" json:"location,omitempty"`        // 打卡地點，如設備名稱或經緯度
+	CorrectionID *uint     `json:"correction_id,omitempty"`                            // 由補卡申請產生時的申請ID
+}
+
+// AttendanceCorrection 補卡申請，核准後產生一筆打卡記錄；審批流程與請假相同，狀態沿用請假狀態
+type AttendanceCorrection struct {
+	gorm.Model
+	EmployeeID    uint       `gorm:"not null;index" json:"employee_id"`                // 員工ID
+	Employee      Employee   `gorm:"foreignKey:EmployeeID" json:"employee"`            // 關聯員工
+	Kind          string     `gorm:"type:varchar(20);not null" json:"kind"`            // 補上班或下班打卡（clock_in/clock_out）
+	Time          time.Time  `gorm:"not null" json:"time"`                             // 補登的打卡時間
+	Reason        string     `gorm:"type:text" json:"reason"`                          // 原因
+	Status        string     `gorm:"type:varchar(20);default:'pending'" json:"status"` // 狀態（pending/approved/rejected）
+	ApproverID    *uint      `json:"approver_id,omitempty"`                            // 審批人ID
+	OnBehalfOfID  *uint      `json:"on_behalf_of_id,omitempty"`                        // 代理審批時被代理的原審批人ID
+	ApproveTime   *time.Time `json:"approve_time,omitempty"`                           // 審批時間
+	ApproveRemark string     `gorm:"type:text" json:"approve_remark"`                  // 審批備註
+}
+
+// AttendanceDay 員工一天的考勤摘要，按班表與打卡、請假記錄即時計算
+type AttendanceDay struct {
+	EmployeeID     uint       `json:"employee_id"`
+	Date           string     `json:"date"`                      // YYYY-MM-DD
+	WorkDay        bool       `json:"work_day"`                  // 是否應出勤
+	ScheduledStart *time.Time `json:"scheduled_start,omitempty"` // 應上班時間
+	ScheduledEnd   *time.Time `json:"scheduled_end,omitempty"`   // 應下班時間
+	ClockIn        *time.Time `json:"clock_in,omitempty"`        // 當天第一次上班打卡
+	ClockOut       *time.Time `json:"clock_out,omitempty"`       // 當天最後一次下班打卡
+	WorkedMinutes  int        `json:"worked_minutes"`            // 上下班打卡之間的分鐘數
+	LeaveIDs       []uint     `json:"leave_ids,omitempty"`       // 當天的已核准請假
+	Anomalies      []string   `json:"anomalies"`                 // 考勤異常
+}
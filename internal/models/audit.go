@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// 審計操作
+const (
+	AuditActionLeaveApprove = "leave.approve" // 核准請假
+	AuditActionLeaveReject  = "leave.reject"  // 駁回請假
+)
+
+// 操作來源
+const (
+	AuditChannelAPI       = "api"        // 通過 API
+	AuditChannelEmailLink = "email_link" // 通過郵件中的操作連結
+	AuditChannelSystem    = "system"     // 系統自動執行
+)
+
+// AuditLog 審計記錄，只增不改
+type AuditLog struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time `gorm:"index" json:"created_at"`
+	ActorID    *uint     `gorm:"index" json:"actor_id,omitempty"`                                                // 執行操作的員工ID，系統操作時為空
+	Action     string    `gorm:"type:varchar(50);not null" json:"action"`                                        // 操作，如 leave.approve
+	EntityType string    `gorm:"type:varchar(30);not null;index:idx_audit_entity,priority:1" json:"entity_type"` // 操作對象類型
+	EntityID   uint      `gorm:"not null;index:idx_audit_entity,priority:2" json:"entity_id"`                    // 操作對象ID
+	Channel    string    `gorm:"type:varchar(20);not null" json:"channel"`                                       // 操作來源
+	TokenID    string    `gorm:"type:varchar(64);index" json:"token_id,omitempty"`                               // 使用的操作令牌ID
+	RequestID  string    `gorm:"type:varchar(64)" json:"request_id,omitempty"`                                   // 請求ID
+	IP         string    `gorm:"type:varchar(45)" json:"ip,omitempty"`                                           // 客戶端IP
+	Detail     string    `gorm:"type:text" json:"detail,omitempty"`                                              // 補充說明，如審批備註
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// 批量請假的對象
+const (
+	BulkLeaveTargetAll        = "all"        // 所有在職員工
+	BulkLeaveTargetDepartment = "department" // 部門的在職員工
+	BulkLeaveTargetEmployees  = "employees"  // 指定的員工
+)
+
+// 與已有請假重疊時的處理方式
+const (
+	BulkLeaveConflictSkip  = "skip"  // 跳過有重疊請假的員工，其餘員工照常創建
+	BulkLeaveConflictAbort = "abort" // 任一員工有重疊請假時不創建任何請假
+)
+
+// 批量請假任務狀態
+const (
+	BulkLeaveJobCompleted = "completed" // 已創建請假
+	BulkLeaveJobAborted   = "aborted"   // 因重疊請假未創建任何請假
+)
+
+// 單個員工的處理結果
+const (
+	BulkLeaveOutcomeCreated  = "created"   // 已創建請假
+	BulkLeaveOutcomeConflict = "conflict"  // 與已有的待審批或已核准請假重疊，未創建
+	BulkLeaveOutcomeInactive = "inactive"  // 員工已離職，未創建
+	BulkLeaveOutcomeNotFound = "not_found" // 指定的員工不存在，未創建
+	BulkLeaveOutcomeAborted  = "aborted"   // 沒有重疊請假，但任務因其他員工的重疊請假中止，未創建
+)
+
+// BulkLeaveJob 批量請假任務，如颱風停班或公司停工時為一批員工創建已核准的請假
+type BulkLeaveJob struct {
+	ID             uint            `gorm:"primarykey" json:"id"`
+	CreatedAt      time.Time       `json:"created_at"`
+	CreatedByID    *uint           `json:"created_by_id,omitempty"`                          // 發起人
+	Target         string          `gorm:"type:varchar(20);not null" json:"target"`          // all/department/employees
+	Department     string          `gorm:"type:varchar(50)" json:"department,omitempty"`     // 對象為部門時的部門
+	StartDate      time.Time       `json:"start_date"`                                       // 開始日期
+	EndDate        time.Time       `json:"end_date"`                                         // 結束日期
+	LeaveType      string          `gorm:"type:varchar(20);not null" json:"leave_type"`      // 請假類型
+	Reason         string          `gorm:"type:text" json:"reason"`                          // 請假原因
+	ConflictPolicy string          `gorm:"type:varchar(10);not null" json:"conflict_policy"` // skip/abort
+	Status         string          `gorm:"type:varchar(20);not null" json:"status"`          // completed/aborted
+	CreatedCount   int             `json:"created_count"`                                    // 已創建的請假數
+	SkippedCount   int             `json:"skipped_count"`                                    // 未創建請假的員工數
+	Items          []BulkLeaveItem `gorm:"foreignKey:JobID" json:"items,omitempty"`          // 每個員工的處理結果
+}
+
+// BulkLeaveItem 批量請假中單個員工的處理結果
+type BulkLeaveItem struct {
+	ID               uint   `gorm:"primarykey" json:"id"`
+	JobID            uint   `gorm:"not null;index" json:"job_id"`
+	EmployeeID       uint   `gorm:"not null" json:"employee_id"`
+	Outcome          string `gorm:"type:varchar(20);not null" json:"outcome"`                      // created/conflict/inactive/not_found/aborted
+	LeaveID          *uint  `json:"leave_id,omitempty"`                                            // 創建的請假
+	ConflictLeaveIDs []uint `gorm:"type:json;serializer:json" json:"conflict_leave_ids,omitempty"` // 重疊的請假
+}
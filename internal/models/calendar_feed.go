@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// 行事曆訂閱範圍
+const (
+	CalendarFeedScopeSelf       = "self"       // 本人的請假
+	CalendarFeedScopeTeam       = "team"       // 本人及所有直接與間接下屬的請假
+	CalendarFeedScopeDepartment = "department" // 本人所在部門的請假
+)
+
+// CalendarFeedScopes 所有有效的行事曆訂閱範圍
+var CalendarFeedScopes = []string{CalendarFeedScopeSelf, CalendarFeedScopeTeam, CalendarFeedScopeDepartment}
+
+// CalendarFeed 員工的 iCalendar 訂閱，憑連結中的令牌匿名讀取，令牌只保存雜湊
+type CalendarFeed struct {
+	ID             uint       `gorm:"primarykey" json:"id"`
+	CreatedAt      time.Time  `json:"created_at"`
+	OwnerID        uint       `gorm:"not null;index" json:"owner_id"`              // 訂閱所屬員工ID
+	Scope          string     `gorm:"type:varchar(20);not null" json:"scope"`      // self/team/department
+	TokenHash      string     `gorm:"type:char(64);not null;uniqueIndex" json:"-"` // 令牌的 SHA-256 雜湊
+	LastAccessedAt *time.Time `json:"last_accessed_at,omitempty"`                  // 最近一次被日曆客戶端讀取的時間
+}
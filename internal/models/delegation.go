@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ApprovalDelegation 審批代理，主管在期間內把所屬員工的請假審批交給代理人
+type ApprovalDelegation struct {
+	gorm.Model
+	ManagerID  uint      `gorm:"not null;index" json:"manager_id"`  // 委託的主管ID
+	DelegateID uint      `gorm:"not null;index" json:"delegate_id"` // 代理人ID
+	StartDate  time.Time `gorm:"not null" json:"start_date"`        // 代理開始時間
+	EndDate    time.Time `gorm:"not null" json:"end_date"`          // 代理結束時間
+	Reason     string    `gorm:"type:varchar(255)" json:"reason"`   // 代理原因
+}
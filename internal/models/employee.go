@@ -6,18 +6,32 @@ import (
 	"gorm.io/gorm"
 )
 
+// 員工狀態
+const (
+	EmployeeStatusActive   = "active"   // 在職
+	EmployeeStatusInactive = "inactive" // 離職
+)
+
+// EmployeeStatuses 所有有效的員工狀態
+var EmployeeStatuses = []string{EmployeeStatusActive, EmployeeStatusInactive}
+
 // Employee 員工模型
 type Employee struct {
 	gorm.Model
-	Name             string    `gorm:"type:varchar(100);not null" json:"name"`              // 姓名
-	Email            string    `gorm:"type:varchar(100);uniqueIndex;not null" json:"email"` // 電子郵件
-	Phone            string    `gorm:"type:varchar(20)" json:"phone"`                       // 電話
-	Position         string    `gorm:"type:varchar(50)" json:"position"`                    // 職位
-	Department       string    `gorm:"type:varchar(50)" json:"department"`                  // 部門
-	Level            int       `json:"level"`                                               // 職等
-	Salary           float64   `json:"salary"`                                              // 薪資
-	HireDate         time.Time `json:"hire_date"`                                           // 入職日期
-	Address          string    `gorm:"type:varchar(200)" json:"address"`                    // 地址
-	EmergencyContact string    `gorm:"type:varchar(100)" json:"emergency_contact"`          // 緊急聯絡人
-	Status           string    `gorm:"type:varchar(20);default:'active'" json:"status"`     // 狀態（active/inactive）
+	Name                 string     `gorm:"type:varchar(100);not null" json:"name"`              // 姓名
+	Email                string     `gorm:"type:varchar(100);uniqueIndex;not null" json:"email"` // 電子郵件
+	Phone                string     `gorm:"type:varchar(20)" json:"phone"`                       // 電話
+	Position             string     `gorm:"type:varchar(50)" json:"position"`                    // 職位
+	Department           string     `gorm:"type:varchar(50)" json:"department"`                  // 部門
+	Level                int        `json:"level"`                                               // 職等
+	Salary               float64    `json:"salary"`                                              // 薪資
+	HireDate             time.Time  `json:"hire_date"`                                           // 入職日期
+	TerminationDate      *time.Time `json:"termination_date,omitempty"`                          // 離職日期（最後在職日），狀態變為離職時未指定則為當天
+	HealthDependents     int        `json:"health_dependents"`                                   // 依附投保健保的眷屬人數
+	PensionVoluntaryRate float64    `json:"pension_voluntary_rate"`                              // 勞退個人自願提繳比例（%，0～6）
+	Address              string     `gorm:"type:varchar(200)" json:"address"`                    // 地址
+	EmergencyContact     string     `gorm:"type:varchar(100)" json:"emergency_contact"`          // 緊急聯絡人
+	Status               string     `gorm:"type:varchar(20);default:'active'" json:"status"`     // 狀態（active/inactive）
+	ManagerID            *uint      `gorm:"index" json:"manager_id,omitempty"`                   // 直屬主管ID，負責審批請假
+	Locale               string     `gorm:"type:varchar(10)" json:"locale"`                      // 偏好語系（zh-TW/en），用於通知等非請求場景
 }
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// Holiday 公司假日（國定假日、補假、颱風假等），不需上班的日子
+type Holiday struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	Date      time.Time `gorm:"type:date;not null;uniqueIndex" json:"date"` // 日期
+	Name      string    `gorm:"type:varchar(100);not null" json:"name"`     // 名稱，如 端午節
+}
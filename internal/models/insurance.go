@@ -0,0 +1,101 @@
+package models
+
+import "time"
+
+// 社會保險的類型，各自使用不同的分級表
+const (
+	InsuranceLabor   = "labor"   // 勞工保險（含就業保險與職災保險）
+	InsuranceHealth  = "health"  // 全民健康保險
+	InsurancePension = "pension" // 勞工退休金
+)
+
+// InsuranceKinds 所有社會保險類型
+var InsuranceKinds = []string{InsuranceLabor, InsuranceHealth, InsurancePension}
+
+// InsuranceGradeTable 投保薪資分級表，同一類型按生效日期區分版本，計算某月份時使用當月一日已生效的最新版本
+type InsuranceGradeTable struct {
+	ID            uint             `gorm:"primarykey" json:"id"`
+	CreatedAt     time.Time        `json:"created_at"`
+	Kind          string           `gorm:"type:varchar(20);not null;uniqueIndex:idx_insurance_table_version;uniqueIndex:idx_insurance_table_effective" json:"kind"` // 保險類型
+	Version       string           `gorm:"type:varchar(50);not null;uniqueIndex:idx_insurance_table_version" json:"version"`                                        // 版本名稱，如 2024
+	EffectiveFrom time.Time        `gorm:"type:date;not null;uniqueIndex:idx_insurance_table_effective" json:"effective_from"`                                      // 生效日期
+	Description   string           `gorm:"type:text" json:"description"`                                                                                            // 說明
+	Grades        []InsuranceGrade `gorm:"foreignKey:TableID" json:"grades,omitempty"`
+}
+
+// InsuranceGrade 分級表中的一級，月薪不超過 InsuredSalary 的按該級投保
+type InsuranceGrade struct {
+	ID            uint    `gorm:"primarykey" json:"id"`
+	TableID       uint    `gorm:"not null;index" json:"table_id"`
+	Grade         int     `gorm:"not null" json:"grade"`          // 級距
+	InsuredSalary float64 `gorm:"not null" json:"insured_salary"` // 月投保薪資（金額）
+}
+
+// GradeFor 返回月薪對應的級距：不超過月投保薪資的最低一級，超過最高一級時按最高一級投保；分級表為空時返回空
+func (t *InsuranceGradeTable) GradeFor(salary float64) *InsuranceGrade {
+	if len(t.Grades) == 0 {
+		return nil
+	}
+	for i := range t.Grades {
+		if salary <= t.Grades[i].InsuredSalary {
+			return &t.Grades[i]
+		}
+	}
+	return &t.Grades[len(t.Grades)-1]
+}
+
+// InsuredGrade 員工某類保險的投保級距
+type InsuredGrade struct {
+	Kind          string  `json:"kind"`
+	TableID       uint    `json:"table_id"`
+	Version       string  `json:"version"`
+	Grade         int     `json:"grade"`
+	InsuredSalary float64 `json:"insured_salary"`
+}
+
+// InsuranceContribution 薪資單中某類保險的月保費，員工負擔的部分同時記為薪資單的扣款明細
+type InsuranceContribution struct {
+	ID             uint    `gorm:"primarykey" json:"id"`
+	PayslipID      uint    `gorm:"not null;index" json:"payslip_id"`
+	RunID          uint    `gorm:"not null;index" json:"run_id"`
+	EmployeeID     uint    `gorm:"not null" json:"employee_id"`
+	Month          string  `gorm:"type:varchar(7);not null" json:"month"`
+	Kind           string  `gorm:"type:varchar(20);not null" json:"kind"` // 保險類型
+	TableID        uint    `gorm:"index" json:"table_id"`                 // 使用的分級表
+	Grade          int     `json:"grade"`                                 // 投保級距
+	InsuredSalary  float64 `json:"insured_salary"`                        // 月投保薪資
+	Days           int     `json:"days"`                                  // 計費天數，勞保與勞退按日計算，健保按月計算
+	Dependents     int     `json:"dependents,omitempty"`                  // 計費的健保眷屬人數
+	EmployeeRate   float64 `json:"employee_rate,omitempty"`               // 勞退個人自願提繳比例
+	EmployeeAmount float64 `json:"employee_amount"`                       // 員工負擔
+	EmployerAmount float64 `json:"employer_amount"`                       // 雇主負擔
+}
+
+// InsuranceReconciliation 薪資批次的保費對賬報表
+type InsuranceReconciliation struct {
+	RunID            uint                          `json:"run_id"`
+	Month            string                        `json:"month"`
+	Status           string                        `json:"status"`            // 薪資批次狀態
+	EmployeeTotal    float64                       `json:"employee_total"`    // 記錄的員工負擔合計
+	DeductedTotal    float64                       `json:"deducted_total"`    // 薪資單中保費扣款明細合計
+	EmployerTotal    float64                       `json:"employer_total"`    // 記錄的雇主負擔合計
+	ExpectedEmployee float64                       `json:"expected_employee"` // 按當前分級表與員工資料重算的員工負擔合計
+	ExpectedEmployer float64                       `json:"expected_employer"` // 按當前分級表與員工資料重算的雇主負擔合計
+	MismatchCount    int                           `json:"mismatch_count"`    // 不一致的項目數
+	Items            []InsuranceReconciliationItem `json:"items"`
+}
+
+// InsuranceReconciliationItem 薪資單中某類保險的對賬結果
+type InsuranceReconciliationItem struct {
+	EmployeeID       uint    `json:"employee_id"`
+	EmployeeName     string  `json:"employee_name"`
+	Kind             string  `json:"kind"`
+	Grade            int     `json:"grade"`             // 記錄的投保級距
+	ExpectedGrade    int     `json:"expected_grade"`    // 重算的投保級距
+	EmployeeAmount   float64 `json:"employee_amount"`   // 記錄的員工負擔
+	Deducted         float64 `json:"deducted"`          // 薪資單中的扣款
+	ExpectedEmployee float64 `json:"expected_employee"` // 重算的員工負擔
+	EmployerAmount   float64 `json:"employer_amount"`   // 記錄的雇主負擔
+	ExpectedEmployer float64 `json:"expected_employer"` // 重算的雇主負擔
+	Matched          bool    `json:"matched"`           // 記錄、扣款與重算的金額是否一致
+}
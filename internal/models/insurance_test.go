@@ -0,0 +1,43 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsuranceGradeTableGradeFor(t *testing.T) {
+	table := InsuranceGradeTable{Grades: []InsuranceGrade{
+		{Grade: 1, InsuredSalary: 27470},
+		{Grade: 2, InsuredSalary: 27600},
+		{Grade: 3, InsuredSalary: 28800},
+		{Grade: 4, InsuredSalary: 45800},
+	}}
+
+	tests := []struct {
+		name   string
+		salary float64
+		want   int
+	}{
+		{name: "未填月薪按最低一級", salary: 0, want: 1},
+		{name: "低於最低一級", salary: 20000, want: 1},
+		{name: "等於第一級", salary: 27470, want: 1},
+		{name: "略高於第一級", salary: 27471, want: 2},
+		{name: "等於中間一級", salary: 27600, want: 2},
+		{name: "介於兩級之間", salary: 28000, want: 3},
+		{name: "等於最高一級", salary: 45800, want: 4},
+		{name: "超過最高一級按最高一級", salary: 100000, want: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grade := table.GradeFor(tt.salary)
+			require.NotNil(t, grade)
+			assert.Equal(t, tt.want, grade.Grade)
+		})
+	}
+
+	t.Run("分級表為空", func(t *testing.T) {
+		assert.Nil(t, (&InsuranceGradeTable{}).GradeFor(30000))
+	})
+}
@@ -6,6 +6,54 @@ import (
 	"gorm.io/gorm"
 )
 
+// 請假類型
+const (
+	LeaveTypeAnnual       = "年假"  // 特別休假
+	LeaveTypeSick         = "病假"  // 普通傷病假
+	LeaveTypePersonal     = "事假"  // 事假
+	LeaveTypeMarriage     = "婚假"  // 婚假
+	LeaveTypeBereavement  = "喪假"  // 喪假
+	LeaveTypeMaternity    = "產假"  // 產假
+	LeaveTypePaternity    = "陪產假" // 陪產檢及陪產假
+	LeaveTypeMenstrual    = "生理假" // 生理假
+	LeaveTypeOfficial     = "公假"  // 公假
+	LeaveTypeCompensatory = "補休"  // 加班補休
+	LeaveTypeClosure      = "停班"  // 颱風等天然災害停班或公司停工，只能由批量請假創建
+)
+
+// LeaveTypes 員工可以申請的請假類型
+var LeaveTypes = []string{
+	LeaveTypeAnnual,
+	LeaveTypeSick,
+	LeaveTypePersonal,
+	LeaveTypeMarriage,
+	LeaveTypeBereavement,
+	LeaveTypeMaternity,
+	LeaveTypePaternity,
+	LeaveTypeMenstrual,
+	LeaveTypeOfficial,
+	LeaveTypeCompensatory,
+}
+
+// BulkLeaveTypes 批量請假可以使用的請假類型
+var BulkLeaveTypes = append(append([]string{}, LeaveTypes...), LeaveTypeClosure)
+
+// LeaveTypeDeductsBalance 判斷請假類型是否扣除員工的假期額度，公假與停班不扣除
+func LeaveTypeDeductsBalance(leaveType string) bool {
+	return leaveType != LeaveTypeOfficial && leaveType != LeaveTypeClosure
+}
+
+// 請假狀態
+const (
+	LeaveStatusPending   = "pending"   // 待審批
+	LeaveStatusApproved  = "approved"  // 已核准
+	LeaveStatusRejected  = "rejected"  // 已駁回
+	LeaveStatusCancelled = "cancelled" // 已取消
+)
+
+// LeaveStatuses 所有有效的請假狀態
+var LeaveStatuses = []string{LeaveStatusPending, LeaveStatusApproved, LeaveStatusRejected, LeaveStatusCancelled}
+
 // Leave 請假記錄模型
 type Leave struct {
 	gorm.Model
@@ -15,8 +63,14 @@ type Leave struct {
 	EndDate       time.Time  `json:"end_date"`                                         // 結束日期
 	LeaveType     string     `gorm:"type:varchar(20);not null" json:"leave_type"`      // 請假類型（年假/病假/事假等）
 	Reason        string     `gorm:"type:text" json:"reason"`                          // 請假原因
-	Status        string     `gorm:"type:varchar(20);default:'pending'" json:"status"` // 狀態（pending/approved/rejected）
+	Status        string     `gorm:"type:varchar(20);default:'pending'" json:"status"` // 狀態（pending/approved/rejected/cancelled）
 	ApproverID    *uint      `json:"approver_id,omitempty"`                            // 審批人ID
+	OnBehalfOfID  *uint      `json:"on_behalf_of_id,omitempty"`                        // 代理審批時被代理的原審批人ID
 	ApproveTime   *time.Time `json:"approve_time,omitempty"`                           // 審批時間
 	ApproveRemark string     `gorm:"type:text" json:"approve_remark"`                  // 審批備註
+	RemindedAt    *time.Time `json:"reminded_at,omitempty"`                            // 超過審批時限後提醒審批人的時間
+	EscalatedAt   *time.Time `json:"escalated_at,omitempty"`                           // 升級給上一級主管的時間
+	EscalatedToID *uint      `json:"escalated_to_id,omitempty"`                        // 升級後的審批人ID
+
+	Attachments []LeaveAttachment `gorm:"foreignKey:LeaveID" json:"attachments,omitempty"` // 證明文件，查詢單筆請假時加載
 }
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// 郵件中可執行的請假操作
+const (
+	LeaveActionApprove = "approve"
+	LeaveActionReject  = "reject"
+)
+
+// LeaveActionToken 郵件操作令牌，令牌本身經 HMAC 簽名，數據庫記錄用於保證只能使用一次
+type LeaveActionToken struct {
+	ID         string     `gorm:"primaryKey;type:varchar(32)" json:"id"` // 令牌ID
+	CreatedAt  time.Time  `json:"created_at"`
+	LeaveID    uint       `gorm:"not null;index" json:"leave_id"`          // 請假記錄ID
+	ApproverID uint       `gorm:"not null" json:"approver_id"`             // 以該員工身份審批
+	Action     string     `gorm:"type:varchar(10);not null" json:"action"` // approve/reject
+	ExpiresAt  time.Time  `gorm:"not null;index" json:"expires_at"`        // 過期時間
+	UsedAt     *time.Time `json:"used_at,omitempty"`                       // 使用時間，未使用時為空
+}
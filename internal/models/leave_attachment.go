@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// LeaveAttachment 請假附件，如診斷證明、結婚證書、訃聞等證明文件
+type LeaveAttachment struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	LeaveID     uint      `gorm:"not null;index" json:"leave_id"`                 // 請假ID
+	UploaderID  uint      `gorm:"not null" json:"uploader_id"`                    // 上傳者ID
+	FileName    string    `gorm:"type:varchar(255);not null" json:"file_name"`    // 原始文件名
+	ContentType string    `gorm:"type:varchar(100);not null" json:"content_type"` // 按文件內容識別的類型
+	Size        int64     `gorm:"not null" json:"size"`                           // 大小（字節）
+	SHA256      string    `gorm:"type:char(64);not null" json:"sha256"`           // 內容雜湊，供核對文件是否被替換
+	StorageKey  string    `gorm:"type:varchar(255);not null" json:"-"`            // 在附件存儲中的路徑
+}
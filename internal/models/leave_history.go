@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// 請假歷程動作
+const (
+	LeaveHistorySubmitted    = "submitted"     // 提交申請
+	LeaveHistoryApproved     = "approved"      // 核准
+	LeaveHistoryRejected     = "rejected"      // 駁回
+	LeaveHistoryCancelled    = "cancelled"     // 取消
+	LeaveHistoryReminded     = "reminded"      // 超過審批時限，提醒審批人
+	LeaveHistoryEscalated    = "escalated"     // 超過升級時限，改由上一級主管審批
+	LeaveHistoryAutoApproved = "auto_approved" // 開始日期已過仍未審批，按政策自動核准
+	LeaveHistoryAttached     = "attached"      // 上傳附件
+	LeaveHistoryDetached     = "detached"      // 刪除附件
+	LeaveHistoryBulkCreated  = "bulk_created"  // 由批量請假直接創建為已核准
+)
+
+// LeaveHistory 請假歷程，只增不改
+type LeaveHistory struct {
+	ID                 uint      `gorm:"primarykey" json:"id"`
+	CreatedAt          time.Time `json:"created_at"`
+	LeaveID            uint      `gorm:"not null;index" json:"leave_id"`          // 請假ID
+	Action             string    `gorm:"type:varchar(30);not null" json:"action"` // 動作
+	ActorID            *uint     `json:"actor_id,omitempty"`                      // 執行動作的員工ID，系統自動執行時為空
+	ApproverID         *uint     `json:"approver_id,omitempty"`                   // 被提醒或升級後的審批人
+	PreviousApproverID *uint     `json:"previous_approver_id,omitempty"`          // 升級前或被代理的審批人
+	Remark             string    `gorm:"type:text" json:"remark,omitempty"`       // 備註
+}
@@ -0,0 +1,81 @@
+package models
+
+import "time"
+
+// 假期額度流水類型，Days 為正數時增加額度，負數時扣減額度
+const (
+	LeaveLedgerGrant       = "grant"        // 發放年度額度
+	LeaveLedgerAdjustment  = "adjustment"   // 人工調整，可正可負
+	LeaveLedgerCarryIn     = "carry_in"     // 從上一年度遞延轉入
+	LeaveLedgerOvertime    = "overtime"     // 核准的加班轉為補休
+	LeaveLedgerCarriedOver = "carried_over" // 年終結算遞延到次年
+	LeaveLedgerExpired     = "expired"      // 年終結算作廢
+	LeaveLedgerPaidOut     = "paid_out"     // 年終結算折發工資
+)
+
+// LeaveLedgerManualKinds 可以人工寫入的流水類型，其餘由年終結算產生
+var LeaveLedgerManualKinds = []string{LeaveLedgerGrant, LeaveLedgerAdjustment}
+
+// LeaveLedgerEntry 假期額度流水，只增不改；某年度的額度為該年度所有流水之和減去已核准請假的天數
+type LeaveLedgerEntry struct {
+	ID         uint       `gorm:"primarykey" json:"id"`
+	CreatedAt  time.Time  `json:"created_at"`
+	EmployeeID uint       `gorm:"not null;index:idx_leave_ledger_employee_year" json:"employee_id"`
+	Year       int        `gorm:"not null;index:idx_leave_ledger_employee_year" json:"year"`          // 所屬年度
+	LeaveType  string     `gorm:"type:varchar(20);not null" json:"leave_type"`                        // 請假類型
+	Kind       string     `gorm:"type:varchar(20);not null" json:"kind"`                              // 流水類型
+	Days       float64    `json:"days"`                                                               // 天數
+	Amount     float64    `json:"amount,omitempty"`                                                   // 折發工資的金額，僅 paid_out 使用
+	CloseID    *uint      `gorm:"index" json:"close_id,omitempty"`                                    // 產生該流水的年終結算
+	OvertimeID *uint      `gorm:"uniqueIndex:idx_leave_ledger_overtime" json:"overtime_id,omitempty"` // 產生該流水的加班申請，每筆加班申請只轉入一次補休
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`                                               // 額度到期日
+	ActorID    *uint      `json:"actor_id,omitempty"`                                                 // 寫入流水的員工
+	Remark     string     `gorm:"type:text" json:"remark,omitempty"`                                  // 備註
+}
+
+// LeaveBalance 員工某年度某類請假的額度
+type LeaveBalance struct {
+	LeaveType string  `json:"leave_type"`
+	Year      int     `json:"year"`
+	Entitled  float64 `json:"entitled"`  // 發放、調整與遞延轉入的天數
+	Used      float64 `json:"used"`      // 該年度內已核准請假的天數
+	Settled   float64 `json:"settled"`   // 年終結算遞延、作廢與折發的天數
+	Remaining float64 `json:"remaining"` // 剩餘天數
+}
+
+// YearEndClose 年終結算，每個年度只能結算一次
+type YearEndClose struct {
+	ID           uint               `gorm:"primarykey" json:"id"`
+	CreatedAt    time.Time          `json:"created_at"`
+	Year         int                `gorm:"uniqueIndex;not null" json:"year"` // 結算年度
+	CreatedByID  *uint              `json:"created_by_id,omitempty"`          // 發起人
+	CarriedDays  float64            `json:"carried_days"`                     // 遞延到次年的總天數
+	ExpiredDays  float64            `json:"expired_days"`                     // 作廢的總天數
+	PaidDays     float64            `json:"paid_days"`                        // 折發工資的總天數
+	PayoutAmount float64            `json:"payout_amount"`                    // 折發工資的總金額
+	Items        []YearEndCloseItem `gorm:"foreignKey:CloseID" json:"items,omitempty"`
+}
+
+// YearEndCloseItem 年終結算中單個員工某類請假的結算結果
+type YearEndCloseItem struct {
+	ID           uint    `gorm:"primarykey" json:"id"`
+	CloseID      uint    `gorm:"not null;index" json:"close_id"`
+	EmployeeID   uint    `gorm:"not null" json:"employee_id"`
+	EmployeeName string  `gorm:"type:varchar(100)" json:"employee_name"` // 結算時的員工姓名
+	Department   string  `gorm:"type:varchar(50)" json:"department"`     // 結算時的部門
+	LeaveType    string  `gorm:"type:varchar(20);not null" json:"leave_type"`
+	Unused       float64 `json:"unused"`       // 未休天數
+	CarriedOver  float64 `json:"carried_over"` // 遞延到次年的天數
+	Expired      float64 `json:"expired"`      // 作廢的天數
+	PaidOut      float64 `json:"paid_out"`     // 折發工資的天數
+	DailyRate    float64 `json:"daily_rate"`   // 結算時的日薪
+	Amount       float64 `json:"amount"`       // 折發工資的金額
+}
+
+// YearEndPayoutReport 年終結算的折發工資報表
+type YearEndPayoutReport struct {
+	Year        int                `json:"year"`
+	TotalDays   float64            `json:"total_days"`   // 折發工資的總天數
+	TotalAmount float64            `json:"total_amount"` // 折發工資的總金額
+	Items       []YearEndCloseItem `json:"items"`        // 有折發工資的結算結果
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// NotificationEventTypes 會發送通知的事件類型，用戶可逐一關閉
+var NotificationEventTypes = []string{
+	EventLeaveSubmitted,
+	EventLeaveApproved,
+	EventLeaveRejected,
+	EventLeaveCancelled,
+	EventLeaveReminded,
+	EventLeaveEscalated,
+}
+
+// NotificationPreference 用戶對某類事件的通知偏好，沒有記錄時默認接收
+type NotificationPreference struct {
+	gorm.Model
+	EmployeeID uint   `gorm:"not null;uniqueIndex:idx_notification_pref,priority:1" json:"employee_id"`                 // 員工ID
+	EventType  string `gorm:"type:varchar(50);not null;uniqueIndex:idx_notification_pref,priority:2" json:"event_type"` // 事件類型
+	Email      bool   `gorm:"not null" json:"email"`                                                                    // 是否接收郵件通知
+}
+
+// 通知渠道
+const (
+	NotificationChannelEmail = "email"
+)
+
+// 通知發送狀態
+const (
+	NotificationStatusPending = "pending" // 等待發送
+	NotificationStatusSent    = "sent"    // 已發送
+	NotificationStatusFailed  = "failed"  // 重試次數用盡，發送失敗
+	NotificationStatusSkipped = "skipped" // 未發送（用戶已關閉、沒有收件人等）
+)
+
+// NotificationStatuses 所有通知發送狀態
+var NotificationStatuses = []string{NotificationStatusPending, NotificationStatusSent, NotificationStatusFailed, NotificationStatusSkipped}
+
+// NotificationLog 通知發送記錄
+type NotificationLog struct {
+	gorm.Model
+	EventID       string     `gorm:"type:varchar(64);not null;uniqueIndex:idx_notification_event,priority:1" json:"event_id"` // 觸發通知的事件ID
+	EventType     string     `gorm:"type:varchar(50);not null" json:"event_type"`                                             // 事件類型
+	RecipientID   uint       `gorm:"not null;uniqueIndex:idx_notification_event,priority:2;index" json:"recipient_id"`        // 收件員工ID
+	Channel       string     `gorm:"type:varchar(20);not null;uniqueIndex:idx_notification_event,priority:3" json:"channel"`  // 通知渠道
+	LeaveID       uint       `gorm:"index" json:"leave_id,omitempty"`                                                         // 相關請假記錄ID
+	Address       string     `gorm:"type:varchar(100)" json:"address"`                                                        // 收件地址
+	Locale        string     `gorm:"type:varchar(10)" json:"locale"`                                                          // 通知使用的語系
+	Subject       string     `gorm:"type:varchar(200)" json:"subject"`                                                        // 主旨
+	TextBody      string     `gorm:"type:mediumtext" json:"-"`                                                                // 純文本正文
+	HTMLBody      string     `gorm:"type:mediumtext" json:"-"`                                                                // HTML 正文
+	Status        string     `gorm:"type:varchar(20);not null;index:idx_notification_due,priority:1" json:"status"`           // 發送狀態
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`                                                      // 已嘗試次數
+	NextAttemptAt time.Time  `gorm:"index:idx_notification_due,priority:2" json:"next_attempt_at"`                            // 下一次發送時間
+	LastError     string     `gorm:"type:text" json:"last_error,omitempty"`                                                   // 最近一次失敗或跳過的原因
+	SentAt        *time.Time `json:"sent_at,omitempty"`                                                                       // 發送成功時間
+}
+
+// Notification 站內通知，每個收件人一條，記錄已讀狀態
+type Notification struct {
+	gorm.Model
+	RecipientID uint       `gorm:"not null;uniqueIndex:idx_inbox_event,priority:1;index:idx_inbox_recipient,priority:1" json:"recipient_id"` // 收件員工ID
+	EventID     string     `gorm:"type:varchar(64);not null;uniqueIndex:idx_inbox_event,priority:2" json:"event_id"`                         // 觸發通知的事件ID
+	EventType   string     `gorm:"type:varchar(50);not null" json:"event_type"`                                                              // 事件類型
+	LeaveID     uint       `json:"leave_id,omitempty"`                                                                                       // 相關請假記錄ID
+	Title       string     `gorm:"type:varchar(200)" json:"title"`                                                                           // 標題，按收件人的語系生成
+	Body        string     `gorm:"type:text" json:"body"`                                                                                    // 正文
+	ReadAt      *time.Time `gorm:"index:idx_inbox_recipient,priority:2" json:"read_at"`                                                      // 已讀時間，未讀時為空
+}
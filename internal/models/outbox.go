@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// OutboxMessage 待發佈的領域事件
+// 與業務數據在同一事務中寫入，由中繼程序按順序發佈到各個事件出口
+type OutboxMessage struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`                                                                  // 自增ID，同一實體的事件按ID順序發佈
+	EventID       string     `gorm:"type:varchar(64);not null;uniqueIndex" json:"event_id"`                                 // 事件ID
+	EventType     string     `gorm:"type:varchar(50);not null" json:"event_type"`                                           // 事件類型
+	AggregateType string     `gorm:"type:varchar(20);not null;index:idx_outbox_aggregate,priority:1" json:"aggregate_type"` // 實體類型（employee/leave/overtime）
+	AggregateID   uint       `gorm:"not null;index:idx_outbox_aggregate,priority:2" json:"aggregate_id"`                    // 實體ID
+	Payload       string     `gorm:"type:mediumtext;not null" json:"payload"`                                               // 事件 JSON
+	CreatedAt     time.Time  `json:"created_at"`
+	PublishedAt   *time.Time `gorm:"index" json:"published_at,omitempty"`   // 發佈成功時間，為空表示尚未發佈
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`    // 發佈失敗次數
+	NextAttemptAt time.Time  `json:"next_attempt_at"`                       // 下一次嘗試發佈的時間
+	LastError     string     `gorm:"type:text" json:"last_error,omitempty"` // 最近一次失敗原因
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 加班日的類型，薪資按不同的倍率計算
+const (
+	OvertimeDayWeekday = "weekday"  // 工作日
+	OvertimeDayRestDay = "rest_day" // 週末休息日
+	OvertimeDayHoliday = "holiday"  // 公司假日
+)
+
+// OvertimeDayTypes 所有加班日類型
+var OvertimeDayTypes = []string{OvertimeDayWeekday, OvertimeDayRestDay, OvertimeDayHoliday}
+
+// 加班的補償方式
+const (
+	OvertimeCompensationPay      = "pay"       // 加班費
+	OvertimeCompensationCompTime = "comp_time" // 補休
+)
+
+// OvertimeCompensations 所有補償方式
+var OvertimeCompensations = []string{OvertimeCompensationPay, OvertimeCompensationCompTime}
+
+// OvertimeRequest 加班申請，審批流程與請假相同，狀態沿用請假狀態
+type OvertimeRequest struct {
+	gorm.Model
+	EmployeeID    uint       `gorm:"not null;index" json:"employee_id"`                // 員工ID
+	Employee      Employee   `gorm:"foreignKey:EmployeeID" json:"employee"`            // 關聯員工
+	Date          time.Time  `gorm:"type:date;not null" json:"date"`                   // 加班日期
+	Hours         float64    `gorm:"not null" json:"hours"`                            // 加班時數
+	Reason        string     `gorm:"type:text" json:"reason"`                          // 加班原因
+	DayType       string     `gorm:"type:varchar(20);not null" json:"day_type"`        // 加班日類型（weekday/rest_day/holiday），提交時按日期判斷
+	Compensation  string     `gorm:"type:varchar(20);not null" json:"compensation"`    // 補償方式（pay/comp_time）
+	Status        string     `gorm:"type:varchar(20);default:'pending'" json:"status"` // 狀態（pending/approved/rejected/cancelled）
+	ApproverID    *uint      `json:"approver_id,omitempty"`                            // 審批人ID
+	OnBehalfOfID  *uint      `json:"on_behalf_of_id,omitempty"`                        // 代理審批時被代理的原審批人ID
+	ApproveTime   *time.Time `json:"approve_time,omitempty"`                           // 審批時間
+	ApproveRemark string     `gorm:"type:text" json:"approve_remark"`                  // 審批備註
+	CompDays      float64    `json:"comp_days,omitempty"`                              // 核准後轉入的補休天數
+	CompExpiresAt *time.Time `json:"comp_expires_at,omitempty"`                        // 轉入的補休到期日，未休的按年終結算處理
+}
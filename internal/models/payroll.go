@@ -0,0 +1,122 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 薪資批次的狀態，草稿可以重新計算，鎖定後不可修改
+const (
+	PayrollRunDraft  = "draft"  // 草稿
+	PayrollRunLocked = "locked" // 已鎖定
+)
+
+// PayrollRunStatuses 所有薪資批次狀態
+var PayrollRunStatuses = []string{PayrollRunDraft, PayrollRunLocked}
+
+// 薪資項目的類型
+const (
+	PayComponentAllowance = "allowance" // 津貼，計入應發薪資
+	PayComponentDeduction = "deduction" // 扣款，從應發薪資中扣除
+)
+
+// PayComponentKinds 所有薪資項目類型
+var PayComponentKinds = []string{PayComponentAllowance, PayComponentDeduction}
+
+// 薪資明細的收支方向
+const (
+	PayrollLineEarning   = "earning"   // 應發
+	PayrollLineDeduction = "deduction" // 應扣
+)
+
+// 薪資明細的來源
+const (
+	PayrollCodeBaseSalary      = "base_salary"      // 底薪，月中入職或離職時按在職天數折算
+	PayrollCodeUnpaidLeave     = "unpaid_leave"     // 不給薪或半薪的請假扣款
+	PayrollCodeOvertime        = "overtime"         // 選擇加班費的已核准加班
+	PayrollCodeAllowance       = "allowance"        // 配置的津貼
+	PayrollCodeDeduction       = "deduction"        // 配置的扣款
+	PayrollCodeLaborInsurance  = "labor_insurance"  // 勞保費員工負擔
+	PayrollCodeHealthInsurance = "health_insurance" // 健保費員工負擔（本人與眷屬）
+	PayrollCodePension         = "pension"          // 勞退個人自願提繳
+)
+
+// PayComponent 固定的津貼或扣款，適用於指定員工、指定部門或全體員工（員工與部門均為空）
+type PayComponent struct {
+	gorm.Model
+	Name        string  `gorm:"type:varchar(100);not null" json:"name"` // 項目名稱
+	Kind        string  `gorm:"type:varchar(20);not null" json:"kind"`  // 類型（allowance/deduction）
+	Amount      float64 `gorm:"not null" json:"amount"`                 // 每月金額
+	EmployeeID  *uint   `gorm:"index" json:"employee_id,omitempty"`     // 適用員工
+	Department  string  `gorm:"type:varchar(50)" json:"department"`     // 適用部門
+	StartMonth  string  `gorm:"type:varchar(7)" json:"start_month"`     // 起始月份（YYYY-MM），為空時不限
+	EndMonth    string  `gorm:"type:varchar(7)" json:"end_month"`       // 結束月份（YYYY-MM，含），為空時不限
+	ProRate     bool    `json:"pro_rate"`                               // 月中入職或離職時是否按在職天數折算
+	Active      bool    `gorm:"not null" json:"active"`                 // 是否啟用
+	Description string  `gorm:"type:text" json:"description"`           // 說明
+}
+
+// AppliesTo 判斷薪資項目是否適用於員工在某月份（YYYY-MM）的薪資
+func (c *PayComponent) AppliesTo(employee *Employee, month string) bool {
+	if !c.Active || (c.StartMonth != "" && month < c.StartMonth) || (c.EndMonth != "" && month > c.EndMonth) {
+		return false
+	}
+	if c.EmployeeID != nil {
+		return *c.EmployeeID == employee.ID
+	}
+	return c.Department == "" || c.Department == employee.Department
+}
+
+// PayrollRun 某月份的薪資批次，每個月份只有一個批次
+type PayrollRun struct {
+	ID                        uint       `gorm:"primarykey" json:"id"`
+	CreatedAt                 time.Time  `json:"created_at"`
+	UpdatedAt                 time.Time  `json:"updated_at"`
+	Month                     string     `gorm:"type:varchar(7);uniqueIndex;not null" json:"month"` // 薪資月份（YYYY-MM）
+	Status                    string     `gorm:"type:varchar(20);not null" json:"status"`           // 狀態（draft/locked）
+	CreatedByID               *uint      `json:"created_by_id,omitempty"`                           // 發起人
+	CalculatedAt              time.Time  `json:"calculated_at"`                                     // 最近一次計算的時間
+	LockedAt                  *time.Time `json:"locked_at,omitempty"`                               // 鎖定時間
+	LockedByID                *uint      `json:"locked_by_id,omitempty"`                            // 鎖定人
+	EmployeeCount             int        `json:"employee_count"`                                    // 計薪人數
+	GrossTotal                float64    `json:"gross_total"`                                       // 應發總額
+	DeductionTotal            float64    `json:"deduction_total"`                                   // 應扣總額
+	NetTotal                  float64    `json:"net_total"`                                         // 實發總額
+	EmployerContributionTotal float64    `json:"employer_contribution_total"`                       // 雇主負擔的勞健保費與勞退提繳總額
+	Payslips                  []Payslip  `gorm:"foreignKey:RunID" json:"payslips,omitempty"`
+}
+
+// Payslip 薪資批次中單個員工的薪資單
+type Payslip struct {
+	ID                    uint                    `gorm:"primarykey" json:"id"`
+	RunID                 uint                    `gorm:"not null;uniqueIndex:idx_payslip_run_employee" json:"run_id"`
+	Month                 string                  `gorm:"type:varchar(7);not null" json:"month"`
+	EmployeeID            uint                    `gorm:"not null;uniqueIndex:idx_payslip_run_employee;index" json:"employee_id"`
+	EmployeeName          string                  `gorm:"type:varchar(100)" json:"employee_name"` // 計算時的員工姓名
+	Department            string                  `gorm:"type:varchar(50)" json:"department"`     // 計算時的部門
+	BaseSalary            float64                 `json:"base_salary"`                            // 計算時的月薪
+	PeriodDays            int                     `json:"period_days"`                            // 當月天數
+	EmployedDays          int                     `json:"employed_days"`                          // 當月在職天數
+	Gross                 float64                 `json:"gross"`                                  // 應發薪資
+	Deductions            float64                 `json:"deductions"`                             // 應扣金額
+	Net                   float64                 `json:"net"`                                    // 實發薪資
+	EmployerContributions float64                 `json:"employer_contributions"`                 // 雇主負擔的勞健保費與勞退提繳
+	Lines                 []PayrollLine           `gorm:"foreignKey:PayslipID" json:"lines,omitempty"`
+	Contributions         []InsuranceContribution `gorm:"foreignKey:PayslipID" json:"contributions,omitempty"`
+}
+
+// PayrollLine 薪資單的明細
+type PayrollLine struct {
+	ID          uint    `gorm:"primarykey" json:"id"`
+	PayslipID   uint    `gorm:"not null;index" json:"payslip_id"`
+	Code        string  `gorm:"type:varchar(20);not null" json:"code"` // 來源
+	Kind        string  `gorm:"type:varchar(20);not null" json:"kind"` // 收支方向（earning/deduction）
+	Description string  `gorm:"type:varchar(255)" json:"description"`  // 說明
+	Quantity    float64 `json:"quantity"`                              // 天數或時數
+	Rate        float64 `json:"rate"`                                  // 單價
+	Amount      float64 `json:"amount"`                                // 金額，始終為正數
+	LeaveID     *uint   `json:"leave_id,omitempty"`                    // 產生扣款的請假
+	OvertimeID  *uint   `json:"overtime_id,omitempty"`                 // 產生加班費的加班申請
+	ComponentID *uint   `json:"component_id,omitempty"`                // 產生該明細的薪資項目
+}
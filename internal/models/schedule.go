@@ -0,0 +1,121 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 班表模板類型
+const (
+	ScheduleKindFixed    = "fixed"     // 固定工時，按星期排班
+	ScheduleKindRotating = "rotating"  // 輪班，按週期天數循環，週期從排班開始日期起算
+	ScheduleKindPartTime = "part_time" // 兼職，按星期排班，通常只有部分日子上班
+)
+
+// ScheduleKinds 所有有效的班表模板類型
+var ScheduleKinds = []string{ScheduleKindFixed, ScheduleKindRotating, ScheduleKindPartTime}
+
+// WorkScheduleTemplate 班表模板，一個週期內每天的班次；週期內沒有班次的日子不上班
+// 固定工時與兼職的週期為 7 天，第 0 天為週一
+type WorkScheduleTemplate struct {
+	gorm.Model
+	Name         string          `gorm:"type:varchar(100);not null;uniqueIndex" json:"name"` // 模板名稱
+	Kind         string          `gorm:"type:varchar(20);not null" json:"kind"`              // fixed/rotating/part_time
+	CycleDays    int             `gorm:"not null" json:"cycle_days"`                         // 週期天數
+	SkipHolidays bool            `gorm:"not null" json:"skip_holidays"`                      // 公司假日是否不上班
+	Description  string          `gorm:"type:text" json:"description"`                       // 說明
+	Shifts       []ScheduleShift `gorm:"foreignKey:TemplateID" json:"shifts"`                // 週期內的班次
+}
+
+// ScheduleShift 班表模板中週期內某一天的班次，下班時間早於上班時間時表示跨夜到次日
+type ScheduleShift struct {
+	ID           uint   `gorm:"primarykey" json:"id"`
+	TemplateID   uint   `gorm:"not null;uniqueIndex:idx_template_day" json:"template_id"`
+	DayIndex     int    `gorm:"not null;uniqueIndex:idx_template_day" json:"day_index"` // 週期內的第幾天，從 0 起算
+	Start        string `gorm:"type:varchar(5);not null" json:"start"`                  // 上班時間，HH:MM
+	End          string `gorm:"type:varchar(5);not null" json:"end"`                    // 下班時間，HH:MM
+	BreakMinutes int    `json:"break_minutes"`                                          // 休息分鐘數
+}
+
+// RosterAssignment 員工在一段期間內使用的班表模板，同一員工的期間不能重疊
+type RosterAssignment struct {
+	ID          uint                 `gorm:"primarykey" json:"id"`
+	CreatedAt   time.Time            `json:"created_at"`
+	EmployeeID  uint                 `gorm:"not null;index" json:"employee_id"`
+	TemplateID  uint                 `gorm:"not null;index" json:"template_id"`
+	Template    WorkScheduleTemplate `gorm:"foreignKey:TemplateID" json:"template"`
+	StartDate   time.Time            `gorm:"type:date;not null" json:"start_date"`
+	EndDate     *time.Time           `gorm:"type:date" json:"end_date,omitempty"` // 結束日期（包含），為空時不限
+	CycleOffset int                  `json:"cycle_offset"`                        // 輪班時開始日期對應週期內的第幾天，用於錯開同一模板的不同組
+	CreatedByID *uint                `json:"created_by_id,omitempty"`
+}
+
+// 換班申請狀態：對方同意後再由申請人的審批人審批
+const (
+	ShiftSwapPending   = "pending"   // 等待對方同意
+	ShiftSwapAccepted  = "accepted"  // 對方已同意，等待審批
+	ShiftSwapDeclined  = "declined"  // 對方拒絕
+	ShiftSwapApproved  = "approved"  // 已核准，班次已交換
+	ShiftSwapRejected  = "rejected"  // 審批人駁回
+	ShiftSwapCancelled = "cancelled" // 申請人取消
+)
+
+// ShiftSwapStatuses 所有換班申請狀態
+var ShiftSwapStatuses = []string{ShiftSwapPending, ShiftSwapAccepted, ShiftSwapDeclined, ShiftSwapApproved, ShiftSwapRejected, ShiftSwapCancelled}
+
+// ShiftSwapRequest 換班申請：申請人把 Date 的班次讓給對方，並接手對方在 CounterpartDate 的班次；
+// 兩個日期相同時為同一天互換班次
+type ShiftSwapRequest struct {
+	gorm.Model
+	RequesterID     uint       `gorm:"not null;index" json:"requester_id"`
+	Requester       Employee   `gorm:"foreignKey:RequesterID" json:"requester"`
+	CounterpartID   uint       `gorm:"not null;index" json:"counterpart_id"`
+	Counterpart     Employee   `gorm:"foreignKey:CounterpartID" json:"counterpart"`
+	Date            time.Time  `gorm:"type:date;not null" json:"date"`             // 申請人讓出班次的日期
+	CounterpartDate time.Time  `gorm:"type:date;not null" json:"counterpart_date"` // 對方讓出班次的日期
+	Reason          string     `gorm:"type:text" json:"reason"`
+	Status          string     `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	RespondTime     *time.Time `json:"respond_time,omitempty"`    // 對方同意或拒絕的時間
+	ApproverID      *uint      `json:"approver_id,omitempty"`     // 審批人ID
+	OnBehalfOfID    *uint      `json:"on_behalf_of_id,omitempty"` // 代理審批時被代理的原審批人ID
+	ApproveTime     *time.Time `json:"approve_time,omitempty"`
+	ApproveRemark   string     `gorm:"type:text" json:"approve_remark"`
+}
+
+// ShiftOverride 覆蓋班表模板的單日班次，由核准的換班產生；同一員工同一天有多筆時以最新的為準
+type ShiftOverride struct {
+	ID           uint       `gorm:"primarykey" json:"id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	EmployeeID   uint       `gorm:"not null;index:idx_override_employee_date" json:"employee_id"`
+	Date         time.Time  `gorm:"type:date;not null;index:idx_override_employee_date" json:"date"`
+	Off          bool       `gorm:"not null" json:"off"`            // 當天不上班
+	Start        *time.Time `json:"start,omitempty"`                // 上班時間
+	End          *time.Time `json:"end,omitempty"`                  // 下班時間
+	BreakMinutes int        `json:"break_minutes"`                  // 休息分鐘數
+	SwapID       *uint      `gorm:"index" json:"swap_id,omitempty"` // 產生覆蓋的換班申請
+}
+
+// 排定班次的來源
+const (
+	ShiftSourceDefault = "default" // 沒有排班時的默認上下班時間
+	ShiftSourceRoster  = "roster"  // 班表模板
+	ShiftSourceSwap    = "swap"    // 換班
+)
+
+// ScheduledShift 員工某天排定的班次，按排班、換班與公司假日即時計算
+type ScheduledShift struct {
+	EmployeeID   uint      `json:"employee_id"`
+	Date         string    `json:"date"` // 班次開始的日期，YYYY-MM-DD
+	Start        time.Time `json:"start"`
+	End          time.Time `json:"end"`
+	BreakMinutes int       `json:"break_minutes"`
+	Source       string    `json:"source"`                // default/roster/swap
+	TemplateID   *uint     `json:"template_id,omitempty"` // 來源為 roster 時的班表模板
+	SwapID       *uint     `json:"swap_id,omitempty"`     // 來源為 swap 時的換班申請
+}
+
+// Contains 返回 t 是否在班次的上下班時間之內
+func (s *ScheduledShift) Contains(t time.Time) bool {
+	return !t.Before(s.Start) && t.Before(s.End)
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 人力規則不滿足時的處理方式
+const (
+	StaffingEnforcementWarn  = "warn"  // 允許提交與核准，但返回警告
+	StaffingEnforcementBlock = "block" // 拒絕提交與核准
+)
+
+// StaffingEnforcements 所有有效的處理方式
+var StaffingEnforcements = []string{StaffingEnforcementWarn, StaffingEnforcementBlock}
+
+// StaffingRule 人力規則，限制部門或團隊同時請假的人數
+// 適用範圍為部門，或主管本人及其所有直接與間接下屬，二者只能指定其一
+type StaffingRule struct {
+	gorm.Model
+	Name           string     `gorm:"type:varchar(100);not null" json:"name"`                      // 規則名稱
+	Department     string     `gorm:"type:varchar(50);index" json:"department,omitempty"`          // 適用的部門
+	ManagerID      *uint      `gorm:"index" json:"manager_id,omitempty"`                           // 適用的團隊主管
+	MinPresent     int        `json:"min_present"`                                                 // 最少在崗人數，為 0 時不限制
+	MaxAbsentRatio float64    `json:"max_absent_ratio"`                                            // 同時請假人數佔比上限（0~1），為 0 時不限制
+	StartDate      *time.Time `json:"start_date,omitempty"`                                        // 生效開始日期，為空時不限
+	EndDate        *time.Time `json:"end_date,omitempty"`                                          // 生效結束日期（包含），為空時不限
+	Enforcement    string     `gorm:"type:varchar(10);not null;default:'warn'" json:"enforcement"` // warn/block
+	Active         bool       `gorm:"not null" json:"active"`                                      // 是否啟用，新增時默認啟用
+}
+
+// StaffingDay 規則範圍內某一天的在崗情況
+type StaffingDay struct {
+	Date      string `json:"date"`
+	Headcount int    `json:"headcount"` // 當天排定有班次的人數
+	Absent    int    `json:"absent"`    // 請假人數，包括正在評估的請假
+	Present   int    `json:"present"`   // 在崗人數
+	Satisfied bool   `json:"satisfied"` // 是否滿足規則
+}
+
+// StaffingCoverage 請假核准後某條人力規則的在崗情況，不入庫
+type StaffingCoverage struct {
+	RuleID      uint          `json:"rule_id"`
+	RuleName    string        `json:"rule_name"`
+	Enforcement string        `json:"enforcement"`
+	Satisfied   bool          `json:"satisfied"` // 請假期間每天都滿足規則
+	Days        []StaffingDay `json:"days"`      // 請假期間內規則生效且不是假日的每一天
+}
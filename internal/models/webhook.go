@@ -0,0 +1,100 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 事件類型
+const (
+	EventEmployeeHired      = "employee.hired"      // 新增員工
+	EventEmployeeUpdated    = "employee.updated"    // 員工資料變更
+	EventEmployeeTerminated = "employee.terminated" // 員工狀態變為離職
+	EventEmployeeDeleted    = "employee.deleted"    // 刪除員工
+	EventLeaveSubmitted     = "leave.submitted"     // 提交請假申請
+	EventLeaveApproved      = "leave.approved"      // 請假核准
+	EventLeaveRejected      = "leave.rejected"      // 請假駁回
+	EventLeaveCancelled     = "leave.cancelled"     // 請假取消
+	EventLeaveReminded      = "leave.reminded"      // 請假超過審批時限，提醒審批人
+	EventLeaveEscalated     = "leave.escalated"     // 請假超過升級時限，升級給上一級主管
+	EventLeaveDeleted       = "leave.deleted"       // 刪除請假記錄
+	EventOvertimeSubmitted  = "overtime.submitted"  // 提交加班申請
+	EventOvertimeApproved   = "overtime.approved"   // 加班核准
+	EventOvertimeRejected   = "overtime.rejected"   // 加班駁回
+	EventOvertimeCancelled  = "overtime.cancelled"  // 加班取消
+	EventPayrollLocked      = "payroll.locked"      // 薪資批次鎖定
+
+	// EventAll 訂閱所有事件
+	EventAll = "*"
+)
+
+// EventTypes 所有可訂閱的事件類型
+var EventTypes = []string{
+	EventEmployeeHired,
+	EventEmployeeUpdated,
+	EventEmployeeTerminated,
+	EventEmployeeDeleted,
+	EventLeaveSubmitted,
+	EventLeaveApproved,
+	EventLeaveRejected,
+	EventLeaveCancelled,
+	EventLeaveReminded,
+	EventLeaveEscalated,
+	EventLeaveDeleted,
+	EventOvertimeSubmitted,
+	EventOvertimeApproved,
+	EventOvertimeRejected,
+	EventOvertimeCancelled,
+	EventPayrollLocked,
+}
+
+// WebhookSubscription Webhook 訂閱
+type WebhookSubscription struct {
+	gorm.Model
+	URL         string   `gorm:"type:varchar(500);not null" json:"url"`        // 接收事件的地址
+	Secret      string   `gorm:"type:varchar(100);not null" json:"-"`          // 簽名密鑰，只在創建時返回
+	EventTypes  []string `gorm:"type:json;serializer:json" json:"event_types"` // 訂閱的事件類型，為空或包含 * 時訂閱所有事件
+	Description string   `gorm:"type:varchar(200)" json:"description"`         // 說明
+	Active      bool     `gorm:"not null" json:"active"`                       // 是否啟用
+}
+
+// Matches 判斷訂閱是否接收指定類型的事件
+func (s *WebhookSubscription) Matches(eventType string) bool {
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range s.EventTypes {
+		if t == EventAll || t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// 投遞狀態
+const (
+	DeliveryStatusPending   = "pending"   // 等待首次投遞
+	DeliveryStatusRetrying  = "retrying"  // 投遞失敗，等待重試
+	DeliveryStatusSucceeded = "succeeded" // 投遞成功
+	DeliveryStatusDead      = "dead"      // 重試次數用盡，進入死信列表
+)
+
+// DeliveryStatuses 所有投遞狀態
+var DeliveryStatuses = []string{DeliveryStatusPending, DeliveryStatusRetrying, DeliveryStatusSucceeded, DeliveryStatusDead}
+
+// WebhookDelivery 單個事件對單個訂閱的投遞記錄
+type WebhookDelivery struct {
+	gorm.Model
+	SubscriptionID uint                `gorm:"not null;uniqueIndex:idx_delivery_event,priority:1" json:"subscription_id"`           // 訂閱ID
+	Subscription   WebhookSubscription `gorm:"foreignKey:SubscriptionID" json:"-"`                                                  // 關聯訂閱
+	EventID        string              `gorm:"type:varchar(64);not null;uniqueIndex:idx_delivery_event,priority:2" json:"event_id"` // 事件ID，接收方可據此去重
+	EventType      string              `gorm:"type:varchar(50);not null" json:"event_type"`                                         // 事件類型
+	Payload        string              `gorm:"type:mediumtext;not null" json:"payload"`                                             // 投遞的請求體，重新投遞時原樣發送
+	Status         string              `gorm:"type:varchar(20);not null;index:idx_delivery_due,priority:1" json:"status"`           // 投遞狀態
+	Attempts       int                 `gorm:"not null;default:0" json:"attempts"`                                                  // 已嘗試次數
+	NextAttemptAt  time.Time           `gorm:"index:idx_delivery_due,priority:2" json:"next_attempt_at"`                            // 下一次投遞時間
+	LastStatusCode int                 `json:"last_status_code,omitempty"`                                                          // 最近一次響應的狀態碼
+	LastError      string              `gorm:"type:text" json:"last_error,omitempty"`                                               // 最近一次失敗原因
+	DeliveredAt    *time.Time          `json:"delivered_at,omitempty"`                                                              // 投遞成功時間
+}
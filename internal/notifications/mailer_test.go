@@ -0,0 +1,192 @@
+package notifications
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"hr-system/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTPServer 最小的 SMTP 服務器，記錄收到的信封與郵件內容
+type fakeSMTPServer struct {
+	listener net.Listener
+	rejectTo string // 拒絕投遞到該地址
+	received chan receivedMail
+}
+
+type receivedMail struct {
+	from string
+	to   []string
+	data string
+}
+
+func startFakeSMTPServer(t *testing.T, rejectTo string) *fakeSMTPServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &fakeSMTPServer{listener: listener, rejectTo: rejectTo, received: make(chan receivedMail, 1)}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeSMTPServer) config() config.SMTPConfig {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	return config.SMTPConfig{
+		Host:    "127.0.0.1",
+		Port:    addr.Port,
+		From:    "HR System <hr@example.com>",
+		Timeout: 5 * time.Second,
+	}
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+
+	var current receivedMail
+	reply("220 localhost fake ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250-localhost")
+			reply("250 8BITMIME")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			current = receivedMail{from: envelopeAddress(line)}
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			to := envelopeAddress(line)
+			if to == s.rejectTo {
+				reply("550 mailbox unavailable")
+				continue
+			}
+			current.to = append(current.to, to)
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			current.data = data.String()
+			s.received <- current
+			reply("250 OK queued")
+		case cmd == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+// envelopeAddress 取出 MAIL FROM/RCPT TO 命令中尖括號內的地址
+func envelopeAddress(line string) string {
+	start, end := strings.Index(line, "<"), strings.Index(line, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return line[start+1 : end]
+}
+
+func TestSMTPMailerSend(t *testing.T) {
+	server := startFakeSMTPServer(t, "")
+	mailer := NewSMTPMailer(server.config())
+
+	err := mailer.Send(context.Background(), &Mail{
+		To:      "wang@example.com",
+		Subject: "請假申請已核准",
+		Text:    "您的請假申請已核准。",
+		HTML:    "<p>您的請假申請已核准。</p>",
+	})
+	require.NoError(t, err)
+
+	var got receivedMail
+	select {
+	case got = <-server.received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("fake SMTP server did not receive the mail")
+	}
+	assert.Equal(t, "hr@example.com", got.from)
+	assert.Equal(t, []string{"wang@example.com"}, got.to)
+
+	msg, err := mail.ReadMessage(strings.NewReader(got.data))
+	require.NoError(t, err)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "請假申請已核准", subject)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	bodies := map[string]string{}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		content, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, part))
+		require.NoError(t, err)
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		bodies[contentType] = string(content)
+	}
+	assert.Equal(t, "您的請假申請已核准。", bodies["text/plain"])
+	assert.Equal(t, "<p>您的請假申請已核准。</p>", bodies["text/html"])
+}
+
+func TestSMTPMailerRejectedRecipient(t *testing.T) {
+	server := startFakeSMTPServer(t, "nobody@example.com")
+	mailer := NewSMTPMailer(server.config())
+
+	err := mailer.Send(context.Background(), &Mail{To: "nobody@example.com", Subject: "hi", Text: "hi"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "550")
+}
+
+func TestSMTPMailerConnectionRefused(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	mailer := NewSMTPMailer(config.SMTPConfig{Host: "127.0.0.1", Port: port, From: "hr@example.com", Timeout: time.Second})
+	err = mailer.Send(context.Background(), &Mail{To: "wang@example.com", Subject: "hi", Text: "hi"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), strconv.Itoa(port))
+}
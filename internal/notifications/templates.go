@@ -0,0 +1,122 @@
+package notifications
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+
+	"hr-system/internal/i18n"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+// 通知模板名稱，與 i18n 目錄中 notification.<name> 的鍵一致
+const (
+	TemplateLeaveSubmitted = "leave_submitted"
+	TemplateLeaveApproved  = "leave_approved"
+	TemplateLeaveRejected  = "leave_rejected"
+	TemplateLeaveCancelled = "leave_cancelled"
+	TemplateLeaveReminded  = "leave_reminded"
+	TemplateLeaveEscalated = "leave_escalated"
+)
+
+// Templates 所有通知模板
+var Templates = []string{
+	TemplateLeaveSubmitted,
+	TemplateLeaveApproved,
+	TemplateLeaveRejected,
+	TemplateLeaveCancelled,
+	TemplateLeaveReminded,
+	TemplateLeaveEscalated,
+}
+
+// TemplateName 返回事件類型對應的模板名稱，如 leave.submitted 對應 leave_submitted
+func TemplateName(eventType string) string {
+	return strings.ReplaceAll(eventType, ".", "_")
+}
+
+// TemplateData 渲染通知模板的數據，文本均應已按收件人語系本地化
+type TemplateData struct {
+	Locale        string
+	Subject       string // 由 Render 填入
+	RecipientName string
+	EmployeeName  string
+	LeaveID       uint
+	LeaveType     string
+	StartDate     string
+	EndDate       string
+	Reason        string
+	Remark        string
+
+	// 審批郵件中的操作連結，為空時不顯示
+	ApproveURL    string
+	RejectURL     string
+	LinksExpireAt string
+}
+
+// Content 渲染後的通知內容
+type Content struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+type localeTemplates struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// templates 按「語系/模板名稱」索引的已解析模板
+var templates = mustParseTemplates()
+
+func mustParseTemplates() map[string]localeTemplates {
+	parsed := make(map[string]localeTemplates)
+	for _, locale := range i18n.Supported {
+		for _, name := range Templates {
+			base := "templates/" + locale + "/" + name
+			text, err := texttemplate.New(name+".txt").Option("missingkey=error").ParseFS(templateFS, base+".txt")
+			if err != nil {
+				panic(fmt.Sprintf("notifications: parse %s.txt: %v", base, err))
+			}
+			html, err := htmltemplate.New("layout").Option("missingkey=error").ParseFS(templateFS, "templates/layout.html", base+".html")
+			if err != nil {
+				panic(fmt.Sprintf("notifications: parse %s.html: %v", base, err))
+			}
+			parsed[locale+"/"+name] = localeTemplates{text: text, html: html}
+		}
+	}
+	return parsed
+}
+
+// Render 以指定語系渲染通知，主旨取自 i18n 目錄，語系不支持時使用默認語系
+func Render(locale, name string, data TemplateData) (*Content, error) {
+	locale = i18n.Preferred(locale)
+	tmpl, ok := templates[locale+"/"+name]
+	if !ok {
+		return nil, fmt.Errorf("notification template %q not found", name)
+	}
+
+	data.Locale = locale
+	data.Subject, _ = i18n.Notification(locale, name, i18n.Vars{
+		"employee":   data.EmployeeName,
+		"leave_type": data.LeaveType,
+		"start_date": data.StartDate,
+		"end_date":   data.EndDate,
+		"remark":     data.Remark,
+		"leave_id":   strconv.FormatUint(uint64(data.LeaveID), 10),
+	})
+
+	var text, html bytes.Buffer
+	if err := tmpl.text.Execute(&text, data); err != nil {
+		return nil, err
+	}
+	if err := tmpl.html.ExecuteTemplate(&html, "layout", data); err != nil {
+		return nil, err
+	}
+	return &Content{Subject: data.Subject, Text: text.String(), HTML: html.String()}, nil
+}
@@ -0,0 +1,46 @@
+package notifications
+
+import (
+	"testing"
+
+	"hr-system/internal/i18n"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEveryTemplateRendersInEveryLocale(t *testing.T) {
+	data := TemplateData{
+		RecipientName: "王小明",
+		EmployeeName:  "<李大華>",
+		LeaveID:       42,
+		LeaveType:     "年假",
+		StartDate:     "2024-03-01",
+		EndDate:       "2024-03-02",
+		Reason:        "家庭旅遊",
+		Remark:        "同意",
+	}
+	for _, locale := range i18n.Supported {
+		for _, name := range Templates {
+			content, err := Render(locale, name, data)
+			require.NoError(t, err, "%s/%s", locale, name)
+			assert.NotEmpty(t, content.Subject, "%s/%s", locale, name)
+			assert.Contains(t, content.Text, "#42", "%s/%s", locale, name)
+			assert.Contains(t, content.HTML, `lang="`+locale+`"`, "%s/%s", locale, name)
+			// HTML 正文需轉義用戶輸入
+			assert.NotContains(t, content.HTML, "<李大華>", "%s/%s", locale, name)
+		}
+	}
+}
+
+func TestRenderFallsBackToDefaultLocale(t *testing.T) {
+	content, err := Render("fr", TemplateLeaveApproved, TemplateData{LeaveID: 1})
+	require.NoError(t, err)
+	title, _ := i18n.Notification(i18n.Default, TemplateLeaveApproved, nil)
+	assert.Equal(t, title, content.Subject)
+}
+
+func TestRenderUnknownTemplate(t *testing.T) {
+	_, err := Render(i18n.En, "leave_exploded", TemplateData{})
+	assert.Error(t, err)
+}
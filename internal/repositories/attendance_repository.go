@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type AttendanceRepository struct {
+	tx *gorm.DB // 非空時所有操作都在該事務中執行
+}
+
+func NewAttendanceRepository() *AttendanceRepository {
+	return &AttendanceRepository{}
+}
+
+// WithTx 返回在指定事務中執行的倉庫
+func (r *AttendanceRepository) WithTx(tx *gorm.DB) *AttendanceRepository {
+	return &AttendanceRepository{tx: tx}
+}
+
+func (r *AttendanceRepository) db() *gorm.DB {
+	if r.tx != nil {
+		return r.tx
+	}
+	return config.DB
+}
+
+func errCorrectionNotFound() *apperrors.Error {
+	return apperrors.NotFound(apperrors.CodeCorrectionNotFound, "Attendance correction not found")
+}
+
+// CreatePunch 寫入打卡記錄
+func (r *AttendanceRepository) CreatePunch(punch *models.AttendancePunch) error {
+	return apperrors.FromDB(r.db().Create(punch).Error, nil, nil)
+}
+
+// ListPunches 按時間順序獲取 employeeIDs 中員工在 [from, to) 內的打卡記錄
+func (r *AttendanceRepository) ListPunches(employeeIDs []uint, from, to time.Time) ([]models.AttendancePunch, error) {
+	var punches []models.AttendancePunch
+	err := r.db().Where("employee_id IN ? AND time >= ? AND time < ?", employeeIDs, from, to).
+		Order("time, id").
+		Find(&punches).Error
+	if err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return punches, nil
+}
+
+// CreateCorrection 創建補卡申請
+func (r *AttendanceRepository) CreateCorrection(correction *models.AttendanceCorrection) error {
+	return apperrors.FromDB(r.db().Omit("Employee").Create(correction).Error, nil, nil)
+}
+
+// GetCorrection 根據ID獲取補卡申請，包括員工
+func (r *AttendanceRepository) GetCorrection(id uint) (*models.AttendanceCorrection, error) {
+	var correction models.AttendanceCorrection
+	if err := r.db().Preload("Employee").First(&correction, id).Error; err != nil {
+		return nil, apperrors.FromDB(err, errCorrectionNotFound(), nil)
+	}
+	return &correction, nil
+}
+
+// UpdateCorrectionStatus 僅在補卡申請仍為待審批時更新審批結果，返回是否更新成功
+func (r *AttendanceRepository) UpdateCorrectionStatus(correction *models.AttendanceCorrection) (bool, error) {
+	result := r.db().Model(&models.AttendanceCorrection{}).
+		Where("id = ? AND status = ?", correction.ID, models.LeaveStatusPending).
+		Updates(map[string]interface{}{
+			"status":          correction.Status,
+			"approver_id":     correction.ApproverID,
+			"on_behalf_of_id": correction.OnBehalfOfID,
+			"approve_time":    correction.ApproveTime,
+			"approve_remark":  correction.ApproveRemark,
+		})
+	if result.Error != nil {
+		return false, apperrors.FromDB(result.Error, nil, nil)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ListCorrections 按創建時間倒序獲取補卡申請，employeeID 為 0 或 status 為空時不限制
+func (r *AttendanceRepository) ListCorrections(employeeID uint, status string) ([]models.AttendanceCorrection, error) {
+	query := r.db().Preload("Employee")
+	if employeeID != 0 {
+		query = query.Where("employee_id = ?", employeeID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var corrections []models.AttendanceCorrection
+	if err := query.Order("id DESC").Find(&corrections).Error; err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return corrections, nil
+}
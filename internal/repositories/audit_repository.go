@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type AuditRepository struct {
+	tx *gorm.DB // 非空時所有操作都在該事務中執行
+}
+
+func NewAuditRepository() *AuditRepository {
+	return &AuditRepository{}
+}
+
+// WithTx 返回在指定事務中執行的倉庫
+func (r *AuditRepository) WithTx(tx *gorm.DB) *AuditRepository {
+	return &AuditRepository{tx: tx}
+}
+
+func (r *AuditRepository) db() *gorm.DB {
+	if r.tx != nil {
+		return r.tx
+	}
+	return config.DB
+}
+
+// Create 寫入審計記錄
+func (r *AuditRepository) Create(entry *models.AuditLog) error {
+	return apperrors.FromDB(r.db().Create(entry).Error, nil, nil)
+}
+
+// List 按操作對象篩選審計記錄，最新的在前；entityType 為空或 entityID 為 0 時不篩選
+func (r *AuditRepository) List(entityType string, entityID uint, limit int) ([]models.AuditLog, error) {
+	query := r.db().Order("id DESC").Limit(limit)
+	if entityType != "" {
+		query = query.Where("entity_type = ?", entityType)
+	}
+	if entityID != 0 {
+		query = query.Where("entity_id = ?", entityID)
+	}
+	var entries []models.AuditLog
+	if err := query.Find(&entries).Error; err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return entries, nil
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type BulkLeaveRepository struct {
+	tx *gorm.DB // 非空時所有操作都在該事務中執行
+}
+
+func NewBulkLeaveRepository() *BulkLeaveRepository {
+	return &BulkLeaveRepository{}
+}
+
+// WithTx 返回在指定事務中執行的倉庫
+func (r *BulkLeaveRepository) WithTx(tx *gorm.DB) *BulkLeaveRepository {
+	return &BulkLeaveRepository{tx: tx}
+}
+
+func (r *BulkLeaveRepository) db() *gorm.DB {
+	if r.tx != nil {
+		return r.tx
+	}
+	return config.DB
+}
+
+func errBulkLeaveJobNotFound() *apperrors.Error {
+	return apperrors.NotFound(apperrors.CodeBulkLeaveJobNotFound, "Bulk leave job not found")
+}
+
+// CreateJob 創建批量請假任務，不寫入 Items
+func (r *BulkLeaveRepository) CreateJob(job *models.BulkLeaveJob) error {
+	return apperrors.FromDB(r.db().Omit("Items").Create(job).Error, nil, nil)
+}
+
+// CreateItems 批量寫入任務中每個員工的處理結果
+func (r *BulkLeaveRepository) CreateItems(items []models.BulkLeaveItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	return apperrors.FromDB(r.db().CreateInBatches(items, batchInsertSize).Error, nil, nil)
+}
+
+// GetJob 根據ID獲取批量請假任務，包括每個員工的處理結果
+func (r *BulkLeaveRepository) GetJob(id uint) (*models.BulkLeaveJob, error) {
+	var job models.BulkLeaveJob
+	if err := r.db().Preload("Items", orderByID).First(&job, id).Error; err != nil {
+		return nil, apperrors.FromDB(err, errBulkLeaveJobNotFound(), nil)
+	}
+	return &job, nil
+}
+
+// ListJobs 按創建時間倒序獲取批量請假任務，不包括處理結果
+func (r *BulkLeaveRepository) ListJobs() ([]models.BulkLeaveJob, error) {
+	var jobs []models.BulkLeaveJob
+	if err := r.db().Order("id DESC").Find(&jobs).Error; err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return jobs, nil
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type CalendarFeedRepository struct {
+	tx *gorm.DB // 非空時所有操作都在該事務中執行
+}
+
+func NewCalendarFeedRepository() *CalendarFeedRepository {
+	return &CalendarFeedRepository{}
+}
+
+// WithTx 返回在指定事務中執行的倉庫
+func (r *CalendarFeedRepository) WithTx(tx *gorm.DB) *CalendarFeedRepository {
+	return &CalendarFeedRepository{tx: tx}
+}
+
+func (r *CalendarFeedRepository) db() *gorm.DB {
+	if r.tx != nil {
+		return r.tx
+	}
+	return config.DB
+}
+
+func errCalendarFeedNotFound() *apperrors.Error {
+	return apperrors.NotFound(apperrors.CodeCalendarFeedNotFound, "Calendar feed not found")
+}
+
+// Create 創建行事曆訂閱
+func (r *CalendarFeedRepository) Create(feed *models.CalendarFeed) error {
+	return apperrors.FromDB(r.db().Create(feed).Error, nil, nil)
+}
+
+// GetByTokenHash 根據令牌雜湊獲取行事曆訂閱
+func (r *CalendarFeedRepository) GetByTokenHash(hash string) (*models.CalendarFeed, error) {
+	var feed models.CalendarFeed
+	if err := r.db().Where("token_hash = ?", hash).First(&feed).Error; err != nil {
+		return nil, apperrors.FromDB(err, errCalendarFeedNotFound(), nil)
+	}
+	return &feed, nil
+}
+
+// ListByOwner 獲取員工的行事曆訂閱
+func (r *CalendarFeedRepository) ListByOwner(ownerID uint) ([]models.CalendarFeed, error) {
+	var feeds []models.CalendarFeed
+	if err := r.db().Where("owner_id = ?", ownerID).Order("id").Find(&feeds).Error; err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return feeds, nil
+}
+
+// Touch 記錄訂閱最近一次被讀取的時間
+func (r *CalendarFeedRepository) Touch(id uint, at time.Time) error {
+	err := r.db().Model(&models.CalendarFeed{}).Where("id = ?", id).Update("last_accessed_at", at).Error
+	return apperrors.FromDB(err, nil, nil)
+}
+
+// Delete 撤銷員工的行事曆訂閱
+func (r *CalendarFeedRepository) Delete(ownerID, id uint) error {
+	result := r.db().Where("owner_id = ?", ownerID).Delete(&models.CalendarFeed{}, id)
+	if result.Error != nil {
+		return apperrors.FromDB(result.Error, nil, nil)
+	}
+	if result.RowsAffected == 0 {
+		return errCalendarFeedNotFound()
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type DelegationRepository struct {
+	tx *gorm.DB // 非空時所有操作都在該事務中執行
+}
+
+func NewDelegationRepository() *DelegationRepository {
+	return &DelegationRepository{}
+}
+
+// WithTx 返回在指定事務中執行的倉庫
+func (r *DelegationRepository) WithTx(tx *gorm.DB) *DelegationRepository {
+	return &DelegationRepository{tx: tx}
+}
+
+func (r *DelegationRepository) db() *gorm.DB {
+	if r.tx != nil {
+		return r.tx
+	}
+	return config.DB
+}
+
+func errDelegationNotFound() *apperrors.Error {
+	return apperrors.NotFound(apperrors.CodeDelegationNotFound, "Delegation not found")
+}
+
+// Create 創建審批代理
+func (r *DelegationRepository) Create(delegation *models.ApprovalDelegation) error {
+	return apperrors.FromDB(r.db().Create(delegation).Error, nil, nil)
+}
+
+// ListByManager 獲取主管的所有審批代理，按開始時間排序
+func (r *DelegationRepository) ListByManager(managerID uint) ([]models.ApprovalDelegation, error) {
+	var delegations []models.ApprovalDelegation
+	err := r.db().Where("manager_id = ?", managerID).Order("start_date").Find(&delegations).Error
+	if err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return delegations, nil
+}
+
+// Delete 刪除主管的審批代理
+func (r *DelegationRepository) Delete(managerID, id uint) error {
+	result := r.db().Where("manager_id = ?", managerID).Delete(&models.ApprovalDelegation{}, id)
+	if result.Error != nil {
+		return apperrors.FromDB(result.Error, nil, nil)
+	}
+	if result.RowsAffected == 0 {
+		return errDelegationNotFound()
+	}
+	return nil
+}
+
+// HasOverlap 檢查主管在 [start, end] 內是否已有審批代理
+func (r *DelegationRepository) HasOverlap(managerID uint, start, end time.Time) (bool, error) {
+	var count int64
+	err := r.db().Model(&models.ApprovalDelegation{}).
+		Where("manager_id = ? AND start_date <= ? AND end_date >= ?", managerID, end, start).
+		Count(&count).Error
+	if err != nil {
+		return false, apperrors.FromDB(err, nil, nil)
+	}
+	return count > 0, nil
+}
+
+// GetActive 獲取主管在 at 時生效的審批代理，沒有時返回空
+func (r *DelegationRepository) GetActive(managerID uint, at time.Time) (*models.ApprovalDelegation, error) {
+	var delegation models.ApprovalDelegation
+	err := r.db().Where("manager_id = ? AND start_date <= ? AND end_date >= ?", managerID, at, at).
+		Order("start_date DESC").
+		First(&delegation).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return &delegation, nil
+}
@@ -1,27 +1,54 @@
 package repositories
 
 import (
+	"time"
+
 	"hr-system/config"
+	"hr-system/internal/apperrors"
 	"hr-system/internal/models"
+
+	"gorm.io/gorm"
 )
 
-type EmployeeRepository struct{}
+type EmployeeRepository struct {
+	tx *gorm.DB // 非空時所有操作都在該事務中執行
+}
 
 func NewEmployeeRepository() *EmployeeRepository {
 	return &EmployeeRepository{}
 }
 
+// WithTx 返回在指定事務中執行的倉庫
+func (r *EmployeeRepository) WithTx(tx *gorm.DB) *EmployeeRepository {
+	return &EmployeeRepository{tx: tx}
+}
+
+func (r *EmployeeRepository) db() *gorm.DB {
+	if r.tx != nil {
+		return r.tx
+	}
+	return config.DB
+}
+
+func errEmployeeNotFound() *apperrors.Error {
+	return apperrors.NotFound(apperrors.CodeEmployeeNotFound, "Employee not found")
+}
+
+func errEmailAlreadyExists() *apperrors.Error {
+	return apperrors.Conflict(apperrors.CodeEmailAlreadyExists, "Email already exists")
+}
+
 // Create 創建員工
 func (r *EmployeeRepository) Create(employee *models.Employee) error {
-	return config.DB.Create(employee).Error
+	return apperrors.FromDB(r.db().Create(employee).Error, nil, errEmailAlreadyExists())
 }
 
 // GetByID 根據ID獲取員工
 func (r *EmployeeRepository) GetByID(id uint) (*models.Employee, error) {
 	var employee models.Employee
-	err := config.DB.First(&employee, id).Error
+	err := r.db().First(&employee, id).Error
 	if err != nil {
-		return nil, err
+		return nil, apperrors.FromDB(err, errEmployeeNotFound(), nil)
 	}
 	return &employee, nil
 }
@@ -29,29 +56,76 @@ func (r *EmployeeRepository) GetByID(id uint) (*models.Employee, error) {
 // GetByEmail 根據郵箱獲取員工
 func (r *EmployeeRepository) GetByEmail(email string) (*models.Employee, error) {
 	var employee models.Employee
-	err := config.DB.Where("email = ?", email).First(&employee).Error
+	err := r.db().Where("email = ?", email).First(&employee).Error
 	if err != nil {
-		return nil, err
+		return nil, apperrors.FromDB(err, errEmployeeNotFound(), nil)
 	}
 	return &employee, nil
 }
 
 // Update 更新員工信息
 func (r *EmployeeRepository) Update(employee *models.Employee) error {
-	return config.DB.Save(employee).Error
+	return apperrors.FromDB(r.db().Save(employee).Error, nil, errEmailAlreadyExists())
 }
 
 // Delete 刪除員工
 func (r *EmployeeRepository) Delete(id uint) error {
-	return config.DB.Delete(&models.Employee{}, id).Error
+	result := r.db().Delete(&models.Employee{}, id)
+	if result.Error != nil {
+		return apperrors.FromDB(result.Error, nil, nil)
+	}
+	if result.RowsAffected == 0 {
+		return errEmployeeNotFound()
+	}
+	return nil
 }
 
 // GetAll 獲取所有員工
 func (r *EmployeeRepository) GetAll() ([]models.Employee, error) {
 	var employees []models.Employee
-	err := config.DB.Find(&employees).Error
+	err := r.db().Find(&employees).Error
+	if err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return employees, nil
+}
+
+// ListByIDs 獲取指定ID的員工，不存在的ID會被忽略
+func (r *EmployeeRepository) ListByIDs(ids []uint) ([]models.Employee, error) {
+	var employees []models.Employee
+	if err := r.db().Where("id IN ?", ids).Order("id").Find(&employees).Error; err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return employees, nil
+}
+
+// ListByDepartment 獲取部門的所有員工
+func (r *EmployeeRepository) ListByDepartment(department string) ([]models.Employee, error) {
+	var employees []models.Employee
+	if err := r.db().Where("department = ?", department).Order("id").Find(&employees).Error; err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return employees, nil
+}
+
+// ListReports 獲取直屬主管為 managerIDs 其中之一的員工
+func (r *EmployeeRepository) ListReports(managerIDs []uint) ([]models.Employee, error) {
+	var employees []models.Employee
+	if err := r.db().Where("manager_id IN ?", managerIDs).Order("id").Find(&employees).Error; err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return employees, nil
+}
+
+// GetUpdatedSince 按ID分批獲取 since 之後有更新的員工，afterID 為上一批最後一條的ID
+func (r *EmployeeRepository) GetUpdatedSince(since time.Time, afterID uint, limit int) ([]models.Employee, error) {
+	var employees []models.Employee
+	err := r.db().Where("updated_at >= ? AND id > ?", since, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&employees).Error
 	if err != nil {
-		return nil, err
+		return nil, apperrors.FromDB(err, nil, nil)
 	}
 	return employees, nil
 }
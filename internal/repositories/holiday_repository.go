@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type HolidayRepository struct {
+	tx *gorm.DB // 非空時所有操作都在該事務中執行
+}
+
+func NewHolidayRepository() *HolidayRepository {
+	return &HolidayRepository{}
+}
+
+// WithTx 返回在指定事務中執行的倉庫
+func (r *HolidayRepository) WithTx(tx *gorm.DB) *HolidayRepository {
+	return &HolidayRepository{tx: tx}
+}
+
+func (r *HolidayRepository) db() *gorm.DB {
+	if r.tx != nil {
+		return r.tx
+	}
+	return config.DB
+}
+
+// Create 創建假日，同一日期只能有一個假日
+func (r *HolidayRepository) Create(holiday *models.Holiday) error {
+	return apperrors.FromDB(r.db().Create(holiday).Error, nil,
+		apperrors.Conflict(apperrors.CodeHolidayExists, "A holiday already exists on this date"))
+}
+
+// ListBetween 獲取 [from, to) 內的假日，按日期排序
+func (r *HolidayRepository) ListBetween(from, to time.Time) ([]models.Holiday, error) {
+	var holidays []models.Holiday
+	err := r.db().Where("date >= ? AND date < ?", from, to).Order("date").Find(&holidays).Error
+	if err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return holidays, nil
+}
+
+// Delete 刪除假日
+func (r *HolidayRepository) Delete(id uint) error {
+	result := r.db().Delete(&models.Holiday{}, id)
+	if result.Error != nil {
+		return apperrors.FromDB(result.Error, nil, nil)
+	}
+	if result.RowsAffected == 0 {
+		return apperrors.NotFound(apperrors.CodeHolidayNotFound, "Holiday not found")
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package repositories
+
+import (
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type InsuranceRepository struct {
+	tx *gorm.DB // 非空時所有操作都在該事務中執行
+}
+
+func NewInsuranceRepository() *InsuranceRepository {
+	return &InsuranceRepository{}
+}
+
+// WithTx 返回在指定事務中執行的倉庫
+func (r *InsuranceRepository) WithTx(tx *gorm.DB) *InsuranceRepository {
+	return &InsuranceRepository{tx: tx}
+}
+
+func (r *InsuranceRepository) db() *gorm.DB {
+	if r.tx != nil {
+		return r.tx
+	}
+	return config.DB
+}
+
+func errGradeTableNotFound() *apperrors.Error {
+	return apperrors.NotFound(apperrors.CodeGradeTableNotFound, "Insurance grade table not found")
+}
+
+func errGradeTableExists() *apperrors.Error {
+	return apperrors.Conflict(apperrors.CodeGradeTableExists,
+		"A grade table of this kind with the same version or effective date already exists")
+}
+
+// orderByInsuredSalary 分級表的級距按月投保薪資由低到高排列
+func orderByInsuredSalary(db *gorm.DB) *gorm.DB {
+	return db.Order("insured_salary, id")
+}
+
+// CreateTable 創建分級表及其級距；同一類型已有相同版本或生效日期的分級表時返回衝突錯誤
+func (r *InsuranceRepository) CreateTable(table *models.InsuranceGradeTable) error {
+	return apperrors.FromDB(r.db().Create(table).Error, nil, errGradeTableExists())
+}
+
+// GetTable 根據ID獲取分級表，包括級距
+func (r *InsuranceRepository) GetTable(id uint) (*models.InsuranceGradeTable, error) {
+	var table models.InsuranceGradeTable
+	if err := r.db().Preload("Grades", orderByInsuredSalary).First(&table, id).Error; err != nil {
+		return nil, apperrors.FromDB(err, errGradeTableNotFound(), nil)
+	}
+	return &table, nil
+}
+
+// ListTables 按類型與生效日期倒序獲取分級表，不包括級距；kind 為空時不限制
+func (r *InsuranceRepository) ListTables(kind string) ([]models.InsuranceGradeTable, error) {
+	query := r.db()
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	var tables []models.InsuranceGradeTable
+	if err := query.Order("kind, effective_from DESC").Find(&tables).Error; err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return tables, nil
+}
+
+// EffectiveTables 獲取每類保險在 date 已生效的最新分級表，包括級距
+func (r *InsuranceRepository) EffectiveTables(date time.Time) (map[string]*models.InsuranceGradeTable, error) {
+	var tables []models.InsuranceGradeTable
+	err := r.db().Preload("Grades", orderByInsuredSalary).
+		Where("effective_from <= ?", date).
+		Order("effective_from DESC, id DESC").
+		Find(&tables).Error
+	if err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	effective := make(map[string]*models.InsuranceGradeTable)
+	for i := range tables {
+		if _, ok := effective[tables[i].Kind]; !ok {
+			effective[tables[i].Kind] = &tables[i]
+		}
+	}
+	return effective, nil
+}
+
+// DeleteTable 刪除分級表及其級距，應在事務中調用
+func (r *InsuranceRepository) DeleteTable(id uint) error {
+	if err := r.db().Where("table_id = ?", id).Delete(&models.InsuranceGrade{}).Error; err != nil {
+		return apperrors.FromDB(err, nil, nil)
+	}
+	result := r.db().Delete(&models.InsuranceGradeTable{}, id)
+	if result.Error != nil {
+		return apperrors.FromDB(result.Error, nil, nil)
+	}
+	if result.RowsAffected == 0 {
+		return errGradeTableNotFound()
+	}
+	return nil
+}
+
+// TableInUse 檢查是否有薪資單的保費使用分級表
+func (r *InsuranceRepository) TableInUse(id uint) (bool, error) {
+	var count int64
+	if err := r.db().Model(&models.InsuranceContribution{}).Where("table_id = ?", id).Count(&count).Error; err != nil {
+		return false, apperrors.FromDB(err, nil, nil)
+	}
+	return count > 0, nil
+}
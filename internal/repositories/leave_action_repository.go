@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type LeaveActionRepository struct {
+	tx *gorm.DB // 非空時所有操作都在該事務中執行
+}
+
+func NewLeaveActionRepository() *LeaveActionRepository {
+	return &LeaveActionRepository{}
+}
+
+// WithTx 返回在指定事務中執行的倉庫
+func (r *LeaveActionRepository) WithTx(tx *gorm.DB) *LeaveActionRepository {
+	return &LeaveActionRepository{tx: tx}
+}
+
+func (r *LeaveActionRepository) db() *gorm.DB {
+	if r.tx != nil {
+		return r.tx
+	}
+	return config.DB
+}
+
+// CreateTokens 批量創建操作令牌
+func (r *LeaveActionRepository) CreateTokens(tokens []models.LeaveActionToken) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+	return apperrors.FromDB(r.db().Create(&tokens).Error, nil, nil)
+}
+
+// GetToken 根據ID獲取操作令牌，不存在時的錯誤可用 apperrors.IsNotFound 判斷
+func (r *LeaveActionRepository) GetToken(id string) (*models.LeaveActionToken, error) {
+	var token models.LeaveActionToken
+	if err := r.db().Where("id = ?", id).First(&token).Error; err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return &token, nil
+}
+
+// ConsumeToken 將未使用且未過期的令牌標記為已使用，並發使用同一令牌時只有一個請求成功
+func (r *LeaveActionRepository) ConsumeToken(id string, now time.Time) (bool, error) {
+	result := r.db().Model(&models.LeaveActionToken{}).
+		Where("id = ? AND used_at IS NULL AND expires_at > ?", id, now).
+		Update("used_at", now)
+	if result.Error != nil {
+		return false, apperrors.FromDB(result.Error, nil, nil)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ReleaseToken 撤銷令牌的使用標記，用於審批因臨時錯誤失敗後允許重試
+func (r *LeaveActionRepository) ReleaseToken(id string) error {
+	err := r.db().Model(&models.LeaveActionToken{}).Where("id = ?", id).Update("used_at", nil).Error
+	return apperrors.FromDB(err, nil, nil)
+}
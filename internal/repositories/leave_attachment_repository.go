@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type LeaveAttachmentRepository struct {
+	tx *gorm.DB // 非空時所有操作都在該事務中執行
+}
+
+func NewLeaveAttachmentRepository() *LeaveAttachmentRepository {
+	return &LeaveAttachmentRepository{}
+}
+
+// WithTx 返回在指定事務中執行的倉庫
+func (r *LeaveAttachmentRepository) WithTx(tx *gorm.DB) *LeaveAttachmentRepository {
+	return &LeaveAttachmentRepository{tx: tx}
+}
+
+func (r *LeaveAttachmentRepository) db() *gorm.DB {
+	if r.tx != nil {
+		return r.tx
+	}
+	return config.DB
+}
+
+func errAttachmentNotFound() *apperrors.Error {
+	return apperrors.NotFound(apperrors.CodeAttachmentNotFound, "Attachment not found")
+}
+
+// Create 創建附件記錄
+func (r *LeaveAttachmentRepository) Create(attachment *models.LeaveAttachment) error {
+	return apperrors.FromDB(r.db().Create(attachment).Error, nil, nil)
+}
+
+// Get 獲取請假的附件
+func (r *LeaveAttachmentRepository) Get(leaveID, id uint) (*models.LeaveAttachment, error) {
+	var attachment models.LeaveAttachment
+	if err := r.db().Where("leave_id = ?", leaveID).First(&attachment, id).Error; err != nil {
+		return nil, apperrors.FromDB(err, errAttachmentNotFound(), nil)
+	}
+	return &attachment, nil
+}
+
+// ListByLeave 獲取請假的所有附件，按上傳順序排序
+func (r *LeaveAttachmentRepository) ListByLeave(leaveID uint) ([]models.LeaveAttachment, error) {
+	var attachments []models.LeaveAttachment
+	if err := r.db().Where("leave_id = ?", leaveID).Order("id").Find(&attachments).Error; err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return attachments, nil
+}
+
+// CountByLeave 統計請假的附件數
+func (r *LeaveAttachmentRepository) CountByLeave(leaveID uint) (int64, error) {
+	var count int64
+	if err := r.db().Model(&models.LeaveAttachment{}).Where("leave_id = ?", leaveID).Count(&count).Error; err != nil {
+		return 0, apperrors.FromDB(err, nil, nil)
+	}
+	return count, nil
+}
+
+// Delete 刪除請假的附件記錄
+func (r *LeaveAttachmentRepository) Delete(leaveID, id uint) error {
+	result := r.db().Where("leave_id = ?", leaveID).Delete(&models.LeaveAttachment{}, id)
+	if result.Error != nil {
+		return apperrors.FromDB(result.Error, nil, nil)
+	}
+	if result.RowsAffected == 0 {
+		return errAttachmentNotFound()
+	}
+	return nil
+}
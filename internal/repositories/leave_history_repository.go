@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type LeaveHistoryRepository struct {
+	tx *gorm.DB // 非空時所有操作都在該事務中執行
+}
+
+func NewLeaveHistoryRepository() *LeaveHistoryRepository {
+	return &LeaveHistoryRepository{}
+}
+
+// WithTx 返回在指定事務中執行的倉庫
+func (r *LeaveHistoryRepository) WithTx(tx *gorm.DB) *LeaveHistoryRepository {
+	return &LeaveHistoryRepository{tx: tx}
+}
+
+func (r *LeaveHistoryRepository) db() *gorm.DB {
+	if r.tx != nil {
+		return r.tx
+	}
+	return config.DB
+}
+
+// Create 寫入請假歷程
+func (r *LeaveHistoryRepository) Create(entry *models.LeaveHistory) error {
+	return apperrors.FromDB(r.db().Create(entry).Error, nil, nil)
+}
+
+// CreateBatch 批量寫入請假歷程
+func (r *LeaveHistoryRepository) CreateBatch(entries []models.LeaveHistory) error {
+	return apperrors.FromDB(r.db().CreateInBatches(entries, batchInsertSize).Error, nil, nil)
+}
+
+// ListByLeave 按時間順序獲取請假的歷程
+func (r *LeaveHistoryRepository) ListByLeave(leaveID uint) ([]models.LeaveHistory, error) {
+	var entries []models.LeaveHistory
+	if err := r.db().Where("leave_id = ?", leaveID).Order("id").Find(&entries).Error; err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return entries, nil
+}
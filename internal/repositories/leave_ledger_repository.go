@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type LeaveLedgerRepository struct {
+	tx *gorm.DB // 非空時所有操作都在該事務中執行
+}
+
+func NewLeaveLedgerRepository() *LeaveLedgerRepository {
+	return &LeaveLedgerRepository{}
+}
+
+// WithTx 返回在指定事務中執行的倉庫
+func (r *LeaveLedgerRepository) WithTx(tx *gorm.DB) *LeaveLedgerRepository {
+	return &LeaveLedgerRepository{tx: tx}
+}
+
+func (r *LeaveLedgerRepository) db() *gorm.DB {
+	if r.tx != nil {
+		return r.tx
+	}
+	return config.DB
+}
+
+// Create 寫入一條額度流水
+func (r *LeaveLedgerRepository) Create(entry *models.LeaveLedgerEntry) error {
+	return apperrors.FromDB(r.db().Create(entry).Error, nil, nil)
+}
+
+// CreateBatch 批量寫入額度流水
+func (r *LeaveLedgerRepository) CreateBatch(entries []models.LeaveLedgerEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return apperrors.FromDB(r.db().CreateInBatches(entries, batchInsertSize).Error, nil, nil)
+}
+
+// ListByEmployee 按時間順序獲取員工某年度的額度流水
+func (r *LeaveLedgerRepository) ListByEmployee(employeeID uint, year int) ([]models.LeaveLedgerEntry, error) {
+	var entries []models.LeaveLedgerEntry
+	if err := r.db().Where("employee_id = ? AND year = ?", employeeID, year).Order("id").Find(&entries).Error; err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return entries, nil
+}
+
+// LeaveLedgerSum 員工某年度某類請假的流水合計
+type LeaveLedgerSum struct {
+	EmployeeID uint
+	LeaveType  string
+	Entitled   float64 // 發放、調整與遞延轉入
+	Settled    float64 // 年終結算的遞延、作廢與折發，為負數
+}
+
+// SumByYear 按員工與請假類型合計某年度的流水，employeeID 為 0 時合計所有員工
+func (r *LeaveLedgerRepository) SumByYear(year int, employeeID uint) ([]LeaveLedgerSum, error) {
+	settled := []string{models.LeaveLedgerCarriedOver, models.LeaveLedgerExpired, models.LeaveLedgerPaidOut}
+	query := r.db().Model(&models.LeaveLedgerEntry{}).
+		Select("employee_id, leave_type, "+
+			"COALESCE(SUM(CASE WHEN kind NOT IN ? THEN days ELSE 0 END), 0) AS entitled, "+
+			"COALESCE(SUM(CASE WHEN kind IN ? THEN days ELSE 0 END), 0) AS settled", settled, settled).
+		Where("year = ?", year)
+	if employeeID != 0 {
+		query = query.Where("employee_id = ?", employeeID)
+	}
+	var sums []LeaveLedgerSum
+	if err := query.Group("employee_id, leave_type").Order("employee_id, leave_type").Scan(&sums).Error; err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return sums, nil
+}
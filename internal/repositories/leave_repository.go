@@ -1,27 +1,60 @@
 package repositories
 
 import (
+	"time"
+
 	"hr-system/config"
+	"hr-system/internal/apperrors"
 	"hr-system/internal/models"
+
+	"gorm.io/gorm"
 )
 
-type LeaveRepository struct{}
+type LeaveRepository struct {
+	tx *gorm.DB // 非空時所有操作都在該事務中執行
+}
 
 func NewLeaveRepository() *LeaveRepository {
 	return &LeaveRepository{}
 }
 
+// WithTx 返回在指定事務中執行的倉庫
+func (r *LeaveRepository) WithTx(tx *gorm.DB) *LeaveRepository {
+	return &LeaveRepository{tx: tx}
+}
+
+func (r *LeaveRepository) db() *gorm.DB {
+	if r.tx != nil {
+		return r.tx
+	}
+	return config.DB
+}
+
+// orderByID 按ID排序預加載的關聯記錄
+func orderByID(db *gorm.DB) *gorm.DB {
+	return db.Order("id")
+}
+
+func errLeaveNotFound() *apperrors.Error {
+	return apperrors.NotFound(apperrors.CodeLeaveNotFound, "Leave record not found")
+}
+
 // Create 創建請假記錄
 func (r *LeaveRepository) Create(leave *models.Leave) error {
-	return config.DB.Create(leave).Error
+	return apperrors.FromDB(r.db().Create(leave).Error, nil, nil)
 }
 
-// GetByID 根據ID獲取請假記錄
+// CreateBatch 批量創建請假記錄，創建後 leaves 中的每筆記錄都會帶上ID
+func (r *LeaveRepository) CreateBatch(leaves []models.Leave) error {
+	return apperrors.FromDB(r.db().Omit("Employee").CreateInBatches(leaves, batchInsertSize).Error, nil, nil)
+}
+
+// GetByID 根據ID獲取請假記錄，包括附件
 func (r *LeaveRepository) GetByID(id uint) (*models.Leave, error) {
 	var leave models.Leave
-	err := config.DB.Preload("Employee").First(&leave, id).Error
+	err := r.db().Preload("Employee").Preload("Attachments", orderByID).First(&leave, id).Error
 	if err != nil {
-		return nil, err
+		return nil, apperrors.FromDB(err, errLeaveNotFound(), nil)
 	}
 	return &leave, nil
 }
@@ -29,31 +62,145 @@ func (r *LeaveRepository) GetByID(id uint) (*models.Leave, error) {
 // GetByEmployeeID 獲取員工的請假記錄
 func (r *LeaveRepository) GetByEmployeeID(employeeID uint) ([]models.Leave, error) {
 	var leaves []models.Leave
-	err := config.DB.Where("employee_id = ?", employeeID).Find(&leaves).Error
+	err := r.db().Where("employee_id = ?", employeeID).Find(&leaves).Error
 	if err != nil {
-		return nil, err
+		return nil, apperrors.FromDB(err, nil, nil)
 	}
 	return leaves, nil
 }
 
 // Update 更新請假記錄
 func (r *LeaveRepository) Update(leave *models.Leave) error {
-	return config.DB.Save(leave).Error
+	return apperrors.FromDB(r.db().Save(leave).Error, nil, nil)
+}
+
+// Decide 按 leave 的審批結果更新仍待審批的請假，請假已被處理時返回 false
+func (r *LeaveRepository) Decide(leave *models.Leave) (bool, error) {
+	result := r.db().Model(&models.Leave{}).
+		Where("id = ? AND status = ?", leave.ID, models.LeaveStatusPending).
+		Updates(map[string]interface{}{
+			"status":          leave.Status,
+			"approver_id":     leave.ApproverID,
+			"on_behalf_of_id": leave.OnBehalfOfID,
+			"approve_time":    leave.ApproveTime,
+			"approve_remark":  leave.ApproveRemark,
+		})
+	if result.Error != nil {
+		return false, apperrors.FromDB(result.Error, nil, nil)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Cancel 取消待審批或已核准的請假，請假已是其他狀態時返回 false
+func (r *LeaveRepository) Cancel(id uint) (bool, error) {
+	result := r.db().Model(&models.Leave{}).
+		Where("id = ? AND status IN ?", id, []string{models.LeaveStatusPending, models.LeaveStatusApproved}).
+		Update("status", models.LeaveStatusCancelled)
+	if result.Error != nil {
+		return false, apperrors.FromDB(result.Error, nil, nil)
+	}
+	return result.RowsAffected > 0, nil
 }
 
 // Delete 刪除請假記錄
 func (r *LeaveRepository) Delete(id uint) error {
-	return config.DB.Delete(&models.Leave{}, id).Error
+	result := r.db().Delete(&models.Leave{}, id)
+	if result.Error != nil {
+		return apperrors.FromDB(result.Error, nil, nil)
+	}
+	if result.RowsAffected == 0 {
+		return errLeaveNotFound()
+	}
+	return nil
 }
 
-// GetPendingLeaves 獲取待審批的請假記錄
+// GetPendingLeaves 獲取待審批的請假記錄，包括附件
 func (r *LeaveRepository) GetPendingLeaves() ([]models.Leave, error) {
 	var leaves []models.Leave
-	err := config.DB.Where("status = ?", "pending").
+	err := r.db().Where("status = ?", models.LeaveStatusPending).
+		Preload("Employee").
+		Preload("Attachments", orderByID).
+		Find(&leaves).Error
+	if err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return leaves, nil
+}
+
+// MarkReminded 記錄已提醒審批人，請假已不是待審批或已提醒過時返回 false
+func (r *LeaveRepository) MarkReminded(id uint, at time.Time) (bool, error) {
+	result := r.db().Model(&models.Leave{}).
+		Where("id = ? AND status = ? AND reminded_at IS NULL", id, models.LeaveStatusPending).
+		Update("reminded_at", at)
+	if result.Error != nil {
+		return false, apperrors.FromDB(result.Error, nil, nil)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// MarkEscalated 記錄已升級給 approverID，請假已不是待審批或已升級過時返回 false
+func (r *LeaveRepository) MarkEscalated(id, approverID uint, at time.Time) (bool, error) {
+	result := r.db().Model(&models.Leave{}).
+		Where("id = ? AND status = ? AND escalated_at IS NULL", id, models.LeaveStatusPending).
+		Updates(map[string]interface{}{"escalated_at": at, "escalated_to_id": approverID})
+	if result.Error != nil {
+		return false, apperrors.FromDB(result.Error, nil, nil)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// AutoApprove 由系統核准仍待審批的請假，請假已被處理時返回 false
+func (r *LeaveRepository) AutoApprove(id uint, remark string, at time.Time) (bool, error) {
+	result := r.db().Model(&models.Leave{}).
+		Where("id = ? AND status = ?", id, models.LeaveStatusPending).
+		Updates(map[string]interface{}{
+			"status":          models.LeaveStatusApproved,
+			"approver_id":     nil,
+			"on_behalf_of_id": nil,
+			"approve_time":    at,
+			"approve_remark":  remark,
+		})
+	if result.Error != nil {
+		return false, apperrors.FromDB(result.Error, nil, nil)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// HasApprovedLeaveBetween 檢查員工是否有與 [from, to) 重疊的已核准請假
+func (r *LeaveRepository) HasApprovedLeaveBetween(employeeID uint, from, to time.Time) (bool, error) {
+	var count int64
+	err := r.db().Model(&models.Leave{}).
+		Where("employee_id = ? AND status = ?", employeeID, models.LeaveStatusApproved).
+		Where("start_date < ? AND end_date >= ?", to, from).
+		Count(&count).Error
+	if err != nil {
+		return false, apperrors.FromDB(err, nil, nil)
+	}
+	return count > 0, nil
+}
+
+// ListOverlapping 獲取 employeeIDs 中員工與 [from, to) 重疊、狀態為 statuses 之一的請假，按開始日期排序
+func (r *LeaveRepository) ListOverlapping(employeeIDs []uint, statuses []string, from, to time.Time) ([]models.Leave, error) {
+	var leaves []models.Leave
+	err := r.db().Where("employee_id IN ? AND status IN ? AND start_date < ? AND end_date >= ?", employeeIDs, statuses, to, from).
 		Preload("Employee").
+		Order("start_date, id").
 		Find(&leaves).Error
 	if err != nil {
-		return nil, err
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return leaves, nil
+}
+
+// ListApprovedByTypes 獲取類型為 leaveTypes 之一、與 [from, to) 重疊的已核准請假
+func (r *LeaveRepository) ListApprovedByTypes(leaveTypes []string, from, to time.Time) ([]models.Leave, error) {
+	var leaves []models.Leave
+	err := r.db().Where("leave_type IN ? AND status = ? AND start_date < ? AND end_date >= ?",
+		leaveTypes, models.LeaveStatusApproved, to, from).
+		Order("id").
+		Find(&leaves).Error
+	if err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
 	}
 	return leaves, nil
 }
@@ -61,9 +208,27 @@ func (r *LeaveRepository) GetPendingLeaves() ([]models.Leave, error) {
 // GetAll 獲取所有請假記錄
 func (r *LeaveRepository) GetAll() ([]models.Leave, error) {
 	var leaves []models.Leave
-	err := config.DB.Preload("Employee").Find(&leaves).Error
+	err := r.db().Preload("Employee").Preload("Attachments", orderByID).Find(&leaves).Error
+	if err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return leaves, nil
+}
+
+// GetUpdatedSince 按ID分批獲取 since 之後有更新的請假記錄，afterID 為上一批最後一條的ID
+// 請假記錄內嵌員工數據，所屬員工有更新的記錄也一併返回
+func (r *LeaveRepository) GetUpdatedSince(since time.Time, afterID uint, limit int) ([]models.Leave, error) {
+	var leaves []models.Leave
+	err := r.db().Where("id > ?", afterID).
+		Where(r.db().Where("updated_at >= ?", since).
+			Or("employee_id IN (?)", r.db().Model(&models.Employee{}).Select("id").Where("updated_at >= ?", since))).
+		Preload("Employee").
+		Preload("Attachments", orderByID).
+		Order("id").
+		Limit(limit).
+		Find(&leaves).Error
 	if err != nil {
-		return nil, err
+		return nil, apperrors.FromDB(err, nil, nil)
 	}
 	return leaves, nil
 }
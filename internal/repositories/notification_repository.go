@@ -0,0 +1,202 @@
+package repositories
+
+import (
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationRepository struct {
+	tx *gorm.DB // 非空時所有操作都在該事務中執行
+}
+
+func NewNotificationRepository() *NotificationRepository {
+	return &NotificationRepository{}
+}
+
+// WithTx 返回在指定事務中執行的倉庫
+func (r *NotificationRepository) WithTx(tx *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{tx: tx}
+}
+
+func (r *NotificationRepository) db() *gorm.DB {
+	if r.tx != nil {
+		return r.tx
+	}
+	return config.DB
+}
+
+// GetPreferences 獲取員工已保存的通知偏好
+func (r *NotificationRepository) GetPreferences(employeeID uint) ([]models.NotificationPreference, error) {
+	var prefs []models.NotificationPreference
+	if err := r.db().Where("employee_id = ?", employeeID).Find(&prefs).Error; err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return prefs, nil
+}
+
+// UpsertPreferences 保存通知偏好，同一員工同一事件類型已有記錄時覆蓋
+func (r *NotificationRepository) UpsertPreferences(prefs []models.NotificationPreference) error {
+	if len(prefs) == 0 {
+		return nil
+	}
+	err := r.db().Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "employee_id"}, {Name: "event_type"}},
+		DoUpdates: clause.AssignmentColumns([]string{"email", "updated_at"}),
+	}).Create(&prefs).Error
+	return apperrors.FromDB(err, nil, nil)
+}
+
+// CreateLogs 批量創建通知記錄，已存在的（同一事件、收件人、渠道）記錄會被忽略
+func (r *NotificationRepository) CreateLogs(logs []models.NotificationLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	err := r.db().Clauses(clause.OnConflict{DoNothing: true}).Create(&logs).Error
+	return apperrors.FromDB(err, nil, nil)
+}
+
+// ListLogs 按收件人與狀態篩選通知記錄，最新的在前；recipientID 或 status 為零值時不篩選
+func (r *NotificationRepository) ListLogs(recipientID uint, status string, limit int) ([]models.NotificationLog, error) {
+	query := r.db().Order("id DESC").Limit(limit)
+	if recipientID != 0 {
+		query = query.Where("recipient_id = ?", recipientID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var logs []models.NotificationLog
+	if err := query.Find(&logs).Error; err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return logs, nil
+}
+
+// GetDueLogs 獲取到期待發送的通知
+func (r *NotificationRepository) GetDueLogs(now time.Time, limit int) ([]models.NotificationLog, error) {
+	var logs []models.NotificationLog
+	err := r.db().
+		Where("status = ?", models.NotificationStatusPending).
+		Where("next_attempt_at <= ?", now).
+		Order("next_attempt_at, id").
+		Limit(limit).
+		Find(&logs).Error
+	if err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return logs, nil
+}
+
+// ClaimLog 佔用一條到期的通知，將下一次發送時間推遲到 leaseUntil，多副本時只有一個副本能佔用成功
+func (r *NotificationRepository) ClaimLog(id uint, now, leaseUntil time.Time) (bool, error) {
+	result := r.db().Model(&models.NotificationLog{}).
+		Where("id = ?", id).
+		Where("status = ?", models.NotificationStatusPending).
+		Where("next_attempt_at <= ?", now).
+		Update("next_attempt_at", leaseUntil)
+	if result.Error != nil {
+		return false, apperrors.FromDB(result.Error, nil, nil)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// UpdateLog 更新通知記錄
+func (r *NotificationRepository) UpdateLog(log *models.NotificationLog) error {
+	return apperrors.FromDB(r.db().Save(log).Error, nil, nil)
+}
+
+func errNotificationNotFound() *apperrors.Error {
+	return apperrors.NotFound(apperrors.CodeNotificationNotFound, "Notification not found")
+}
+
+// CreateNotification 創建站內通知，同一事件對同一收件人已有通知時忽略並返回 false
+func (r *NotificationRepository) CreateNotification(n *models.Notification) (bool, error) {
+	result := r.db().Clauses(clause.OnConflict{DoNothing: true}).Create(n)
+	if result.Error != nil {
+		return false, apperrors.FromDB(result.Error, nil, nil)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// ListNotifications 分頁獲取收件人的站內通知，最新的在前，並返回符合條件的總數
+func (r *NotificationRepository) ListNotifications(recipientID uint, unreadOnly bool, offset, limit int) ([]models.Notification, int64, error) {
+	filter := func(db *gorm.DB) *gorm.DB {
+		db = db.Where("recipient_id = ?", recipientID)
+		if unreadOnly {
+			db = db.Where("read_at IS NULL")
+		}
+		return db
+	}
+
+	var total int64
+	if err := r.db().Model(&models.Notification{}).Scopes(filter).Count(&total).Error; err != nil {
+		return nil, 0, apperrors.FromDB(err, nil, nil)
+	}
+	var notifications []models.Notification
+	if err := r.db().Scopes(filter).Order("id DESC").Offset(offset).Limit(limit).Find(&notifications).Error; err != nil {
+		return nil, 0, apperrors.FromDB(err, nil, nil)
+	}
+	return notifications, total, nil
+}
+
+// ListNotificationsAfter 獲取收件人ID大於 afterID 的站內通知，按ID升序，用於推送流斷線重連後補發
+func (r *NotificationRepository) ListNotificationsAfter(recipientID, afterID uint, limit int) ([]models.Notification, error) {
+	var notifications []models.Notification
+	err := r.db().
+		Where("recipient_id = ? AND id > ?", recipientID, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&notifications).Error
+	if err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return notifications, nil
+}
+
+// CountUnread 統計收件人的未讀通知數
+func (r *NotificationRepository) CountUnread(recipientID uint) (int64, error) {
+	var count int64
+	err := r.db().Model(&models.Notification{}).
+		Where("recipient_id = ? AND read_at IS NULL", recipientID).
+		Count(&count).Error
+	if err != nil {
+		return 0, apperrors.FromDB(err, nil, nil)
+	}
+	return count, nil
+}
+
+// MarkNotificationRead 將收件人的一條通知標記為已讀，已讀的通知保持原已讀時間
+func (r *NotificationRepository) MarkNotificationRead(recipientID, id uint, now time.Time) error {
+	result := r.db().Model(&models.Notification{}).
+		Where("id = ? AND recipient_id = ? AND read_at IS NULL", id, recipientID).
+		Update("read_at", now)
+	if result.Error != nil {
+		return apperrors.FromDB(result.Error, nil, nil)
+	}
+	if result.RowsAffected == 1 {
+		return nil
+	}
+
+	// 沒有更新時區分已讀與不存在（包括屬於其他收件人的通知）
+	var n models.Notification
+	if err := r.db().Where("id = ? AND recipient_id = ?", id, recipientID).First(&n).Error; err != nil {
+		return apperrors.FromDB(err, errNotificationNotFound(), nil)
+	}
+	return nil
+}
+
+// MarkAllNotificationsRead 將收件人的所有未讀通知標記為已讀，返回標記的數量
+func (r *NotificationRepository) MarkAllNotificationsRead(recipientID uint, now time.Time) (int64, error) {
+	result := r.db().Model(&models.Notification{}).
+		Where("recipient_id = ? AND read_at IS NULL", recipientID).
+		Update("read_at", now)
+	if result.Error != nil {
+		return 0, apperrors.FromDB(result.Error, nil, nil)
+	}
+	return result.RowsAffected, nil
+}
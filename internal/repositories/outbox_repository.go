@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type OutboxRepository struct {
+	tx *gorm.DB // 非空時所有操作都在該事務中執行
+}
+
+func NewOutboxRepository() *OutboxRepository {
+	return &OutboxRepository{}
+}
+
+// WithTx 返回在指定事務中執行的倉庫
+func (r *OutboxRepository) WithTx(tx *gorm.DB) *OutboxRepository {
+	return &OutboxRepository{tx: tx}
+}
+
+func (r *OutboxRepository) db() *gorm.DB {
+	if r.tx != nil {
+		return r.tx
+	}
+	return config.DB
+}
+
+// Append 寫入一條待發佈的事件，應與業務數據在同一事務中調用
+func (r *OutboxRepository) Append(msg *models.OutboxMessage) error {
+	return apperrors.FromDB(r.db().Create(msg).Error, nil, nil)
+}
+
+// GetHeads 獲取每個實體最早的一條未發佈事件（且已到重試時間），按ID排序
+// 同一實體的後續事件要等前一條發佈成功後才會返回，以保證實體內的順序
+func (r *OutboxRepository) GetHeads(now time.Time, limit int) ([]models.OutboxMessage, error) {
+	heads := r.db().Model(&models.OutboxMessage{}).
+		Select("MIN(id)").
+		Where("published_at IS NULL").
+		Group("aggregate_type, aggregate_id")
+
+	var messages []models.OutboxMessage
+	err := r.db().
+		Where("id IN (?)", heads).
+		Where("next_attempt_at <= ?", now).
+		Order("id").
+		Limit(limit).
+		Find(&messages).Error
+	if err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return messages, nil
+}
+
+// MarkPublished 標記事件已發佈
+func (r *OutboxRepository) MarkPublished(id uint, at time.Time) error {
+	err := r.db().Model(&models.OutboxMessage{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"published_at": at, "last_error": ""}).Error
+	return apperrors.FromDB(err, nil, nil)
+}
+
+// MarkFailed 記錄發佈失敗，並設置下一次嘗試的時間
+func (r *OutboxRepository) MarkFailed(id uint, attempts int, nextAttemptAt time.Time, lastError string) error {
+	err := r.db().Model(&models.OutboxMessage{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":        attempts,
+			"next_attempt_at": nextAttemptAt,
+			"last_error":      lastError,
+		}).Error
+	return apperrors.FromDB(err, nil, nil)
+}
+
+// DeletePublishedBefore 刪除指定時間之前已發佈的事件，每次最多刪除 limit 條，返回刪除的條數
+func (r *OutboxRepository) DeletePublishedBefore(before time.Time, limit int) (int64, error) {
+	result := r.db().
+		Where("published_at IS NOT NULL AND published_at < ?", before).
+		Limit(limit).
+		Delete(&models.OutboxMessage{})
+	if result.Error != nil {
+		return 0, apperrors.FromDB(result.Error, nil, nil)
+	}
+	return result.RowsAffected, nil
+}
+
+// OutboxStats 未發佈事件的統計
+type OutboxStats struct {
+	Pending       int64      `json:"pending"`                  // 未發佈的事件數
+	Failing       int64      `json:"failing"`                  // 至少失敗過一次的未發佈事件數
+	OldestPending *time.Time `json:"oldest_pending,omitempty"` // 最早的未發佈事件的創建時間
+}
+
+// Stats 統計未發佈的事件
+func (r *OutboxRepository) Stats() (*OutboxStats, error) {
+	var row struct {
+		Pending int64
+		Failing int64
+		Oldest  *time.Time
+	}
+	err := r.db().Model(&models.OutboxMessage{}).
+		Select("COUNT(*) AS pending, COALESCE(SUM(attempts > 0), 0) AS failing, MIN(created_at) AS oldest").
+		Where("published_at IS NULL").
+		Scan(&row).Error
+	if err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return &OutboxStats{Pending: row.Pending, Failing: row.Failing, OldestPending: row.Oldest}, nil
+}
@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type OvertimeRepository struct {
+	tx *gorm.DB // 非空時所有操作都在該事務中執行
+}
+
+func NewOvertimeRepository() *OvertimeRepository {
+	return &OvertimeRepository{}
+}
+
+// WithTx 返回在指定事務中執行的倉庫
+func (r *OvertimeRepository) WithTx(tx *gorm.DB) *OvertimeRepository {
+	return &OvertimeRepository{tx: tx}
+}
+
+func (r *OvertimeRepository) db() *gorm.DB {
+	if r.tx != nil {
+		return r.tx
+	}
+	return config.DB
+}
+
+func errOvertimeNotFound() *apperrors.Error {
+	return apperrors.NotFound(apperrors.CodeOvertimeNotFound, "Overtime request not found")
+}
+
+// OvertimeFilter 加班申請的查詢條件，零值的條件不限制
+type OvertimeFilter struct {
+	EmployeeID uint
+	Status     string
+	From       time.Time // 加班日期不早於 From
+	To         time.Time // 加班日期早於 To
+}
+
+// Create 創建加班申請
+func (r *OvertimeRepository) Create(overtime *models.OvertimeRequest) error {
+	return apperrors.FromDB(r.db().Omit("Employee").Create(overtime).Error, nil, nil)
+}
+
+// GetByID 根據ID獲取加班申請，包括員工
+func (r *OvertimeRepository) GetByID(id uint) (*models.OvertimeRequest, error) {
+	var overtime models.OvertimeRequest
+	if err := r.db().Preload("Employee").First(&overtime, id).Error; err != nil {
+		return nil, apperrors.FromDB(err, errOvertimeNotFound(), nil)
+	}
+	return &overtime, nil
+}
+
+// Decide 按 overtime 的審批結果更新仍待審批的加班申請，申請已被處理時返回 false
+func (r *OvertimeRepository) Decide(overtime *models.OvertimeRequest) (bool, error) {
+	result := r.db().Model(&models.OvertimeRequest{}).
+		Where("id = ? AND status = ?", overtime.ID, models.LeaveStatusPending).
+		Updates(map[string]interface{}{
+			"status":          overtime.Status,
+			"approver_id":     overtime.ApproverID,
+			"on_behalf_of_id": overtime.OnBehalfOfID,
+			"approve_time":    overtime.ApproveTime,
+			"approve_remark":  overtime.ApproveRemark,
+			"comp_days":       overtime.CompDays,
+			"comp_expires_at": overtime.CompExpiresAt,
+		})
+	if result.Error != nil {
+		return false, apperrors.FromDB(result.Error, nil, nil)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Cancel 取消待審批的加班申請，申請已被處理時返回 false
+func (r *OvertimeRepository) Cancel(id uint) (bool, error) {
+	result := r.db().Model(&models.OvertimeRequest{}).
+		Where("id = ? AND status = ?", id, models.LeaveStatusPending).
+		Update("status", models.LeaveStatusCancelled)
+	if result.Error != nil {
+		return false, apperrors.FromDB(result.Error, nil, nil)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ExistsOnDate 檢查員工在某天是否已有待審批或已核准的加班申請
+func (r *OvertimeRepository) ExistsOnDate(employeeID uint, date time.Time) (bool, error) {
+	var count int64
+	err := r.db().Model(&models.OvertimeRequest{}).
+		Where("employee_id = ? AND date = ? AND status IN ?", employeeID, date,
+			[]string{models.LeaveStatusPending, models.LeaveStatusApproved}).
+		Count(&count).Error
+	if err != nil {
+		return false, apperrors.FromDB(err, nil, nil)
+	}
+	return count > 0, nil
+}
+
+// List 按加班日期倒序獲取符合條件的加班申請
+func (r *OvertimeRepository) List(filter OvertimeFilter) ([]models.OvertimeRequest, error) {
+	query := r.db().Preload("Employee")
+	if filter.EmployeeID != 0 {
+		query = query.Where("employee_id = ?", filter.EmployeeID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("date >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("date < ?", filter.To)
+	}
+	var overtimes []models.OvertimeRequest
+	if err := query.Order("date DESC, id DESC").Find(&overtimes).Error; err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return overtimes, nil
+}
@@ -0,0 +1,244 @@
+package repositories
+
+import (
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type PayrollRepository struct {
+	tx *gorm.DB // 非空時所有操作都在該事務中執行
+}
+
+func NewPayrollRepository() *PayrollRepository {
+	return &PayrollRepository{}
+}
+
+// WithTx 返回在指定事務中執行的倉庫
+func (r *PayrollRepository) WithTx(tx *gorm.DB) *PayrollRepository {
+	return &PayrollRepository{tx: tx}
+}
+
+func (r *PayrollRepository) db() *gorm.DB {
+	if r.tx != nil {
+		return r.tx
+	}
+	return config.DB
+}
+
+func errPayComponentNotFound() *apperrors.Error {
+	return apperrors.NotFound(apperrors.CodePayComponentNotFound, "Pay component not found")
+}
+
+func errPayrollRunNotFound() *apperrors.Error {
+	return apperrors.NotFound(apperrors.CodePayrollRunNotFound, "Payroll run not found")
+}
+
+func errPayrollRunExists() *apperrors.Error {
+	return apperrors.Conflict(apperrors.CodePayrollRunExists, "A payroll run for this month already exists")
+}
+
+func errPayslipNotFound() *apperrors.Error {
+	return apperrors.NotFound(apperrors.CodePayslipNotFound, "Payslip not found")
+}
+
+// CreateComponent 創建薪資項目
+func (r *PayrollRepository) CreateComponent(component *models.PayComponent) error {
+	return apperrors.FromDB(r.db().Create(component).Error, nil, nil)
+}
+
+// GetComponent 根據ID獲取薪資項目
+func (r *PayrollRepository) GetComponent(id uint) (*models.PayComponent, error) {
+	var component models.PayComponent
+	if err := r.db().First(&component, id).Error; err != nil {
+		return nil, apperrors.FromDB(err, errPayComponentNotFound(), nil)
+	}
+	return &component, nil
+}
+
+// ListComponents 獲取所有薪資項目，activeOnly 為 true 時只返回啟用的項目
+func (r *PayrollRepository) ListComponents(activeOnly bool) ([]models.PayComponent, error) {
+	query := r.db()
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
+	var components []models.PayComponent
+	if err := query.Order("id").Find(&components).Error; err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return components, nil
+}
+
+// UpdateComponent 更新薪資項目
+func (r *PayrollRepository) UpdateComponent(component *models.PayComponent) error {
+	return apperrors.FromDB(r.db().Save(component).Error, nil, nil)
+}
+
+// DeleteComponent 刪除薪資項目，已計算的薪資明細不受影響
+func (r *PayrollRepository) DeleteComponent(id uint) error {
+	result := r.db().Delete(&models.PayComponent{}, id)
+	if result.Error != nil {
+		return apperrors.FromDB(result.Error, nil, nil)
+	}
+	if result.RowsAffected == 0 {
+		return errPayComponentNotFound()
+	}
+	return nil
+}
+
+// CreateRun 創建薪資批次，不寫入 Payslips；該月份已有批次時返回衝突錯誤
+func (r *PayrollRepository) CreateRun(run *models.PayrollRun) error {
+	return apperrors.FromDB(r.db().Omit("Payslips").Create(run).Error, nil, errPayrollRunExists())
+}
+
+// GetRun 根據ID獲取薪資批次，包括薪資單但不包括明細
+func (r *PayrollRepository) GetRun(id uint) (*models.PayrollRun, error) {
+	var run models.PayrollRun
+	if err := r.db().Preload("Payslips", orderByID).First(&run, id).Error; err != nil {
+		return nil, apperrors.FromDB(err, errPayrollRunNotFound(), nil)
+	}
+	return &run, nil
+}
+
+// ListRuns 按月份倒序獲取所有薪資批次，不包括薪資單
+func (r *PayrollRepository) ListRuns() ([]models.PayrollRun, error) {
+	var runs []models.PayrollRun
+	if err := r.db().Order("month DESC").Find(&runs).Error; err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return runs, nil
+}
+
+// GetRunDetails 根據ID獲取薪資批次，包括薪資單及其明細與保費
+func (r *PayrollRepository) GetRunDetails(id uint) (*models.PayrollRun, error) {
+	var run models.PayrollRun
+	err := r.db().Preload("Payslips", orderByID).
+		Preload("Payslips.Lines", orderByID).
+		Preload("Payslips.Contributions", orderByID).
+		First(&run, id).Error
+	if err != nil {
+		return nil, apperrors.FromDB(err, errPayrollRunNotFound(), nil)
+	}
+	return &run, nil
+}
+
+// ReplacePayslips 以 payslips 及其明細與保費替換薪資批次原有的薪資單，應在事務中調用
+func (r *PayrollRepository) ReplacePayslips(runID uint, payslips []models.Payslip) error {
+	if err := r.db().Where("run_id = ?", runID).Delete(&models.InsuranceContribution{}).Error; err != nil {
+		return apperrors.FromDB(err, nil, nil)
+	}
+	existing := r.db().Model(&models.Payslip{}).Select("id").Where("run_id = ?", runID)
+	if err := r.db().Where("payslip_id IN (?)", existing).Delete(&models.PayrollLine{}).Error; err != nil {
+		return apperrors.FromDB(err, nil, nil)
+	}
+	if err := r.db().Where("run_id = ?", runID).Delete(&models.Payslip{}).Error; err != nil {
+		return apperrors.FromDB(err, nil, nil)
+	}
+	if len(payslips) == 0 {
+		return nil
+	}
+	for i := range payslips {
+		payslips[i].ID = 0
+		payslips[i].RunID = runID
+	}
+	if err := r.db().Omit("Lines", "Contributions").CreateInBatches(payslips, batchInsertSize).Error; err != nil {
+		return apperrors.FromDB(err, nil, nil)
+	}
+	var lines []models.PayrollLine
+	var contributions []models.InsuranceContribution
+	for _, payslip := range payslips {
+		for _, line := range payslip.Lines {
+			line.ID = 0
+			line.PayslipID = payslip.ID
+			lines = append(lines, line)
+		}
+		for _, contribution := range payslip.Contributions {
+			contribution.ID = 0
+			contribution.PayslipID = payslip.ID
+			contribution.RunID = runID
+			contributions = append(contributions, contribution)
+		}
+	}
+	if len(lines) > 0 {
+		if err := r.db().CreateInBatches(lines, batchInsertSize).Error; err != nil {
+			return apperrors.FromDB(err, nil, nil)
+		}
+	}
+	if len(contributions) == 0 {
+		return nil
+	}
+	return apperrors.FromDB(r.db().CreateInBatches(contributions, batchInsertSize).Error, nil, nil)
+}
+
+// UpdateDraftRun 僅在薪資批次仍為草稿時更新計算時間與匯總金額，返回是否更新成功
+func (r *PayrollRepository) UpdateDraftRun(run *models.PayrollRun) (bool, error) {
+	result := r.db().Model(&models.PayrollRun{}).
+		Where("id = ? AND status = ?", run.ID, models.PayrollRunDraft).
+		Updates(map[string]interface{}{
+			"calculated_at":               run.CalculatedAt,
+			"employee_count":              run.EmployeeCount,
+			"gross_total":                 run.GrossTotal,
+			"deduction_total":             run.DeductionTotal,
+			"net_total":                   run.NetTotal,
+			"employer_contribution_total": run.EmployerContributionTotal,
+		})
+	if result.Error != nil {
+		return false, apperrors.FromDB(result.Error, nil, nil)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// LockRun 僅在薪資批次仍為草稿時將其鎖定，返回是否鎖定成功
+func (r *PayrollRepository) LockRun(run *models.PayrollRun) (bool, error) {
+	result := r.db().Model(&models.PayrollRun{}).
+		Where("id = ? AND status = ?", run.ID, models.PayrollRunDraft).
+		Updates(map[string]interface{}{
+			"status":       models.PayrollRunLocked,
+			"locked_at":    run.LockedAt,
+			"locked_by_id": run.LockedByID,
+		})
+	if result.Error != nil {
+		return false, apperrors.FromDB(result.Error, nil, nil)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteDraftRun 僅在薪資批次仍為草稿時刪除批次及其薪資單，返回是否刪除成功，應在事務中調用
+func (r *PayrollRepository) DeleteDraftRun(id uint) (bool, error) {
+	result := r.db().Where("status = ?", models.PayrollRunDraft).Delete(&models.PayrollRun{}, id)
+	if result.Error != nil {
+		return false, apperrors.FromDB(result.Error, nil, nil)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	return true, r.ReplacePayslips(id, nil)
+}
+
+// GetPayslip 獲取員工在薪資批次中的薪資單，包括明細與保費
+func (r *PayrollRepository) GetPayslip(runID, employeeID uint) (*models.Payslip, error) {
+	var payslip models.Payslip
+	err := r.db().Preload("Lines", orderByID).Preload("Contributions", orderByID).
+		Where("run_id = ? AND employee_id = ?", runID, employeeID).
+		First(&payslip).Error
+	if err != nil {
+		return nil, apperrors.FromDB(err, errPayslipNotFound(), nil)
+	}
+	return &payslip, nil
+}
+
+// ListEmployeePayslips 按月份倒序獲取員工在已鎖定薪資批次中的薪資單，包括明細與保費
+func (r *PayrollRepository) ListEmployeePayslips(employeeID uint) ([]models.Payslip, error) {
+	locked := r.db().Model(&models.PayrollRun{}).Select("id").Where("status = ?", models.PayrollRunLocked)
+	var payslips []models.Payslip
+	err := r.db().Preload("Lines", orderByID).Preload("Contributions", orderByID).
+		Where("employee_id = ? AND run_id IN (?)", employeeID, locked).
+		Order("month DESC").
+		Find(&payslips).Error
+	if err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return payslips, nil
+}
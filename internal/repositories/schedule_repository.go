@@ -0,0 +1,248 @@
+package repositories
+
+import (
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ScheduleRepository struct {
+	tx *gorm.DB // 非空時所有操作都在該事務中執行
+}
+
+func NewScheduleRepository() *ScheduleRepository {
+	return &ScheduleRepository{}
+}
+
+// WithTx 返回在指定事務中執行的倉庫
+func (r *ScheduleRepository) WithTx(tx *gorm.DB) *ScheduleRepository {
+	return &ScheduleRepository{tx: tx}
+}
+
+func (r *ScheduleRepository) db() *gorm.DB {
+	if r.tx != nil {
+		return r.tx
+	}
+	return config.DB
+}
+
+func errTemplateNotFound() *apperrors.Error {
+	return apperrors.NotFound(apperrors.CodeScheduleTemplateNotFound, "Schedule template not found")
+}
+
+func errTemplateExists() *apperrors.Error {
+	return apperrors.Conflict(apperrors.CodeScheduleTemplateExists, "A schedule template with this name already exists")
+}
+
+func errRosterNotFound() *apperrors.Error {
+	return apperrors.NotFound(apperrors.CodeRosterNotFound, "Roster assignment not found")
+}
+
+func errShiftSwapNotFound() *apperrors.Error {
+	return apperrors.NotFound(apperrors.CodeShiftSwapNotFound, "Shift swap request not found")
+}
+
+// CreateTemplate 創建班表模板及其班次
+func (r *ScheduleRepository) CreateTemplate(template *models.WorkScheduleTemplate) error {
+	return apperrors.FromDB(r.db().Create(template).Error, nil, errTemplateExists())
+}
+
+// GetTemplate 根據ID獲取班表模板，包括班次
+func (r *ScheduleRepository) GetTemplate(id uint) (*models.WorkScheduleTemplate, error) {
+	var template models.WorkScheduleTemplate
+	if err := r.db().Preload("Shifts", orderByID).First(&template, id).Error; err != nil {
+		return nil, apperrors.FromDB(err, errTemplateNotFound(), nil)
+	}
+	return &template, nil
+}
+
+// ListTemplates 獲取所有班表模板，包括班次
+func (r *ScheduleRepository) ListTemplates() ([]models.WorkScheduleTemplate, error) {
+	var templates []models.WorkScheduleTemplate
+	if err := r.db().Preload("Shifts", orderByID).Order("id").Find(&templates).Error; err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return templates, nil
+}
+
+// UpdateTemplate 更新班表模板並以 template.Shifts 替換原有班次，應在事務中調用
+func (r *ScheduleRepository) UpdateTemplate(template *models.WorkScheduleTemplate) error {
+	if err := r.db().Omit("Shifts").Save(template).Error; err != nil {
+		return apperrors.FromDB(err, nil, errTemplateExists())
+	}
+	if err := r.db().Where("template_id = ?", template.ID).Delete(&models.ScheduleShift{}).Error; err != nil {
+		return apperrors.FromDB(err, nil, nil)
+	}
+	for i := range template.Shifts {
+		template.Shifts[i].ID = 0
+		template.Shifts[i].TemplateID = template.ID
+	}
+	if len(template.Shifts) == 0 {
+		return nil
+	}
+	return apperrors.FromDB(r.db().Create(&template.Shifts).Error, nil, nil)
+}
+
+// DeleteTemplate 刪除班表模板及其班次，名稱可以再次使用，應在事務中調用
+func (r *ScheduleRepository) DeleteTemplate(id uint) error {
+	if err := r.db().Where("template_id = ?", id).Delete(&models.ScheduleShift{}).Error; err != nil {
+		return apperrors.FromDB(err, nil, nil)
+	}
+	result := r.db().Unscoped().Delete(&models.WorkScheduleTemplate{}, id)
+	if result.Error != nil {
+		return apperrors.FromDB(result.Error, nil, nil)
+	}
+	if result.RowsAffected == 0 {
+		return errTemplateNotFound()
+	}
+	return nil
+}
+
+// TemplateInUse 檢查是否有排班使用班表模板
+func (r *ScheduleRepository) TemplateInUse(id uint) (bool, error) {
+	var count int64
+	if err := r.db().Model(&models.RosterAssignment{}).Where("template_id = ?", id).Count(&count).Error; err != nil {
+		return false, apperrors.FromDB(err, nil, nil)
+	}
+	return count > 0, nil
+}
+
+// CreateRoster 創建排班
+func (r *ScheduleRepository) CreateRoster(roster *models.RosterAssignment) error {
+	return apperrors.FromDB(r.db().Omit("Template").Create(roster).Error, nil, nil)
+}
+
+// GetRoster 根據ID獲取排班
+func (r *ScheduleRepository) GetRoster(id uint) (*models.RosterAssignment, error) {
+	var roster models.RosterAssignment
+	if err := r.db().First(&roster, id).Error; err != nil {
+		return nil, apperrors.FromDB(err, errRosterNotFound(), nil)
+	}
+	return &roster, nil
+}
+
+// DeleteRoster 刪除排班
+func (r *ScheduleRepository) DeleteRoster(id uint) error {
+	result := r.db().Delete(&models.RosterAssignment{}, id)
+	if result.Error != nil {
+		return apperrors.FromDB(result.Error, nil, nil)
+	}
+	if result.RowsAffected == 0 {
+		return errRosterNotFound()
+	}
+	return nil
+}
+
+// HasRosterOverlap 檢查員工在 [start, end] 內是否已有排班，end 為空時表示不限
+func (r *ScheduleRepository) HasRosterOverlap(employeeID uint, start time.Time, end *time.Time) (bool, error) {
+	query := r.db().Model(&models.RosterAssignment{}).
+		Where("employee_id = ?", employeeID).
+		Where("end_date IS NULL OR end_date >= ?", start)
+	if end != nil {
+		query = query.Where("start_date <= ?", *end)
+	}
+	var count int64
+	if err := query.Count(&count).Error; err != nil {
+		return false, apperrors.FromDB(err, nil, nil)
+	}
+	return count > 0, nil
+}
+
+// ListRosters 按開始日期獲取員工的排班，包括班表模板
+func (r *ScheduleRepository) ListRosters(employeeID uint) ([]models.RosterAssignment, error) {
+	var rosters []models.RosterAssignment
+	err := r.db().Preload("Template.Shifts", orderByID).
+		Where("employee_id = ?", employeeID).
+		Order("start_date, id").
+		Find(&rosters).Error
+	if err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return rosters, nil
+}
+
+// ListRostersBetween 獲取 employeeIDs 中員工與 [from, to) 重疊的排班，包括班表模板及其班次
+func (r *ScheduleRepository) ListRostersBetween(employeeIDs []uint, from, to time.Time) ([]models.RosterAssignment, error) {
+	var rosters []models.RosterAssignment
+	err := r.db().Preload("Template.Shifts", orderByID).
+		Where("employee_id IN ? AND start_date < ?", employeeIDs, to).
+		Where("end_date IS NULL OR end_date >= ?", from).
+		Order("start_date, id").
+		Find(&rosters).Error
+	if err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return rosters, nil
+}
+
+// CreateOverrides 批量寫入單日班次覆蓋
+func (r *ScheduleRepository) CreateOverrides(overrides []models.ShiftOverride) error {
+	if len(overrides) == 0 {
+		return nil
+	}
+	return apperrors.FromDB(r.db().CreateInBatches(overrides, batchInsertSize).Error, nil, nil)
+}
+
+// ListOverrides 按創建順序獲取 employeeIDs 中員工在 [from, to) 內的單日班次覆蓋
+func (r *ScheduleRepository) ListOverrides(employeeIDs []uint, from, to time.Time) ([]models.ShiftOverride, error) {
+	var overrides []models.ShiftOverride
+	err := r.db().Where("employee_id IN ? AND date >= ? AND date < ?", employeeIDs, from, to).
+		Order("id").
+		Find(&overrides).Error
+	if err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return overrides, nil
+}
+
+// CreateSwap 創建換班申請
+func (r *ScheduleRepository) CreateSwap(swap *models.ShiftSwapRequest) error {
+	return apperrors.FromDB(r.db().Omit("Requester", "Counterpart").Create(swap).Error, nil, nil)
+}
+
+// GetSwap 根據ID獲取換班申請，包括雙方員工
+func (r *ScheduleRepository) GetSwap(id uint) (*models.ShiftSwapRequest, error) {
+	var swap models.ShiftSwapRequest
+	if err := r.db().Preload("Requester").Preload("Counterpart").First(&swap, id).Error; err != nil {
+		return nil, apperrors.FromDB(err, errShiftSwapNotFound(), nil)
+	}
+	return &swap, nil
+}
+
+// UpdateSwapStatus 僅在換班申請仍為 from 狀態時更新狀態與回覆、審批結果，返回是否更新成功
+func (r *ScheduleRepository) UpdateSwapStatus(swap *models.ShiftSwapRequest, from string) (bool, error) {
+	result := r.db().Model(&models.ShiftSwapRequest{}).
+		Where("id = ? AND status = ?", swap.ID, from).
+		Updates(map[string]interface{}{
+			"status":          swap.Status,
+			"respond_time":    swap.RespondTime,
+			"approver_id":     swap.ApproverID,
+			"on_behalf_of_id": swap.OnBehalfOfID,
+			"approve_time":    swap.ApproveTime,
+			"approve_remark":  swap.ApproveRemark,
+		})
+	if result.Error != nil {
+		return false, apperrors.FromDB(result.Error, nil, nil)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ListSwaps 按創建時間倒序獲取換班申請，employeeID 非 0 時只返回其作為申請人或對方的申請，status 為空時不限制
+func (r *ScheduleRepository) ListSwaps(employeeID uint, status string) ([]models.ShiftSwapRequest, error) {
+	query := r.db().Preload("Requester").Preload("Counterpart")
+	if employeeID != 0 {
+		query = query.Where("requester_id = ? OR counterpart_id = ?", employeeID, employeeID)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var swaps []models.ShiftSwapRequest
+	if err := query.Order("id DESC").Find(&swaps).Error; err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return swaps, nil
+}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type StaffingRuleRepository struct {
+	tx *gorm.DB // 非空時所有操作都在該事務中執行
+}
+
+func NewStaffingRuleRepository() *StaffingRuleRepository {
+	return &StaffingRuleRepository{}
+}
+
+// WithTx 返回在指定事務中執行的倉庫
+func (r *StaffingRuleRepository) WithTx(tx *gorm.DB) *StaffingRuleRepository {
+	return &StaffingRuleRepository{tx: tx}
+}
+
+func (r *StaffingRuleRepository) db() *gorm.DB {
+	if r.tx != nil {
+		return r.tx
+	}
+	return config.DB
+}
+
+func errStaffingRuleNotFound() *apperrors.Error {
+	return apperrors.NotFound(apperrors.CodeStaffingRuleNotFound, "Staffing rule not found")
+}
+
+// Create 創建人力規則
+func (r *StaffingRuleRepository) Create(rule *models.StaffingRule) error {
+	return apperrors.FromDB(r.db().Create(rule).Error, nil, nil)
+}
+
+// GetByID 根據ID獲取人力規則
+func (r *StaffingRuleRepository) GetByID(id uint) (*models.StaffingRule, error) {
+	var rule models.StaffingRule
+	if err := r.db().First(&rule, id).Error; err != nil {
+		return nil, apperrors.FromDB(err, errStaffingRuleNotFound(), nil)
+	}
+	return &rule, nil
+}
+
+// List 獲取所有人力規則
+func (r *StaffingRuleRepository) List() ([]models.StaffingRule, error) {
+	var rules []models.StaffingRule
+	if err := r.db().Order("id").Find(&rules).Error; err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return rules, nil
+}
+
+// Update 更新人力規則
+func (r *StaffingRuleRepository) Update(rule *models.StaffingRule) error {
+	return apperrors.FromDB(r.db().Save(rule).Error, nil, nil)
+}
+
+// Delete 刪除人力規則
+func (r *StaffingRuleRepository) Delete(id uint) error {
+	result := r.db().Delete(&models.StaffingRule{}, id)
+	if result.Error != nil {
+		return apperrors.FromDB(result.Error, nil, nil)
+	}
+	if result.RowsAffected == 0 {
+		return errStaffingRuleNotFound()
+	}
+	return nil
+}
+
+// ListApplicable 獲取適用於部門 department 或 managerIDs 中任一主管團隊、
+// 生效期間與 [from, to) 重疊的已啟用規則
+func (r *StaffingRuleRepository) ListApplicable(department string, managerIDs []uint, from, to time.Time) ([]models.StaffingRule, error) {
+	scope := r.db().Where("manager_id IN ?", managerIDs)
+	if department != "" {
+		scope = scope.Or("department = ?", department)
+	}
+	var rules []models.StaffingRule
+	err := r.db().Where("active = ?", true).
+		Where(scope).
+		Where("start_date IS NULL OR start_date < ?", to).
+		Where("end_date IS NULL OR end_date >= ?", from).
+		Order("id").
+		Find(&rules).Error
+	if err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return rules, nil
+}
@@ -0,0 +1,27 @@
+package repositories
+
+import (
+	"errors"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+
+	"gorm.io/gorm"
+)
+
+// Transaction 在數據庫事務中執行 fn，fn 返回錯誤時回滾
+// fn 內應通過各倉庫的 WithTx(tx) 執行操作
+func Transaction(fn func(tx *gorm.DB) error) error {
+	err := config.DB.Transaction(fn)
+	if err == nil {
+		return nil
+	}
+	var appErr *apperrors.Error
+	if errors.As(err, &appErr) {
+		return err
+	}
+	return apperrors.FromDB(err, nil, nil)
+}
+
+// batchInsertSize 批量寫入時每條 INSERT 語句包含的記錄數
+const batchInsertSize = 200
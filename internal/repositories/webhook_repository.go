@@ -0,0 +1,158 @@
+package repositories
+
+import (
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WebhookRepository struct {
+	tx *gorm.DB // 非空時所有操作都在該事務中執行
+}
+
+func NewWebhookRepository() *WebhookRepository {
+	return &WebhookRepository{}
+}
+
+// WithTx 返回在指定事務中執行的倉庫
+func (r *WebhookRepository) WithTx(tx *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{tx: tx}
+}
+
+func (r *WebhookRepository) db() *gorm.DB {
+	if r.tx != nil {
+		return r.tx
+	}
+	return config.DB
+}
+
+func errWebhookNotFound() *apperrors.Error {
+	return apperrors.NotFound(apperrors.CodeWebhookNotFound, "Webhook subscription not found")
+}
+
+func errDeliveryNotFound() *apperrors.Error {
+	return apperrors.NotFound(apperrors.CodeDeliveryNotFound, "Webhook delivery not found")
+}
+
+// CreateSubscription 創建訂閱
+func (r *WebhookRepository) CreateSubscription(sub *models.WebhookSubscription) error {
+	return apperrors.FromDB(r.db().Create(sub).Error, nil, nil)
+}
+
+// GetSubscription 根據ID獲取訂閱
+func (r *WebhookRepository) GetSubscription(id uint) (*models.WebhookSubscription, error) {
+	var sub models.WebhookSubscription
+	if err := r.db().First(&sub, id).Error; err != nil {
+		return nil, apperrors.FromDB(err, errWebhookNotFound(), nil)
+	}
+	return &sub, nil
+}
+
+// ListSubscriptions 獲取所有訂閱
+func (r *WebhookRepository) ListSubscriptions() ([]models.WebhookSubscription, error) {
+	var subs []models.WebhookSubscription
+	if err := r.db().Order("id").Find(&subs).Error; err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return subs, nil
+}
+
+// ListActiveSubscriptions 獲取所有啟用中的訂閱
+func (r *WebhookRepository) ListActiveSubscriptions() ([]models.WebhookSubscription, error) {
+	var subs []models.WebhookSubscription
+	if err := r.db().Where("active = ?", true).Find(&subs).Error; err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return subs, nil
+}
+
+// UpdateSubscription 更新訂閱
+func (r *WebhookRepository) UpdateSubscription(sub *models.WebhookSubscription) error {
+	return apperrors.FromDB(r.db().Save(sub).Error, nil, nil)
+}
+
+// DeleteSubscription 刪除訂閱
+func (r *WebhookRepository) DeleteSubscription(id uint) error {
+	result := r.db().Delete(&models.WebhookSubscription{}, id)
+	if result.Error != nil {
+		return apperrors.FromDB(result.Error, nil, nil)
+	}
+	if result.RowsAffected == 0 {
+		return errWebhookNotFound()
+	}
+	return nil
+}
+
+// CreateDeliveries 批量創建投遞記錄，已存在的（同一訂閱、同一事件）記錄會被忽略
+func (r *WebhookRepository) CreateDeliveries(deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	err := r.db().Omit("Subscription").Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries).Error
+	return apperrors.FromDB(err, nil, nil)
+}
+
+// GetDelivery 根據ID獲取投遞記錄
+func (r *WebhookRepository) GetDelivery(id uint) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	if err := r.db().First(&delivery, id).Error; err != nil {
+		return nil, apperrors.FromDB(err, errDeliveryNotFound(), nil)
+	}
+	return &delivery, nil
+}
+
+// ListDeliveries 按狀態與訂閱篩選投遞記錄，最新的在前；status 或 subscriptionID 為零值時不篩選
+func (r *WebhookRepository) ListDeliveries(status string, subscriptionID uint, limit int) ([]models.WebhookDelivery, error) {
+	query := r.db().Order("id DESC").Limit(limit)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if subscriptionID != 0 {
+		query = query.Where("subscription_id = ?", subscriptionID)
+	}
+	var deliveries []models.WebhookDelivery
+	if err := query.Find(&deliveries).Error; err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return deliveries, nil
+}
+
+// GetDueDeliveries 獲取到期待投遞的記錄，並預加載訂閱（包括已刪除的訂閱）
+func (r *WebhookRepository) GetDueDeliveries(now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	err := r.db().
+		Where("status IN ?", []string{models.DeliveryStatusPending, models.DeliveryStatusRetrying}).
+		Where("next_attempt_at <= ?", now).
+		Preload("Subscription", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Order("next_attempt_at, id").
+		Limit(limit).
+		Find(&deliveries).Error
+	if err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return deliveries, nil
+}
+
+// ClaimDelivery 佔用一條到期的投遞記錄，將下一次投遞時間推遲到 leaseUntil
+// 多副本同時輪詢時只有一個副本能佔用成功，佔用期間副本崩潰的記錄在租約到期後會被重新投遞
+func (r *WebhookRepository) ClaimDelivery(id uint, now, leaseUntil time.Time) (bool, error) {
+	result := r.db().Model(&models.WebhookDelivery{}).
+		Where("id = ?", id).
+		Where("status IN ?", []string{models.DeliveryStatusPending, models.DeliveryStatusRetrying}).
+		Where("next_attempt_at <= ?", now).
+		Update("next_attempt_at", leaseUntil)
+	if result.Error != nil {
+		return false, apperrors.FromDB(result.Error, nil, nil)
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// UpdateDelivery 更新投遞記錄
+func (r *WebhookRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	return apperrors.FromDB(r.db().Omit("Subscription").Save(delivery).Error, nil, nil)
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type YearEndCloseRepository struct {
+	tx *gorm.DB // 非空時所有操作都在該事務中執行
+}
+
+func NewYearEndCloseRepository() *YearEndCloseRepository {
+	return &YearEndCloseRepository{}
+}
+
+// WithTx 返回在指定事務中執行的倉庫
+func (r *YearEndCloseRepository) WithTx(tx *gorm.DB) *YearEndCloseRepository {
+	return &YearEndCloseRepository{tx: tx}
+}
+
+func (r *YearEndCloseRepository) db() *gorm.DB {
+	if r.tx != nil {
+		return r.tx
+	}
+	return config.DB
+}
+
+func errYearEndCloseNotFound() *apperrors.Error {
+	return apperrors.NotFound(apperrors.CodeYearEndCloseNotFound, "Year-end close not found")
+}
+
+func errYearAlreadyClosed() *apperrors.Error {
+	return apperrors.Conflict(apperrors.CodeYearAlreadyClosed, "The year has already been closed")
+}
+
+// Create 創建年終結算，不寫入 Items；該年度已結算時返回衝突錯誤
+func (r *YearEndCloseRepository) Create(record *models.YearEndClose) error {
+	err := r.db().Omit("Items").Create(record).Error
+	return apperrors.FromDB(err, nil, errYearAlreadyClosed())
+}
+
+// CreateItems 批量寫入結算結果
+func (r *YearEndCloseRepository) CreateItems(items []models.YearEndCloseItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	return apperrors.FromDB(r.db().CreateInBatches(items, batchInsertSize).Error, nil, nil)
+}
+
+// GetByYear 獲取某年度的年終結算，包括每個員工的結算結果
+func (r *YearEndCloseRepository) GetByYear(year int) (*models.YearEndClose, error) {
+	var record models.YearEndClose
+	if err := r.db().Preload("Items", orderByID).Where("year = ?", year).First(&record).Error; err != nil {
+		return nil, apperrors.FromDB(err, errYearEndCloseNotFound(), nil)
+	}
+	return &record, nil
+}
+
+// Exists 檢查某年度是否已結算
+func (r *YearEndCloseRepository) Exists(year int) (bool, error) {
+	var count int64
+	if err := r.db().Model(&models.YearEndClose{}).Where("year = ?", year).Count(&count).Error; err != nil {
+		return false, apperrors.FromDB(err, nil, nil)
+	}
+	return count > 0, nil
+}
+
+// List 按年度倒序獲取所有年終結算，不包括結算結果
+func (r *YearEndCloseRepository) List() ([]models.YearEndClose, error) {
+	var closes []models.YearEndClose
+	if err := r.db().Order("year DESC").Find(&closes).Error; err != nil {
+		return nil, apperrors.FromDB(err, nil, nil)
+	}
+	return closes, nil
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"time"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+	"hr-system/internal/repositories"
+)
+
+// 由誰審批的原因
+const (
+	ApproverReasonManager        = "manager"          // 直屬主管
+	ApproverReasonDelegation     = "delegation"       // 主管指定的代理人
+	ApproverReasonManagerOnLeave = "manager_on_leave" // 主管請假中，由上一級主管審批
+	ApproverReasonEscalated      = "escalated"        // 超過審批時限，已升級給上一級主管
+)
+
+// Approver 請假當前的審批人
+type Approver struct {
+	ApproverID         uint   `json:"approver_id"`             // 當前可審批的員工
+	OriginalApproverID uint   `json:"original_approver_id"`    // 原審批人，即申請人的直屬主管
+	Reason             string `json:"reason"`                  // manager/delegation/manager_on_leave/escalated
+	DelegationID       *uint  `json:"delegation_id,omitempty"` // 經代理時的代理設定ID
+}
+
+// Delegated 是否由原審批人以外的人審批
+func (a *Approver) Delegated() bool {
+	return a.ApproverID != a.OriginalApproverID
+}
+
+// ApprovalService 管理審批代理並決定請假由誰審批
+// 主管在期間內指定了代理人時由代理人審批；主管今天有已核准的請假時改由其上一級主管審批，
+// 上一級主管也在請假時繼續往上找，直到沒有上級為止；請假超過審批時限被升級後由升級後的審批人審批
+type ApprovalService struct {
+	delegationRepo *repositories.DelegationRepository
+	employeeRepo   *repositories.EmployeeRepository
+	leaveRepo      *repositories.LeaveRepository
+}
+
+func NewApprovalService(delegationRepo *repositories.DelegationRepository, employeeRepo *repositories.EmployeeRepository, leaveRepo *repositories.LeaveRepository) *ApprovalService {
+	return &ApprovalService{
+		delegationRepo: delegationRepo,
+		employeeRepo:   employeeRepo,
+		leaveRepo:      leaveRepo,
+	}
+}
+
+// CreateDelegation 主管指定期間內的代理人，同一主管的代理期間不能重疊
+func (s *ApprovalService) CreateDelegation(delegation *models.ApprovalDelegation) error {
+	if _, err := s.employeeRepo.GetByID(delegation.ManagerID); err != nil {
+		return err
+	}
+	invalid := apperrors.Validation(apperrors.CodeInvalidDelegate, "Invalid delegate",
+		apperrors.Field("delegate_id", apperrors.CodeInvalidDelegate, "The delegate must be another active employee"))
+	if delegation.DelegateID == delegation.ManagerID {
+		return invalid
+	}
+	delegate, err := s.employeeRepo.GetByID(delegation.DelegateID)
+	if apperrors.IsNotFound(err) {
+		return invalid
+	}
+	if err != nil {
+		return err
+	}
+	if delegate.Status == models.EmployeeStatusInactive {
+		return invalid
+	}
+
+	overlap, err := s.delegationRepo.HasOverlap(delegation.ManagerID, delegation.StartDate, delegation.EndDate)
+	if err != nil {
+		return err
+	}
+	if overlap {
+		return apperrors.Conflict(apperrors.CodeDelegationOverlap, "The manager already has a delegation in this period")
+	}
+	return s.delegationRepo.Create(delegation)
+}
+
+// ListDelegations 獲取主管的審批代理
+func (s *ApprovalService) ListDelegations(managerID uint) ([]models.ApprovalDelegation, error) {
+	if _, err := s.employeeRepo.GetByID(managerID); err != nil {
+		return nil, err
+	}
+	return s.delegationRepo.ListByManager(managerID)
+}
+
+// DeleteDelegation 刪除主管的審批代理
+func (s *ApprovalService) DeleteDelegation(managerID, id uint) error {
+	return s.delegationRepo.Delete(managerID, id)
+}
+
+// ResolveApprover 決定員工的請假在 at 時由誰審批，員工沒有主管時返回錯誤
+func (s *ApprovalService) ResolveApprover(employee *models.Employee, at time.Time) (*Approver, error) {
+	if employee.ManagerID == nil {
+		return nil, apperrors.NotFound(apperrors.CodeNoApprover, "Employee has no approver")
+	}
+	original := *employee.ManagerID
+	dayStart := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, at.Location())
+	dayEnd := dayStart.AddDate(0, 0, 1)
+
+	candidate := original
+	for depth := 0; depth < maxManagerDepth; depth++ {
+		delegation, err := s.delegationRepo.GetActive(candidate, at)
+		if err != nil {
+			return nil, err
+		}
+		// 申請人自己是代理人時不能審批自己的請假，按主管不在處理
+		if delegation != nil && delegation.DelegateID != employee.ID {
+			return &Approver{
+				ApproverID:         delegation.DelegateID,
+				OriginalApproverID: original,
+				Reason:             ApproverReasonDelegation,
+				DelegationID:       &delegation.ID,
+			}, nil
+		}
+
+		onLeave, err := s.leaveRepo.HasApprovedLeaveBetween(candidate, dayStart, dayEnd)
+		if err != nil {
+			return nil, err
+		}
+		if !onLeave && delegation == nil {
+			reason := ApproverReasonManager
+			if candidate != original {
+				reason = ApproverReasonManagerOnLeave
+			}
+			return &Approver{ApproverID: candidate, OriginalApproverID: original, Reason: reason}, nil
+		}
+
+		manager, err := s.employeeRepo.GetByID(candidate)
+		if err != nil {
+			return nil, err
+		}
+		if manager.ManagerID == nil || *manager.ManagerID == employee.ID {
+			break
+		}
+		candidate = *manager.ManagerID
+	}
+	// 往上沒有可審批的人，仍由直屬主管審批
+	return &Approver{ApproverID: original, OriginalApproverID: original, Reason: ApproverReasonManager}, nil
+}
+
+// GetLeaveApprover 獲取請假當前的審批人
+func (s *ApprovalService) GetLeaveApprover(leaveID uint) (*Approver, error) {
+	leave, err := s.leaveRepo.GetByID(leaveID)
+	if err != nil {
+		return nil, err
+	}
+	return s.ResolveLeaveApprover(leave, time.Now())
+}
+
+// ResolveLeaveApprover 決定請假在 at 時由誰審批，已升級的請假由升級後的審批人審批
+// leave.Employee 應已加載
+func (s *ApprovalService) ResolveLeaveApprover(leave *models.Leave, at time.Time) (*Approver, error) {
+	employee := &leave.Employee
+	if leave.EscalatedToID == nil {
+		return s.ResolveApprover(employee, at)
+	}
+	if employee.ManagerID == nil {
+		return nil, apperrors.NotFound(apperrors.CodeNoApprover, "Employee has no approver")
+	}
+	return &Approver{ApproverID: *leave.EscalatedToID, OriginalApproverID: *employee.ManagerID, Reason: ApproverReasonEscalated}, nil
+}
+
+// EscalationTarget 返回 current 的上一級主管，作為請假升級後的審批人
+// current 沒有上級或上級是申請人本人時返回空
+func (s *ApprovalService) EscalationTarget(employee *models.Employee, current uint) (*uint, error) {
+	approver, err := s.employeeRepo.GetByID(current)
+	if err != nil {
+		return nil, err
+	}
+	if approver.ManagerID == nil || *approver.ManagerID == employee.ID {
+		return nil, nil
+	}
+	return approver.ManagerID, nil
+}
+
+// AuthorizeApproval 檢查 approverID 能否審批請假，直屬主管隨時可以審批；
+// 由代理人、上一級主管或升級後的審批人審批時返回被代理的原審批人，否則返回空
+// 員工不能審批自己的申請，員工沒有主管時沒有人可以審批
+func (s *ApprovalService) AuthorizeApproval(leave *models.Leave, approverID uint) (*uint, error) {
+	return s.authorize(&leave.Employee, approverID, func() (*Approver, error) {
+		return s.ResolveLeaveApprover(leave, time.Now())
+	})
+}
+
+// AuthorizeEmployeeApproval 檢查 approverID 能否審批員工的加班等申請，規則與請假相同，但不考慮升級
+func (s *ApprovalService) AuthorizeEmployeeApproval(employee *models.Employee, approverID uint) (*uint, error) {
+	return s.authorize(employee, approverID, func() (*Approver, error) {
+		return s.ResolveApprover(employee, time.Now())
+	})
+}
+
+func (s *ApprovalService) authorize(employee *models.Employee, approverID uint, resolve func() (*Approver, error)) (*uint, error) {
+	if approverID == employee.ID {
+		return nil, apperrors.Forbidden(apperrors.CodeNotApprover, "You cannot approve your own request")
+	}
+	if employee.ManagerID == nil {
+		return nil, apperrors.Forbidden(apperrors.CodeNoApprover, "Employee has no approver")
+	}
+	if *employee.ManagerID == approverID {
+		return nil, nil
+	}
+	approver, err := resolve()
+	if err != nil {
+		return nil, err
+	}
+	if approver.ApproverID != approverID {
+		return nil, apperrors.Forbidden(apperrors.CodeNotApprover, "You are not the current approver of this leave request")
+	}
+	return &approver.OriginalApproverID, nil
+}
+
+// PendingApprovals 獲取 approverID 當前可以審批的待審批請假，包括代理、代上級審批與升級給其審批的
+func (s *ApprovalService) PendingApprovals(approverID uint) ([]models.Leave, error) {
+	leaves, err := s.leaveRepo.GetPendingLeaves()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	result := make([]models.Leave, 0)
+	for _, leave := range leaves {
+		employee := &leave.Employee
+		if employee.ManagerID == nil || employee.ID == approverID {
+			continue
+		}
+		if *employee.ManagerID != approverID {
+			approver, err := s.ResolveLeaveApprover(&leave, now)
+			if err != nil {
+				return nil, err
+			}
+			if approver.ApproverID != approverID {
+				continue
+			}
+		}
+		result = append(result, leave)
+	}
+	return result, nil
+}
@@ -0,0 +1,55 @@
+package services
+
+import (
+	"testing"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+	"hr-system/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorizeEmployeeApproval(t *testing.T) {
+	db := setupTestDB(t)
+	employeeRepo := repositories.NewEmployeeRepository()
+	service := NewApprovalService(repositories.NewDelegationRepository(), employeeRepo, repositories.NewLeaveRepository())
+	director := seedEmployee(t, db, models.Employee{Name: "總監"})
+	manager := seedEmployee(t, db, models.Employee{Name: "主管", ManagerID: &director.ID})
+	employee := seedEmployee(t, db, models.Employee{Name: "員工", ManagerID: &manager.ID})
+	delegate := seedEmployee(t, db, models.Employee{Name: "代理人", ManagerID: &manager.ID})
+	require.NoError(t, db.Create(&models.ApprovalDelegation{
+		ManagerID: manager.ID, DelegateID: delegate.ID, StartDate: date(2000, 1, 1), EndDate: date(2100, 1, 1),
+	}).Error)
+
+	tests := []struct {
+		name           string
+		employee       *models.Employee
+		approver       *models.Employee
+		wantOnBehalfOf *uint
+		wantCode       string
+	}{
+		{name: "直屬主管", employee: employee, approver: manager},
+		{name: "主管的代理人", employee: employee, approver: delegate, wantOnBehalfOf: &manager.ID},
+		{name: "申請人本人", employee: employee, approver: employee, wantCode: apperrors.CodeNotApprover},
+		{name: "代理人審批自己的申請", employee: delegate, approver: delegate, wantCode: apperrors.CodeNotApprover},
+		{name: "其他員工", employee: employee, approver: director, wantCode: apperrors.CodeNotApprover},
+		{name: "沒有主管的員工不能自己審批", employee: director, approver: director, wantCode: apperrors.CodeNotApprover},
+		{name: "沒有主管的員工沒有審批人", employee: director, approver: manager, wantCode: apperrors.CodeNoApprover},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			onBehalfOf, err := service.AuthorizeEmployeeApproval(tt.employee, tt.approver.ID)
+			if tt.wantCode == "" {
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantOnBehalfOf, onBehalfOf)
+				return
+			}
+			var appErr *apperrors.Error
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, tt.wantCode, appErr.Code)
+			assert.Equal(t, apperrors.KindForbidden, appErr.Kind)
+		})
+	}
+}
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+	"hr-system/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+const approvalSLALockName = "approval_sla"
+
+// autoApproveRemark 自動核准時寫入的審批備註
+const autoApproveRemark = "Automatically approved: the leave started before it was reviewed"
+
+// approvalSLARun 單次審批時限檢查的統計
+type approvalSLARun struct {
+	pending      int // 檢查的待審批請假數
+	reminded     int // 提醒審批人的請假數
+	escalated    int // 升級給上一級主管的請假數
+	autoApproved int // 自動核准的請假數
+	failed       int // 處理失敗的請假數
+}
+
+// ApprovalSLAService 定期檢查待審批的請假
+// 提交後超過提醒時限時提醒當前的審批人，超過升級時限時改由審批人的上一級主管審批；
+// 按配置可自動核准開始日期已過仍未審批的請假，缺少必要附件的請假不會自動核准。每個步驟對同一請假只執行一次，並記錄在請假歷程中。
+// 多副本部署時通過分佈式鎖保證同一時間只有一個副本在檢查。
+type ApprovalSLAService struct {
+	leaveRepo    *repositories.LeaveRepository
+	historyRepo  *repositories.LeaveHistoryRepository
+	outboxRepo   *repositories.OutboxRepository
+	approvals    *ApprovalService
+	attachments  *AttachmentService
+	cacheService CacheService
+	lock         DistributedLock
+	cfg          config.ApprovalSLAConfig
+}
+
+func NewApprovalSLAService(
+	leaveRepo *repositories.LeaveRepository,
+	historyRepo *repositories.LeaveHistoryRepository,
+	outboxRepo *repositories.OutboxRepository,
+	approvals *ApprovalService,
+	attachments *AttachmentService,
+	cacheService CacheService,
+	lock DistributedLock,
+	cfg config.ApprovalSLAConfig,
+) *ApprovalSLAService {
+	return &ApprovalSLAService{
+		leaveRepo:    leaveRepo,
+		historyRepo:  historyRepo,
+		outboxRepo:   outboxRepo,
+		approvals:    approvals,
+		attachments:  attachments,
+		cacheService: cacheService,
+		lock:         lock,
+		cfg:          cfg,
+	}
+}
+
+// StartChecking 開始後台檢查待審批請假的審批時限
+func (s *ApprovalSLAService) StartChecking(ctx context.Context) {
+	if s.cfg.Interval <= 0 {
+		log.Println("Approval SLA checks disabled")
+		return
+	}
+
+	ticker := time.NewTicker(s.cfg.Interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.check(ctx)
+			}
+		}
+	}()
+}
+
+// check 持鎖檢查一次所有待審批的請假
+func (s *ApprovalSLAService) check(ctx context.Context) {
+	unlock, ok, err := s.lock.TryLock(ctx, approvalSLALockName, s.cfg.LockTTL)
+	if err != nil {
+		log.Printf("Skipping approval SLA check: failed to acquire lock: %v", err)
+		return
+	}
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	run := s.checkPending(ctx, time.Now())
+	if run.reminded+run.escalated+run.autoApproved+run.failed > 0 {
+		log.Printf("Approval SLA check: %d pending, %d reminded, %d escalated, %d auto-approved, %d failed",
+			run.pending, run.reminded, run.escalated, run.autoApproved, run.failed)
+	}
+}
+
+// checkPending 按 now 檢查所有待審批的請假，單筆請假失敗時記錄日誌並繼續處理其餘請假
+func (s *ApprovalSLAService) checkPending(ctx context.Context, now time.Time) *approvalSLARun {
+	run := &approvalSLARun{}
+	leaves, err := s.leaveRepo.GetPendingLeaves()
+	if err != nil {
+		log.Printf("Failed to load pending leaves for approval SLA check: %v", err)
+		return run
+	}
+	run.pending = len(leaves)
+
+	for i := range leaves {
+		if ctx.Err() != nil {
+			break
+		}
+		leave := &leaves[i]
+		if err := s.checkLeave(ctx, leave, now, run); err != nil {
+			run.failed++
+			log.Printf("Approval SLA check failed for leave %d: %v", leave.ID, err)
+		}
+	}
+	return run
+}
+
+// checkLeave 對單筆請假執行到期的步驟，自動核准優先於升級，升級優先於提醒
+func (s *ApprovalSLAService) checkLeave(ctx context.Context, leave *models.Leave, now time.Time, run *approvalSLARun) error {
+	if s.cfg.AutoApprovePastStart && !leave.StartDate.After(now) && s.attachments.RequireAttachments(leave) == nil {
+		done, err := s.autoApprove(ctx, leave, now)
+		if done {
+			run.autoApproved++
+		}
+		return err
+	}
+
+	sla := s.cfg.For(leave.LeaveType)
+	waited := now.Sub(leave.CreatedAt)
+	if leave.EscalatedAt == nil && sla.EscalateAfter > 0 && waited >= sla.EscalateAfter {
+		done, err := s.escalate(ctx, leave, now)
+		if done {
+			run.escalated++
+		}
+		// 審批人沒有上級無法升級時仍提醒審批人
+		if done || err != nil {
+			return err
+		}
+	}
+	if leave.RemindedAt == nil && leave.EscalatedAt == nil && sla.RemindAfter > 0 && waited >= sla.RemindAfter {
+		done, err := s.remind(ctx, leave, now)
+		if done {
+			run.reminded++
+		}
+		return err
+	}
+	return nil
+}
+
+// remind 提醒請假當前的審批人
+func (s *ApprovalSLAService) remind(ctx context.Context, leave *models.Leave, now time.Time) (bool, error) {
+	approver, err := s.approvals.ResolveLeaveApprover(leave, now)
+	if apperrors.IsNotFound(err) {
+		// 沒有審批人的請假無人可提醒
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	leave.RemindedAt = &now
+	return s.apply(ctx, leave, func(tx *gorm.DB) (bool, error) {
+		return s.leaveRepo.WithTx(tx).MarkReminded(leave.ID, now)
+	}, models.EventLeaveReminded, &models.LeaveHistory{
+		LeaveID:    leave.ID,
+		Action:     models.LeaveHistoryReminded,
+		ApproverID: &approver.ApproverID,
+	})
+}
+
+// escalate 將請假升級給當前審批人的上一級主管，審批人沒有上級時不升級
+func (s *ApprovalSLAService) escalate(ctx context.Context, leave *models.Leave, now time.Time) (bool, error) {
+	approver, err := s.approvals.ResolveLeaveApprover(leave, now)
+	if apperrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	target, err := s.approvals.EscalationTarget(&leave.Employee, approver.ApproverID)
+	if err != nil {
+		return false, err
+	}
+	if target == nil {
+		return false, nil
+	}
+
+	leave.EscalatedAt = &now
+	leave.EscalatedToID = target
+	return s.apply(ctx, leave, func(tx *gorm.DB) (bool, error) {
+		return s.leaveRepo.WithTx(tx).MarkEscalated(leave.ID, *target, now)
+	}, models.EventLeaveEscalated, &models.LeaveHistory{
+		LeaveID:            leave.ID,
+		Action:             models.LeaveHistoryEscalated,
+		ApproverID:         target,
+		PreviousApproverID: &approver.ApproverID,
+		Remark:             fmt.Sprintf("Pending for %s", now.Sub(leave.CreatedAt).Truncate(time.Minute)),
+	})
+}
+
+// autoApprove 由系統核准開始日期已過仍未審批的請假
+func (s *ApprovalSLAService) autoApprove(ctx context.Context, leave *models.Leave, now time.Time) (bool, error) {
+	leave.Status = models.LeaveStatusApproved
+	leave.ApproverID = nil
+	leave.OnBehalfOfID = nil
+	leave.ApproveTime = &now
+	leave.ApproveRemark = autoApproveRemark
+	return s.apply(ctx, leave, func(tx *gorm.DB) (bool, error) {
+		return s.leaveRepo.WithTx(tx).AutoApprove(leave.ID, autoApproveRemark, now)
+	}, models.EventLeaveApproved, &models.LeaveHistory{
+		LeaveID: leave.ID,
+		Action:  models.LeaveHistoryAutoApproved,
+		Remark:  autoApproveRemark,
+	})
+}
+
+// apply 在同一事務中更新請假、寫入歷程與事件；請假已被處理或步驟已執行過時不寫入，返回 false
+func (s *ApprovalSLAService) apply(ctx context.Context, leave *models.Leave, update func(tx *gorm.DB) (bool, error), eventType string, entry *models.LeaveHistory) (bool, error) {
+	var applied bool
+	err := repositories.Transaction(func(tx *gorm.DB) error {
+		var err error
+		applied, err = update(tx)
+		if err != nil || !applied {
+			return err
+		}
+		if err := s.historyRepo.WithTx(tx).Create(entry); err != nil {
+			return err
+		}
+		return appendEvent(s.outboxRepo.WithTx(tx), NewEvent(eventType, AggregateLeave, leave.ID, leave))
+	})
+	if err != nil || !applied {
+		return false, err
+	}
+
+	// 緩存失敗不影響主流程，只記錄日誌
+	if err := s.cacheService.SetLeave(ctx, leave); err != nil {
+		log.Printf("Failed to update leave cache: %v", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,322 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+	"hr-system/internal/repositories"
+	"hr-system/internal/storage"
+
+	"github.com/gabriel-vasile/mimetype"
+	"gorm.io/gorm"
+)
+
+// attachmentSniffLen 識別文件類型時讀取的開頭字節數
+const attachmentSniffLen = 3072
+
+// AttachmentUpload 一個待保存的上傳文件
+type AttachmentUpload struct {
+	FileName string
+	Size     int64 // 客戶端聲明的大小，實際寫入時仍會限制
+	Content  io.Reader
+}
+
+// AttachmentService 管理請假附件
+// 請假的員工本人、其主管鏈上的主管與當前的審批人可以查看與上傳附件；
+// 附件只在請假待審批時可以刪除。需要附件的請假類型在上傳附件前不能核准。
+type AttachmentService struct {
+	leaveRepo      *repositories.LeaveRepository
+	attachmentRepo *repositories.LeaveAttachmentRepository
+	historyRepo    *repositories.LeaveHistoryRepository
+	employeeRepo   *repositories.EmployeeRepository
+	approvals      *ApprovalService
+	store          storage.Store
+	cacheService   CacheService
+	cfg            config.AttachmentConfig
+}
+
+func NewAttachmentService(
+	leaveRepo *repositories.LeaveRepository,
+	attachmentRepo *repositories.LeaveAttachmentRepository,
+	historyRepo *repositories.LeaveHistoryRepository,
+	employeeRepo *repositories.EmployeeRepository,
+	approvals *ApprovalService,
+	store storage.Store,
+	cacheService CacheService,
+	cfg config.AttachmentConfig,
+) *AttachmentService {
+	return &AttachmentService{
+		leaveRepo:      leaveRepo,
+		attachmentRepo: attachmentRepo,
+		historyRepo:    historyRepo,
+		employeeRepo:   employeeRepo,
+		approvals:      approvals,
+		store:          store,
+		cacheService:   cacheService,
+		cfg:            cfg,
+	}
+}
+
+// Upload 為請假上傳附件，文件類型按內容識別，不信任客戶端聲明的類型
+func (s *AttachmentService) Upload(leaveID, uploaderID uint, upload AttachmentUpload) (*models.LeaveAttachment, error) {
+	leave, err := s.accessibleLeave(leaveID, uploaderID)
+	if err != nil {
+		return nil, err
+	}
+	if leave.Status == models.LeaveStatusRejected || leave.Status == models.LeaveStatusCancelled {
+		return nil, apperrors.Conflict(apperrors.CodeAttachmentLeaveClosed, "Attachments cannot be changed on a rejected or cancelled leave")
+	}
+	if upload.Size <= 0 {
+		return nil, apperrors.Validation(apperrors.CodeValidationFailed, "File is required",
+			apperrors.Field("file", "required", "File is required"))
+	}
+	if upload.Size > s.cfg.MaxSize {
+		return nil, errAttachmentTooLarge(s.cfg.MaxSize)
+	}
+	if len(leave.Attachments) >= s.cfg.MaxPerLeave {
+		return nil, apperrors.Conflict(apperrors.CodeAttachmentLimitReached,
+			fmt.Sprintf("A leave can have at most %d attachments", s.cfg.MaxPerLeave))
+	}
+
+	head := make([]byte, attachmentSniffLen)
+	n, err := io.ReadFull(upload.Content, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, apperrors.Internal(err)
+	}
+	detected := mimetype.Detect(head[:n])
+	contentType, _, _ := strings.Cut(detected.String(), ";")
+	if !s.cfg.Allows(contentType) {
+		return nil, apperrors.Validation(apperrors.CodeAttachmentTypeNotAllowed, "File type is not allowed",
+			apperrors.Field("file", apperrors.CodeAttachmentTypeNotAllowed,
+				fmt.Sprintf("File type must be one of: %s", strings.Join(s.cfg.AllowedTypes, ", "))))
+	}
+
+	name, err := randomAttachmentName()
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	attachment := &models.LeaveAttachment{
+		LeaveID:     leave.ID,
+		UploaderID:  uploaderID,
+		FileName:    sanitizeFileName(upload.FileName),
+		ContentType: contentType,
+		StorageKey:  fmt.Sprintf("leaves/%d/%s%s", leave.ID, name, detected.Extension()),
+	}
+
+	// 限制實際寫入的大小，客戶端聲明的大小不可信
+	hash := sha256.New()
+	counter := &countingWriter{}
+	body := io.TeeReader(io.LimitReader(io.MultiReader(bytes.NewReader(head[:n]), upload.Content), s.cfg.MaxSize+1), io.MultiWriter(hash, counter))
+	ctx := context.Background()
+	if err := s.store.Put(ctx, attachment.StorageKey, body, upload.Size, contentType); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	if counter.n > s.cfg.MaxSize {
+		s.deleteObject(ctx, attachment.StorageKey)
+		return nil, errAttachmentTooLarge(s.cfg.MaxSize)
+	}
+	attachment.Size = counter.n
+	attachment.SHA256 = hex.EncodeToString(hash.Sum(nil))
+
+	err = repositories.Transaction(func(tx *gorm.DB) error {
+		if err := s.attachmentRepo.WithTx(tx).Create(attachment); err != nil {
+			return err
+		}
+		return s.historyRepo.WithTx(tx).Create(&models.LeaveHistory{
+			LeaveID: leave.ID,
+			Action:  models.LeaveHistoryAttached,
+			ActorID: &uploaderID,
+			Remark:  attachment.FileName,
+		})
+	})
+	if err != nil {
+		s.deleteObject(ctx, attachment.StorageKey)
+		return nil, err
+	}
+
+	s.invalidateLeave(ctx, leave.ID)
+	return attachment, nil
+}
+
+// List 獲取請假的附件
+func (s *AttachmentService) List(leaveID, requesterID uint) ([]models.LeaveAttachment, error) {
+	leave, err := s.accessibleLeave(leaveID, requesterID)
+	if err != nil {
+		return nil, err
+	}
+	return leave.Attachments, nil
+}
+
+// Open 讀取請假附件的內容，調用方負責關閉返回的 io.ReadCloser
+func (s *AttachmentService) Open(leaveID, id, requesterID uint) (*models.LeaveAttachment, io.ReadCloser, error) {
+	if _, err := s.accessibleLeave(leaveID, requesterID); err != nil {
+		return nil, nil, err
+	}
+	attachment, err := s.attachmentRepo.Get(leaveID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := s.store.Open(context.Background(), attachment.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, apperrors.NotFound(apperrors.CodeAttachmentNotFound, "Attachment not found").Wrap(err)
+	}
+	if err != nil {
+		return nil, nil, apperrors.Internal(err)
+	}
+	return attachment, content, nil
+}
+
+// Delete 刪除待審批請假的附件，只有請假的員工本人或上傳者可以刪除
+func (s *AttachmentService) Delete(leaveID, id, requesterID uint) error {
+	leave, err := s.leaveRepo.GetByID(leaveID)
+	if err != nil {
+		return err
+	}
+	attachment, err := s.attachmentRepo.Get(leaveID, id)
+	if err != nil {
+		return err
+	}
+	if requesterID != leave.EmployeeID && requesterID != attachment.UploaderID {
+		return errAttachmentAccessDenied()
+	}
+	if leave.Status != models.LeaveStatusPending {
+		return apperrors.Conflict(apperrors.CodeAttachmentLeaveClosed, "Attachments can only be removed while the leave is pending")
+	}
+
+	err = repositories.Transaction(func(tx *gorm.DB) error {
+		if err := s.attachmentRepo.WithTx(tx).Delete(leaveID, id); err != nil {
+			return err
+		}
+		return s.historyRepo.WithTx(tx).Create(&models.LeaveHistory{
+			LeaveID: leaveID,
+			Action:  models.LeaveHistoryDetached,
+			ActorID: &requesterID,
+			Remark:  attachment.FileName,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	s.deleteObject(ctx, attachment.StorageKey)
+	s.invalidateLeave(ctx, leaveID)
+	return nil
+}
+
+// RequireAttachments 檢查請假在核准前是否已上傳必要的附件，leave 須已加載附件
+func (s *AttachmentService) RequireAttachments(leave *models.Leave) error {
+	if len(leave.Attachments) > 0 || !s.cfg.Requires(leave.LeaveType, leaveDays(leave)) {
+		return nil
+	}
+	return apperrors.Conflict(apperrors.CodeAttachmentRequired, "A supporting document must be attached before this leave can be approved")
+}
+
+// accessibleLeave 獲取請假並檢查請求者是否可以查看其附件
+func (s *AttachmentService) accessibleLeave(leaveID, requesterID uint) (*models.Leave, error) {
+	leave, err := s.leaveRepo.GetByID(leaveID)
+	if err != nil {
+		return nil, err
+	}
+	if requesterID == leave.EmployeeID {
+		return leave, nil
+	}
+	chain, err := managerChain(s.employeeRepo, &leave.Employee)
+	if err != nil {
+		return nil, err
+	}
+	for _, managerID := range chain[1:] {
+		if managerID == requesterID {
+			return leave, nil
+		}
+	}
+	// 代理或升級後的審批人不一定在主管鏈上
+	approver, err := s.approvals.ResolveLeaveApprover(leave, time.Now())
+	if err != nil && !apperrors.IsNotFound(err) {
+		return nil, err
+	}
+	if approver != nil && approver.ApproverID == requesterID {
+		return leave, nil
+	}
+	return nil, errAttachmentAccessDenied()
+}
+
+// deleteObject 刪除存儲中的文件，失敗時只記錄日誌，留下的孤兒文件不影響功能
+func (s *AttachmentService) deleteObject(ctx context.Context, key string) {
+	if err := s.store.Delete(ctx, key); err != nil {
+		log.Printf("Failed to delete attachment object %s: %v", key, err)
+	}
+}
+
+// invalidateLeave 刪除請假緩存，緩存中的請假內嵌了附件列表
+func (s *AttachmentService) invalidateLeave(ctx context.Context, leaveID uint) {
+	if err := s.cacheService.DeleteLeave(ctx, leaveID); err != nil {
+		log.Printf("Failed to delete leave cache: %v", err)
+	}
+}
+
+func errAttachmentAccessDenied() *apperrors.Error {
+	return apperrors.Forbidden(apperrors.CodeAttachmentAccessDenied, "You are not allowed to access attachments of this leave")
+}
+
+func errAttachmentTooLarge(maxSize int64) *apperrors.Error {
+	msg := fmt.Sprintf("File must not exceed %d bytes", maxSize)
+	return apperrors.Validation(apperrors.CodeAttachmentTooLarge, msg,
+		apperrors.Field("file", apperrors.CodeAttachmentTooLarge, msg))
+}
+
+// leaveDays 返回請假跨越的日曆天數，開始與結束日期都計算在內
+func leaveDays(leave *models.Leave) int {
+	return int(startOfDay(leave.EndDate).Sub(startOfDay(leave.StartDate)).Hours()/24) + 1
+}
+
+// randomAttachmentName 生成存儲路徑中的隨機文件名，不使用客戶端提供的文件名
+func randomAttachmentName() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// sanitizeFileName 去除文件名中的路徑與控制字符，下載時作為建議的文件名
+func sanitizeFileName(name string) string {
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+	if runes := []rune(strings.TrimSpace(name)); len(runes) > 200 {
+		name = string(runes[:200])
+	}
+	if name = strings.TrimSpace(name); name == "" {
+		return "attachment"
+	}
+	return name
+}
+
+// countingWriter 統計寫入的字節數
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
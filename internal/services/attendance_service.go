@@ -0,0 +1,318 @@
+package services
+
+import (
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+	"hr-system/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+// AttendanceService 記錄打卡，按排定的班次與請假計算每天的考勤摘要並標記異常，並處理補卡申請
+// 補卡申請的審批人與請假相同，核准後產生一筆來源為 correction 的打卡記錄
+type AttendanceService struct {
+	attendanceRepo *repositories.AttendanceRepository
+	employeeRepo   *repositories.EmployeeRepository
+	leaveRepo      *repositories.LeaveRepository
+	schedules      *ScheduleService
+	approvals      *ApprovalService
+	config         config.AttendanceConfig
+	now            func() time.Time
+}
+
+func NewAttendanceService(attendanceRepo *repositories.AttendanceRepository, employeeRepo *repositories.EmployeeRepository, leaveRepo *repositories.LeaveRepository, schedules *ScheduleService, approvals *ApprovalService, cfg config.AttendanceConfig) *AttendanceService {
+	return &AttendanceService{
+		attendanceRepo: attendanceRepo,
+		employeeRepo:   employeeRepo,
+		leaveRepo:      leaveRepo,
+		schedules:      schedules,
+		approvals:      approvals,
+		config:         cfg,
+		now:            time.Now,
+	}
+}
+
+// Punch 記錄打卡，未指定時間時使用當前時間；離職員工不能打卡
+func (s *AttendanceService) Punch(punch *models.AttendancePunch) error {
+	employee, err := s.employeeRepo.GetByID(punch.EmployeeID)
+	if apperrors.IsNotFound(err) {
+		return apperrors.Validation(apperrors.CodeEmployeeNotFound, "Employee not found",
+			apperrors.Field("employee_id", apperrors.CodeEmployeeNotFound, "Employee not found"))
+	}
+	if err != nil {
+		return err
+	}
+	if employee.Status == models.EmployeeStatusInactive {
+		return apperrors.Validation(apperrors.CodeEmployeeNotFound, "Employee is inactive",
+			apperrors.Field("employee_id", apperrors.CodeEmployeeNotFound, "Employee is inactive"))
+	}
+
+	now := s.now()
+	if punch.Time.IsZero() {
+		punch.Time = now
+	}
+	if punch.Time.After(now.Add(s.config.MaxClockSkew)) {
+		return errInvalidPunchTime()
+	}
+	return s.attendanceRepo.CreatePunch(punch)
+}
+
+// EmployeeAttendance 獲取員工 [from, to] 每天的考勤摘要
+func (s *AttendanceService) EmployeeAttendance(employeeID uint, from, to time.Time) ([]models.AttendanceDay, error) {
+	employee, err := s.employeeRepo.GetByID(employeeID)
+	if err != nil {
+		return nil, err
+	}
+	from, end, err := s.attendanceRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return s.summaries([]models.Employee{*employee}, from, end)
+}
+
+// Anomalies 獲取某天有考勤異常的在職員工，department 為空時不限部門
+func (s *AttendanceService) Anomalies(date time.Time, department string) ([]models.AttendanceDay, error) {
+	var employees []models.Employee
+	var err error
+	if department != "" {
+		employees, err = s.employeeRepo.ListByDepartment(department)
+	} else {
+		employees, err = s.employeeRepo.GetAll()
+	}
+	if err != nil {
+		return nil, err
+	}
+	active := employees[:0]
+	for _, employee := range employees {
+		if employee.Status != models.EmployeeStatusInactive {
+			active = append(active, employee)
+		}
+	}
+
+	from := startOfDay(date)
+	days, err := s.summaries(active, from, from.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	anomalies := []models.AttendanceDay{}
+	for _, day := range days {
+		if len(day.Anomalies) > 0 {
+			anomalies = append(anomalies, day)
+		}
+	}
+	return anomalies, nil
+}
+
+// CreateCorrection 提交補卡申請，補登的時間不能晚於當前時間
+func (s *AttendanceService) CreateCorrection(correction *models.AttendanceCorrection) error {
+	employee, err := s.employeeRepo.GetByID(correction.EmployeeID)
+	if apperrors.IsNotFound(err) {
+		return apperrors.Validation(apperrors.CodeEmployeeNotFound, "Employee not found",
+			apperrors.Field("employee_id", apperrors.CodeEmployeeNotFound, "Employee not found"))
+	}
+	if err != nil {
+		return err
+	}
+	if correction.Time.After(s.now()) {
+		return errInvalidPunchTime()
+	}
+	correction.Status = models.LeaveStatusPending
+	if err := s.attendanceRepo.CreateCorrection(correction); err != nil {
+		return err
+	}
+	correction.Employee = *employee
+	return nil
+}
+
+// ListCorrections 獲取補卡申請，employeeID 為 0 或 status 為空時不限制
+func (s *AttendanceService) ListCorrections(employeeID uint, status string) ([]models.AttendanceCorrection, error) {
+	return s.attendanceRepo.ListCorrections(employeeID, status)
+}
+
+// GetCorrection 獲取補卡申請
+func (s *AttendanceService) GetCorrection(id uint) (*models.AttendanceCorrection, error) {
+	return s.attendanceRepo.GetCorrection(id)
+}
+
+// UpdateCorrectionStatus 審批待審批的補卡申請，approverID 為執行審批的員工
+func (s *AttendanceService) UpdateCorrectionStatus(id uint, status, remark string, approverID uint) (*models.AttendanceCorrection, error) {
+	correction, err := s.attendanceRepo.GetCorrection(id)
+	if err != nil {
+		return nil, err
+	}
+	if status != models.LeaveStatusApproved && status != models.LeaveStatusRejected {
+		return nil, apperrors.Validation(apperrors.CodeInvalidStatus, "Invalid status",
+			apperrors.Field("status", apperrors.CodeInvalidStatus, "Status must be approved or rejected"))
+	}
+	if correction.Status != models.LeaveStatusPending {
+		return nil, errCorrectionNotPending()
+	}
+
+	// 檢查審批人是否為直屬主管或當前的代理審批人，代理審批時記錄原審批人
+	onBehalfOf, err := s.approvals.AuthorizeEmployeeApproval(&correction.Employee, approverID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	correction.Status = status
+	correction.ApproveRemark = remark
+	correction.ApproverID = &approverID
+	correction.OnBehalfOfID = onBehalfOf
+	correction.ApproveTime = &now
+	err = repositories.Transaction(func(tx *gorm.DB) error {
+		// 只有仍為待審批的申請會被更新，避免並發審批時重複產生打卡記錄
+		updated, err := s.attendanceRepo.WithTx(tx).UpdateCorrectionStatus(correction)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return errCorrectionNotPending()
+		}
+		if status != models.LeaveStatusApproved {
+			return nil
+		}
+		return s.attendanceRepo.WithTx(tx).CreatePunch(&models.AttendancePunch{
+			EmployeeID:   correction.EmployeeID,
+			Kind:         correction.Kind,
+			Time:         correction.Time,
+			Source:       models.PunchSourceCorrection,
+			CorrectionID: &correction.ID,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return correction, nil
+}
+
+// attendanceRange 檢查查詢期間，返回 [from, to 的次日零點)
+func (s *AttendanceService) attendanceRange(from, to time.Time) (time.Time, time.Time, error) {
+	from, end := startOfDay(from), startOfDay(to).AddDate(0, 0, 1)
+	if !end.After(from) {
+		return from, end, apperrors.Validation(apperrors.CodeInvalidDateRange, "Start date must be before end date",
+			apperrors.Field("to", apperrors.CodeInvalidDateRange, "Start date must be before end date"))
+	}
+	if end.Sub(from) > s.config.MaxRange {
+		return from, end, apperrors.Validation(apperrors.CodeAttendanceRangeTooLong, "Attendance range is too long")
+	}
+	return from, end, nil
+}
+
+// summaries 計算員工在 [from, to) 每天的考勤摘要，按員工、日期排序
+func (s *AttendanceService) summaries(employees []models.Employee, from, to time.Time) ([]models.AttendanceDay, error) {
+	days := []models.AttendanceDay{}
+	if len(employees) == 0 {
+		return days, nil
+	}
+	ids := make([]uint, len(employees))
+	for i, employee := range employees {
+		ids[i] = employee.ID
+	}
+
+	// 前一天開始的跨夜班次會延續到 from 之後，最後一天的班次可能在 to 之後才結束
+	shifts, err := s.schedules.scheduledShifts(employees, from.AddDate(0, 0, -1), to)
+	if err != nil {
+		return nil, err
+	}
+	punchList, err := s.attendanceRepo.ListPunches(ids, from, to.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	punches := make(map[uint][]models.AttendancePunch)
+	for _, punch := range punchList {
+		punches[punch.EmployeeID] = append(punches[punch.EmployeeID], punch)
+	}
+	leaveList, err := s.leaveRepo.ListOverlapping(ids, []string{models.LeaveStatusApproved}, from, to)
+	if err != nil {
+		return nil, err
+	}
+	leaves := make(map[uint][]models.Leave)
+	for _, leave := range leaveList {
+		leaves[leave.EmployeeID] = append(leaves[leave.EmployeeID], leave)
+	}
+
+	now := s.now()
+	for i := range employees {
+		employee := &employees[i]
+		for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+			next := day.AddDate(0, 0, 1)
+			summary := models.AttendanceDay{EmployeeID: employee.ID, Date: day.Format("2006-01-02"), Anomalies: []string{}}
+			shift, scheduled := shifts[employee.ID][summary.Date]
+			// 當天的打卡從零點（或前一天跨夜班次的下班時間）起算，到次日零點或班次下班時間為止
+			windowStart, windowEnd := day, next
+			if previous, ok := shifts[employee.ID][day.AddDate(0, 0, -1).Format("2006-01-02")]; ok && previous.End.After(day) {
+				windowStart = previous.End
+			}
+			if scheduled && shift.End.After(next) {
+				windowEnd = shift.End
+			}
+			for _, punch := range punches[employee.ID] {
+				if punch.Time.Before(windowStart) || !punch.Time.Before(windowEnd) {
+					continue
+				}
+				punchTime := punch.Time
+				if punch.Kind == models.PunchClockIn && summary.ClockIn == nil {
+					summary.ClockIn = &punchTime
+				}
+				if punch.Kind == models.PunchClockOut {
+					summary.ClockOut = &punchTime
+				}
+			}
+			for _, leave := range leaves[employee.ID] {
+				if leave.StartDate.Before(next) && !startOfDay(leave.EndDate).Before(day) {
+					summary.LeaveIDs = append(summary.LeaveIDs, leave.ID)
+				}
+			}
+			if summary.ClockIn != nil && summary.ClockOut != nil && summary.ClockOut.After(*summary.ClockIn) {
+				summary.WorkedMinutes = int(summary.ClockOut.Sub(*summary.ClockIn).Minutes())
+			}
+
+			if scheduled {
+				summary.WorkDay = true
+				summary.ScheduledStart, summary.ScheduledEnd = &shift.Start, &shift.End
+				// 有已核准請假的日子不標記異常
+				if len(summary.LeaveIDs) == 0 {
+					summary.Anomalies = detectAnomalies(&summary, &shift, s.config, now)
+				}
+			}
+			days = append(days, summary)
+		}
+	}
+	return days, nil
+}
+
+// detectAnomalies 對照排定的班次標記異常，下班時間未到時只檢查遲到
+func detectAnomalies(day *models.AttendanceDay, shift *models.ScheduledShift, cfg config.AttendanceConfig, now time.Time) []string {
+	anomalies := []string{}
+	if day.ClockIn != nil && day.ClockIn.After(shift.Start.Add(cfg.LateGrace)) {
+		anomalies = append(anomalies, models.AttendanceLate)
+	}
+	if now.Before(shift.End) {
+		return anomalies
+	}
+	switch {
+	case day.ClockIn == nil && day.ClockOut == nil:
+		anomalies = append(anomalies, models.AttendanceAbsent)
+	case day.ClockIn == nil:
+		anomalies = append(anomalies, models.AttendanceMissingClockIn)
+	case day.ClockOut == nil:
+		anomalies = append(anomalies, models.AttendanceMissingClockOut)
+	}
+	if day.ClockOut != nil && day.ClockOut.Before(shift.End.Add(-cfg.EarlyGrace)) {
+		anomalies = append(anomalies, models.AttendanceEarlyLeave)
+	}
+	return anomalies
+}
+
+func errInvalidPunchTime() *apperrors.Error {
+	return apperrors.Validation(apperrors.CodeInvalidPunchTime, "Punch time must not be in the future",
+		apperrors.Field("time", apperrors.CodeInvalidPunchTime, "Punch time must not be in the future"))
+}
+
+func errCorrectionNotPending() *apperrors.Error {
+	return apperrors.Conflict(apperrors.CodeCorrectionNotPending, "Only pending attendance corrections can be approved or rejected")
+}
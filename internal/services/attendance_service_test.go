@@ -0,0 +1,71 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+	"hr-system/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAttendanceService() *AttendanceService {
+	employeeRepo := repositories.NewEmployeeRepository()
+	leaveRepo := repositories.NewLeaveRepository()
+	approvals := NewApprovalService(repositories.NewDelegationRepository(), employeeRepo, leaveRepo)
+	return NewAttendanceService(repositories.NewAttendanceRepository(), employeeRepo, leaveRepo, newTestScheduleService(), approvals,
+		config.AttendanceConfig{})
+}
+
+func TestUpdateCorrectionStatusAuthorizesApprover(t *testing.T) {
+	tests := []struct {
+		name     string
+		approver string
+		wantCode string
+	}{
+		{name: "直屬主管核准", approver: "主管"},
+		{name: "申請人不能核准自己的補卡", approver: "員工", wantCode: apperrors.CodeNotApprover},
+		{name: "其他員工不能核准", approver: "同事", wantCode: apperrors.CodeNotApprover},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupTestDB(t)
+			service := newTestAttendanceService()
+			manager := seedEmployee(t, db, models.Employee{Name: "主管"})
+			employee := seedEmployee(t, db, models.Employee{Name: "員工", ManagerID: &manager.ID})
+			colleague := seedEmployee(t, db, models.Employee{Name: "同事", ManagerID: &manager.ID})
+			approvers := map[string]uint{"主管": manager.ID, "員工": employee.ID, "同事": colleague.ID}
+			correction := models.AttendanceCorrection{
+				EmployeeID: employee.ID,
+				Kind:       models.PunchClockIn,
+				Time:       date(2024, 10, 7).Add(9 * time.Hour),
+				Status:     models.LeaveStatusPending,
+			}
+			require.NoError(t, db.Create(&correction).Error)
+
+			_, err := service.UpdateCorrectionStatus(correction.ID, models.LeaveStatusApproved, "", approvers[tt.approver])
+
+			var stored models.AttendanceCorrection
+			require.NoError(t, db.First(&stored, correction.ID).Error)
+			var punches int64
+			db.Model(&models.AttendancePunch{}).Where("correction_id = ?", correction.ID).Count(&punches)
+			if tt.wantCode == "" {
+				require.NoError(t, err)
+				assert.Equal(t, models.LeaveStatusApproved, stored.Status)
+				require.NotNil(t, stored.ApproverID)
+				assert.Equal(t, manager.ID, *stored.ApproverID)
+				assert.Equal(t, int64(1), punches)
+				return
+			}
+			var appErr *apperrors.Error
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, tt.wantCode, appErr.Code)
+			assert.Equal(t, models.LeaveStatusPending, stored.Status)
+			assert.Zero(t, punches, "未授權的審批不應產生打卡記錄")
+		})
+	}
+}
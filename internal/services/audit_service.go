@@ -0,0 +1,31 @@
+package services
+
+import (
+	"hr-system/internal/models"
+	"hr-system/internal/repositories"
+)
+
+// RequestMeta 發起操作的請求信息，記錄在審計記錄中
+type RequestMeta struct {
+	RequestID string
+	IP        string
+}
+
+// AuditService 記錄與查詢審計記錄
+type AuditService struct {
+	repo *repositories.AuditRepository
+}
+
+func NewAuditService(repo *repositories.AuditRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// Record 寫入審計記錄
+func (s *AuditService) Record(entry *models.AuditLog) error {
+	return s.repo.Create(entry)
+}
+
+// ListAuditLogs 按操作對象查詢審計記錄
+func (s *AuditService) ListAuditLogs(entityType string, entityID uint, limit int) ([]models.AuditLog, error) {
+	return s.repo.List(entityType, entityID, limit)
+}
@@ -0,0 +1,21 @@
+package services
+
+import (
+	"math/rand"
+	"time"
+)
+
+// exponentialBackoff 第 attempts 次失敗後的等待時間：base * 2^(attempts-1)，不超過 max，並加上最多 20% 的隨機抖動
+func exponentialBackoff(base, max time.Duration, attempts int) time.Duration {
+	wait := base
+	for i := 1; i < attempts && wait < max; i++ {
+		wait *= 2
+	}
+	if wait > max {
+		wait = max
+	}
+	if wait <= 0 {
+		return 0
+	}
+	return wait + time.Duration(rand.Int63n(int64(wait)/5+1))
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+	"hr-system/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+// bulkLeaveConflictStatuses 視為重疊的已有請假狀態
+var bulkLeaveConflictStatuses = []string{models.LeaveStatusPending, models.LeaveStatusApproved}
+
+// BulkLeaveService 為一批員工創建已核准的請假，如颱風停班或公司停工
+// 所有請假、歷程、事件與處理結果在同一事務中寫入；不檢查人力規則與附件
+type BulkLeaveService struct {
+	bulkRepo     *repositories.BulkLeaveRepository
+	leaveRepo    *repositories.LeaveRepository
+	employeeRepo *repositories.EmployeeRepository
+	historyRepo  *repositories.LeaveHistoryRepository
+	outboxRepo   *repositories.OutboxRepository
+}
+
+func NewBulkLeaveService(bulkRepo *repositories.BulkLeaveRepository, leaveRepo *repositories.LeaveRepository, employeeRepo *repositories.EmployeeRepository, historyRepo *repositories.LeaveHistoryRepository, outboxRepo *repositories.OutboxRepository) *BulkLeaveService {
+	return &BulkLeaveService{
+		bulkRepo:     bulkRepo,
+		leaveRepo:    leaveRepo,
+		employeeRepo: employeeRepo,
+		historyRepo:  historyRepo,
+		outboxRepo:   outboxRepo,
+	}
+}
+
+// Create 按任務的對象創建請假，employeeIDs 僅在對象為指定員工時使用
+// 與已有的待審批或已核准請假重疊時按 ConflictPolicy 跳過該員工或不創建任何請假
+func (s *BulkLeaveService) Create(job *models.BulkLeaveJob, employeeIDs []uint) error {
+	if job.StartDate.After(job.EndDate) {
+		return apperrors.Validation(apperrors.CodeInvalidDateRange, "Start date must be before end date",
+			apperrors.Field("end_date", apperrors.CodeInvalidDateRange, "Start date must be before end date"))
+	}
+	if job.ConflictPolicy == "" {
+		job.ConflictPolicy = models.BulkLeaveConflictSkip
+	}
+
+	items, employees, err := s.resolveTargets(job, employeeIDs)
+	if err != nil {
+		return err
+	}
+	if len(employees) == 0 {
+		return apperrors.Validation(apperrors.CodeBulkLeaveNoEmployees, "No active employees match the bulk leave target")
+	}
+
+	// 找出與已有請假重疊的員工
+	ids := make([]uint, len(employees))
+	for i, employee := range employees {
+		ids[i] = employee.ID
+	}
+	overlapping, err := s.leaveRepo.ListOverlapping(ids, bulkLeaveConflictStatuses,
+		startOfDay(job.StartDate), startOfDay(job.EndDate).AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+	conflicts := make(map[uint][]uint)
+	for _, leave := range overlapping {
+		conflicts[leave.EmployeeID] = append(conflicts[leave.EmployeeID], leave.ID)
+	}
+
+	var leaves []models.Leave
+	var leaveItems []int // leaves[i] 對應的 items 下標
+	for _, employee := range employees {
+		if leaveIDs, ok := conflicts[employee.ID]; ok {
+			items = append(items, models.BulkLeaveItem{
+				EmployeeID:       employee.ID,
+				Outcome:          models.BulkLeaveOutcomeConflict,
+				ConflictLeaveIDs: leaveIDs,
+			})
+			continue
+		}
+		items = append(items, models.BulkLeaveItem{EmployeeID: employee.ID, Outcome: models.BulkLeaveOutcomeCreated})
+		leaveItems = append(leaveItems, len(items)-1)
+		leaves = append(leaves, models.Leave{
+			EmployeeID: employee.ID,
+			StartDate:  job.StartDate,
+			EndDate:    job.EndDate,
+			LeaveType:  job.LeaveType,
+			Reason:     job.Reason,
+			Status:     models.LeaveStatusApproved,
+			ApproverID: job.CreatedByID,
+		})
+	}
+
+	job.Status = models.BulkLeaveJobCompleted
+	if len(conflicts) > 0 && job.ConflictPolicy == models.BulkLeaveConflictAbort {
+		// 不創建任何請假，但保留處理結果供 HR 查看重疊的請假
+		job.Status = models.BulkLeaveJobAborted
+		leaves = nil
+		for _, i := range leaveItems {
+			items[i].Outcome = models.BulkLeaveOutcomeAborted
+		}
+		leaveItems = nil
+	}
+	job.CreatedCount = len(leaves)
+	job.SkippedCount = len(items) - len(leaves)
+
+	err = repositories.Transaction(func(tx *gorm.DB) error {
+		if err := s.bulkRepo.WithTx(tx).CreateJob(job); err != nil {
+			return err
+		}
+		if len(leaves) > 0 {
+			if err := s.createLeaves(tx, job, leaves, employees); err != nil {
+				return err
+			}
+		}
+		for i, leave := range leaves {
+			leaveID := leave.ID
+			items[leaveItems[i]].LeaveID = &leaveID
+		}
+		for i := range items {
+			items[i].JobID = job.ID
+		}
+		return s.bulkRepo.WithTx(tx).CreateItems(items)
+	})
+	if err != nil {
+		return err
+	}
+	job.Items = items
+	return nil
+}
+
+// createLeaves 寫入請假及其歷程與核准事件
+func (s *BulkLeaveService) createLeaves(tx *gorm.DB, job *models.BulkLeaveJob, leaves []models.Leave, employees []models.Employee) error {
+	now := time.Now()
+	remark := fmt.Sprintf("Bulk leave #%d", job.ID)
+	for i := range leaves {
+		leaves[i].ApproveTime = &now
+		leaves[i].ApproveRemark = remark
+	}
+	if err := s.leaveRepo.WithTx(tx).CreateBatch(leaves); err != nil {
+		return err
+	}
+
+	byID := make(map[uint]models.Employee, len(employees))
+	for _, employee := range employees {
+		byID[employee.ID] = employee
+	}
+	history := make([]models.LeaveHistory, len(leaves))
+	outbox := s.outboxRepo.WithTx(tx)
+	for i := range leaves {
+		leave := &leaves[i]
+		history[i] = models.LeaveHistory{
+			LeaveID: leave.ID,
+			Action:  models.LeaveHistoryBulkCreated,
+			ActorID: job.CreatedByID,
+			Remark:  remark,
+		}
+		// 與 GetByID 預加載的結果保持一致，避免事件中的 Employee 為空
+		leave.Employee = byID[leave.EmployeeID]
+		if err := appendEvent(outbox, NewEvent(models.EventLeaveApproved, AggregateLeave, leave.ID, leave)); err != nil {
+			return err
+		}
+	}
+	return s.historyRepo.WithTx(tx).CreateBatch(history)
+}
+
+// resolveTargets 返回任務對象中的在職員工，以及不存在或已離職員工的處理結果
+func (s *BulkLeaveService) resolveTargets(job *models.BulkLeaveJob, employeeIDs []uint) ([]models.BulkLeaveItem, []models.Employee, error) {
+	var candidates []models.Employee
+	var err error
+	switch job.Target {
+	case models.BulkLeaveTargetAll:
+		job.Department = ""
+		candidates, err = s.employeeRepo.GetAll()
+	case models.BulkLeaveTargetDepartment:
+		if job.Department == "" {
+			return nil, nil, errInvalidBulkLeaveTarget("department")
+		}
+		candidates, err = s.employeeRepo.ListByDepartment(job.Department)
+	case models.BulkLeaveTargetEmployees:
+		if len(employeeIDs) == 0 {
+			return nil, nil, errInvalidBulkLeaveTarget("employee_ids")
+		}
+		job.Department = ""
+		candidates, err = s.employeeRepo.ListByIDs(employeeIDs)
+	default:
+		return nil, nil, errInvalidBulkLeaveTarget("target")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var items []models.BulkLeaveItem
+	found := make(map[uint]bool, len(candidates))
+	var employees []models.Employee
+	for _, employee := range candidates {
+		found[employee.ID] = true
+		if employee.Status == models.EmployeeStatusInactive {
+			// 對象為全體或部門時離職員工不在範圍內，不記錄結果
+			if job.Target == models.BulkLeaveTargetEmployees {
+				items = append(items, models.BulkLeaveItem{EmployeeID: employee.ID, Outcome: models.BulkLeaveOutcomeInactive})
+			}
+			continue
+		}
+		employees = append(employees, employee)
+	}
+	seen := make(map[uint]bool, len(employeeIDs))
+	for _, id := range employeeIDs {
+		if job.Target != models.BulkLeaveTargetEmployees || found[id] || seen[id] {
+			continue
+		}
+		seen[id] = true
+		items = append(items, models.BulkLeaveItem{EmployeeID: id, Outcome: models.BulkLeaveOutcomeNotFound})
+	}
+	return items, employees, nil
+}
+
+func errInvalidBulkLeaveTarget(field string) *apperrors.Error {
+	msg := "Department target needs a department and employees target needs employee_ids"
+	return apperrors.Validation(apperrors.CodeInvalidBulkLeaveTarget, msg,
+		apperrors.Field(field, apperrors.CodeInvalidBulkLeaveTarget, msg))
+}
+
+// GetJob 獲取批量請假任務及每個員工的處理結果
+func (s *BulkLeaveService) GetJob(id uint) (*models.BulkLeaveJob, error) {
+	return s.bulkRepo.GetJob(id)
+}
+
+// ListJobs 獲取所有批量請假任務
+func (s *BulkLeaveService) ListJobs() ([]models.BulkLeaveJob, error) {
+	return s.bulkRepo.ListJobs()
+}
@@ -0,0 +1,284 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"hr-system/internal/models"
+)
+
+// 熔斷器狀態
+const (
+	BreakerClosed   = "closed"    // 主緩存正常，所有請求走主緩存
+	BreakerOpen     = "open"      // 主緩存故障，所有請求走備援緩存
+	BreakerHalfOpen = "half-open" // 冷卻期結束，放行一個探測請求到主緩存
+)
+
+// FallbackCacheService 帶熔斷器的緩存實現
+// 主緩存（Redis）連續失敗達到閾值後熔斷，請求轉到備援緩存（進程內 LRU），
+// 冷卻期結束後以單個請求探測主緩存，成功則恢復。
+// 熔斷期間寫入或刪除過的鍵會被記錄下來，恢復時從主緩存中刪除，避免讀到熔斷前的舊數據；
+// 備援緩存只在熔斷期間提供讀取，恢復時清空，避免之後主緩存偶發失敗時讀到熔斷期間留下的舊數據。
+type FallbackCacheService struct {
+	primary   CacheService
+	fallback  *MemoryCacheService
+	threshold int
+	cooldown  time.Duration
+
+	mu             sync.Mutex
+	state          string
+	failures       int
+	openedAt       time.Time
+	lastError      string
+	dirtyEmployees map[uint]struct{}
+	dirtyLeaves    map[uint]struct{}
+	dirtyDeps      map[uint]struct{} // 熔斷期間需要在主緩存中失效依賴請假的員工ID
+}
+
+// NewFallbackCacheService 創建帶熔斷器的緩存服務，startOpen 為 true 時直接使用備援緩存
+func NewFallbackCacheService(primary CacheService, fallback *MemoryCacheService, threshold int, cooldown time.Duration, startOpen bool) *FallbackCacheService {
+	if threshold <= 0 {
+		threshold = 1
+	}
+	s := &FallbackCacheService{
+		primary:        primary,
+		fallback:       fallback,
+		threshold:      threshold,
+		cooldown:       cooldown,
+		state:          BreakerClosed,
+		dirtyEmployees: make(map[uint]struct{}),
+		dirtyLeaves:    make(map[uint]struct{}),
+		dirtyDeps:      make(map[uint]struct{}),
+	}
+	if startOpen {
+		s.state = BreakerOpen
+		s.openedAt = time.Now()
+		s.lastError = "redis unavailable at startup"
+	}
+	return s
+}
+
+// usePrimary 判斷本次請求是否應該走主緩存
+func (s *FallbackCacheService) usePrimary() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch s.state {
+	case BreakerClosed:
+		return true
+	case BreakerOpen:
+		if time.Since(s.openedAt) < s.cooldown {
+			return false
+		}
+		// 冷卻期結束，當前請求作為探測請求
+		s.state = BreakerHalfOpen
+		return true
+	default:
+		// 探測請求尚未返回，其餘請求繼續走備援緩存
+		return false
+	}
+}
+
+// fallbackActive 判斷主緩存調用失敗後能否改讀備援緩存
+// 熔斷器關閉時備援緩存沒有同步主緩存的寫入，調用方應直接返回主緩存的錯誤並回源
+func (s *FallbackCacheService) fallbackActive() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state != BreakerClosed
+}
+
+// record 記錄主緩存的調用結果，返回 false 表示主緩存故障、調用方需要改用備援緩存
+func (s *FallbackCacheService) record(ctx context.Context, err error) bool {
+	if err == nil || errors.Is(err, ErrCacheMiss) || errors.Is(err, ErrCacheNotFound) {
+		s.onSuccess(ctx)
+		return true
+	}
+	s.onFailure(err)
+	return false
+}
+
+func (s *FallbackCacheService) onSuccess(ctx context.Context) {
+	s.mu.Lock()
+	s.failures = 0
+	if s.state != BreakerHalfOpen {
+		s.mu.Unlock()
+		return
+	}
+
+	s.state = BreakerClosed
+	s.lastError = ""
+	dirtyEmployees, dirtyLeaves, dirtyDeps := s.dirtyEmployees, s.dirtyLeaves, s.dirtyDeps
+	s.dirtyEmployees = make(map[uint]struct{})
+	s.dirtyLeaves = make(map[uint]struct{})
+	s.dirtyDeps = make(map[uint]struct{})
+	s.mu.Unlock()
+
+	// 熔斷期間的寫入已同步到數據庫，恢復後以主緩存為準，清空備援緩存
+	s.fallback.Purge()
+	log.Printf("Redis cache recovered, invalidating %d employee and %d leave keys written during outage",
+		len(dirtyEmployees), len(dirtyLeaves))
+	for id := range dirtyDeps {
+		if err := s.primary.InvalidateEmployeeLeaves(ctx, id); err != nil {
+			s.markDepsDirty(id)
+		}
+	}
+	for id := range dirtyEmployees {
+		if err := s.primary.DeleteEmployee(ctx, id); err != nil {
+			s.markEmployeeDirty(id)
+		}
+	}
+	for id := range dirtyLeaves {
+		if err := s.primary.DeleteLeave(ctx, id); err != nil {
+			s.markLeaveDirty(id)
+		}
+	}
+}
+
+func (s *FallbackCacheService) onFailure(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastError = err.Error()
+	switch s.state {
+	case BreakerHalfOpen:
+		// 探測失敗，重新進入冷卻期；備援緩存中的數據仍是最新的，無需清空
+		s.state = BreakerOpen
+		s.openedAt = time.Now()
+		log.Printf("Redis cache probe failed, staying on fallback: %v", err)
+	case BreakerClosed:
+		s.failures++
+		if s.failures >= s.threshold {
+			s.state = BreakerOpen
+			s.openedAt = time.Now()
+			// 熔斷器關閉期間主緩存偶發失敗時寫入的備援數據可能已過期，切換前先清空
+			s.fallback.Purge()
+			log.Printf("Redis cache circuit opened after %d consecutive failures, switching to in-process cache: %v", s.failures, err)
+		}
+	}
+}
+
+func (s *FallbackCacheService) markEmployeeDirty(id uint) {
+	s.mu.Lock()
+	s.dirtyEmployees[id] = struct{}{}
+	s.mu.Unlock()
+}
+
+func (s *FallbackCacheService) markLeaveDirty(id uint) {
+	s.mu.Lock()
+	s.dirtyLeaves[id] = struct{}{}
+	s.mu.Unlock()
+}
+
+func (s *FallbackCacheService) markDepsDirty(employeeID uint) {
+	s.mu.Lock()
+	s.dirtyDeps[employeeID] = struct{}{}
+	s.mu.Unlock()
+}
+
+// 員工緩存操作
+func (s *FallbackCacheService) GetEmployee(ctx context.Context, id uint) (*models.Employee, error) {
+	if s.usePrimary() {
+		employee, err := s.primary.GetEmployee(ctx, id)
+		if s.record(ctx, err) || !s.fallbackActive() {
+			return employee, err
+		}
+	}
+	return s.fallback.GetEmployee(ctx, id)
+}
+
+func (s *FallbackCacheService) SetEmployee(ctx context.Context, employee *models.Employee) error {
+	if s.usePrimary() && s.record(ctx, s.primary.SetEmployee(ctx, employee)) {
+		return nil
+	}
+	s.markEmployeeDirty(employee.ID)
+	return s.fallback.SetEmployee(ctx, employee)
+}
+
+func (s *FallbackCacheService) DeleteEmployee(ctx context.Context, id uint) error {
+	if s.usePrimary() && s.record(ctx, s.primary.DeleteEmployee(ctx, id)) {
+		return nil
+	}
+	s.markEmployeeDirty(id)
+	return s.fallback.DeleteEmployee(ctx, id)
+}
+
+func (s *FallbackCacheService) SetEmployeeNotFound(ctx context.Context, id uint) error {
+	if s.usePrimary() && s.record(ctx, s.primary.SetEmployeeNotFound(ctx, id)) {
+		return nil
+	}
+	s.markEmployeeDirty(id)
+	return s.fallback.SetEmployeeNotFound(ctx, id)
+}
+
+// 請假記錄緩存操作
+func (s *FallbackCacheService) GetLeave(ctx context.Context, id uint) (*models.Leave, error) {
+	if s.usePrimary() {
+		leave, err := s.primary.GetLeave(ctx, id)
+		if s.record(ctx, err) || !s.fallbackActive() {
+			return leave, err
+		}
+	}
+	return s.fallback.GetLeave(ctx, id)
+}
+
+func (s *FallbackCacheService) SetLeave(ctx context.Context, leave *models.Leave) error {
+	if s.usePrimary() && s.record(ctx, s.primary.SetLeave(ctx, leave)) {
+		return nil
+	}
+	s.markLeaveDirty(leave.ID)
+	return s.fallback.SetLeave(ctx, leave)
+}
+
+func (s *FallbackCacheService) DeleteLeave(ctx context.Context, id uint) error {
+	if s.usePrimary() && s.record(ctx, s.primary.DeleteLeave(ctx, id)) {
+		return nil
+	}
+	s.markLeaveDirty(id)
+	return s.fallback.DeleteLeave(ctx, id)
+}
+
+func (s *FallbackCacheService) SetLeaveNotFound(ctx context.Context, id uint) error {
+	if s.usePrimary() && s.record(ctx, s.primary.SetLeaveNotFound(ctx, id)) {
+		return nil
+	}
+	s.markLeaveDirty(id)
+	return s.fallback.SetLeaveNotFound(ctx, id)
+}
+
+func (s *FallbackCacheService) InvalidateEmployeeLeaves(ctx context.Context, employeeID uint) error {
+	if s.usePrimary() && s.record(ctx, s.primary.InvalidateEmployeeLeaves(ctx, employeeID)) {
+		return nil
+	}
+	s.markDepsDirty(employeeID)
+	return s.fallback.InvalidateEmployeeLeaves(ctx, employeeID)
+}
+
+// PrewarmCache 預熱當前生效的緩存後端
+func (s *FallbackCacheService) PrewarmCache(ctx context.Context, employees []models.Employee, leaves []models.Leave) error {
+	if s.usePrimary() && s.record(ctx, s.primary.PrewarmCache(ctx, employees, leaves)) {
+		return nil
+	}
+	return s.fallback.PrewarmCache(ctx, employees, leaves)
+}
+
+// Health 返回當前生效的後端與熔斷器狀態
+func (s *FallbackCacheService) Health(ctx context.Context) CacheHealth {
+	s.mu.Lock()
+	state, lastError := s.state, s.lastError
+	s.mu.Unlock()
+
+	if state == BreakerClosed {
+		health := s.primary.Health(ctx)
+		health.Breaker = state
+		return health
+	}
+
+	health := s.fallback.Health(ctx)
+	health.Healthy = false
+	health.Breaker = state
+	health.LastError = lastError
+	return health
+}
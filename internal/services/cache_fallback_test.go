@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"hr-system/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+var errRedisDown = errors.New("dial tcp: connection refused")
+
+// flakyCache 可模擬故障的主緩存，err 不為空時員工相關操作返回該錯誤
+type flakyCache struct {
+	*MemoryCacheService
+	err     error
+	deleted []uint
+}
+
+func newFlakyCache() *flakyCache {
+	return &flakyCache{MemoryCacheService: NewMemoryCacheService(10)}
+}
+
+func (c *flakyCache) GetEmployee(ctx context.Context, id uint) (*models.Employee, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.MemoryCacheService.GetEmployee(ctx, id)
+}
+
+func (c *flakyCache) SetEmployee(ctx context.Context, employee *models.Employee) error {
+	if c.err != nil {
+		return c.err
+	}
+	return c.MemoryCacheService.SetEmployee(ctx, employee)
+}
+
+func (c *flakyCache) DeleteEmployee(ctx context.Context, id uint) error {
+	if c.err != nil {
+		return c.err
+	}
+	c.deleted = append(c.deleted, id)
+	return c.MemoryCacheService.DeleteEmployee(ctx, id)
+}
+
+func (c *flakyCache) Health(ctx context.Context) CacheHealth {
+	return CacheHealth{Backend: "redis", Healthy: c.err == nil}
+}
+
+func newTestBreaker(primary *flakyCache, threshold int) (*FallbackCacheService, *MemoryCacheService) {
+	fallback := NewMemoryCacheService(10)
+	return NewFallbackCacheService(primary, fallback, threshold, time.Hour, false), fallback
+}
+
+// expireCooldown 讓熔斷器的冷卻期立即結束
+func expireCooldown(s *FallbackCacheService) {
+	s.mu.Lock()
+	s.openedAt = time.Now().Add(-2 * s.cooldown)
+	s.mu.Unlock()
+}
+
+func breakerState(s *FallbackCacheService) string {
+	return s.Health(context.Background()).Breaker
+}
+
+func TestFallbackCacheOpensAfterThreshold(t *testing.T) {
+	ctx := context.Background()
+	primary := newFlakyCache()
+	breaker, fallback := newTestBreaker(primary, 2)
+
+	primary.err = errRedisDown
+	_, err := breaker.GetEmployee(ctx, 1)
+	assert.ErrorIs(t, err, errRedisDown, "熔斷器關閉時應返回主緩存的錯誤由調用方回源")
+	assert.Equal(t, BreakerClosed, breakerState(breaker))
+
+	_, err = breaker.GetEmployee(ctx, 1)
+	assert.ErrorIs(t, err, ErrCacheMiss)
+	assert.Equal(t, BreakerOpen, breakerState(breaker))
+
+	// 熔斷期間讀寫備援緩存，不再調用主緩存
+	primary.err = nil
+	require.NoError(t, breaker.SetEmployee(ctx, &models.Employee{Model: gorm.Model{ID: 1}, Name: "王小明"}))
+	employee, err := breaker.GetEmployee(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "王小明", employee.Name)
+	assert.Equal(t, 0, primary.Len())
+	assert.Equal(t, 1, fallback.Len())
+	assert.False(t, breaker.Health(ctx).Healthy)
+}
+
+func TestFallbackCacheRecovers(t *testing.T) {
+	ctx := context.Background()
+	primary := newFlakyCache()
+	breaker, fallback := newTestBreaker(primary, 1)
+
+	require.NoError(t, primary.SetEmployee(ctx, &models.Employee{Model: gorm.Model{ID: 1}, Name: "舊資料"}))
+	primary.err = errRedisDown
+	_, _ = breaker.GetEmployee(ctx, 1)
+	require.Equal(t, BreakerOpen, breakerState(breaker))
+	require.NoError(t, breaker.SetEmployee(ctx, &models.Employee{Model: gorm.Model{ID: 1}, Name: "新資料"}))
+
+	// 冷卻期結束後探測失敗，重新打開並保留備援數據
+	expireCooldown(breaker)
+	employee, err := breaker.GetEmployee(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "新資料", employee.Name)
+	assert.Equal(t, BreakerOpen, breakerState(breaker))
+	assert.Equal(t, 1, fallback.Len())
+
+	// 探測成功後關閉，刪除熔斷期間寫過的鍵並清空備援緩存
+	primary.err = nil
+	expireCooldown(breaker)
+	_, err = breaker.GetEmployee(ctx, 2)
+	assert.ErrorIs(t, err, ErrCacheMiss)
+	assert.Equal(t, BreakerClosed, breakerState(breaker))
+	assert.Equal(t, []uint{1}, primary.deleted)
+	assert.Equal(t, 0, fallback.Len())
+	_, err = breaker.GetEmployee(ctx, 1)
+	assert.ErrorIs(t, err, ErrCacheMiss)
+}
+
+func TestFallbackCacheHalfOpenUsesFallback(t *testing.T) {
+	ctx := context.Background()
+	primary := newFlakyCache()
+	breaker, _ := newTestBreaker(primary, 1)
+
+	primary.err = errRedisDown
+	_, _ = breaker.GetEmployee(ctx, 1)
+	require.NoError(t, breaker.SetEmployee(ctx, &models.Employee{Model: gorm.Model{ID: 1}, Name: "王小明"}))
+
+	// 冷卻期結束的第一個請求作為探測，探測返回前其餘請求仍走備援緩存
+	expireCooldown(breaker)
+	assert.True(t, breaker.usePrimary())
+	assert.Equal(t, BreakerHalfOpen, breakerState(breaker))
+	employee, err := breaker.GetEmployee(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "王小明", employee.Name)
+}
+
+func TestFallbackCacheNoStaleReadAfterRecovery(t *testing.T) {
+	ctx := context.Background()
+	primary := newFlakyCache()
+	breaker, _ := newTestBreaker(primary, 1)
+
+	// 熔斷期間寫入備援緩存
+	primary.err = errRedisDown
+	_, _ = breaker.GetEmployee(ctx, 1)
+	require.NoError(t, breaker.SetEmployee(ctx, &models.Employee{Model: gorm.Model{ID: 1}, Name: "熔斷期間"}))
+
+	// 恢復後更新到主緩存
+	primary.err = nil
+	expireCooldown(breaker)
+	require.NoError(t, breaker.SetEmployee(ctx, &models.Employee{Model: gorm.Model{ID: 1}, Name: "恢復之後"}))
+	require.Equal(t, BreakerClosed, breakerState(breaker))
+
+	// 之後主緩存偶發失敗，不能讀到熔斷期間的舊數據
+	breaker.threshold = 5
+	primary.err = errRedisDown
+	employee, err := breaker.GetEmployee(ctx, 1)
+	assert.Nil(t, employee)
+	assert.ErrorIs(t, err, errRedisDown)
+	assert.Equal(t, BreakerClosed, breakerState(breaker))
+}
@@ -0,0 +1,224 @@
+package services
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/models"
+)
+
+// MemoryCacheService 進程內 LRU 緩存實現
+// 數據以 JSON 形式保存，避免調用方修改返回的對象而污染緩存
+type MemoryCacheService struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List                     // 最近使用的條目位於隊首
+	deps     map[string]map[string]struct{} // 依賴鍵 -> 依賴它的條目鍵，如員工鍵 -> 請假鍵
+}
+
+type memoryCacheEntry struct {
+	key       string
+	parent    string // 該條目依賴的鍵，為空表示無依賴
+	data      []byte
+	expiresAt time.Time
+}
+
+func NewMemoryCacheService(capacity int) *MemoryCacheService {
+	if capacity <= 0 {
+		capacity = 1
+	}
+	return &MemoryCacheService{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+		deps:     make(map[string]map[string]struct{}),
+	}
+}
+
+func (s *MemoryCacheService) get(key string, dest interface{}) error {
+	s.mu.Lock()
+	elem, ok := s.items[key]
+	if !ok {
+		s.mu.Unlock()
+		return ErrCacheMiss
+	}
+	entry := elem.Value.(*memoryCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		s.removeElement(elem)
+		s.mu.Unlock()
+		return ErrCacheMiss
+	}
+	s.order.MoveToFront(elem)
+	data := entry.data
+	s.mu.Unlock()
+
+	if bytes.Equal(data, notFoundMarker) {
+		return ErrCacheNotFound
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func (s *MemoryCacheService) set(key, parent string, value interface{}, expiration time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	s.setRaw(key, parent, data, expiration)
+	return nil
+}
+
+func (s *MemoryCacheService) setRaw(key, parent string, data []byte, expiration time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.items[key]; ok {
+		s.removeElement(elem)
+	}
+
+	entry := &memoryCacheEntry{
+		key:       key,
+		parent:    parent,
+		data:      data,
+		expiresAt: time.Now().Add(jitteredExpiration(expiration)),
+	}
+	s.items[key] = s.order.PushFront(entry)
+	if parent != "" {
+		if s.deps[parent] == nil {
+			s.deps[parent] = make(map[string]struct{})
+		}
+		s.deps[parent][key] = struct{}{}
+	}
+	// 超出容量時淘汰最久未使用的條目
+	for s.order.Len() > s.capacity {
+		s.removeElement(s.order.Back())
+	}
+}
+
+func (s *MemoryCacheService) delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if elem, ok := s.items[key]; ok {
+		s.removeElement(elem)
+	}
+}
+
+// removeElement 調用方需持有鎖
+func (s *MemoryCacheService) removeElement(elem *list.Element) {
+	entry := elem.Value.(*memoryCacheEntry)
+	s.order.Remove(elem)
+	delete(s.items, entry.key)
+	if children, ok := s.deps[entry.parent]; ok {
+		delete(children, entry.key)
+		if len(children) == 0 {
+			delete(s.deps, entry.parent)
+		}
+	}
+}
+
+// deleteDependents 刪除所有依賴 parent 的條目
+func (s *MemoryCacheService) deleteDependents(parent string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.deps[parent] {
+		if elem, ok := s.items[key]; ok {
+			s.removeElement(elem)
+		}
+	}
+	delete(s.deps, parent)
+}
+
+// Purge 清空所有緩存條目
+func (s *MemoryCacheService) Purge() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = make(map[string]*list.Element)
+	s.deps = make(map[string]map[string]struct{})
+	s.order.Init()
+}
+
+// Len 返回當前條目數
+func (s *MemoryCacheService) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// 員工緩存操作
+func (s *MemoryCacheService) GetEmployee(ctx context.Context, id uint) (*models.Employee, error) {
+	var employee models.Employee
+	if err := s.get(employeeCacheKey(id), &employee); err != nil {
+		return nil, err
+	}
+	return &employee, nil
+}
+
+func (s *MemoryCacheService) SetEmployee(ctx context.Context, employee *models.Employee) error {
+	return s.set(employeeCacheKey(employee.ID), "", employee, config.EmployeeCacheExpiration)
+}
+
+func (s *MemoryCacheService) DeleteEmployee(ctx context.Context, id uint) error {
+	s.delete(employeeCacheKey(id))
+	return nil
+}
+
+func (s *MemoryCacheService) SetEmployeeNotFound(ctx context.Context, id uint) error {
+	s.setRaw(employeeCacheKey(id), "", notFoundMarker, config.NotFoundCacheExpiration)
+	return nil
+}
+
+// 請假記錄緩存操作
+func (s *MemoryCacheService) GetLeave(ctx context.Context, id uint) (*models.Leave, error) {
+	var leave models.Leave
+	if err := s.get(leaveCacheKey(id), &leave); err != nil {
+		return nil, err
+	}
+	return &leave, nil
+}
+
+func (s *MemoryCacheService) SetLeave(ctx context.Context, leave *models.Leave) error {
+	return s.set(leaveCacheKey(leave.ID), employeeCacheKey(leave.EmployeeID), leave, config.LeaveCacheExpiration)
+}
+
+func (s *MemoryCacheService) DeleteLeave(ctx context.Context, id uint) error {
+	s.delete(leaveCacheKey(id))
+	return nil
+}
+
+func (s *MemoryCacheService) SetLeaveNotFound(ctx context.Context, id uint) error {
+	s.setRaw(leaveCacheKey(id), "", notFoundMarker, config.NotFoundCacheExpiration)
+	return nil
+}
+
+func (s *MemoryCacheService) InvalidateEmployeeLeaves(ctx context.Context, employeeID uint) error {
+	s.deleteDependents(employeeCacheKey(employeeID))
+	return nil
+}
+
+// PrewarmCache 預熱緩存
+func (s *MemoryCacheService) PrewarmCache(ctx context.Context, employees []models.Employee, leaves []models.Leave) error {
+	for i := range employees {
+		if err := s.SetEmployee(ctx, &employees[i]); err != nil {
+			log.Printf("Failed to marshal employee data: %v", err)
+		}
+	}
+	for i := range leaves {
+		if err := s.SetLeave(ctx, &leaves[i]); err != nil {
+			log.Printf("Failed to marshal leave data: %v", err)
+		}
+	}
+
+	log.Printf("Successfully prewarmed memory cache with %d employees and %d leaves", len(employees), len(leaves))
+	return nil
+}
+
+// Health 進程內緩存始終可用
+func (s *MemoryCacheService) Health(ctx context.Context) CacheHealth {
+	return CacheHealth{Backend: config.CacheBackendMemory, Healthy: true}
+}
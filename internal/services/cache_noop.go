@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+
+	"hr-system/config"
+	"hr-system/internal/models"
+)
+
+// NoopCacheService 不緩存任何數據，所有讀取均視為未命中
+type NoopCacheService struct{}
+
+func NewNoopCacheService() *NoopCacheService {
+	return &NoopCacheService{}
+}
+
+func (s *NoopCacheService) GetEmployee(ctx context.Context, id uint) (*models.Employee, error) {
+	return nil, ErrCacheMiss
+}
+
+func (s *NoopCacheService) SetEmployee(ctx context.Context, employee *models.Employee) error {
+	return nil
+}
+
+func (s *NoopCacheService) DeleteEmployee(ctx context.Context, id uint) error {
+	return nil
+}
+
+func (s *NoopCacheService) SetEmployeeNotFound(ctx context.Context, id uint) error {
+	return nil
+}
+
+func (s *NoopCacheService) GetLeave(ctx context.Context, id uint) (*models.Leave, error) {
+	return nil, ErrCacheMiss
+}
+
+func (s *NoopCacheService) SetLeave(ctx context.Context, leave *models.Leave) error {
+	return nil
+}
+
+func (s *NoopCacheService) DeleteLeave(ctx context.Context, id uint) error {
+	return nil
+}
+
+func (s *NoopCacheService) SetLeaveNotFound(ctx context.Context, id uint) error {
+	return nil
+}
+
+func (s *NoopCacheService) InvalidateEmployeeLeaves(ctx context.Context, employeeID uint) error {
+	return nil
+}
+
+func (s *NoopCacheService) PrewarmCache(ctx context.Context, employees []models.Employee, leaves []models.Leave) error {
+	return nil
+}
+
+func (s *NoopCacheService) Health(ctx context.Context) CacheHealth {
+	return CacheHealth{Backend: config.CacheBackendNone, Healthy: true}
+}
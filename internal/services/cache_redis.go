@@ -0,0 +1,171 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/models"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisCacheService 基於 Redis 的緩存實現
+// 每條請假緩存的鍵會同時加入所屬員工的依賴集合（employee_leaves:{id}），員工變更時據此批量失效
+type RedisCacheService struct {
+	client *redis.Client
+}
+
+// employeeLeavesExpiration 依賴集合的過期時間，需長於其中任何一條（帶抖動的）請假緩存
+const employeeLeavesExpiration = 2 * config.LeaveCacheExpiration
+
+// invalidateDependentsScript 原子地刪除依賴集合中的所有鍵以及集合本身
+var invalidateDependentsScript = redis.NewScript(`
+local members = redis.call('SMEMBERS', KEYS[1])
+for _, key in ipairs(members) do
+	redis.call('DEL', key)
+end
+redis.call('DEL', KEYS[1])
+return #members
+`)
+
+func NewRedisCacheService(client *redis.Client) *RedisCacheService {
+	return &RedisCacheService{client: client}
+}
+
+// get 讀取並反序列化緩存
+// 鍵不存在（redis.Nil）時返回 ErrCacheMiss，命中負緩存時返回 ErrCacheNotFound，其餘為 Redis 本身的錯誤
+func (s *RedisCacheService) get(ctx context.Context, key string, dest interface{}) error {
+	data, err := s.client.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return ErrCacheMiss
+	}
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(data, notFoundMarker) {
+		return ErrCacheNotFound
+	}
+	return json.Unmarshal(data, dest)
+}
+
+func (s *RedisCacheService) set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, key, data, jitteredExpiration(expiration)).Err()
+}
+
+// 員工緩存操作
+func (s *RedisCacheService) GetEmployee(ctx context.Context, id uint) (*models.Employee, error) {
+	var employee models.Employee
+	if err := s.get(ctx, employeeCacheKey(id), &employee); err != nil {
+		return nil, err
+	}
+	return &employee, nil
+}
+
+func (s *RedisCacheService) SetEmployee(ctx context.Context, employee *models.Employee) error {
+	return s.set(ctx, employeeCacheKey(employee.ID), employee, config.EmployeeCacheExpiration)
+}
+
+func (s *RedisCacheService) DeleteEmployee(ctx context.Context, id uint) error {
+	return s.client.Del(ctx, employeeCacheKey(id)).Err()
+}
+
+func (s *RedisCacheService) SetEmployeeNotFound(ctx context.Context, id uint) error {
+	return s.client.Set(ctx, employeeCacheKey(id), notFoundMarker, jitteredExpiration(config.NotFoundCacheExpiration)).Err()
+}
+
+// 請假記錄緩存操作
+func (s *RedisCacheService) GetLeave(ctx context.Context, id uint) (*models.Leave, error) {
+	var leave models.Leave
+	if err := s.get(ctx, leaveCacheKey(id), &leave); err != nil {
+		return nil, err
+	}
+	return &leave, nil
+}
+
+func (s *RedisCacheService) SetLeave(ctx context.Context, leave *models.Leave) error {
+	data, err := json.Marshal(leave)
+	if err != nil {
+		return err
+	}
+
+	pipeline := s.client.TxPipeline()
+	queueLeave(ctx, pipeline, leave, data)
+	_, err = pipeline.Exec(ctx)
+	return err
+}
+
+// queueLeave 寫入請假緩存並登記到所屬員工的依賴集合
+func queueLeave(ctx context.Context, pipeline redis.Pipeliner, leave *models.Leave, data []byte) {
+	key := leaveCacheKey(leave.ID)
+	depKey := employeeLeavesKey(leave.EmployeeID)
+	pipeline.Set(ctx, key, data, jitteredExpiration(config.LeaveCacheExpiration))
+	pipeline.SAdd(ctx, depKey, key)
+	pipeline.Expire(ctx, depKey, employeeLeavesExpiration)
+}
+
+func (s *RedisCacheService) DeleteLeave(ctx context.Context, id uint) error {
+	return s.client.Del(ctx, leaveCacheKey(id)).Err()
+}
+
+func (s *RedisCacheService) SetLeaveNotFound(ctx context.Context, id uint) error {
+	return s.client.Set(ctx, leaveCacheKey(id), notFoundMarker, jitteredExpiration(config.NotFoundCacheExpiration)).Err()
+}
+
+func (s *RedisCacheService) InvalidateEmployeeLeaves(ctx context.Context, employeeID uint) error {
+	return invalidateDependentsScript.Run(ctx, s.client, []string{employeeLeavesKey(employeeID)}).Err()
+}
+
+// PrewarmCache 預熱緩存
+func (s *RedisCacheService) PrewarmCache(ctx context.Context, employees []models.Employee, leaves []models.Leave) error {
+	// 使用管道批量寫入緩存
+	pipeline := s.client.Pipeline()
+
+	// 預熱員工數據
+	for _, employee := range employees {
+		data, err := json.Marshal(employee)
+		if err != nil {
+			log.Printf("Failed to marshal employee data: %v", err)
+			continue
+		}
+		pipeline.Set(ctx, employeeCacheKey(employee.ID), data, jitteredExpiration(config.EmployeeCacheExpiration))
+	}
+
+	// 預熱請假記錄數據
+	for _, leave := range leaves {
+		data, err := json.Marshal(leave)
+		if err != nil {
+			log.Printf("Failed to marshal leave data: %v", err)
+			continue
+		}
+		queueLeave(ctx, pipeline, &leave, data)
+	}
+
+	// 執行管道命令
+	_, err := pipeline.Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to execute pipeline: %v", err)
+	}
+
+	log.Printf("Successfully prewarmed cache with %d employees and %d leaves", len(employees), len(leaves))
+	return nil
+}
+
+// Health 通過 PING 檢查 Redis 是否可用
+func (s *RedisCacheService) Health(ctx context.Context) CacheHealth {
+	health := CacheHealth{Backend: config.CacheBackendRedis, Healthy: true}
+	if err := s.client.Ping(ctx).Err(); err != nil {
+		health.Healthy = false
+		health.LastError = err.Error()
+	}
+	return health
+}
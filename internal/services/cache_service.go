@@ -2,115 +2,93 @@ package services
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"math/rand"
+	"time"
 
 	"hr-system/config"
 	"hr-system/internal/models"
 )
 
-type CacheService struct{}
+// ErrCacheMiss 緩存中不存在對應的數據
+var ErrCacheMiss = errors.New("cache miss")
 
-func NewCacheService() *CacheService {
-	return &CacheService{}
-}
+// ErrCacheNotFound 緩存中記錄了該數據在數據庫中不存在（負緩存）
+var ErrCacheNotFound = errors.New("cache: record not found")
 
-// 員工緩存操作
-func (s *CacheService) GetEmployee(ctx context.Context, id uint) (*models.Employee, error) {
-	key := fmt.Sprintf("%s%d", config.EmployeeKeyPrefix, id)
-	data, err := config.RedisClient.Get(ctx, key).Bytes()
-	if err != nil {
-		return nil, err
-	}
+// notFoundMarker 負緩存條目的值
+var notFoundMarker = []byte("__not_found__")
 
-	var employee models.Employee
-	if err := json.Unmarshal(data, &employee); err != nil {
-		return nil, err
-	}
+// CacheService 定義緩存服務接口
+// 目前有 Redis、進程內 LRU 與 no-op 三種實現，另有帶熔斷器的備援實現組合前兩者
+type CacheService interface {
+	GetEmployee(ctx context.Context, id uint) (*models.Employee, error)
+	SetEmployee(ctx context.Context, employee *models.Employee) error
+	DeleteEmployee(ctx context.Context, id uint) error
+	// SetEmployeeNotFound 寫入負緩存，之後的 GetEmployee 在過期前返回 ErrCacheNotFound
+	SetEmployeeNotFound(ctx context.Context, id uint) error
 
-	return &employee, nil
-}
+	GetLeave(ctx context.Context, id uint) (*models.Leave, error)
+	SetLeave(ctx context.Context, leave *models.Leave) error
+	DeleteLeave(ctx context.Context, id uint) error
+	// SetLeaveNotFound 寫入負緩存，之後的 GetLeave 在過期前返回 ErrCacheNotFound
+	SetLeaveNotFound(ctx context.Context, id uint) error
 
-func (s *CacheService) SetEmployee(ctx context.Context, employee *models.Employee) error {
-	key := fmt.Sprintf("%s%d", config.EmployeeKeyPrefix, employee.ID)
-	data, err := json.Marshal(employee)
-	if err != nil {
-		return err
-	}
+	// InvalidateEmployeeLeaves 刪除所有內嵌了該員工數據的請假緩存
+	// 請假緩存會連同預加載的 Employee 一起保存，員工資料變更後需要調用此方法
+	InvalidateEmployeeLeaves(ctx context.Context, employeeID uint) error
 
-	return config.RedisClient.Set(ctx, key, data, config.EmployeeCacheExpiration).Err()
-}
+	PrewarmCache(ctx context.Context, employees []models.Employee, leaves []models.Leave) error
 
-func (s *CacheService) DeleteEmployee(ctx context.Context, id uint) error {
-	key := fmt.Sprintf("%s%d", config.EmployeeKeyPrefix, id)
-	return config.RedisClient.Del(ctx, key).Err()
+	// Health 返回緩存後端的健康狀態
+	Health(ctx context.Context) CacheHealth
 }
 
-// 請假記錄緩存操作
-func (s *CacheService) GetLeave(ctx context.Context, id uint) (*models.Leave, error) {
-	key := fmt.Sprintf("%s%d", config.LeaveKeyPrefix, id)
-	data, err := config.RedisClient.Get(ctx, key).Bytes()
-	if err != nil {
-		return nil, err
-	}
+// CacheHealth 緩存健康狀態
+type CacheHealth struct {
+	Backend   string `json:"backend"`              // 當前實際服務請求的後端
+	Healthy   bool   `json:"healthy"`              // 後端是否可用
+	Breaker   string `json:"breaker,omitempty"`    // 熔斷器狀態（closed/open/half-open）
+	LastError string `json:"last_error,omitempty"` // 最近一次錯誤
+}
 
-	var leave models.Leave
-	if err := json.Unmarshal(data, &leave); err != nil {
-		return nil, err
+// NewCacheService 根據配置創建緩存服務
+// redisAvailable 表示啟動時 Redis 是否連接成功，不可用時熔斷器直接處於打開狀態
+func NewCacheService(cfg config.CacheConfig, redisAvailable bool) CacheService {
+	switch cfg.Backend {
+	case config.CacheBackendMemory:
+		return NewMemoryCacheService(cfg.LRUCapacity)
+	case config.CacheBackendNone:
+		return NewNoopCacheService()
+	default:
+		return NewFallbackCacheService(
+			NewRedisCacheService(config.RedisClient),
+			NewMemoryCacheService(cfg.LRUCapacity),
+			cfg.BreakerThreshold,
+			cfg.BreakerCooldown,
+			!redisAvailable,
+		)
 	}
-
-	return &leave, nil
 }
 
-func (s *CacheService) SetLeave(ctx context.Context, leave *models.Leave) error {
-	key := fmt.Sprintf("%s%d", config.LeaveKeyPrefix, leave.ID)
-	data, err := json.Marshal(leave)
-	if err != nil {
-		return err
+// jitteredExpiration 在基礎過期時間上加入隨機抖動
+func jitteredExpiration(base time.Duration) time.Duration {
+	spread := int64(float64(base) * config.CacheExpirationJitter)
+	if spread <= 0 {
+		return base
 	}
-
-	return config.RedisClient.Set(ctx, key, data, config.LeaveCacheExpiration).Err()
+	return base - time.Duration(spread) + time.Duration(rand.Int63n(2*spread+1))
 }
 
-func (s *CacheService) DeleteLeave(ctx context.Context, id uint) error {
-	key := fmt.Sprintf("%s%d", config.LeaveKeyPrefix, id)
-	return config.RedisClient.Del(ctx, key).Err()
+func employeeCacheKey(id uint) string {
+	return fmt.Sprintf("%s%d", config.EmployeeKeyPrefix, id)
 }
 
-// PrewarmCache 預熱緩存
-func (s *CacheService) PrewarmCache(ctx context.Context, employees []models.Employee, leaves []models.Leave) error {
-	// 使用管道批量寫入緩存
-	pipeline := config.RedisClient.Pipeline()
-
-	// 預熱員工數據
-	for _, employee := range employees {
-		key := fmt.Sprintf("%s%d", config.EmployeeKeyPrefix, employee.ID)
-		data, err := json.Marshal(employee)
-		if err != nil {
-			log.Printf("Failed to marshal employee data: %v", err)
-			continue
-		}
-		pipeline.Set(ctx, key, data, config.EmployeeCacheExpiration)
-	}
-
-	// 預熱請假記錄數據
-	for _, leave := range leaves {
-		key := fmt.Sprintf("%s%d", config.LeaveKeyPrefix, leave.ID)
-		data, err := json.Marshal(leave)
-		if err != nil {
-			log.Printf("Failed to marshal leave data: %v", err)
-			continue
-		}
-		pipeline.Set(ctx, key, data, config.LeaveCacheExpiration)
-	}
-
-	// 執行管道命令
-	_, err := pipeline.Exec(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to execute pipeline: %v", err)
-	}
+func leaveCacheKey(id uint) string {
+	return fmt.Sprintf("%s%d", config.LeaveKeyPrefix, id)
+}
 
-	log.Printf("Successfully prewarmed cache with %d employees and %d leaves", len(employees), len(leaves))
-	return nil
+func employeeLeavesKey(employeeID uint) string {
+	return fmt.Sprintf("%s%d", config.EmployeeLeavesKeyPrefix, employeeID)
 }
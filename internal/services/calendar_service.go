@@ -0,0 +1,265 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/i18n"
+	"hr-system/internal/ical"
+	"hr-system/internal/models"
+	"hr-system/internal/repositories"
+)
+
+// calendarProdID iCalendar 訂閱的產生者標識
+const calendarProdID = "-//HR System//Leave Calendar//EN"
+
+// calendarStatuses 行事曆顯示的請假狀態
+var calendarStatuses = []string{models.LeaveStatusApproved, models.LeaveStatusPending}
+
+// CalendarQuery 團隊行事曆的查詢條件，Department 與 ManagerID 只能指定其一
+// From 與 To 為當地時間的日期，兩天都包含在內
+type CalendarQuery struct {
+	Department string
+	ManagerID  *uint // 主管本人及其所有直接與間接下屬
+	From       time.Time
+	To         time.Time
+}
+
+// CalendarDay 行事曆中的一天
+type CalendarDay struct {
+	Date    time.Time
+	Holiday string         // 當天的假日名稱，不是假日時為空
+	Leaves  []models.Leave // 當天請假中的已核准與待審批請假
+}
+
+// CalendarFeedSubscription 新建的行事曆訂閱，連結中的令牌只在創建時返回
+type CalendarFeedSubscription struct {
+	models.CalendarFeed
+	URL string `json:"url"`
+}
+
+// CalendarService 提供團隊請假行事曆、iCalendar 訂閱與公司假日
+type CalendarService struct {
+	leaveRepo    *repositories.LeaveRepository
+	employeeRepo *repositories.EmployeeRepository
+	holidayRepo  *repositories.HolidayRepository
+	feedRepo     *repositories.CalendarFeedRepository
+	cfg          config.CalendarConfig
+}
+
+func NewCalendarService(leaveRepo *repositories.LeaveRepository, employeeRepo *repositories.EmployeeRepository, holidayRepo *repositories.HolidayRepository, feedRepo *repositories.CalendarFeedRepository, cfg config.CalendarConfig) *CalendarService {
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	return &CalendarService{
+		leaveRepo:    leaveRepo,
+		employeeRepo: employeeRepo,
+		holidayRepo:  holidayRepo,
+		feedRepo:     feedRepo,
+		cfg:          cfg,
+	}
+}
+
+// TeamCalendar 按天列出部門或主管團隊在期間內的請假與假日
+func (s *CalendarService) TeamCalendar(query CalendarQuery) ([]CalendarDay, error) {
+	if (query.Department == "") == (query.ManagerID == nil) {
+		return nil, apperrors.Validation(apperrors.CodeInvalidCalendarScope, "Specify exactly one of department or manager_id")
+	}
+	from, to := startOfDay(query.From), startOfDay(query.To).AddDate(0, 0, 1)
+	if !to.After(from) {
+		return nil, apperrors.Validation(apperrors.CodeInvalidDateRange, "Start date must be before end date",
+			apperrors.Field("to", apperrors.CodeInvalidDateRange, "Start date must be before end date"))
+	}
+	if to.Sub(from) > s.cfg.MaxRange {
+		return nil, apperrors.Validation(apperrors.CodeCalendarRangeTooLong,
+			fmt.Sprintf("The date range must not exceed %d days", int(s.cfg.MaxRange.Hours()/24)))
+	}
+
+	var members []models.Employee
+	var err error
+	if query.ManagerID != nil {
+		members, err = teamMembers(s.employeeRepo, *query.ManagerID)
+	} else {
+		members, err = s.employeeRepo.ListByDepartment(query.Department)
+	}
+	if err != nil {
+		return nil, err
+	}
+	leaves, err := s.memberLeaves(members, from, to)
+	if err != nil {
+		return nil, err
+	}
+	holidays, err := s.holidayRepo.ListBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	holidayNames := make(map[string]string, len(holidays))
+	for _, holiday := range holidays {
+		holidayNames[holiday.Date.Format("2006-01-02")] = holiday.Name
+	}
+	days := make([]CalendarDay, 0)
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		entry := CalendarDay{Date: day, Holiday: holidayNames[day.Format("2006-01-02")], Leaves: []models.Leave{}}
+		next := day.AddDate(0, 0, 1)
+		for _, leave := range leaves {
+			if leave.StartDate.Before(next) && !startOfDay(leave.EndDate).Before(day) {
+				entry.Leaves = append(entry.Leaves, leave)
+			}
+		}
+		days = append(days, entry)
+	}
+	return days, nil
+}
+
+// memberLeaves 獲取員工在 [from, to) 內的已核准與待審批請假
+func (s *CalendarService) memberLeaves(members []models.Employee, from, to time.Time) ([]models.Leave, error) {
+	if len(members) == 0 {
+		return nil, nil
+	}
+	ids := make([]uint, 0, len(members))
+	for _, member := range members {
+		ids = append(ids, member.ID)
+	}
+	return s.leaveRepo.ListOverlapping(ids, calendarStatuses, from, to)
+}
+
+// CreateFeed 為員工創建 iCalendar 訂閱
+func (s *CalendarService) CreateFeed(ownerID uint, scope string) (*CalendarFeedSubscription, error) {
+	if _, err := s.employeeRepo.GetByID(ownerID); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	token := hex.EncodeToString(buf)
+
+	feed := &models.CalendarFeed{OwnerID: ownerID, Scope: scope, TokenHash: hashFeedToken(token)}
+	if err := s.feedRepo.Create(feed); err != nil {
+		return nil, err
+	}
+	return &CalendarFeedSubscription{CalendarFeed: *feed, URL: s.cfg.BaseURL + "/calendar/feeds/" + token + ".ics"}, nil
+}
+
+// ListFeeds 獲取員工的 iCalendar 訂閱，不包含令牌
+func (s *CalendarService) ListFeeds(ownerID uint) ([]models.CalendarFeed, error) {
+	return s.feedRepo.ListByOwner(ownerID)
+}
+
+// DeleteFeed 撤銷員工的 iCalendar 訂閱，已訂閱的日曆客戶端將無法再讀取
+func (s *CalendarService) DeleteFeed(ownerID, id uint) error {
+	return s.feedRepo.Delete(ownerID, id)
+}
+
+// RenderFeed 以令牌讀取訂閱，生成訂閱範圍內近期請假與假日的 iCalendar 內容
+func (s *CalendarService) RenderFeed(token string) ([]byte, error) {
+	feed, err := s.feedRepo.GetByTokenHash(hashFeedToken(token))
+	if err != nil {
+		return nil, err
+	}
+	owner, err := s.employeeRepo.GetByID(feed.OwnerID)
+	if apperrors.IsNotFound(err) {
+		return nil, apperrors.NotFound(apperrors.CodeCalendarFeedNotFound, "Calendar feed not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	locale := i18n.Preferred(owner.Locale)
+	members := []models.Employee{*owner}
+	name := i18n.T(locale, "calendar.feed_name.self")
+	switch {
+	case feed.Scope == models.CalendarFeedScopeTeam:
+		if members, err = teamMembers(s.employeeRepo, owner.ID); err != nil {
+			return nil, err
+		}
+		name = i18n.Tf(locale, "calendar.feed_name.team", i18n.Vars{"name": owner.Name})
+	case feed.Scope == models.CalendarFeedScopeDepartment && owner.Department != "":
+		if members, err = s.employeeRepo.ListByDepartment(owner.Department); err != nil {
+			return nil, err
+		}
+		name = i18n.Tf(locale, "calendar.feed_name.department",
+			i18n.Vars{"department": i18n.Label(locale, "department", owner.Department)})
+	}
+
+	now := time.Now()
+	from, to := startOfDay(now.Add(-s.cfg.FeedPast)), startOfDay(now.Add(s.cfg.FeedFuture))
+	leaves, err := s.memberLeaves(members, from, to)
+	if err != nil {
+		return nil, err
+	}
+	holidays, err := s.holidayRepo.ListBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	cal := &ical.Calendar{ProdID: calendarProdID, Name: name, Events: make([]ical.Event, 0, len(leaves)+len(holidays))}
+	for _, holiday := range holidays {
+		cal.Events = append(cal.Events, ical.Event{
+			UID:     fmt.Sprintf("holiday-%d@hr-system", holiday.ID),
+			Stamp:   holiday.CreatedAt,
+			Start:   holiday.Date,
+			End:     holiday.Date,
+			Summary: holiday.Name,
+			Free:    true,
+		})
+	}
+	for _, leave := range leaves {
+		key := "calendar.leave_summary"
+		if leave.Status == models.LeaveStatusPending {
+			key = "calendar.pending_leave_summary"
+		}
+		cal.Events = append(cal.Events, ical.Event{
+			UID:   fmt.Sprintf("leave-%d@hr-system", leave.ID),
+			Stamp: leave.UpdatedAt,
+			Start: leave.StartDate,
+			End:   leave.EndDate,
+			Summary: i18n.Tf(locale, key, i18n.Vars{
+				"employee":   leave.Employee.Name,
+				"leave_type": i18n.Label(locale, "leave_type", leave.LeaveType),
+			}),
+			Tentative: leave.Status == models.LeaveStatusPending,
+			// 他人的請假不佔用訂閱者的忙碌時間
+			Free: leave.EmployeeID != owner.ID,
+		})
+	}
+
+	// 記錄讀取時間失敗不影響訂閱內容
+	if err := s.feedRepo.Touch(feed.ID, now); err != nil {
+		log.Printf("Failed to record calendar feed %d access: %v", feed.ID, err)
+	}
+	return ical.Encode(cal), nil
+}
+
+// CreateHoliday 創建公司假日，同一日期只能有一個假日
+func (s *CalendarService) CreateHoliday(holiday *models.Holiday) error {
+	holiday.Date = startOfDay(holiday.Date)
+	return s.holidayRepo.Create(holiday)
+}
+
+// ListHolidays 獲取 from 至 to（包含）內的公司假日
+func (s *CalendarService) ListHolidays(from, to time.Time) ([]models.Holiday, error) {
+	return s.holidayRepo.ListBetween(startOfDay(from), startOfDay(to).AddDate(0, 0, 1))
+}
+
+// DeleteHoliday 刪除公司假日
+func (s *CalendarService) DeleteHoliday(id uint) error {
+	return s.holidayRepo.Delete(id)
+}
+
+// hashFeedToken 計算訂閱令牌的雜湊，數據庫中只保存雜湊
+func hashFeedToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// startOfDay 返回 t 當天的零點
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
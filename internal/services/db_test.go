@@ -0,0 +1,107 @@
+package services
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/models"
+	"hr-system/internal/repositories"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// setupTestDB 為每個測試創建獨立的 SQLite 數據庫並替換 config.DB，測試結束後恢復
+// 使用 WAL 模式讓事務外的讀取不被事務內的寫入阻塞，與 MySQL 的行為一致
+func setupTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := filepath.Join(t.TempDir(), "hr.db") + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_pragma=foreign_keys(0)"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	require.NoError(t, err)
+	require.NoError(t, config.AutoMigrate(db))
+
+	previous := config.DB
+	config.DB = db
+	t.Cleanup(func() {
+		config.DB = previous
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+// seedEmployee 創建測試員工，未指定入職日期時為 2020-01-01
+func seedEmployee(t *testing.T, db *gorm.DB, employee models.Employee) *models.Employee {
+	t.Helper()
+	if employee.Email == "" {
+		employee.Email = employee.Name + "@example.com"
+	}
+	if employee.Status == "" {
+		employee.Status = "active"
+	}
+	if employee.HireDate.IsZero() {
+		employee.HireDate = time.Date(2020, 1, 1, 0, 0, 0, 0, time.Local)
+	}
+	require.NoError(t, db.Create(&employee).Error)
+	return &employee
+}
+
+// date 返回本地時區某天的零點
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.Local)
+}
+
+// seedLeave 創建已核准的請假
+func seedLeave(t *testing.T, db *gorm.DB, employeeID uint, leaveType string, start, end time.Time) *models.Leave {
+	t.Helper()
+	leave := models.Leave{EmployeeID: employeeID, LeaveType: leaveType, StartDate: start, EndDate: end, Status: models.LeaveStatusApproved}
+	require.NoError(t, db.Create(&leave).Error)
+	return &leave
+}
+
+// seedHoliday 創建公司假日
+func seedHoliday(t *testing.T, db *gorm.DB, day time.Time, name string) {
+	t.Helper()
+	require.NoError(t, db.Create(&models.Holiday{Date: day, Name: name}).Error)
+}
+
+// newTestScheduleService 創建沒有排班時週一至週五 09:00-18:00 上班的排班服務
+func newTestScheduleService() *ScheduleService {
+	employeeRepo := repositories.NewEmployeeRepository()
+	approvals := NewApprovalService(repositories.NewDelegationRepository(), employeeRepo, repositories.NewLeaveRepository())
+	return NewScheduleService(repositories.NewScheduleRepository(), employeeRepo, repositories.NewHolidayRepository(), approvals,
+		config.ScheduleConfig{DefaultStart: 9 * time.Hour, DefaultEnd: 18 * time.Hour, MaxRange: 92 * 24 * time.Hour})
+}
+
+// seedRoster 為員工排定固定班表，days 為上班的星期（週一為 0），每天 09:00-18:00
+func seedRoster(t *testing.T, db *gorm.DB, employeeID uint, name string, days ...int) {
+	t.Helper()
+	template := models.WorkScheduleTemplate{Name: name, Kind: models.ScheduleKindFixed, CycleDays: 7, SkipHolidays: true}
+	for _, day := range days {
+		template.Shifts = append(template.Shifts, models.ScheduleShift{DayIndex: day, Start: "09:00", End: "18:00"})
+	}
+	require.NoError(t, db.Create(&template).Error)
+	require.NoError(t, db.Create(&models.RosterAssignment{EmployeeID: employeeID, TemplateID: template.ID, StartDate: date(2020, 1, 1)}).Error)
+}
+
+// newTestInsuranceService 創建使用 2024 年默認費率的勞健保服務
+func newTestInsuranceService() *InsuranceService {
+	return NewInsuranceService(repositories.NewInsuranceRepository(), repositories.NewPayrollRepository(), repositories.NewEmployeeRepository(),
+		config.InsuranceConfig{
+			LaborRate:           0.125,
+			LaborEmployeeShare:  0.2,
+			LaborEmployerShare:  0.7,
+			OccupationalRate:    0.0021,
+			HealthRate:          0.0517,
+			HealthEmployeeShare: 0.3,
+			HealthEmployerShare: 0.6,
+			HealthAvgDependents: 0.57,
+			HealthMaxDependents: 3,
+			PensionEmployerRate: 0.06,
+		})
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// DistributedLock 定義分佈式鎖接口，用於保證多副本部署時某項任務只由一個副本執行
+type DistributedLock interface {
+	// TryLock 嘗試獲取鎖，獲取成功時返回釋放函數；鎖被其他持有者佔用時返回 ok=false
+	TryLock(ctx context.Context, name string, ttl time.Duration) (unlock func(), ok bool, err error)
+}
+
+// RedisLock 基於 Redis SET NX 的分佈式鎖
+// 鎖的值為隨機令牌，釋放時校驗令牌，避免鎖過期後誤刪其他副本持有的鎖
+type RedisLock struct {
+	client *redis.Client
+}
+
+func NewRedisLock(client *redis.Client) *RedisLock {
+	return &RedisLock{client: client}
+}
+
+var releaseLockScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+func (l *RedisLock) TryLock(ctx context.Context, name string, ttl time.Duration) (func(), bool, error) {
+	token, err := newLockToken()
+	if err != nil {
+		return nil, false, err
+	}
+
+	key := "lock:" + name
+	ok, err := l.client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil || !ok {
+		return nil, false, err
+	}
+
+	unlock := func() {
+		// 使用獨立的 context，避免調用方 context 已取消導致鎖無法釋放
+		releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		releaseLockScript.Run(releaseCtx, l.client, []string{key}, token)
+	}
+	return unlock, true, nil
+}
+
+func newLockToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// LocalLock 進程內的鎖，用於沒有 Redis 的單副本部署
+type LocalLock struct {
+	mu   sync.Mutex
+	held map[string]time.Time
+}
+
+func NewLocalLock() *LocalLock {
+	return &LocalLock{held: make(map[string]time.Time)}
+}
+
+func (l *LocalLock) TryLock(ctx context.Context, name string, ttl time.Duration) (func(), bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if expiresAt, ok := l.held[name]; ok && time.Now().Before(expiresAt) {
+		return nil, false, nil
+	}
+	expiresAt := time.Now().Add(ttl)
+	l.held[name] = expiresAt
+
+	unlock := func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if l.held[name] == expiresAt {
+			delete(l.held, name)
+		}
+	}
+	return unlock, true, nil
+}
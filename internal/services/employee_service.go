@@ -4,20 +4,28 @@ import (
 	"context"
 	"errors"
 	"log"
+	"time"
 
+	"hr-system/internal/apperrors"
 	"hr-system/internal/models"
 	"hr-system/internal/repositories"
+
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
 )
 
 type EmployeeService struct {
 	employeeRepo *repositories.EmployeeRepository
-	cacheService *CacheService
+	cacheService CacheService
+	outboxRepo   *repositories.OutboxRepository
+	loadGroup    singleflight.Group // 合併同一ID的並發回源請求
 }
 
-func NewEmployeeService(employeeRepo *repositories.EmployeeRepository, cacheService *CacheService) *EmployeeService {
+func NewEmployeeService(employeeRepo *repositories.EmployeeRepository, cacheService CacheService, outboxRepo *repositories.OutboxRepository) *EmployeeService {
 	return &EmployeeService{
 		employeeRepo: employeeRepo,
 		cacheService: cacheService,
+		outboxRepo:   outboxRepo,
 	}
 }
 
@@ -26,10 +34,26 @@ func (s *EmployeeService) CreateEmployee(employee *models.Employee) error {
 	// 檢查郵箱是否已存在
 	existingEmployee, err := s.employeeRepo.GetByEmail(employee.Email)
 	if err == nil && existingEmployee != nil {
-		return errors.New("email already exists")
+		return apperrors.Conflict(apperrors.CodeEmailAlreadyExists, "Email already exists")
+	}
+	if err != nil && !apperrors.IsNotFound(err) {
+		return err
+	}
+	if err := s.validateManager(0, employee.ManagerID); err != nil {
+		return err
+	}
+	if err := validateTermination(employee, nil); err != nil {
+		return err
 	}
 
-	if err := s.employeeRepo.Create(employee); err != nil {
+	// 員工與事件在同一事務中寫入
+	err = repositories.Transaction(func(tx *gorm.DB) error {
+		if err := s.employeeRepo.WithTx(tx).Create(employee); err != nil {
+			return err
+		}
+		return appendEvent(s.outboxRepo.WithTx(tx), NewEvent(models.EventEmployeeHired, AggregateEmployee, employee.ID, employee))
+	})
+	if err != nil {
 		return err
 	}
 
@@ -49,22 +73,45 @@ func (s *EmployeeService) GetEmployee(id uint) (*models.Employee, error) {
 
 	// 先從緩存獲取
 	employee, err := s.cacheService.GetEmployee(ctx, id)
-	if err == nil {
+	switch {
+	case err == nil:
 		return employee, nil
+	case errors.Is(err, ErrCacheNotFound):
+		// 命中負緩存，該員工不存在
+		return nil, apperrors.NotFound(apperrors.CodeEmployeeNotFound, "Employee not found")
+	case !errors.Is(err, ErrCacheMiss):
+		// 緩存本身出錯，記錄日誌後回源，但不再回寫緩存
+		log.Printf("Failed to read employee cache: %v", err)
 	}
+	writeBack := errors.Is(err, ErrCacheMiss)
+
+	// 緩存未命中，從數據庫獲取；同一ID的並發請求只回源一次
+	result, err, _ := s.loadGroup.Do(employeeCacheKey(id), func() (interface{}, error) {
+		employee, err := s.employeeRepo.GetByID(id)
+		if apperrors.IsNotFound(err) && writeBack {
+			if err := s.cacheService.SetEmployeeNotFound(ctx, id); err != nil {
+				log.Printf("Failed to cache missing employee: %v", err)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
 
-	// 緩存未命中，從數據庫獲取
-	employee, err = s.employeeRepo.GetByID(id)
+		// 添加到緩存
+		if writeBack {
+			if err := s.cacheService.SetEmployee(ctx, employee); err != nil {
+				log.Printf("Failed to cache employee: %v", err)
+			}
+		}
+		return employee, nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// 添加到緩存
-	if err := s.cacheService.SetEmployee(ctx, employee); err != nil {
-		log.Printf("Failed to cache employee: %v", err)
-	}
-
-	return employee, nil
+	// 每個調用方拿到獨立的副本，避免共享同一個對象
+	loaded := *result.(*models.Employee)
+	return &loaded, nil
 }
 
 // UpdateEmployee 更新員工信息
@@ -79,11 +126,33 @@ func (s *EmployeeService) UpdateEmployee(employee *models.Employee) error {
 	if oldEmployee.Email != employee.Email {
 		existingEmployee, err := s.employeeRepo.GetByEmail(employee.Email)
 		if err == nil && existingEmployee != nil {
-			return errors.New("email already exists")
+			return apperrors.Conflict(apperrors.CodeEmailAlreadyExists, "Email already exists")
+		}
+		if err != nil && !apperrors.IsNotFound(err) {
+			return err
 		}
 	}
+	if err := s.validateManager(employee.ID, employee.ManagerID); err != nil {
+		return err
+	}
+	if err := validateTermination(employee, oldEmployee); err != nil {
+		return err
+	}
 
-	if err := s.employeeRepo.Update(employee); err != nil {
+	err = repositories.Transaction(func(tx *gorm.DB) error {
+		if err := s.employeeRepo.WithTx(tx).Update(employee); err != nil {
+			return err
+		}
+		outbox := s.outboxRepo.WithTx(tx)
+		if err := appendEvent(outbox, NewEvent(models.EventEmployeeUpdated, AggregateEmployee, employee.ID, employee)); err != nil {
+			return err
+		}
+		if oldEmployee.Status != models.EmployeeStatusInactive && employee.Status == models.EmployeeStatusInactive {
+			return appendEvent(outbox, NewEvent(models.EventEmployeeTerminated, AggregateEmployee, employee.ID, employee))
+		}
+		return nil
+	})
+	if err != nil {
 		return err
 	}
 
@@ -92,13 +161,23 @@ func (s *EmployeeService) UpdateEmployee(employee *models.Employee) error {
 	if err := s.cacheService.SetEmployee(ctx, employee); err != nil {
 		log.Printf("Failed to update employee cache: %v", err)
 	}
+	// 請假緩存內嵌了員工數據，需要一併失效
+	if err := s.cacheService.InvalidateEmployeeLeaves(ctx, employee.ID); err != nil {
+		log.Printf("Failed to invalidate leave cache of employee %d: %v", employee.ID, err)
+	}
 
 	return nil
 }
 
 // DeleteEmployee 刪除員工
 func (s *EmployeeService) DeleteEmployee(id uint) error {
-	if err := s.employeeRepo.Delete(id); err != nil {
+	err := repositories.Transaction(func(tx *gorm.DB) error {
+		if err := s.employeeRepo.WithTx(tx).Delete(id); err != nil {
+			return err
+		}
+		return appendEvent(s.outboxRepo.WithTx(tx), NewEvent(models.EventEmployeeDeleted, AggregateEmployee, id, map[string]uint{"id": id}))
+	})
+	if err != nil {
 		return err
 	}
 
@@ -107,6 +186,9 @@ func (s *EmployeeService) DeleteEmployee(id uint) error {
 	if err := s.cacheService.DeleteEmployee(ctx, id); err != nil {
 		log.Printf("Failed to delete employee cache: %v", err)
 	}
+	if err := s.cacheService.InvalidateEmployeeLeaves(ctx, id); err != nil {
+		log.Printf("Failed to invalidate leave cache of employee %d: %v", id, err)
+	}
 
 	return nil
 }
@@ -115,3 +197,103 @@ func (s *EmployeeService) DeleteEmployee(id uint) error {
 func (s *EmployeeService) ListEmployees() ([]models.Employee, error) {
 	return s.employeeRepo.GetAll()
 }
+
+// validateTermination 離職員工未指定離職日期時沿用原來的離職日期，沒有則為當天；離職日期不能早於入職日期
+func validateTermination(employee, oldEmployee *models.Employee) error {
+	if employee.Status == models.EmployeeStatusInactive && employee.TerminationDate == nil {
+		if oldEmployee != nil && oldEmployee.TerminationDate != nil {
+			employee.TerminationDate = oldEmployee.TerminationDate
+		} else {
+			today := startOfDay(time.Now())
+			employee.TerminationDate = &today
+		}
+	}
+	if employee.TerminationDate != nil && !employee.HireDate.IsZero() && employee.TerminationDate.Before(startOfDay(employee.HireDate)) {
+		return apperrors.Validation(apperrors.CodeInvalidDateRange, "Termination date must not be before hire date",
+			apperrors.Field("termination_date", apperrors.CodeInvalidDateRange, "Termination date must not be before hire date"))
+	}
+	return nil
+}
+
+// maxManagerDepth 檢查主管鏈時最多向上追溯的層數
+const maxManagerDepth = 50
+
+// validateManager 檢查主管存在，且不是員工本人或其下屬（避免主管鏈形成環）
+// employeeID 為 0 表示新員工
+func (s *EmployeeService) validateManager(employeeID uint, managerID *uint) error {
+	if managerID == nil {
+		return nil
+	}
+	invalid := apperrors.Validation(apperrors.CodeInvalidManager, "Invalid manager",
+		apperrors.Field("manager_id", apperrors.CodeInvalidManager, "An employee cannot report to themselves or to one of their reports"))
+	if employeeID != 0 && *managerID == employeeID {
+		return invalid
+	}
+
+	current := *managerID
+	for depth := 0; depth < maxManagerDepth; depth++ {
+		manager, err := s.employeeRepo.GetByID(current)
+		if apperrors.IsNotFound(err) && depth == 0 {
+			return apperrors.Validation(apperrors.CodeManagerNotFound, "Manager not found",
+				apperrors.Field("manager_id", apperrors.CodeManagerNotFound, "Manager not found"))
+		}
+		if err != nil {
+			return err
+		}
+		if manager.ManagerID == nil {
+			return nil
+		}
+		if employeeID != 0 && *manager.ManagerID == employeeID {
+			return invalid
+		}
+		current = *manager.ManagerID
+	}
+	return invalid
+}
+
+// teamMembers 返回主管本人及其所有直接與間接下屬
+func teamMembers(employeeRepo *repositories.EmployeeRepository, managerID uint) ([]models.Employee, error) {
+	manager, err := employeeRepo.GetByID(managerID)
+	if err != nil {
+		return nil, err
+	}
+	members := []models.Employee{*manager}
+	seen := map[uint]bool{manager.ID: true}
+	level := []uint{manager.ID}
+	for depth := 0; depth < maxManagerDepth && len(level) > 0; depth++ {
+		reports, err := employeeRepo.ListReports(level)
+		if err != nil {
+			return nil, err
+		}
+		level = level[:0]
+		for _, report := range reports {
+			if seen[report.ID] {
+				continue
+			}
+			seen[report.ID] = true
+			members = append(members, report)
+			level = append(level, report.ID)
+		}
+	}
+	return members, nil
+}
+
+// managerChain 返回員工本人及其所有上級主管的ID，本人在第一個
+func managerChain(employeeRepo *repositories.EmployeeRepository, employee *models.Employee) ([]uint, error) {
+	chain := []uint{employee.ID}
+	seen := map[uint]bool{employee.ID: true}
+	current := employee.ManagerID
+	for depth := 0; depth < maxManagerDepth && current != nil && !seen[*current]; depth++ {
+		chain = append(chain, *current)
+		seen[*current] = true
+		manager, err := employeeRepo.GetByID(*current)
+		if apperrors.IsNotFound(err) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		current = manager.ManagerID
+	}
+	return chain, nil
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+
+	"hr-system/config"
+	"hr-system/internal/models"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// EventSink 定義事件出口接口
+// 事件中繼保證至少發佈一次，同一事件可能重複發佈，出口或其下游需以事件ID去重
+type EventSink interface {
+	Name() string
+	Publish(ctx context.Context, msg *models.OutboxMessage) error
+}
+
+// RedisStreamSink 將事件追加到 Redis Stream，供其他服務以消費者組讀取
+type RedisStreamSink struct {
+	client *redis.Client
+	stream string
+	maxLen int64
+}
+
+func NewRedisStreamSink(client *redis.Client, stream string, maxLen int64) *RedisStreamSink {
+	return &RedisStreamSink{client: client, stream: stream, maxLen: maxLen}
+}
+
+func (s *RedisStreamSink) Name() string {
+	return config.OutboxSinkRedisStream
+}
+
+func (s *RedisStreamSink) Publish(ctx context.Context, msg *models.OutboxMessage) error {
+	return s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.stream,
+		MaxLen: s.maxLen,
+		Approx: true,
+		Values: map[string]interface{}{
+			"event_id":       msg.EventID,
+			"type":           msg.EventType,
+			"aggregate_type": msg.AggregateType,
+			"aggregate_id":   strconv.FormatUint(uint64(msg.AggregateID), 10),
+			"payload":        msg.Payload,
+		},
+	}).Err()
+}
+
+// StdoutSink 將事件以每行一個 JSON 的格式輸出，用於開發調試或由日誌收集器轉發
+type StdoutSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func NewStdoutSink(w io.Writer) *StdoutSink {
+	return &StdoutSink{w: w}
+}
+
+func (s *StdoutSink) Name() string {
+	return config.OutboxSinkStdout
+}
+
+func (s *StdoutSink) Publish(ctx context.Context, msg *models.OutboxMessage) error {
+	line, err := json.Marshal(struct {
+		Sink  string          `json:"sink"`
+		Event json.RawMessage `json:"event"`
+	}{Sink: "outbox", Event: json.RawMessage(msg.Payload)})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := fmt.Fprintf(s.w, "%s\n", line); err != nil {
+		return err
+	}
+	return nil
+}
+
+// NewEventSinks 依配置創建事件出口，redisClient 為空表示 Redis 不可用
+func NewEventSinks(cfg config.OutboxConfig, webhook *WebhookService, notification *NotificationService, redisClient *redis.Client, stdout io.Writer) ([]EventSink, error) {
+	sinks := make([]EventSink, 0, len(cfg.Sinks))
+	for _, name := range cfg.Sinks {
+		switch name {
+		case config.OutboxSinkWebhook:
+			sinks = append(sinks, webhook)
+		case config.OutboxSinkNotification:
+			sinks = append(sinks, notification)
+		case config.OutboxSinkRedisStream:
+			if redisClient == nil {
+				return nil, fmt.Errorf("outbox sink %q requires Redis", name)
+			}
+			sinks = append(sinks, NewRedisStreamSink(redisClient, cfg.StreamKey, cfg.StreamMaxLen))
+		case config.OutboxSinkStdout:
+			sinks = append(sinks, NewStdoutSink(stdout))
+		default:
+			return nil, fmt.Errorf("unknown outbox sink %q", name)
+		}
+	}
+	return sinks, nil
+}
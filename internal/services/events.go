@@ -0,0 +1,68 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+	"hr-system/internal/repositories"
+)
+
+// Event 業務事件，序列化後即為發佈到各事件出口的內容
+type Event struct {
+	ID            string      `json:"id"`             // 事件ID，接收方可據此去重
+	Type          string      `json:"type"`           // 事件類型，如 employee.hired
+	AggregateType string      `json:"aggregate_type"` // 事件所屬的實體類型（employee/leave/overtime/payroll_run）
+	AggregateID   uint        `json:"aggregate_id"`   // 事件所屬的實體ID
+	OccurredAt    time.Time   `json:"occurred_at"`
+	Data          interface{} `json:"data"` // 事件發生後的實體快照
+}
+
+// 事件所屬的實體類型
+const (
+	AggregateEmployee = "employee"
+	AggregateLeave    = "leave"
+	AggregateOvertime = "overtime"
+	AggregatePayroll  = "payroll_run"
+)
+
+// NewEvent 創建帶有唯一ID的事件
+func NewEvent(eventType, aggregateType string, aggregateID uint, data interface{}) Event {
+	return Event{
+		ID:            newEventID(),
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		OccurredAt:    time.Now(),
+		Data:          data,
+	}
+}
+
+// appendEvent 將事件寫入 outbox，outbox 應綁定到業務操作所在的事務
+func appendEvent(outbox *repositories.OutboxRepository, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return apperrors.Internal(err)
+	}
+	return outbox.Append(&models.OutboxMessage{
+		EventID:       event.ID,
+		EventType:     event.Type,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		Payload:       string(payload),
+		CreatedAt:     event.OccurredAt,
+		NextAttemptAt: event.OccurredAt,
+	})
+}
+
+func newEventID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(buf)
+}
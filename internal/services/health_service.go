@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"hr-system/config"
+)
+
+// 整體健康狀態
+const (
+	HealthStatusOK       = "ok"       // 所有依賴正常
+	HealthStatusDegraded = "degraded" // 緩存降級，但服務可用
+	HealthStatusDown     = "down"     // 數據庫不可用
+)
+
+// HealthReport 健康檢查結果
+type HealthReport struct {
+	Status   string      `json:"status"`
+	Database string      `json:"database"`
+	Cache    CacheHealth `json:"cache"`
+}
+
+type HealthService struct {
+	cacheService CacheService
+}
+
+func NewHealthService(cacheService CacheService) *HealthService {
+	return &HealthService{cacheService: cacheService}
+}
+
+// Check 檢查數據庫與緩存狀態
+func (s *HealthService) Check(ctx context.Context) HealthReport {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	report := HealthReport{
+		Status:   HealthStatusOK,
+		Database: HealthStatusOK,
+		Cache:    s.cacheService.Health(ctx),
+	}
+
+	if !report.Cache.Healthy {
+		report.Status = HealthStatusDegraded
+	}
+
+	sqlDB, err := config.DB.DB()
+	if err == nil {
+		err = sqlDB.PingContext(ctx)
+	}
+	if err != nil {
+		report.Database = HealthStatusDown
+		report.Status = HealthStatusDown
+	}
+
+	return report
+}
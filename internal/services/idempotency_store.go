@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const idempotencyKeyPrefix = "idempotency:"
+
+// IdempotencyRecord 冪等鍵對應的請求摘要與響應
+type IdempotencyRecord struct {
+	RequestHash string    `json:"request_hash"`
+	Completed   bool      `json:"completed"` // false 表示請求仍在處理中
+	StatusCode  int       `json:"status_code,omitempty"`
+	ContentType string    `json:"content_type,omitempty"`
+	Body        []byte    `json:"body,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// IdempotencyStore 定義冪等鍵存儲接口
+type IdempotencyStore interface {
+	// Reserve 佔用冪等鍵並標記為處理中；鍵已存在時不做修改並返回已保存的記錄
+	Reserve(ctx context.Context, key, requestHash string, ttl time.Duration) (existing *IdempotencyRecord, err error)
+	// Complete 保存請求的響應，之後相同冪等鍵的重試會重放該響應
+	Complete(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error
+	// Release 釋放冪等鍵，用於請求失敗後允許客戶端以相同冪等鍵重試
+	Release(ctx context.Context, key string) error
+}
+
+// NewIdempotencyStore 依 Redis 是否可用選擇存儲後端
+// 多副本部署需要共享 Redis，否則重試被路由到其他副本時無法識別
+func NewIdempotencyStore(redisAvailable bool, client *redis.Client) IdempotencyStore {
+	if redisAvailable && client != nil {
+		return NewRedisIdempotencyStore(client)
+	}
+	return NewMemoryIdempotencyStore()
+}
+
+// RedisIdempotencyStore 基於 Redis 的冪等鍵存儲
+type RedisIdempotencyStore struct {
+	client *redis.Client
+}
+
+func NewRedisIdempotencyStore(client *redis.Client) *RedisIdempotencyStore {
+	return &RedisIdempotencyStore{client: client}
+}
+
+func (s *RedisIdempotencyStore) Reserve(ctx context.Context, key, requestHash string, ttl time.Duration) (*IdempotencyRecord, error) {
+	data, err := json.Marshal(&IdempotencyRecord{RequestHash: requestHash, CreatedAt: time.Now()})
+	if err != nil {
+		return nil, err
+	}
+
+	redisKey := idempotencyKeyPrefix + key
+	// 鍵可能在 SETNX 與 GET 之間過期，此時重新嘗試佔用
+	for attempt := 0; attempt < 3; attempt++ {
+		ok, err := s.client.SetNX(ctx, redisKey, data, ttl).Result()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return nil, nil
+		}
+
+		existing, err := s.client.Get(ctx, redisKey).Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var record IdempotencyRecord
+		if err := json.Unmarshal(existing, &record); err != nil {
+			return nil, err
+		}
+		return &record, nil
+	}
+	return nil, errors.New("idempotency key expired repeatedly while reserving")
+}
+
+func (s *RedisIdempotencyStore) Complete(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, idempotencyKeyPrefix+key, data, ttl).Err()
+}
+
+func (s *RedisIdempotencyStore) Release(ctx context.Context, key string) error {
+	return s.client.Del(ctx, idempotencyKeyPrefix+key).Err()
+}
+
+// MemoryIdempotencyStore 進程內的冪等鍵存儲，用於沒有 Redis 的單副本部署
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]memoryIdempotencyEntry
+}
+
+type memoryIdempotencyEntry struct {
+	record    IdempotencyRecord
+	expiresAt time.Time
+}
+
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{records: make(map[string]memoryIdempotencyEntry)}
+}
+
+func (s *MemoryIdempotencyStore) Reserve(ctx context.Context, key, requestHash string, ttl time.Duration) (*IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.evictExpired(now)
+	if entry, ok := s.records[key]; ok {
+		record := entry.record
+		return &record, nil
+	}
+	s.records[key] = memoryIdempotencyEntry{
+		record:    IdempotencyRecord{RequestHash: requestHash, CreatedAt: now},
+		expiresAt: now.Add(ttl),
+	}
+	return nil, nil
+}
+
+func (s *MemoryIdempotencyStore) Complete(ctx context.Context, key string, record *IdempotencyRecord, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[key] = memoryIdempotencyEntry{record: *record, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+func (s *MemoryIdempotencyStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+// evictExpired 清理過期的記錄，調用方需持有鎖
+func (s *MemoryIdempotencyStore) evictExpired(now time.Time) {
+	for key, entry := range s.records {
+		if now.After(entry.expiresAt) {
+			delete(s.records, key)
+		}
+	}
+}
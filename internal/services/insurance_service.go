@@ -0,0 +1,356 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+	"hr-system/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+// maxInsuranceGrades 單個分級表最多的級距數
+const maxInsuranceGrades = 200
+
+// InsuranceService 管理勞保、健保與勞退的投保薪資分級表，並計算薪資單的月保費
+// 勞保與勞退按在職天數計算（整月按 30 天），健保在月底仍在職時按整月計算
+type InsuranceService struct {
+	insuranceRepo *repositories.InsuranceRepository
+	payrollRepo   *repositories.PayrollRepository
+	employeeRepo  *repositories.EmployeeRepository
+	config        config.InsuranceConfig
+}
+
+func NewInsuranceService(insuranceRepo *repositories.InsuranceRepository, payrollRepo *repositories.PayrollRepository, employeeRepo *repositories.EmployeeRepository, cfg config.InsuranceConfig) *InsuranceService {
+	return &InsuranceService{
+		insuranceRepo: insuranceRepo,
+		payrollRepo:   payrollRepo,
+		employeeRepo:  employeeRepo,
+		config:        cfg,
+	}
+}
+
+// ImportTable 從 CSV 讀取級距並創建分級表，每行為「級距,月投保薪資」，可以有標題行
+func (s *InsuranceService) ImportTable(table *models.InsuranceGradeTable, content io.Reader) error {
+	grades, err := parseGradeCSV(content)
+	if err != nil {
+		return err
+	}
+	table.Grades = grades
+	return s.insuranceRepo.CreateTable(table)
+}
+
+// GetTable 獲取分級表，包括級距
+func (s *InsuranceService) GetTable(id uint) (*models.InsuranceGradeTable, error) {
+	return s.insuranceRepo.GetTable(id)
+}
+
+// ListTables 獲取分級表，kind 為空時返回所有類型
+func (s *InsuranceService) ListTables(kind string) ([]models.InsuranceGradeTable, error) {
+	return s.insuranceRepo.ListTables(kind)
+}
+
+// DeleteTable 刪除沒有薪資單使用的分級表
+func (s *InsuranceService) DeleteTable(id uint) error {
+	if _, err := s.insuranceRepo.GetTable(id); err != nil {
+		return err
+	}
+	inUse, err := s.insuranceRepo.TableInUse(id)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return apperrors.Conflict(apperrors.CodeGradeTableInUse, "The grade table is used by payslips")
+	}
+	return repositories.Transaction(func(tx *gorm.DB) error {
+		return s.insuranceRepo.WithTx(tx).DeleteTable(id)
+	})
+}
+
+// EmployeeGrades 按員工當前的月薪與某月份（YYYY-MM）生效的分級表返回各類保險的投保級距，沒有生效分級表的類型不返回
+func (s *InsuranceService) EmployeeGrades(employeeID uint, month string) ([]models.InsuredGrade, error) {
+	employee, err := s.employeeRepo.GetByID(employeeID)
+	if err != nil {
+		return nil, err
+	}
+	from, _, err := parseMonth(month)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := s.insuranceRepo.EffectiveTables(from)
+	if err != nil {
+		return nil, err
+	}
+	grades := []models.InsuredGrade{}
+	for _, kind := range models.InsuranceKinds {
+		table := tables[kind]
+		if table == nil {
+			continue
+		}
+		if grade := table.GradeFor(employee.Salary); grade != nil {
+			grades = append(grades, models.InsuredGrade{
+				Kind:          kind,
+				TableID:       table.ID,
+				Version:       table.Version,
+				Grade:         grade.Grade,
+				InsuredSalary: grade.InsuredSalary,
+			})
+		}
+	}
+	return grades, nil
+}
+
+// Reconcile 核對薪資批次的保費：記錄的員工負擔、薪資單中的扣款明細，以及按當前分級表與員工資料重算的金額
+func (s *InsuranceService) Reconcile(runID uint) (*models.InsuranceReconciliation, error) {
+	run, err := s.payrollRepo.GetRunDetails(runID)
+	if err != nil {
+		return nil, err
+	}
+	from, to, err := parseMonth(run.Month)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := s.insuranceRepo.EffectiveTables(from)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint, len(run.Payslips))
+	for i, payslip := range run.Payslips {
+		ids[i] = payslip.EmployeeID
+	}
+	employees := make(map[uint]*models.Employee, len(ids))
+	if len(ids) > 0 {
+		list, err := s.employeeRepo.ListByIDs(ids)
+		if err != nil {
+			return nil, err
+		}
+		for i := range list {
+			employees[list[i].ID] = &list[i]
+		}
+	}
+
+	report := &models.InsuranceReconciliation{
+		RunID:  run.ID,
+		Month:  run.Month,
+		Status: run.Status,
+		Items:  []models.InsuranceReconciliationItem{},
+	}
+	for _, payslip := range run.Payslips {
+		recorded := make(map[string]models.InsuranceContribution)
+		for _, contribution := range payslip.Contributions {
+			recorded[contribution.Kind] = contribution
+		}
+		deducted := make(map[string]float64)
+		for _, line := range payslip.Lines {
+			if kind, ok := insuranceKindOf(line.Code); ok {
+				deducted[kind] += line.Amount
+			}
+		}
+		// 員工已被刪除時無法重算，重算的金額為 0
+		expected := make(map[string]models.InsuranceContribution)
+		if employee, ok := employees[payslip.EmployeeID]; ok {
+			insured := *employee
+			insured.Salary = payslip.BaseSalary
+			for _, contribution := range s.contributions(&insured, tables, from, to) {
+				expected[contribution.Kind] = contribution
+			}
+		}
+
+		for _, kind := range models.InsuranceKinds {
+			rec, hasRecorded := recorded[kind]
+			exp, hasExpected := expected[kind]
+			if !hasRecorded && !hasExpected && deducted[kind] == 0 {
+				continue
+			}
+			item := models.InsuranceReconciliationItem{
+				EmployeeID:       payslip.EmployeeID,
+				EmployeeName:     payslip.EmployeeName,
+				Kind:             kind,
+				Grade:            rec.Grade,
+				ExpectedGrade:    exp.Grade,
+				EmployeeAmount:   rec.EmployeeAmount,
+				Deducted:         deducted[kind],
+				ExpectedEmployee: exp.EmployeeAmount,
+				EmployerAmount:   rec.EmployerAmount,
+				ExpectedEmployer: exp.EmployerAmount,
+			}
+			item.Matched = item.Grade == item.ExpectedGrade &&
+				item.EmployeeAmount == item.Deducted &&
+				item.EmployeeAmount == item.ExpectedEmployee &&
+				item.EmployerAmount == item.ExpectedEmployer
+			if !item.Matched {
+				report.MismatchCount++
+			}
+			report.EmployeeTotal += item.EmployeeAmount
+			report.DeductedTotal += item.Deducted
+			report.EmployerTotal += item.EmployerAmount
+			report.ExpectedEmployee += item.ExpectedEmployee
+			report.ExpectedEmployer += item.ExpectedEmployer
+			report.Items = append(report.Items, item)
+		}
+	}
+	return report, nil
+}
+
+// effectiveTables 獲取每類保險在某月份一日已生效的分級表
+func (s *InsuranceService) effectiveTables(from time.Time) (map[string]*models.InsuranceGradeTable, error) {
+	return s.insuranceRepo.EffectiveTables(from)
+}
+
+// contributions 按員工的月薪計算其在 [from, to) 月份的保費，沒有生效分級表的類型不計算，金額四捨五入到元
+// 勞保與勞退按在職天數佔 30 天的比例計算，整月在職按 30 天；健保只在月底仍在職時按整月計算，員工負擔按本人與眷屬人數計算
+func (s *InsuranceService) contributions(employee *models.Employee, tables map[string]*models.InsuranceGradeTable, from, to time.Time) []models.InsuranceContribution {
+	start, end := employmentWithin(employee, from, to)
+	if !end.After(start) {
+		return nil
+	}
+	days := 30
+	if !start.Equal(from) || !end.Equal(to) {
+		days = daysBetween(start, end)
+		if days > 30 {
+			days = 30
+		}
+	}
+	ratio := float64(days) / 30
+	month := from.Format("2006-01")
+
+	var contributions []models.InsuranceContribution
+	contribution := func(kind string) (models.InsuranceContribution, bool) {
+		table := tables[kind]
+		if table == nil {
+			return models.InsuranceContribution{}, false
+		}
+		grade := table.GradeFor(employee.Salary)
+		if grade == nil {
+			return models.InsuranceContribution{}, false
+		}
+		return models.InsuranceContribution{
+			EmployeeID:    employee.ID,
+			Month:         month,
+			Kind:          kind,
+			TableID:       table.ID,
+			Grade:         grade.Grade,
+			InsuredSalary: grade.InsuredSalary,
+			Days:          days,
+		}, true
+	}
+
+	if c, ok := contribution(models.InsuranceLabor); ok {
+		c.EmployeeAmount = roundTo(c.InsuredSalary*s.config.LaborRate*s.config.LaborEmployeeShare*ratio, 0)
+		c.EmployerAmount = roundTo(c.InsuredSalary*(s.config.LaborRate*s.config.LaborEmployerShare+s.config.OccupationalRate)*ratio, 0)
+		contributions = append(contributions, c)
+	}
+	if c, ok := contribution(models.InsuranceHealth); ok && end.Equal(to) {
+		dependents := employee.HealthDependents
+		if dependents > s.config.HealthMaxDependents {
+			dependents = s.config.HealthMaxDependents
+		}
+		c.Days = daysBetween(from, to)
+		c.Dependents = dependents
+		c.EmployeeAmount = roundTo(c.InsuredSalary*s.config.HealthRate*s.config.HealthEmployeeShare, 0) * float64(1+dependents)
+		c.EmployerAmount = roundTo(c.InsuredSalary*s.config.HealthRate*s.config.HealthEmployerShare*(1+s.config.HealthAvgDependents), 0)
+		contributions = append(contributions, c)
+	}
+	if c, ok := contribution(models.InsurancePension); ok {
+		c.EmployeeRate = employee.PensionVoluntaryRate
+		c.EmployeeAmount = roundTo(c.InsuredSalary*employee.PensionVoluntaryRate/100*ratio, 0)
+		c.EmployerAmount = roundTo(c.InsuredSalary*s.config.PensionEmployerRate*ratio, 0)
+		contributions = append(contributions, c)
+	}
+	return contributions
+}
+
+// insuranceLineCodes 各類保險員工負擔在薪資單中的明細來源
+var insuranceLineCodes = map[string]string{
+	models.InsuranceLabor:   models.PayrollCodeLaborInsurance,
+	models.InsuranceHealth:  models.PayrollCodeHealthInsurance,
+	models.InsurancePension: models.PayrollCodePension,
+}
+
+// insuranceKindOf 返回薪資明細來源對應的保險類型
+func insuranceKindOf(code string) (string, bool) {
+	for kind, c := range insuranceLineCodes {
+		if c == code {
+			return kind, true
+		}
+	}
+	return "", false
+}
+
+// contributionLine 生成保費員工負擔的扣款明細，健保按本人與眷屬人數計算
+func contributionLine(c *models.InsuranceContribution) models.PayrollLine {
+	line := models.PayrollLine{
+		Code:        insuranceLineCodes[c.Kind],
+		Kind:        models.PayrollLineDeduction,
+		Description: fmt.Sprintf("grade %d, insured %.0f", c.Grade, c.InsuredSalary),
+		Quantity:    1,
+		Rate:        c.EmployeeAmount,
+		Amount:      c.EmployeeAmount,
+	}
+	switch c.Kind {
+	case models.InsuranceHealth:
+		line.Quantity = float64(1 + c.Dependents)
+		line.Rate = c.EmployeeAmount / line.Quantity
+		line.Description = fmt.Sprintf("%s, %d dependents", line.Description, c.Dependents)
+	case models.InsurancePension:
+		line.Description = fmt.Sprintf("%s, voluntary %g%%", line.Description, c.EmployeeRate)
+	}
+	return line
+}
+
+// parseGradeCSV 解析「級距,月投保薪資」格式的 CSV，級距與月投保薪資都必須遞增；
+// 第一行不是數字時視為標題行，金額可以帶千分位逗號
+func parseGradeCSV(content io.Reader) ([]models.InsuranceGrade, error) {
+	reader := csv.NewReader(content)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	var grades []models.InsuranceGrade
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, errInvalidGradeTable(err.Error())
+		}
+		line, _ := reader.FieldPos(0)
+		if first {
+			record[0] = strings.TrimPrefix(record[0], "\ufeff")
+			if _, err := strconv.Atoi(strings.TrimSpace(record[0])); err != nil {
+				continue
+			}
+		}
+		if len(record) < 2 {
+			return nil, errInvalidGradeTable(fmt.Sprintf("Line %d: expected grade and insured salary", line))
+		}
+		grade, gradeErr := strconv.Atoi(strings.TrimSpace(record[0]))
+		salary, salaryErr := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(record[1]), ",", ""), 64)
+		if gradeErr != nil || salaryErr != nil || grade <= 0 || salary <= 0 {
+			return nil, errInvalidGradeTable(fmt.Sprintf("Line %d: grade and insured salary must be positive numbers", line))
+		}
+		if n := len(grades); n > 0 && (grade <= grades[n-1].Grade || salary <= grades[n-1].InsuredSalary) {
+			return nil, errInvalidGradeTable(fmt.Sprintf("Line %d: grades and insured salaries must be increasing", line))
+		}
+		grades = append(grades, models.InsuranceGrade{Grade: grade, InsuredSalary: salary})
+		if len(grades) > maxInsuranceGrades {
+			return nil, errInvalidGradeTable(fmt.Sprintf("A grade table must not have more than %d grades", maxInsuranceGrades))
+		}
+	}
+	if len(grades) == 0 {
+		return nil, errInvalidGradeTable("The grade table must have at least one grade")
+	}
+	return grades, nil
+}
+
+func errInvalidGradeTable(msg string) *apperrors.Error {
+	return apperrors.Validation(apperrors.CodeInvalidGradeTable, msg,
+		apperrors.Field("file", apperrors.CodeInvalidGradeTable, msg))
+}
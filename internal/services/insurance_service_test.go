@@ -0,0 +1,280 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// 測試用的分級表，三類保險使用相同的級距
+const testGradeCSV = "級距,月投保薪資\n1,\"27,470\"\n2,\"48,200\"\n"
+
+// contribution 保費中測試關心的欄位
+type contribution struct {
+	Kind       string
+	Grade      int
+	Days       int
+	Dependents int
+	Employee   float64
+	Employer   float64
+}
+
+func TestInsuranceContributions(t *testing.T) {
+	service := newTestInsuranceService()
+	grades, err := parseGradeCSV(strings.NewReader(testGradeCSV))
+	require.NoError(t, err)
+	tables := make(map[string]*models.InsuranceGradeTable)
+	for _, kind := range models.InsuranceKinds {
+		tables[kind] = &models.InsuranceGradeTable{Kind: kind, Grades: grades}
+	}
+	terminated := func(day time.Time) *time.Time { return &day }
+
+	// 投保薪資 48200：勞保員工 1205、雇主 4319；健保每人 748、雇主 2347；勞退雇主提繳 2892
+	tests := []struct {
+		name     string
+		month    time.Time
+		employee models.Employee
+		want     []contribution
+	}{
+		{
+			name:     "整月在職",
+			employee: models.Employee{Salary: 48000},
+			want: []contribution{
+				{Kind: models.InsuranceLabor, Grade: 2, Days: 30, Employee: 1205, Employer: 4319},
+				{Kind: models.InsuranceHealth, Grade: 2, Days: 31, Employee: 748, Employer: 2347},
+				{Kind: models.InsurancePension, Grade: 2, Days: 30, Employee: 0, Employer: 2892},
+			},
+		},
+		{
+			name:     "二月整月在職按 30 天",
+			month:    date(2024, 2, 1),
+			employee: models.Employee{Salary: 48000},
+			want: []contribution{
+				{Kind: models.InsuranceLabor, Grade: 2, Days: 30, Employee: 1205, Employer: 4319},
+				{Kind: models.InsuranceHealth, Grade: 2, Days: 29, Employee: 748, Employer: 2347},
+				{Kind: models.InsurancePension, Grade: 2, Days: 30, Employee: 0, Employer: 2892},
+			},
+		},
+		{
+			name:     "月薪低於最低一級",
+			employee: models.Employee{Salary: 20000},
+			want: []contribution{
+				{Kind: models.InsuranceLabor, Grade: 1, Days: 30, Employee: 687, Employer: 2461},
+				{Kind: models.InsuranceHealth, Grade: 1, Days: 31, Employee: 426, Employer: 1338},
+				{Kind: models.InsurancePension, Grade: 1, Days: 30, Employee: 0, Employer: 1648},
+			},
+		},
+		{
+			name:     "月中入職按天數比例計算勞保與勞退，健保按整月",
+			employee: models.Employee{Salary: 60000, HireDate: date(2024, 10, 22)},
+			want: []contribution{
+				{Kind: models.InsuranceLabor, Grade: 2, Days: 10, Employee: 402, Employer: 1440},
+				{Kind: models.InsuranceHealth, Grade: 2, Days: 31, Employee: 748, Employer: 2347},
+				{Kind: models.InsurancePension, Grade: 2, Days: 10, Employee: 0, Employer: 964},
+			},
+		},
+		{
+			name:     "月中離職不計健保",
+			employee: models.Employee{Salary: 48000, Status: models.EmployeeStatusInactive, TerminationDate: terminated(date(2024, 10, 11))},
+			want: []contribution{
+				{Kind: models.InsuranceLabor, Grade: 2, Days: 11, Employee: 442, Employer: 1584},
+				{Kind: models.InsurancePension, Grade: 2, Days: 11, Employee: 0, Employer: 1060},
+			},
+		},
+		{
+			name:     "月底最後一天離職按整月計算",
+			employee: models.Employee{Salary: 48000, Status: models.EmployeeStatusInactive, TerminationDate: terminated(date(2024, 10, 31))},
+			want: []contribution{
+				{Kind: models.InsuranceLabor, Grade: 2, Days: 30, Employee: 1205, Employer: 4319},
+				{Kind: models.InsuranceHealth, Grade: 2, Days: 31, Employee: 748, Employer: 2347},
+				{Kind: models.InsurancePension, Grade: 2, Days: 30, Employee: 0, Employer: 2892},
+			},
+		},
+		{
+			name:     "健保按眷屬人數計算，勞退自願提繳",
+			employee: models.Employee{Salary: 48000, HealthDependents: 2, PensionVoluntaryRate: 6},
+			want: []contribution{
+				{Kind: models.InsuranceLabor, Grade: 2, Days: 30, Employee: 1205, Employer: 4319},
+				{Kind: models.InsuranceHealth, Grade: 2, Days: 31, Dependents: 2, Employee: 2244, Employer: 2347},
+				{Kind: models.InsurancePension, Grade: 2, Days: 30, Employee: 2892, Employer: 2892},
+			},
+		},
+		{
+			name:     "眷屬最多計算 3 人",
+			employee: models.Employee{Salary: 48000, HealthDependents: 5},
+			want: []contribution{
+				{Kind: models.InsuranceLabor, Grade: 2, Days: 30, Employee: 1205, Employer: 4319},
+				{Kind: models.InsuranceHealth, Grade: 2, Days: 31, Dependents: 3, Employee: 2992, Employer: 2347},
+				{Kind: models.InsurancePension, Grade: 2, Days: 30, Employee: 0, Employer: 2892},
+			},
+		},
+		{
+			name:     "當月不在職",
+			employee: models.Employee{Salary: 48000, HireDate: date(2024, 11, 1)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from := tt.month
+			if from.IsZero() {
+				from = date(2024, 10, 1)
+			}
+			var got []contribution
+			for _, c := range service.contributions(&tt.employee, tables, from, from.AddDate(0, 1, 0)) {
+				got = append(got, contribution{c.Kind, c.Grade, c.Days, c.Dependents, c.EmployeeAmount, c.EmployerAmount})
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("沒有生效分級表的類型不計算", func(t *testing.T) {
+		got := service.contributions(&models.Employee{Salary: 48000}, map[string]*models.InsuranceGradeTable{
+			models.InsuranceLabor: tables[models.InsuranceLabor],
+		}, date(2024, 10, 1), date(2024, 11, 1))
+		require.Len(t, got, 1)
+		assert.Equal(t, models.InsuranceLabor, got[0].Kind)
+	})
+}
+
+func TestParseGradeCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []models.InsuranceGrade
+		wantMsg string
+	}{
+		{
+			name:    "帶 BOM 與標題行及千分位",
+			content: "\ufeff級距,月投保薪資\n1,\"27,470\"\n2, 27600\n",
+			want:    []models.InsuranceGrade{{Grade: 1, InsuredSalary: 27470}, {Grade: 2, InsuredSalary: 27600}},
+		},
+		{
+			name:    "沒有標題行",
+			content: "1,27470\n2,27600\n",
+			want:    []models.InsuranceGrade{{Grade: 1, InsuredSalary: 27470}, {Grade: 2, InsuredSalary: 27600}},
+		},
+		{name: "缺少月投保薪資", content: "1,27470\n2\n", wantMsg: "Line 2: expected grade and insured salary"},
+		{name: "月投保薪資不是數字", content: "級距,月投保薪資\n1,abc\n", wantMsg: "Line 2: grade and insured salary must be positive numbers"},
+		{name: "月投保薪資為零", content: "1,0\n", wantMsg: "Line 1: grade and insured salary must be positive numbers"},
+		{name: "級距為負數", content: "-1,27470\n", wantMsg: "Line 1: grade and insured salary must be positive numbers"},
+		{name: "月投保薪資未遞增", content: "1,27600\n2,27470\n", wantMsg: "Line 2: grades and insured salaries must be increasing"},
+		{name: "級距重複", content: "1,27470\n1,27600\n", wantMsg: "Line 2: grades and insured salaries must be increasing"},
+		{name: "引號未閉合", content: "1,\"27470\n", wantMsg: "extraneous or missing \" in quoted-field"},
+		{name: "空文件", content: "", wantMsg: "The grade table must have at least one grade"},
+		{name: "只有標題行", content: "級距,月投保薪資\n", wantMsg: "The grade table must have at least one grade"},
+		{name: "級距過多", content: gradeRows(maxInsuranceGrades + 1), wantMsg: "A grade table must not have more than 200 grades"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grades, err := parseGradeCSV(strings.NewReader(tt.content))
+			if tt.wantMsg == "" {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, grades)
+				return
+			}
+			var appErr *apperrors.Error
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, apperrors.CodeInvalidGradeTable, appErr.Code)
+			assert.Contains(t, appErr.Message, tt.wantMsg)
+		})
+	}
+}
+
+// gradeRows 生成 n 行遞增的級距
+func gradeRows(n int) string {
+	var b strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&b, "%d,%d\n", i, 27000+i*100)
+	}
+	return b.String()
+}
+
+// reconciliationKey 對賬項目的員工姓名與保險類型
+type reconciliationKey struct {
+	Employee string
+	Kind     string
+}
+
+func TestInsuranceReconcile(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(t *testing.T, service *InsuranceService, db *gorm.DB)
+		want   []reconciliationKey
+	}{
+		{name: "記錄、扣款與重算一致"},
+		{
+			name: "記錄的員工負擔與扣款不一致",
+			modify: func(t *testing.T, _ *InsuranceService, db *gorm.DB) {
+				require.NoError(t, db.Model(&models.InsuranceContribution{}).
+					Where("kind = ? AND employee_id = (?)", models.InsuranceLabor, db.Model(&models.Employee{}).Select("id").Where("name = ?", "王小明")).
+					Update("employee_amount", 1000).Error)
+			},
+			want: []reconciliationKey{{"王小明", models.InsuranceLabor}},
+		},
+		{
+			name: "扣款明細被刪除",
+			modify: func(t *testing.T, _ *InsuranceService, db *gorm.DB) {
+				require.NoError(t, db.Where("code = ?", models.PayrollCodeHealthInsurance).Delete(&models.PayrollLine{}).Error)
+			},
+			want: []reconciliationKey{{"王小明", models.InsuranceHealth}, {"李小華", models.InsuranceHealth}},
+		},
+		{
+			name: "員工眷屬人數變更後重算不一致",
+			modify: func(t *testing.T, _ *InsuranceService, db *gorm.DB) {
+				require.NoError(t, db.Model(&models.Employee{}).Where("name = ?", "李小華").Update("health_dependents", 2).Error)
+			},
+			want: []reconciliationKey{{"李小華", models.InsuranceHealth}},
+		},
+		{
+			name: "新版分級表調整級距",
+			modify: func(t *testing.T, service *InsuranceService, _ *gorm.DB) {
+				table := &models.InsuranceGradeTable{Kind: models.InsuranceLabor, Version: "2024-10", EffectiveFrom: date(2024, 10, 1)}
+				require.NoError(t, service.ImportTable(table, strings.NewReader("1,27470\n2,45800\n3,48200\n")))
+			},
+			want: []reconciliationKey{{"王小明", models.InsuranceLabor}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupTestDB(t)
+			service := newTestInsuranceService()
+			for _, kind := range models.InsuranceKinds {
+				table := &models.InsuranceGradeTable{Kind: kind, Version: "2024", EffectiveFrom: date(2024, 1, 1)}
+				require.NoError(t, service.ImportTable(table, strings.NewReader(testGradeCSV)))
+			}
+			seedEmployee(t, db, models.Employee{Name: "王小明", Salary: 46000, HealthDependents: 1})
+			seedEmployee(t, db, models.Employee{Name: "李小華", Salary: 27000})
+			run, err := newTestPayrollService().CreateRun("2024-10", nil)
+			require.NoError(t, err)
+			if tt.modify != nil {
+				tt.modify(t, service, db)
+			}
+
+			report, err := service.Reconcile(run.ID)
+			require.NoError(t, err)
+			assert.Len(t, report.Items, 6)
+			var mismatched []reconciliationKey
+			for _, item := range report.Items {
+				if !item.Matched {
+					mismatched = append(mismatched, reconciliationKey{item.EmployeeName, item.Kind})
+				}
+			}
+			assert.Equal(t, tt.want, mismatched)
+			assert.Equal(t, len(tt.want), report.MismatchCount)
+		})
+	}
+
+	t.Run("薪資批次不存在", func(t *testing.T) {
+		setupTestDB(t)
+		_, err := newTestInsuranceService().Reconcile(1)
+		assert.True(t, apperrors.IsNotFound(err))
+	})
+}
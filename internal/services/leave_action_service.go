@@ -0,0 +1,267 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+	"hr-system/internal/repositories"
+)
+
+// leaveActionClaims 操作令牌中經簽名的內容
+type leaveActionClaims struct {
+	TokenID    string `json:"jti"`
+	LeaveID    uint   `json:"lid"`
+	ApproverID uint   `json:"sub"`
+	Action     string `json:"act"`
+	ExpiresAt  int64  `json:"exp"` // Unix 秒
+}
+
+// LeaveActionLinks 審批郵件中的操作連結
+type LeaveActionLinks struct {
+	Approve   string
+	Reject    string
+	ExpiresAt time.Time
+}
+
+// LeaveActionPreview 操作令牌對應的請假與操作，用於確認頁與結果頁
+type LeaveActionPreview struct {
+	TokenID   string
+	Action    string // approve/reject
+	Leave     *models.Leave
+	ExpiresAt time.Time
+}
+
+// LeaveActionService 簽發與兌現郵件審批連結
+// 令牌格式為 base64url(JSON).base64url(HMAC-SHA256)，簽名保證內容未被篡改，
+// 數據庫中的記錄保證每個令牌只能使用一次
+type LeaveActionService struct {
+	repo         *repositories.LeaveActionRepository
+	leaveRepo    *repositories.LeaveRepository
+	leaveService *LeaveService
+	audit        *AuditService
+	cfg          config.LeaveActionConfig
+	secret       []byte
+}
+
+func NewLeaveActionService(repo *repositories.LeaveActionRepository, leaveRepo *repositories.LeaveRepository, leaveService *LeaveService, audit *AuditService, cfg config.LeaveActionConfig) *LeaveActionService {
+	secret := []byte(cfg.Secret)
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			panic(err)
+		}
+		log.Printf("ACTION_TOKEN_SECRET is not set; using a random secret, email approval links will stop working after restart")
+	}
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	return &LeaveActionService{
+		repo:         repo,
+		leaveRepo:    leaveRepo,
+		leaveService: leaveService,
+		audit:        audit,
+		cfg:          cfg,
+		secret:       secret,
+	}
+}
+
+// IssueLinks 為審批人簽發核准與駁回連結
+func (s *LeaveActionService) IssueLinks(leaveID, approverID uint) (*LeaveActionLinks, error) {
+	expiresAt := time.Now().Add(s.cfg.TTL).Truncate(time.Second)
+	actions := []string{models.LeaveActionApprove, models.LeaveActionReject}
+
+	tokens := make([]models.LeaveActionToken, 0, len(actions))
+	links := make([]string, 0, len(actions))
+	for _, action := range actions {
+		id, err := newActionTokenID()
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		token, err := s.sign(leaveActionClaims{
+			TokenID:    id,
+			LeaveID:    leaveID,
+			ApproverID: approverID,
+			Action:     action,
+			ExpiresAt:  expiresAt.Unix(),
+		})
+		if err != nil {
+			return nil, apperrors.Internal(err)
+		}
+		tokens = append(tokens, models.LeaveActionToken{
+			ID:         id,
+			LeaveID:    leaveID,
+			ApproverID: approverID,
+			Action:     action,
+			ExpiresAt:  expiresAt,
+		})
+		links = append(links, s.cfg.BaseURL+"/leave-actions?token="+url.QueryEscape(token))
+	}
+	if err := s.repo.CreateTokens(tokens); err != nil {
+		return nil, err
+	}
+	return &LeaveActionLinks{Approve: links[0], Reject: links[1], ExpiresAt: expiresAt}, nil
+}
+
+// Inspect 驗證令牌但不使用，返回確認頁需要的信息
+func (s *LeaveActionService) Inspect(token string) (*LeaveActionPreview, error) {
+	row, err := s.load(token)
+	if err != nil {
+		return nil, err
+	}
+	leave, err := s.pendingLeave(row.LeaveID)
+	if err != nil {
+		return nil, err
+	}
+	return &LeaveActionPreview{TokenID: row.ID, Action: row.Action, Leave: leave, ExpiresAt: row.ExpiresAt}, nil
+}
+
+// Execute 使用令牌，以簽發時的審批人身份核准或駁回請假，並寫入審計記錄
+func (s *LeaveActionService) Execute(token, remark string, meta RequestMeta) (*LeaveActionPreview, error) {
+	row, err := s.load(token)
+	if err != nil {
+		return nil, err
+	}
+	leave, err := s.pendingLeave(row.LeaveID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 先佔用令牌，並發的重複提交只有一個能繼續
+	consumed, err := s.repo.ConsumeToken(row.ID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !consumed {
+		return nil, errActionTokenUsed()
+	}
+
+	status, action := models.LeaveStatusApproved, models.AuditActionLeaveApprove
+	if row.Action == models.LeaveActionReject {
+		status, action = models.LeaveStatusRejected, models.AuditActionLeaveReject
+	}
+	approverID := row.ApproverID
+	if _, err := s.leaveService.UpdateLeaveStatus(leave.ID, status, remark, approverID); err != nil {
+		// 審批失敗時歸還令牌，允許審批人重試
+		if releaseErr := s.repo.ReleaseToken(row.ID); releaseErr != nil {
+			log.Printf("Failed to release leave action token %s: %v", row.ID, releaseErr)
+		}
+		return nil, err
+	}
+
+	err = s.audit.Record(&models.AuditLog{
+		ActorID:    &approverID,
+		Action:     action,
+		EntityType: AggregateLeave,
+		EntityID:   leave.ID,
+		Channel:    models.AuditChannelEmailLink,
+		TokenID:    row.ID,
+		RequestID:  meta.RequestID,
+		IP:         meta.IP,
+		Detail:     remark,
+	})
+	if err != nil {
+		log.Printf("Failed to record audit log for leave action token %s: %v", row.ID, err)
+	}
+
+	leave.Status = status
+	leave.ApproveRemark = remark
+	leave.ApproverID = &approverID
+	return &LeaveActionPreview{TokenID: row.ID, Action: row.Action, Leave: leave, ExpiresAt: row.ExpiresAt}, nil
+}
+
+// load 驗證令牌的簽名與有效期，並確認令牌存在且未使用
+func (s *LeaveActionService) load(token string) (*models.LeaveActionToken, error) {
+	claims, err := s.verify(token)
+	if err != nil {
+		return nil, err
+	}
+	row, err := s.repo.GetToken(claims.TokenID)
+	if apperrors.IsNotFound(err) {
+		return nil, errInvalidActionToken()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if row.LeaveID != claims.LeaveID || row.ApproverID != claims.ApproverID || row.Action != claims.Action {
+		return nil, errInvalidActionToken()
+	}
+	if row.UsedAt != nil {
+		return nil, errActionTokenUsed()
+	}
+	return row, nil
+}
+
+// pendingLeave 獲取待審批的請假記錄，已處理的請假不能再通過連結審批
+func (s *LeaveActionService) pendingLeave(id uint) (*models.Leave, error) {
+	leave, err := s.leaveRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if leave.Status != models.LeaveStatusPending {
+		return nil, apperrors.Conflict(apperrors.CodeLeaveProcessed, "This leave request has already been processed")
+	}
+	return leave, nil
+}
+
+func (s *LeaveActionService) sign(claims leaveActionClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.mac(encoded)), nil
+}
+
+func (s *LeaveActionService) verify(token string) (*leaveActionClaims, error) {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, errInvalidActionToken()
+	}
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, s.mac(encoded)) {
+		return nil, errInvalidActionToken()
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errInvalidActionToken()
+	}
+	var claims leaveActionClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.TokenID == "" {
+		return nil, errInvalidActionToken()
+	}
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return nil, apperrors.Forbidden(apperrors.CodeActionTokenExpired, "This link has expired")
+	}
+	return &claims, nil
+}
+
+func (s *LeaveActionService) mac(encoded string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
+
+func errInvalidActionToken() error {
+	return apperrors.Forbidden(apperrors.CodeInvalidActionToken, "This link is invalid")
+}
+
+func errActionTokenUsed() error {
+	return apperrors.Conflict(apperrors.CodeActionTokenUsed, "This link has already been used")
+}
+
+func newActionTokenID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,372 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+	"hr-system/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+// LeaveBalanceService 管理假期額度流水，並在年終按配置遞延、作廢或折發未休天數
+// 某年度的剩餘額度 = 該年度的流水合計 - 該年度內已核准且扣除額度的請假天數
+// 請假天數只計算排定有班次的日子，週末、公司假日與排休的日子不扣除額度
+type LeaveBalanceService struct {
+	ledgerRepo   *repositories.LeaveLedgerRepository
+	closeRepo    *repositories.YearEndCloseRepository
+	employeeRepo *repositories.EmployeeRepository
+	leaveRepo    *repositories.LeaveRepository
+	schedules    *ScheduleService
+	config       config.YearEndConfig
+	now          func() time.Time
+}
+
+func NewLeaveBalanceService(ledgerRepo *repositories.LeaveLedgerRepository, closeRepo *repositories.YearEndCloseRepository, employeeRepo *repositories.EmployeeRepository, leaveRepo *repositories.LeaveRepository, schedules *ScheduleService, cfg config.YearEndConfig) *LeaveBalanceService {
+	return &LeaveBalanceService{
+		ledgerRepo:   ledgerRepo,
+		closeRepo:    closeRepo,
+		employeeRepo: employeeRepo,
+		leaveRepo:    leaveRepo,
+		schedules:    schedules,
+		config:       cfg,
+		now:          time.Now,
+	}
+}
+
+// AddEntry 人工寫入額度流水，已結算的年度不能再寫入
+func (s *LeaveBalanceService) AddEntry(entry *models.LeaveLedgerEntry) error {
+	if _, err := s.employeeRepo.GetByID(entry.EmployeeID); err != nil {
+		if apperrors.IsNotFound(err) {
+			return apperrors.Validation(apperrors.CodeEmployeeNotFound, "Employee not found",
+				apperrors.Field("employee_id", apperrors.CodeEmployeeNotFound, "Employee not found"))
+		}
+		return err
+	}
+	closed, err := s.closeRepo.Exists(entry.Year)
+	if err != nil {
+		return err
+	}
+	if closed {
+		return errYearAlreadyClosed()
+	}
+	return s.ledgerRepo.Create(entry)
+}
+
+// ListEntries 按時間順序獲取員工某年度的額度流水
+func (s *LeaveBalanceService) ListEntries(employeeID uint, year int) ([]models.LeaveLedgerEntry, error) {
+	if _, err := s.employeeRepo.GetByID(employeeID); err != nil {
+		return nil, err
+	}
+	return s.ledgerRepo.ListByEmployee(employeeID, year)
+}
+
+// Balances 獲取員工某年度各類請假的額度，包括有流水、已使用或配置了年終結算的類型
+func (s *LeaveBalanceService) Balances(employeeID uint, year int) ([]models.LeaveBalance, error) {
+	employee, err := s.employeeRepo.GetByID(employeeID)
+	if err != nil {
+		return nil, err
+	}
+	sums, err := s.ledgerRepo.SumByYear(year, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	from, to := yearRange(year)
+	leaves, err := s.leaveRepo.ListOverlapping([]uint{employeeID}, []string{models.LeaveStatusApproved}, from, to)
+	if err != nil {
+		return nil, err
+	}
+	shifts, err := s.schedules.scheduledShifts([]models.Employee{*employee}, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make(map[string]*models.LeaveBalance)
+	balance := func(leaveType string) *models.LeaveBalance {
+		if b, ok := balances[leaveType]; ok {
+			return b
+		}
+		b := &models.LeaveBalance{LeaveType: leaveType, Year: year}
+		balances[leaveType] = b
+		return b
+	}
+	for leaveType := range s.config.Policies {
+		balance(leaveType)
+	}
+	for _, sum := range sums {
+		b := balance(sum.LeaveType)
+		b.Entitled += sum.Entitled
+		b.Settled -= sum.Settled
+	}
+	for i := range leaves {
+		if models.LeaveTypeDeductsBalance(leaves[i].LeaveType) {
+			balance(leaves[i].LeaveType).Used += float64(shiftDays(&leaves[i], shifts[employeeID], from, to))
+		}
+	}
+
+	result := make([]models.LeaveBalance, 0, len(balances))
+	for _, b := range balances {
+		b.Remaining = b.Entitled - b.Used - b.Settled
+		result = append(result, *b)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].LeaveType < result[j].LeaveType })
+	return result, nil
+}
+
+// PreviewClose 按當前的流水與請假計算年終結算結果，不寫入任何數據
+func (s *LeaveBalanceService) PreviewClose(year int) (*models.YearEndClose, error) {
+	if err := s.validateCloseYear(year); err != nil {
+		return nil, err
+	}
+	return s.computeClose(year)
+}
+
+// CommitClose 執行年終結算並寫入結算結果與額度流水，返回的 created 表示本次是否執行了結算
+// 該年度已結算時直接返回已有的結算，重複提交不會重複寫入流水
+func (s *LeaveBalanceService) CommitClose(year int, actorID *uint) (*models.YearEndClose, bool, error) {
+	if err := s.validateCloseYear(year); err != nil {
+		return nil, false, err
+	}
+	closed, err := s.closeRepo.Exists(year)
+	if err != nil {
+		return nil, false, err
+	}
+	if closed {
+		existing, err := s.closeRepo.GetByYear(year)
+		return existing, false, err
+	}
+
+	record, err := s.computeClose(year)
+	if err != nil {
+		return nil, false, err
+	}
+	record.CreatedByID = actorID
+	items := record.Items
+	err = repositories.Transaction(func(tx *gorm.DB) error {
+		if err := s.closeRepo.WithTx(tx).Create(record); err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].CloseID = record.ID
+		}
+		if err := s.closeRepo.WithTx(tx).CreateItems(items); err != nil {
+			return err
+		}
+		return s.ledgerRepo.WithTx(tx).CreateBatch(closeEntries(record, actorID))
+	})
+	if apperrors.IsKind(err, apperrors.KindConflict) {
+		// 並發提交時由先提交的請求完成結算
+		existing, err := s.closeRepo.GetByYear(year)
+		return existing, false, err
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return record, true, nil
+}
+
+// GetClose 獲取某年度的年終結算
+func (s *LeaveBalanceService) GetClose(year int) (*models.YearEndClose, error) {
+	return s.closeRepo.GetByYear(year)
+}
+
+// ListCloses 獲取所有年終結算
+func (s *LeaveBalanceService) ListCloses() ([]models.YearEndClose, error) {
+	return s.closeRepo.List()
+}
+
+// PayoutReport 獲取某年度年終結算中折發工資的明細與合計
+func (s *LeaveBalanceService) PayoutReport(year int) (*models.YearEndPayoutReport, error) {
+	record, err := s.closeRepo.GetByYear(year)
+	if err != nil {
+		return nil, err
+	}
+	report := &models.YearEndPayoutReport{Year: year, Items: []models.YearEndCloseItem{}}
+	for _, item := range record.Items {
+		if item.PaidOut > 0 {
+			report.Items = append(report.Items, item)
+			report.TotalDays += item.PaidOut
+			report.TotalAmount += item.Amount
+		}
+	}
+	return report, nil
+}
+
+// validateCloseYear 不能結算未來的年度
+func (s *LeaveBalanceService) validateCloseYear(year int) error {
+	if year < 1900 || year > s.now().Year() {
+		return apperrors.Validation(apperrors.CodeInvalidCloseYear, "Year must not be in the future",
+			apperrors.Field("year", apperrors.CodeInvalidCloseYear, "Year must not be in the future"))
+	}
+	return nil
+}
+
+// computeClose 計算在職員工每類配置了年終結算的請假的未休天數及處理結果
+// 離職員工的未休天數在離職時結算，不在年終處理
+func (s *LeaveBalanceService) computeClose(year int) (*models.YearEndClose, error) {
+	record := &models.YearEndClose{Year: year, Items: []models.YearEndCloseItem{}}
+	if len(s.config.Policies) == 0 {
+		return record, nil
+	}
+	leaveTypes := make([]string, 0, len(s.config.Policies))
+	for leaveType := range s.config.Policies {
+		leaveTypes = append(leaveTypes, leaveType)
+	}
+	sort.Strings(leaveTypes)
+
+	employees, err := s.employeeRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	sums, err := s.ledgerRepo.SumByYear(year, 0)
+	if err != nil {
+		return nil, err
+	}
+	from, to := yearRange(year)
+	leaves, err := s.leaveRepo.ListApprovedByTypes(leaveTypes, from, to)
+	if err != nil {
+		return nil, err
+	}
+	onLeave := make(map[uint]bool)
+	for _, leave := range leaves {
+		onLeave[leave.EmployeeID] = true
+	}
+	var leaveEmployees []models.Employee
+	for _, employee := range employees {
+		if onLeave[employee.ID] {
+			leaveEmployees = append(leaveEmployees, employee)
+		}
+	}
+	shifts, err := s.schedules.scheduledShifts(leaveEmployees, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct {
+		employeeID uint
+		leaveType  string
+	}
+	unused := make(map[key]float64)
+	for _, sum := range sums {
+		unused[key{sum.EmployeeID, sum.LeaveType}] += sum.Entitled + sum.Settled
+	}
+	for i := range leaves {
+		if models.LeaveTypeDeductsBalance(leaves[i].LeaveType) {
+			unused[key{leaves[i].EmployeeID, leaves[i].LeaveType}] -= float64(shiftDays(&leaves[i], shifts[leaves[i].EmployeeID], from, to))
+		}
+	}
+
+	sort.Slice(employees, func(i, j int) bool { return employees[i].ID < employees[j].ID })
+	for _, employee := range employees {
+		if employee.Status == models.EmployeeStatusInactive {
+			continue
+		}
+		dailyRate := roundTo(employee.Salary/float64(s.config.DailyRateDivisor), 2)
+		for _, leaveType := range leaveTypes {
+			days := unused[key{employee.ID, leaveType}]
+			if days <= 0 {
+				continue
+			}
+			item := settleUnused(s.config.Policies[leaveType], days)
+			item.EmployeeID = employee.ID
+			item.EmployeeName = employee.Name
+			item.Department = employee.Department
+			item.LeaveType = leaveType
+			item.DailyRate = dailyRate
+			item.Amount = roundTo(item.PaidOut*dailyRate, 0)
+
+			record.Items = append(record.Items, item)
+			record.CarriedDays += item.CarriedOver
+			record.ExpiredDays += item.Expired
+			record.PaidDays += item.PaidOut
+			record.PayoutAmount += item.Amount
+		}
+	}
+	return record, nil
+}
+
+// settleUnused 按處理方式分配未休天數
+func settleUnused(policy config.YearEndPolicy, days float64) models.YearEndCloseItem {
+	item := models.YearEndCloseItem{Unused: days}
+	action := policy.Action
+	if action == config.YearEndCarryOver {
+		item.CarriedOver = math.Min(days, policy.MaxCarryDays)
+		days -= item.CarriedOver
+		action = policy.Remainder
+	}
+	switch action {
+	case config.YearEndPayout:
+		item.PaidOut = days
+	case config.YearEndExpire:
+		item.Expired = days
+	}
+	return item
+}
+
+// closeEntries 生成年終結算的額度流水：從結算年度扣除遞延、作廢與折發的天數，遞延的天數轉入次年
+func closeEntries(record *models.YearEndClose, actorID *uint) []models.LeaveLedgerEntry {
+	var entries []models.LeaveLedgerEntry
+	entry := func(item models.YearEndCloseItem, year int, kind string, days, amount float64) {
+		entries = append(entries, models.LeaveLedgerEntry{
+			EmployeeID: item.EmployeeID,
+			Year:       year,
+			LeaveType:  item.LeaveType,
+			Kind:       kind,
+			Days:       days,
+			Amount:     amount,
+			CloseID:    &record.ID,
+			ActorID:    actorID,
+		})
+	}
+	for _, item := range record.Items {
+		if item.CarriedOver > 0 {
+			entry(item, record.Year, models.LeaveLedgerCarriedOver, -item.CarriedOver, 0)
+			entry(item, record.Year+1, models.LeaveLedgerCarryIn, item.CarriedOver, 0)
+		}
+		if item.Expired > 0 {
+			entry(item, record.Year, models.LeaveLedgerExpired, -item.Expired, 0)
+		}
+		if item.PaidOut > 0 {
+			entry(item, record.Year, models.LeaveLedgerPaidOut, -item.PaidOut, item.Amount)
+		}
+	}
+	return entries
+}
+
+func errYearAlreadyClosed() *apperrors.Error {
+	return apperrors.Conflict(apperrors.CodeYearAlreadyClosed, "The year has already been closed")
+}
+
+// yearRange 返回某年度的起止時間 [from, to)
+func yearRange(year int) (time.Time, time.Time) {
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.Local)
+	return from, from.AddDate(1, 0, 0)
+}
+
+// shiftDays 計算請假落在 [from, to) 內且排定有班次的天數，shifts 為員工按 YYYY-MM-DD 索引的班次
+func shiftDays(leave *models.Leave, shifts map[string]models.ScheduledShift, from, to time.Time) int {
+	start, end := startOfDay(leave.StartDate), startOfDay(leave.EndDate).AddDate(0, 0, 1)
+	if start.Before(from) {
+		start = from
+	}
+	if end.After(to) {
+		end = to
+	}
+	var days int
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		if _, ok := shifts[day.Format("2006-01-02")]; ok {
+			days++
+		}
+	}
+	return days
+}
+
+// roundTo 四捨五入到 places 位小數
+func roundTo(value float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(value*scale) / scale
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"testing"
+
+	"hr-system/config"
+	"hr-system/internal/models"
+	"hr-system/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLeaveBalanceService(cfg config.YearEndConfig) *LeaveBalanceService {
+	return NewLeaveBalanceService(repositories.NewLeaveLedgerRepository(), repositories.NewYearEndCloseRepository(),
+		repositories.NewEmployeeRepository(), repositories.NewLeaveRepository(), newTestScheduleService(), cfg)
+}
+
+func TestLeaveBalanceCountsScheduledDays(t *testing.T) {
+	db := setupTestDB(t)
+	service := newTestLeaveBalanceService(config.YearEndConfig{
+		Policies:         map[string]config.YearEndPolicy{models.LeaveTypeAnnual: {Action: config.YearEndPayout}},
+		DailyRateDivisor: 30,
+	})
+	employee := seedEmployee(t, db, models.Employee{Name: "王小明", Salary: 45000})
+	require.NoError(t, db.Create(&models.LeaveLedgerEntry{
+		EmployeeID: employee.ID, Year: 2024, LeaveType: models.LeaveTypeAnnual, Kind: models.LeaveLedgerGrant, Days: 10,
+	}).Error)
+	seedHoliday(t, db, date(2024, 10, 10), "國慶日")
+	seedHoliday(t, db, date(2025, 1, 1), "開國紀念日")
+
+	tests := []struct {
+		name      string
+		leaveType string
+		start     int // 2024-10 的日期
+		end       int
+		wantDays  int
+	}{
+		{name: "週五至週一跨週末", leaveType: models.LeaveTypeAnnual, start: 4, end: 7, wantDays: 2},
+		{name: "週三至週五含國慶日", leaveType: models.LeaveTypeAnnual, start: 9, end: 11, wantDays: 2},
+		{name: "只有週末", leaveType: models.LeaveTypeAnnual, start: 12, end: 13, wantDays: 0},
+		{name: "公假不扣除額度", leaveType: models.LeaveTypeOfficial, start: 14, end: 14, wantDays: 0},
+	}
+	used := 0
+	for _, tt := range tests {
+		seedLeave(t, db, employee.ID, tt.leaveType, date(2024, 10, tt.start), date(2024, 10, tt.end))
+		used += tt.wantDays
+	}
+	// 跨年的請假只計算 2024-12-30、2024-12-31
+	seedLeave(t, db, employee.ID, models.LeaveTypeAnnual, date(2024, 12, 30), date(2025, 1, 2))
+	used += 2
+
+	balances, err := service.Balances(employee.ID, 2024)
+	require.NoError(t, err)
+	require.Len(t, balances, 1)
+	assert.Equal(t, models.LeaveTypeAnnual, balances[0].LeaveType)
+	assert.Equal(t, float64(used), balances[0].Used)
+	assert.Equal(t, float64(10-used), balances[0].Remaining)
+
+	// 2025-01-01 為假日，2025-01-02 計入次年
+	balances, err = service.Balances(employee.ID, 2025)
+	require.NoError(t, err)
+	require.Len(t, balances, 1)
+	assert.Equal(t, float64(1), balances[0].Used)
+
+	// 年終折發的天數與請假額度使用相同的計算
+	record, err := service.PreviewClose(2024)
+	require.NoError(t, err)
+	require.Len(t, record.Items, 1)
+	assert.Equal(t, float64(10-used), record.Items[0].Unused)
+	assert.Equal(t, float64(10-used), record.Items[0].PaidOut)
+	assert.Equal(t, float64((10-used)*1500), record.Items[0].Amount)
+}
+
+func TestLeaveBalanceSkipsRosteredDaysOff(t *testing.T) {
+	db := setupTestDB(t)
+	service := newTestLeaveBalanceService(config.YearEndConfig{DailyRateDivisor: 30})
+	employee := seedEmployee(t, db, models.Employee{Name: "輪班員工"})
+	// 週三至週日上班、週一週二休息
+	seedRoster(t, db, employee.ID, "週末班", 2, 3, 4, 5, 6)
+	// 2024-10-05 週六至 2024-10-08 週二，只有週六、週日排班
+	seedLeave(t, db, employee.ID, models.LeaveTypeAnnual, date(2024, 10, 5), date(2024, 10, 8))
+
+	balances, err := service.Balances(employee.ID, 2024)
+	require.NoError(t, err)
+	require.Len(t, balances, 1)
+	assert.Equal(t, float64(2), balances[0].Used)
+}
@@ -6,42 +6,85 @@ import (
 	"log"
 	"time"
 
+	"hr-system/internal/apperrors"
 	"hr-system/internal/models"
 	"hr-system/internal/repositories"
+
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
 )
 
 type LeaveService struct {
 	leaveRepo    *repositories.LeaveRepository
 	employeeRepo *repositories.EmployeeRepository
-	cacheService *CacheService
+	cacheService CacheService
+	outboxRepo   *repositories.OutboxRepository
+	historyRepo  *repositories.LeaveHistoryRepository
+	approvals    *ApprovalService
+	staffing     *StaffingService
+	attachments  *AttachmentService
+	loadGroup    singleflight.Group // 合併同一ID的並發回源請求
 }
 
-func NewLeaveService(leaveRepo *repositories.LeaveRepository, employeeRepo *repositories.EmployeeRepository, cacheService *CacheService) *LeaveService {
+func NewLeaveService(leaveRepo *repositories.LeaveRepository, employeeRepo *repositories.EmployeeRepository, cacheService CacheService, outboxRepo *repositories.OutboxRepository, historyRepo *repositories.LeaveHistoryRepository, approvals *ApprovalService, staffing *StaffingService, attachments *AttachmentService) *LeaveService {
 	return &LeaveService{
 		leaveRepo:    leaveRepo,
 		employeeRepo: employeeRepo,
 		cacheService: cacheService,
+		outboxRepo:   outboxRepo,
+		historyRepo:  historyRepo,
+		approvals:    approvals,
+		staffing:     staffing,
+		attachments:  attachments,
 	}
 }
 
-// CreateLeave 創建請假記錄
-func (s *LeaveService) CreateLeave(leave *models.Leave) error {
+// CreateLeave 創建請假記錄，返回不滿足的 warn 人力規則
+func (s *LeaveService) CreateLeave(leave *models.Leave) ([]models.StaffingCoverage, error) {
 	// 檢查員工是否存在
-	_, err := s.employeeRepo.GetByID(leave.EmployeeID)
+	employee, err := s.employeeRepo.GetByID(leave.EmployeeID)
+	if apperrors.IsNotFound(err) {
+		return nil, apperrors.Validation(apperrors.CodeEmployeeNotFound, "Employee not found",
+			apperrors.Field("employee_id", apperrors.CodeEmployeeNotFound, "Employee not found"))
+	}
 	if err != nil {
-		return errors.New("employee not found")
+		return nil, err
 	}
 
 	// 檢查日期是否有效
 	if leave.StartDate.After(leave.EndDate) {
-		return errors.New("start date must be before end date")
+		return nil, apperrors.Validation(apperrors.CodeInvalidDateRange, "Start date must be before end date",
+			apperrors.Field("end_date", apperrors.CodeInvalidDateRange, "Start date must be before end date"))
 	}
 
 	// 檢查是否有重疊的請假記錄
 	// TODO: 實現日期重疊檢查
 
-	if err := s.leaveRepo.Create(leave); err != nil {
-		return err
+	// 檢查人力規則，不滿足 block 規則時拒絕提交
+	warnings, err := s.staffing.Check(leave)
+	if err != nil {
+		return nil, err
+	}
+
+	// 請假記錄、歷程與事件在同一事務中寫入
+	err = repositories.Transaction(func(tx *gorm.DB) error {
+		if err := s.leaveRepo.WithTx(tx).Create(leave); err != nil {
+			return err
+		}
+		err := s.historyRepo.WithTx(tx).Create(&models.LeaveHistory{
+			LeaveID: leave.ID,
+			Action:  models.LeaveHistorySubmitted,
+			ActorID: &leave.EmployeeID,
+		})
+		if err != nil {
+			return err
+		}
+		// 與 GetByID 預加載的結果保持一致，避免緩存與事件中的 Employee 為空
+		leave.Employee = *employee
+		return appendEvent(s.outboxRepo.WithTx(tx), NewEvent(models.EventLeaveSubmitted, AggregateLeave, leave.ID, leave))
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// 添加到緩存
@@ -51,7 +94,7 @@ func (s *LeaveService) CreateLeave(leave *models.Leave) error {
 		log.Printf("Failed to cache leave: %v", err)
 	}
 
-	return nil
+	return warnings, nil
 }
 
 // GetLeave 獲取請假記錄
@@ -60,44 +103,117 @@ func (s *LeaveService) GetLeave(id uint) (*models.Leave, error) {
 
 	// 先從緩存獲取
 	leave, err := s.cacheService.GetLeave(ctx, id)
-	if err == nil {
+	switch {
+	case err == nil:
 		return leave, nil
+	case errors.Is(err, ErrCacheNotFound):
+		// 命中負緩存，該請假記錄不存在
+		return nil, apperrors.NotFound(apperrors.CodeLeaveNotFound, "Leave record not found")
+	case !errors.Is(err, ErrCacheMiss):
+		// 緩存本身出錯，記錄日誌後回源，但不再回寫緩存
+		log.Printf("Failed to read leave cache: %v", err)
 	}
+	writeBack := errors.Is(err, ErrCacheMiss)
+
+	// 緩存未命中，從數據庫獲取；同一ID的並發請求只回源一次
+	result, err, _ := s.loadGroup.Do(leaveCacheKey(id), func() (interface{}, error) {
+		leave, err := s.leaveRepo.GetByID(id)
+		if apperrors.IsNotFound(err) && writeBack {
+			if err := s.cacheService.SetLeaveNotFound(ctx, id); err != nil {
+				log.Printf("Failed to cache missing leave: %v", err)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
 
-	// 緩存未命中，從數據庫獲取
-	leave, err = s.leaveRepo.GetByID(id)
+		// 添加到緩存
+		if writeBack {
+			if err := s.cacheService.SetLeave(ctx, leave); err != nil {
+				log.Printf("Failed to cache leave: %v", err)
+			}
+		}
+		return leave, nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	// 添加到緩存
-	if err := s.cacheService.SetLeave(ctx, leave); err != nil {
-		log.Printf("Failed to cache leave: %v", err)
-	}
-
-	return leave, nil
+	// 每個調用方拿到獨立的副本，避免共享同一個對象
+	loaded := *result.(*models.Leave)
+	return &loaded, nil
 }
 
-// UpdateLeaveStatus 更新請假狀態
-func (s *LeaveService) UpdateLeaveStatus(id uint, status string, remark string) error {
+// UpdateLeaveStatus 更新請假狀態，approverID 為執行審批的員工
+// 核准時返回不滿足的 warn 人力規則
+func (s *LeaveService) UpdateLeaveStatus(id uint, status string, remark string, approverID uint) ([]models.StaffingCoverage, error) {
 	leave, err := s.leaveRepo.GetByID(id)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	// 只能審批待審批的請假，已核准、已駁回或已取消的請假不能再改變審批結果
+	if leave.Status != models.LeaveStatusPending {
+		return nil, errLeaveProcessed()
 	}
 
 	// 檢查狀態是否有效
-	if status != "approved" && status != "rejected" {
-		return errors.New("invalid status")
+	if status != models.LeaveStatusApproved && status != models.LeaveStatusRejected {
+		return nil, apperrors.Validation(apperrors.CodeInvalidStatus, "Invalid status",
+			apperrors.Field("status", apperrors.CodeInvalidStatus, "Status must be approved or rejected"))
+	}
+
+	// 檢查審批人是否為直屬主管或當前的代理、升級審批人，代理審批時記錄原審批人
+	onBehalfOf, err := s.approvals.AuthorizeApproval(leave, approverID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 核准前檢查必要的附件，並重新檢查人力規則，提交後其他請假可能已被核准
+	var warnings []models.StaffingCoverage
+	if status == models.LeaveStatusApproved {
+		if err := s.attachments.RequireAttachments(leave); err != nil {
+			return nil, err
+		}
+		if warnings, err = s.staffing.Check(leave); err != nil {
+			return nil, err
+		}
 	}
 
 	// 更新狀態
 	leave.Status = status
 	leave.ApproveRemark = remark
+	leave.ApproverID = &approverID
+	leave.OnBehalfOfID = onBehalfOf
 	now := time.Now()
 	leave.ApproveTime = &now
 
-	if err := s.leaveRepo.Update(leave); err != nil {
-		return err
+	eventType, action := models.EventLeaveApproved, models.LeaveHistoryApproved
+	if status == models.LeaveStatusRejected {
+		eventType, action = models.EventLeaveRejected, models.LeaveHistoryRejected
+	}
+	// 只更新仍待審批的請假，並發的核准與駁回只有一個成功
+	err = repositories.Transaction(func(tx *gorm.DB) error {
+		decided, err := s.leaveRepo.WithTx(tx).Decide(leave)
+		if err != nil {
+			return err
+		}
+		if !decided {
+			return errLeaveProcessed()
+		}
+		err = s.historyRepo.WithTx(tx).Create(&models.LeaveHistory{
+			LeaveID:            leave.ID,
+			Action:             action,
+			ActorID:            &approverID,
+			PreviousApproverID: onBehalfOf,
+			Remark:             remark,
+		})
+		if err != nil {
+			return err
+		}
+		return appendEvent(s.outboxRepo.WithTx(tx), NewEvent(eventType, AggregateLeave, leave.ID, leave))
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// 更新緩存
@@ -106,12 +222,62 @@ func (s *LeaveService) UpdateLeaveStatus(id uint, status string, remark string)
 		log.Printf("Failed to update leave cache: %v", err)
 	}
 
+	return warnings, nil
+}
+
+// CancelLeave 取消待審批或已核准的請假，actorID 為執行取消的員工，只能是申請人或請假當前的審批人
+func (s *LeaveService) CancelLeave(id uint, actorID uint) error {
+	leave, err := s.leaveRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if actorID != leave.EmployeeID {
+		if _, err := s.approvals.AuthorizeApproval(leave, actorID); err != nil {
+			if apperrors.IsNotFound(err) || apperrors.IsKind(err, apperrors.KindForbidden) {
+				return apperrors.Forbidden(apperrors.CodeNotLeaveOwner, "Only the applicant or the approver can cancel this leave request")
+			}
+			return err
+		}
+	}
+	if leave.Status != models.LeaveStatusPending && leave.Status != models.LeaveStatusApproved {
+		return errLeaveNotCancelable()
+	}
+
+	leave.Status = models.LeaveStatusCancelled
+	err = repositories.Transaction(func(tx *gorm.DB) error {
+		cancelled, err := s.leaveRepo.WithTx(tx).Cancel(leave.ID)
+		if err != nil {
+			return err
+		}
+		if !cancelled {
+			return errLeaveNotCancelable()
+		}
+		if err := s.historyRepo.WithTx(tx).Create(&models.LeaveHistory{LeaveID: leave.ID, Action: models.LeaveHistoryCancelled, ActorID: &actorID}); err != nil {
+			return err
+		}
+		return appendEvent(s.outboxRepo.WithTx(tx), NewEvent(models.EventLeaveCancelled, AggregateLeave, leave.ID, leave))
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if err := s.cacheService.SetLeave(ctx, leave); err != nil {
+		log.Printf("Failed to update leave cache: %v", err)
+	}
+
 	return nil
 }
 
 // DeleteLeave 刪除請假記錄
 func (s *LeaveService) DeleteLeave(id uint) error {
-	if err := s.leaveRepo.Delete(id); err != nil {
+	err := repositories.Transaction(func(tx *gorm.DB) error {
+		if err := s.leaveRepo.WithTx(tx).Delete(id); err != nil {
+			return err
+		}
+		return appendEvent(s.outboxRepo.WithTx(tx), NewEvent(models.EventLeaveDeleted, AggregateLeave, id, map[string]uint{"id": id}))
+	})
+	if err != nil {
 		return err
 	}
 
@@ -124,7 +290,23 @@ func (s *LeaveService) DeleteLeave(id uint) error {
 	return nil
 }
 
+// GetLeaveHistory 按時間順序獲取請假的審批歷程
+func (s *LeaveService) GetLeaveHistory(id uint) ([]models.LeaveHistory, error) {
+	if _, err := s.leaveRepo.GetByID(id); err != nil {
+		return nil, err
+	}
+	return s.historyRepo.ListByLeave(id)
+}
+
 // ListLeaves 獲取所有請假記錄
 func (s *LeaveService) ListLeaves() ([]models.Leave, error) {
 	return s.leaveRepo.GetAll()
 }
+
+func errLeaveProcessed() *apperrors.Error {
+	return apperrors.Conflict(apperrors.CodeLeaveProcessed, "This leave request has already been processed")
+}
+
+func errLeaveNotCancelable() *apperrors.Error {
+	return apperrors.Conflict(apperrors.CodeLeaveNotCancelable, "Only pending or approved leave can be cancelled")
+}
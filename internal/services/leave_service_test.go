@@ -0,0 +1,206 @@
+package services
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+	"hr-system/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLeaveService() *LeaveService {
+	employeeRepo := repositories.NewEmployeeRepository()
+	leaveRepo := repositories.NewLeaveRepository()
+	historyRepo := repositories.NewLeaveHistoryRepository()
+	cacheService := NewNoopCacheService()
+	approvals := NewApprovalService(repositories.NewDelegationRepository(), employeeRepo, leaveRepo)
+	staffing := NewStaffingService(repositories.NewStaffingRuleRepository(), employeeRepo, leaveRepo, newTestScheduleService())
+	attachments := NewAttachmentService(leaveRepo, repositories.NewLeaveAttachmentRepository(), historyRepo,
+		employeeRepo, approvals, nil, cacheService, config.AttachmentConfig{})
+	return NewLeaveService(leaveRepo, employeeRepo, cacheService, repositories.NewOutboxRepository(), historyRepo,
+		approvals, staffing, attachments)
+}
+
+func TestUpdateLeaveStatusRequiresPending(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  string
+		status   string
+		wantCode string
+	}{
+		{name: "核准待審批的請假", current: models.LeaveStatusPending, status: models.LeaveStatusApproved},
+		{name: "駁回待審批的請假", current: models.LeaveStatusPending, status: models.LeaveStatusRejected},
+		{name: "核准已取消的請假", current: models.LeaveStatusCancelled, status: models.LeaveStatusApproved, wantCode: apperrors.CodeLeaveProcessed},
+		{name: "核准已駁回的請假", current: models.LeaveStatusRejected, status: models.LeaveStatusApproved, wantCode: apperrors.CodeLeaveProcessed},
+		{name: "駁回已核准的請假", current: models.LeaveStatusApproved, status: models.LeaveStatusRejected, wantCode: apperrors.CodeLeaveProcessed},
+		{name: "重複核准", current: models.LeaveStatusApproved, status: models.LeaveStatusApproved, wantCode: apperrors.CodeLeaveProcessed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupTestDB(t)
+			service := newTestLeaveService()
+			manager := seedEmployee(t, db, models.Employee{Name: "主管"})
+			employee := seedEmployee(t, db, models.Employee{Name: "員工", ManagerID: &manager.ID})
+			leave := models.Leave{
+				EmployeeID: employee.ID,
+				StartDate:  time.Date(2024, 7, 1, 0, 0, 0, 0, time.Local),
+				EndDate:    time.Date(2024, 7, 1, 0, 0, 0, 0, time.Local),
+				LeaveType:  models.LeaveTypePersonal,
+				Status:     tt.current,
+			}
+			require.NoError(t, db.Create(&leave).Error)
+
+			_, err := service.UpdateLeaveStatus(leave.ID, tt.status, "", manager.ID)
+
+			var stored models.Leave
+			require.NoError(t, db.First(&stored, leave.ID).Error)
+			var history, events int64
+			db.Model(&models.LeaveHistory{}).Where("leave_id = ?", leave.ID).Count(&history)
+			db.Model(&models.OutboxMessage{}).Count(&events)
+			if tt.wantCode == "" {
+				require.NoError(t, err)
+				assert.Equal(t, tt.status, stored.Status)
+				assert.Equal(t, int64(1), history)
+				assert.Equal(t, int64(1), events)
+				return
+			}
+			var appErr *apperrors.Error
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, tt.wantCode, appErr.Code)
+			assert.Equal(t, tt.current, stored.Status, "已處理的請假狀態不應被修改")
+			assert.Zero(t, history)
+			assert.Zero(t, events)
+		})
+	}
+}
+
+func TestUpdateLeaveStatusAuthorizesApprover(t *testing.T) {
+	db := setupTestDB(t)
+	service := newTestLeaveService()
+	manager := seedEmployee(t, db, models.Employee{Name: "主管"})
+	other := seedEmployee(t, db, models.Employee{Name: "其他主管"})
+	employee := seedEmployee(t, db, models.Employee{Name: "員工", ManagerID: &manager.ID})
+	leave := models.Leave{
+		EmployeeID: employee.ID,
+		StartDate:  time.Date(2024, 7, 1, 0, 0, 0, 0, time.Local),
+		EndDate:    time.Date(2024, 7, 1, 0, 0, 0, 0, time.Local),
+		LeaveType:  models.LeaveTypePersonal,
+		Status:     models.LeaveStatusPending,
+	}
+	require.NoError(t, db.Create(&leave).Error)
+
+	_, err := service.UpdateLeaveStatus(leave.ID, models.LeaveStatusApproved, "", other.ID)
+	var appErr *apperrors.Error
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apperrors.CodeNotApprover, appErr.Code)
+
+	_, err = service.UpdateLeaveStatus(leave.ID, models.LeaveStatusApproved, "", manager.ID)
+	require.NoError(t, err)
+	var stored models.Leave
+	require.NoError(t, db.First(&stored, leave.ID).Error)
+	assert.Equal(t, models.LeaveStatusApproved, stored.Status)
+	require.NotNil(t, stored.ApproverID)
+	assert.Equal(t, manager.ID, *stored.ApproverID)
+}
+
+func TestUpdateLeaveStatusConcurrentDecisions(t *testing.T) {
+	db := setupTestDB(t)
+	service := newTestLeaveService()
+	manager := seedEmployee(t, db, models.Employee{Name: "主管"})
+	employee := seedEmployee(t, db, models.Employee{Name: "員工", ManagerID: &manager.ID})
+	leave := models.Leave{
+		EmployeeID: employee.ID,
+		StartDate:  date(2024, 7, 1),
+		EndDate:    date(2024, 7, 1),
+		LeaveType:  models.LeaveTypePersonal,
+		Status:     models.LeaveStatusPending,
+	}
+	require.NoError(t, db.Create(&leave).Error)
+
+	// 同時核准與駁回，只有一個成功，另一個返回已處理
+	statuses := []string{models.LeaveStatusApproved, models.LeaveStatusRejected, models.LeaveStatusApproved, models.LeaveStatusRejected}
+	errs := make([]error, len(statuses))
+	var wg sync.WaitGroup
+	for i, status := range statuses {
+		wg.Add(1)
+		go func(i int, status string) {
+			defer wg.Done()
+			_, errs[i] = service.UpdateLeaveStatus(leave.ID, status, "", manager.ID)
+		}(i, status)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		var appErr *apperrors.Error
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, apperrors.CodeLeaveProcessed, appErr.Code)
+	}
+	assert.Equal(t, 1, succeeded)
+	var history, events int64
+	db.Model(&models.LeaveHistory{}).Where("leave_id = ?", leave.ID).Count(&history)
+	db.Model(&models.OutboxMessage{}).Count(&events)
+	assert.Equal(t, int64(1), history)
+	assert.Equal(t, int64(1), events)
+}
+
+func TestCancelLeaveRequiresApplicantOrApprover(t *testing.T) {
+	tests := []struct {
+		name     string
+		actor    string
+		status   string
+		wantCode string
+	}{
+		{name: "申請人取消待審批的請假", actor: "員工", status: models.LeaveStatusPending},
+		{name: "申請人取消已核准的請假", actor: "員工", status: models.LeaveStatusApproved},
+		{name: "主管取消請假", actor: "主管", status: models.LeaveStatusApproved},
+		{name: "其他員工不能取消", actor: "同事", status: models.LeaveStatusPending, wantCode: apperrors.CodeNotLeaveOwner},
+		{name: "已駁回的請假不能取消", actor: "員工", status: models.LeaveStatusRejected, wantCode: apperrors.CodeLeaveNotCancelable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupTestDB(t)
+			service := newTestLeaveService()
+			manager := seedEmployee(t, db, models.Employee{Name: "主管"})
+			employee := seedEmployee(t, db, models.Employee{Name: "員工", ManagerID: &manager.ID})
+			colleague := seedEmployee(t, db, models.Employee{Name: "同事", ManagerID: &manager.ID})
+			actors := map[string]uint{"主管": manager.ID, "員工": employee.ID, "同事": colleague.ID}
+			leave := models.Leave{
+				EmployeeID: employee.ID,
+				StartDate:  date(2024, 7, 1),
+				EndDate:    date(2024, 7, 1),
+				LeaveType:  models.LeaveTypePersonal,
+				Status:     tt.status,
+			}
+			require.NoError(t, db.Create(&leave).Error)
+
+			err := service.CancelLeave(leave.ID, actors[tt.actor])
+
+			var stored models.Leave
+			require.NoError(t, db.First(&stored, leave.ID).Error)
+			if tt.wantCode == "" {
+				require.NoError(t, err)
+				assert.Equal(t, models.LeaveStatusCancelled, stored.Status)
+				var history models.LeaveHistory
+				require.NoError(t, db.Where("leave_id = ?", leave.ID).First(&history).Error)
+				require.NotNil(t, history.ActorID)
+				assert.Equal(t, actors[tt.actor], *history.ActorID)
+				return
+			}
+			var appErr *apperrors.Error
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, tt.wantCode, appErr.Code)
+			assert.Equal(t, tt.status, stored.Status)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+
+	"hr-system/internal/models"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// subscriberBuffer 每個推送連接的緩衝通知數，客戶端處理過慢時多餘的通知會被丟棄，客戶端可重新拉取列表
+const subscriberBuffer = 16
+
+// NotificationHub 將新的站內通知推送給本副本上已連接的客戶端
+// Redis 可用時通知經由 Redis pub/sub 廣播到所有副本，客戶端連接在任一副本上都能收到推送
+type NotificationHub struct {
+	client  *redis.Client // 為空時只推送給本副本的客戶端
+	channel string
+
+	mu          sync.RWMutex
+	subscribers map[uint]map[chan models.Notification]struct{}
+}
+
+func NewNotificationHub(client *redis.Client, channel string) *NotificationHub {
+	return &NotificationHub{
+		client:      client,
+		channel:     channel,
+		subscribers: make(map[uint]map[chan models.Notification]struct{}),
+	}
+}
+
+// Subscribe 訂閱收件人的新通知，返回的函數用於取消訂閱
+func (h *NotificationHub) Subscribe(recipientID uint) (<-chan models.Notification, func()) {
+	ch := make(chan models.Notification, subscriberBuffer)
+	h.mu.Lock()
+	if h.subscribers[recipientID] == nil {
+		h.subscribers[recipientID] = make(map[chan models.Notification]struct{})
+	}
+	h.subscribers[recipientID][ch] = struct{}{}
+	h.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			h.mu.Lock()
+			delete(h.subscribers[recipientID], ch)
+			if len(h.subscribers[recipientID]) == 0 {
+				delete(h.subscribers, recipientID)
+			}
+			h.mu.Unlock()
+		})
+	}
+}
+
+// Broadcast 廣播一條新通知
+func (h *NotificationHub) Broadcast(ctx context.Context, n *models.Notification) error {
+	if h.client == nil {
+		h.dispatch(*n)
+		return nil
+	}
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	return h.client.Publish(ctx, h.channel, payload).Err()
+}
+
+// Start 開始接收其他副本（包括本副本）經 Redis 廣播的通知，Redis 不可用時不做任何事
+func (h *NotificationHub) Start(ctx context.Context) {
+	if h.client == nil {
+		return
+	}
+	// 斷線後由 go-redis 自動重連並重新訂閱
+	pubsub := h.client.Subscribe(ctx, h.channel)
+	go func() {
+		defer pubsub.Close()
+		ch := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-ch:
+				if !ok {
+					return
+				}
+				var n models.Notification
+				if err := json.Unmarshal([]byte(msg.Payload), &n); err != nil {
+					log.Printf("Failed to decode broadcast notification: %v", err)
+					continue
+				}
+				h.dispatch(n)
+			}
+		}
+	}()
+}
+
+// dispatch 推送給本副本上該收件人的所有連接
+func (h *NotificationHub) dispatch(n models.Notification) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for ch := range h.subscribers[n.RecipientID] {
+		select {
+		case ch <- n:
+		default:
+		}
+	}
+}
@@ -0,0 +1,434 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/i18n"
+	"hr-system/internal/models"
+	"hr-system/internal/notifications"
+	"hr-system/internal/repositories"
+)
+
+// NotificationService 根據業務事件通知相關員工
+// 作為事件出口接收請假事件，為收件人創建站內通知並即時推送；郵件按收件人的偏好與語系生成發送記錄，
+// 再由後台發送，失敗時按指數退避重試
+type NotificationService struct {
+	repo         *repositories.NotificationRepository
+	employeeRepo *repositories.EmployeeRepository
+	mailer       notifications.Mailer
+	hub          *NotificationHub
+	approvals    *ApprovalService    // 為空時提交與取消通知一律發給直屬主管
+	actions      *LeaveActionService // 為空時審批郵件不包含操作連結
+	cfg          config.NotificationConfig
+	wake         chan struct{}
+}
+
+// NotificationPage 站內通知的分頁結果
+type NotificationPage struct {
+	Items    []models.Notification `json:"items"`
+	Total    int64                 `json:"total"`  // 符合篩選條件的通知總數
+	Unread   int64                 `json:"unread"` // 未讀通知總數，不受篩選條件影響
+	Page     int                   `json:"page"`
+	PageSize int                   `json:"page_size"`
+}
+
+// maxReplayNotifications 推送流重連時最多補發的通知數
+const maxReplayNotifications = 100
+
+func NewNotificationService(repo *repositories.NotificationRepository, employeeRepo *repositories.EmployeeRepository, mailer notifications.Mailer, hub *NotificationHub, approvals *ApprovalService, actions *LeaveActionService, cfg config.NotificationConfig) *NotificationService {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 50
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 1
+	}
+	return &NotificationService{
+		repo:         repo,
+		employeeRepo: employeeRepo,
+		mailer:       mailer,
+		hub:          hub,
+		approvals:    approvals,
+		actions:      actions,
+		cfg:          cfg,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+// GetPreferences 獲取員工對每類事件的通知偏好，未設置的事件默認接收
+func (s *NotificationService) GetPreferences(employeeID uint) ([]models.NotificationPreference, error) {
+	if _, err := s.employeeRepo.GetByID(employeeID); err != nil {
+		return nil, err
+	}
+	saved, err := s.repo.GetPreferences(employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	byEvent := make(map[string]models.NotificationPreference, len(saved))
+	for _, pref := range saved {
+		byEvent[pref.EventType] = pref
+	}
+	prefs := make([]models.NotificationPreference, 0, len(models.NotificationEventTypes))
+	for _, eventType := range models.NotificationEventTypes {
+		pref, ok := byEvent[eventType]
+		if !ok {
+			pref = models.NotificationPreference{EmployeeID: employeeID, EventType: eventType, Email: true}
+		}
+		prefs = append(prefs, pref)
+	}
+	return prefs, nil
+}
+
+// UpdatePreferences 更新員工的通知偏好，未提及的事件保持不變
+func (s *NotificationService) UpdatePreferences(employeeID uint, prefs []models.NotificationPreference) ([]models.NotificationPreference, error) {
+	if _, err := s.employeeRepo.GetByID(employeeID); err != nil {
+		return nil, err
+	}
+	for i := range prefs {
+		if !containsEventType(models.NotificationEventTypes, prefs[i].EventType) {
+			return nil, apperrors.Validation(apperrors.CodeInvalidNotificationEvent,
+				fmt.Sprintf("Notification preferences are not supported for event type %q", prefs[i].EventType))
+		}
+		prefs[i].EmployeeID = employeeID
+	}
+	if err := s.repo.UpsertPreferences(prefs); err != nil {
+		return nil, err
+	}
+	return s.GetPreferences(employeeID)
+}
+
+// ListLogs 按收件人與狀態查詢通知記錄
+func (s *NotificationService) ListLogs(recipientID uint, status string, limit int) ([]models.NotificationLog, error) {
+	return s.repo.ListLogs(recipientID, status, limit)
+}
+
+// ListNotifications 分頁獲取員工的站內通知，page 從 1 開始
+func (s *NotificationService) ListNotifications(recipientID uint, unreadOnly bool, page, pageSize int) (*NotificationPage, error) {
+	items, total, err := s.repo.ListNotifications(recipientID, unreadOnly, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := s.repo.CountUnread(recipientID)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []models.Notification{}
+	}
+	return &NotificationPage{Items: items, Total: total, Unread: unread, Page: page, PageSize: pageSize}, nil
+}
+
+// NotificationsAfter 獲取ID大於 afterID 的站內通知，用於推送流重連後補發錯過的通知
+func (s *NotificationService) NotificationsAfter(recipientID, afterID uint) ([]models.Notification, error) {
+	return s.repo.ListNotificationsAfter(recipientID, afterID, maxReplayNotifications)
+}
+
+// MarkRead 將一條站內通知標記為已讀
+func (s *NotificationService) MarkRead(recipientID, id uint) error {
+	return s.repo.MarkNotificationRead(recipientID, id, time.Now())
+}
+
+// MarkAllRead 將所有站內通知標記為已讀，返回標記的數量
+func (s *NotificationService) MarkAllRead(recipientID uint) (int64, error) {
+	return s.repo.MarkAllNotificationsRead(recipientID, time.Now())
+}
+
+// Subscribe 訂閱員工的新站內通知
+func (s *NotificationService) Subscribe(recipientID uint) (<-chan models.Notification, func()) {
+	return s.hub.Subscribe(recipientID)
+}
+
+// Name 事件出口名稱
+func (s *NotificationService) Name() string {
+	return config.OutboxSinkNotification
+}
+
+// Publish 為事件的收件人創建站內通知與郵件發送記錄，郵件由後台發送
+// 同一事件對同一收件人只會創建一條記錄，事件中繼重複發佈時不會重複推送或發送
+func (s *NotificationService) Publish(ctx context.Context, msg *models.OutboxMessage) error {
+	if !containsEventType(models.NotificationEventTypes, msg.EventType) {
+		return nil
+	}
+	var event struct {
+		Data models.Leave `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+		return fmt.Errorf("decode leave event %s: %w", msg.EventID, err)
+	}
+	leave := &event.Data
+
+	employee, err := s.employeeRepo.GetByID(leave.EmployeeID)
+	if apperrors.IsNotFound(err) {
+		log.Printf("Skipping notification for event %s: employee %d not found", msg.EventID, leave.EmployeeID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// 提交、取消、提醒與升級通知審批人（主管不在時為代理人或上一級主管，升級後為升級後的審批人），
+	// 審批結果通知申請人
+	var recipient *models.Employee
+	switch msg.EventType {
+	case models.EventLeaveSubmitted, models.EventLeaveCancelled, models.EventLeaveReminded, models.EventLeaveEscalated:
+		if employee.ManagerID == nil {
+			return nil
+		}
+		approverID := *employee.ManagerID
+		if s.approvals != nil {
+			leave.Employee = *employee
+			approver, err := s.approvals.ResolveLeaveApprover(leave, msg.CreatedAt)
+			if err != nil {
+				return err
+			}
+			approverID = approver.ApproverID
+		}
+		recipient, err = s.employeeRepo.GetByID(approverID)
+		if apperrors.IsNotFound(err) {
+			log.Printf("Skipping notification for event %s: approver %d not found", msg.EventID, approverID)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	default:
+		recipient = employee
+	}
+
+	if err := s.createInApp(ctx, msg, leave, employee, recipient); err != nil {
+		return err
+	}
+
+	entry, err := s.buildLog(msg, leave, employee, recipient)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.CreateLogs([]models.NotificationLog{*entry}); err != nil {
+		return err
+	}
+	if entry.Status == models.NotificationStatusPending {
+		s.notify()
+	}
+	return nil
+}
+
+// createInApp 按收件人的語系創建站內通知，首次創建時推送給已連接的客戶端
+func (s *NotificationService) createInApp(ctx context.Context, msg *models.OutboxMessage, leave *models.Leave, employee, recipient *models.Employee) error {
+	locale := i18n.Preferred(recipient.Locale)
+	title, body := i18n.Notification(locale, notifications.TemplateName(msg.EventType), i18n.Vars{
+		"employee":   employee.Name,
+		"leave_type": i18n.Label(locale, "leave_type", leave.LeaveType),
+		"start_date": leave.StartDate.Format("2006-01-02"),
+		"end_date":   leave.EndDate.Format("2006-01-02"),
+		"remark":     leave.ApproveRemark,
+	})
+	n := &models.Notification{
+		RecipientID: recipient.ID,
+		EventID:     msg.EventID,
+		EventType:   msg.EventType,
+		LeaveID:     leave.ID,
+		Title:       title,
+		Body:        body,
+	}
+	created, err := s.repo.CreateNotification(n)
+	if err != nil || !created {
+		return err
+	}
+	// 推送失敗不影響通知本身，客戶端刷新列表時仍能看到
+	if err := s.hub.Broadcast(ctx, n); err != nil {
+		log.Printf("Failed to broadcast notification %d: %v", n.ID, err)
+	}
+	return nil
+}
+
+// buildLog 按收件人的語系渲染通知，收件人已關閉該類通知或沒有郵箱時記錄為跳過
+func (s *NotificationService) buildLog(msg *models.OutboxMessage, leave *models.Leave, employee, recipient *models.Employee) (*models.NotificationLog, error) {
+	entry := &models.NotificationLog{
+		EventID:       msg.EventID,
+		EventType:     msg.EventType,
+		RecipientID:   recipient.ID,
+		Channel:       models.NotificationChannelEmail,
+		LeaveID:       leave.ID,
+		Address:       recipient.Email,
+		Locale:        i18n.Preferred(recipient.Locale),
+		Status:        models.NotificationStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+
+	optedIn, err := s.optedIn(recipient.ID, msg.EventType)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case !optedIn:
+		entry.Status = models.NotificationStatusSkipped
+		entry.LastError = "recipient opted out"
+		return entry, nil
+	case recipient.Email == "":
+		entry.Status = models.NotificationStatusSkipped
+		entry.LastError = "recipient has no email address"
+		return entry, nil
+	}
+
+	data := notifications.TemplateData{
+		RecipientName: recipient.Name,
+		EmployeeName:  employee.Name,
+		LeaveID:       leave.ID,
+		LeaveType:     i18n.Label(entry.Locale, "leave_type", leave.LeaveType),
+		StartDate:     leave.StartDate.Format("2006-01-02"),
+		EndDate:       leave.EndDate.Format("2006-01-02"),
+		Reason:        leave.Reason,
+		Remark:        leave.ApproveRemark,
+	}
+	// 提交、提醒與升級通知的收件人是審批人，附上免登入的審批連結
+	if needsActionLinks(msg.EventType) && s.actions != nil {
+		links, err := s.actions.IssueLinks(leave.ID, recipient.ID)
+		if err != nil {
+			return nil, err
+		}
+		data.ApproveURL = links.Approve
+		data.RejectURL = links.Reject
+		data.LinksExpireAt = links.ExpiresAt.Format("2006-01-02 15:04 MST")
+	}
+
+	content, err := notifications.Render(entry.Locale, notifications.TemplateName(msg.EventType), data)
+	if err != nil {
+		return nil, apperrors.Internal(err)
+	}
+	entry.Subject = content.Subject
+	entry.TextBody = content.Text
+	entry.HTMLBody = content.HTML
+	return entry, nil
+}
+
+// optedIn 員工是否接收某類事件的郵件通知，未設置時默認接收
+func (s *NotificationService) optedIn(employeeID uint, eventType string) (bool, error) {
+	prefs, err := s.repo.GetPreferences(employeeID)
+	if err != nil {
+		return false, err
+	}
+	for _, pref := range prefs {
+		if pref.EventType == eventType {
+			return pref.Email, nil
+		}
+	}
+	return true, nil
+}
+
+// notify 喚醒後台發送，已有待處理的喚醒時忽略
+func (s *NotificationService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// StartSending 開始後台發送通知
+func (s *NotificationService) StartSending(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-s.wake:
+			}
+			s.sendDue(ctx)
+		}
+	}()
+}
+
+// sendDue 發送所有到期的通知
+func (s *NotificationService) sendDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		now := time.Now()
+		logs, err := s.repo.GetDueLogs(now, s.cfg.BatchSize)
+		if err != nil {
+			log.Printf("Failed to load due notifications: %v", err)
+			return
+		}
+
+		// 租約需長於單次發送耗時，避免發送中的通知被其他副本重複佔用
+		leaseUntil := now.Add(2*s.cfg.SMTP.Timeout + time.Minute)
+		var wg sync.WaitGroup
+		for i := range logs {
+			entry := &logs[i]
+			claimed, err := s.repo.ClaimLog(entry.ID, now, leaseUntil)
+			if err != nil {
+				log.Printf("Failed to claim notification %d: %v", entry.ID, err)
+				continue
+			}
+			if !claimed {
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.attempt(ctx, entry)
+			}()
+		}
+		wg.Wait()
+
+		if len(logs) < s.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+// attempt 發送一次通知並記錄結果
+func (s *NotificationService) attempt(ctx context.Context, entry *models.NotificationLog) {
+	entry.Attempts++
+	err := s.mailer.Send(ctx, &notifications.Mail{
+		To:      entry.Address,
+		Subject: entry.Subject,
+		Text:    entry.TextBody,
+		HTML:    entry.HTMLBody,
+	})
+
+	now := time.Now()
+	switch {
+	case err == nil:
+		entry.Status = models.NotificationStatusSent
+		entry.SentAt = &now
+		entry.LastError = ""
+	case entry.Attempts >= s.cfg.MaxAttempts:
+		entry.Status = models.NotificationStatusFailed
+		entry.LastError = err.Error()
+		log.Printf("Notification %d (%s) to %s failed after %d attempts: %v",
+			entry.ID, entry.EventType, entry.Address, entry.Attempts, err)
+	default:
+		entry.NextAttemptAt = now.Add(exponentialBackoff(s.cfg.BackoffBase, s.cfg.BackoffMax, entry.Attempts))
+		entry.LastError = err.Error()
+	}
+
+	if err := s.repo.UpdateLog(entry); err != nil {
+		log.Printf("Failed to update notification %d: %v", entry.ID, err)
+	}
+}
+
+// needsActionLinks 事件的通知郵件是否附帶審批連結
+func needsActionLinks(eventType string) bool {
+	switch eventType {
+	case models.EventLeaveSubmitted, models.EventLeaveReminded, models.EventLeaveEscalated:
+		return true
+	}
+	return false
+}
+
+func containsEventType(eventTypes []string, eventType string) bool {
+	for _, t := range eventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/models"
+	"hr-system/internal/repositories"
+)
+
+const (
+	outboxRelayLockName = "outbox_relay"
+	// outboxCleanupInterval 清理已發佈事件的間隔
+	outboxCleanupInterval = time.Hour
+	outboxCleanupBatch    = 1000
+)
+
+// OutboxStatus 事件中繼的狀態
+type OutboxStatus struct {
+	Sinks      []string                  `json:"sinks"`
+	Stats      *repositories.OutboxStats `json:"stats,omitempty"`
+	LastRunAt  *time.Time                `json:"last_run_at,omitempty"` // 本副本最近一次持鎖發佈的時間
+	Published  int64                     `json:"published"`             // 本副本啟動以來發佈的事件數
+	LastError  string                    `json:"last_error,omitempty"`
+	StatsError string                    `json:"stats_error,omitempty"`
+}
+
+// OutboxRelay 將 outbox 中的事件發佈到各事件出口
+// 事件全部出口都發佈成功後才標記為已發佈，失敗時按指數退避重試（至少一次）；
+// 每輪只發佈每個實體最早的一條未發佈事件，前一條成功之前不會發佈同一實體的後續事件（實體內有序）。
+// 多副本部署時通過分佈式鎖保證同一時間只有一個副本在發佈。
+type OutboxRelay struct {
+	repo  *repositories.OutboxRepository
+	sinks []EventSink
+	lock  DistributedLock
+	cfg   config.OutboxConfig
+
+	mu          sync.Mutex
+	lastRunAt   *time.Time
+	published   int64
+	lastError   string
+	lastCleanup time.Time
+}
+
+func NewOutboxRelay(repo *repositories.OutboxRepository, sinks []EventSink, lock DistributedLock, cfg config.OutboxConfig) *OutboxRelay {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	return &OutboxRelay{
+		repo:  repo,
+		sinks: sinks,
+		lock:  lock,
+		cfg:   cfg,
+	}
+}
+
+// StartRelaying 開始後台發佈事件
+func (r *OutboxRelay) StartRelaying(ctx context.Context) {
+	if len(r.sinks) == 0 {
+		log.Println("Outbox relay disabled: no sinks configured")
+		return
+	}
+
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				r.relay(ctx)
+			}
+		}
+	}()
+}
+
+// relay 持鎖發佈所有到期的事件，並定期清理已發佈的事件
+func (r *OutboxRelay) relay(ctx context.Context) {
+	unlock, ok, err := r.lock.TryLock(ctx, outboxRelayLockName, r.cfg.LockTTL)
+	if err != nil {
+		r.setError(fmt.Errorf("failed to acquire relay lock: %w", err))
+		return
+	}
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	// 單輪耗時不超過鎖的一半有效期，避免鎖過期後其他副本同時發佈導致亂序
+	deadline := time.Now().Add(r.cfg.LockTTL / 2)
+	for ctx.Err() == nil && time.Now().Before(deadline) {
+		published, err := r.relayBatch(ctx)
+		if err != nil {
+			r.setError(err)
+			return
+		}
+		if published == 0 {
+			break
+		}
+	}
+
+	now := time.Now()
+	r.mu.Lock()
+	r.lastRunAt = &now
+	needCleanup := now.Sub(r.lastCleanup) >= outboxCleanupInterval
+	if needCleanup {
+		r.lastCleanup = now
+	}
+	r.mu.Unlock()
+	if needCleanup {
+		r.cleanup(now)
+	}
+}
+
+// relayBatch 發佈一批事件，返回成功發佈的條數
+func (r *OutboxRelay) relayBatch(ctx context.Context) (int, error) {
+	messages, err := r.repo.GetHeads(time.Now(), r.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for i := range messages {
+		msg := &messages[i]
+		if err := r.publish(ctx, msg); err != nil {
+			attempts := msg.Attempts + 1
+			next := time.Now().Add(exponentialBackoff(r.cfg.BackoffBase, r.cfg.BackoffMax, attempts))
+			log.Printf("Failed to publish outbox message %d (%s, attempt %d): %v", msg.ID, msg.EventType, attempts, err)
+			if err := r.repo.MarkFailed(msg.ID, attempts, next, err.Error()); err != nil {
+				return published, err
+			}
+			r.setError(err)
+			continue
+		}
+		if err := r.repo.MarkPublished(msg.ID, time.Now()); err != nil {
+			return published, err
+		}
+		published++
+	}
+
+	r.mu.Lock()
+	r.published += int64(published)
+	r.mu.Unlock()
+	return published, nil
+}
+
+// publish 將事件發佈到所有出口，任一出口失敗即返回錯誤
+func (r *OutboxRelay) publish(ctx context.Context, msg *models.OutboxMessage) error {
+	for _, sink := range r.sinks {
+		if err := sink.Publish(ctx, msg); err != nil {
+			return fmt.Errorf("%s: %w", sink.Name(), err)
+		}
+	}
+	return nil
+}
+
+// cleanup 刪除超過保留時間的已發佈事件
+func (r *OutboxRelay) cleanup(now time.Time) {
+	before := now.Add(-r.cfg.Retention)
+	for {
+		deleted, err := r.repo.DeletePublishedBefore(before, outboxCleanupBatch)
+		if err != nil {
+			log.Printf("Failed to clean up published outbox messages: %v", err)
+			return
+		}
+		if deleted < outboxCleanupBatch {
+			return
+		}
+	}
+}
+
+func (r *OutboxRelay) setError(err error) {
+	r.mu.Lock()
+	r.lastError = err.Error()
+	r.mu.Unlock()
+}
+
+// Status 返回事件中繼的狀態與未發佈事件的統計
+func (r *OutboxRelay) Status(ctx context.Context) OutboxStatus {
+	r.mu.Lock()
+	status := OutboxStatus{
+		LastRunAt: r.lastRunAt,
+		Published: r.published,
+		LastError: r.lastError,
+	}
+	r.mu.Unlock()
+
+	status.Sinks = make([]string, 0, len(r.sinks))
+	for _, sink := range r.sinks {
+		status.Sinks = append(status.Sinks, sink.Name())
+	}
+	stats, err := r.repo.Stats()
+	if err != nil {
+		status.StatsError = err.Error()
+	} else {
+		status.Stats = stats
+	}
+	return status
+}
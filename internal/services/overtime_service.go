@@ -0,0 +1,217 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+	"hr-system/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+// OvertimeService 管理加班申請，審批人與請假相同（直屬主管、代理人或代主管審批的上一級主管）
+// 選擇補休的加班核准後按時數轉為補休額度，在當年度的年終結算時到期
+type OvertimeService struct {
+	overtimeRepo *repositories.OvertimeRepository
+	employeeRepo *repositories.EmployeeRepository
+	holidayRepo  *repositories.HolidayRepository
+	ledgerRepo   *repositories.LeaveLedgerRepository
+	closeRepo    *repositories.YearEndCloseRepository
+	outboxRepo   *repositories.OutboxRepository
+	approvals    *ApprovalService
+	config       config.OvertimeConfig
+}
+
+func NewOvertimeService(overtimeRepo *repositories.OvertimeRepository, employeeRepo *repositories.EmployeeRepository, holidayRepo *repositories.HolidayRepository, ledgerRepo *repositories.LeaveLedgerRepository, closeRepo *repositories.YearEndCloseRepository, outboxRepo *repositories.OutboxRepository, approvals *ApprovalService, cfg config.OvertimeConfig) *OvertimeService {
+	return &OvertimeService{
+		overtimeRepo: overtimeRepo,
+		employeeRepo: employeeRepo,
+		holidayRepo:  holidayRepo,
+		ledgerRepo:   ledgerRepo,
+		closeRepo:    closeRepo,
+		outboxRepo:   outboxRepo,
+		approvals:    approvals,
+		config:       cfg,
+	}
+}
+
+// Create 提交加班申請，按日期判斷加班日類型；同一員工同一天只能有一筆待審批或已核准的申請
+func (s *OvertimeService) Create(overtime *models.OvertimeRequest) error {
+	employee, err := s.employeeRepo.GetByID(overtime.EmployeeID)
+	if apperrors.IsNotFound(err) {
+		return apperrors.Validation(apperrors.CodeEmployeeNotFound, "Employee not found",
+			apperrors.Field("employee_id", apperrors.CodeEmployeeNotFound, "Employee not found"))
+	}
+	if err != nil {
+		return err
+	}
+
+	overtime.Date = startOfDay(overtime.Date)
+	exists, err := s.overtimeRepo.ExistsOnDate(overtime.EmployeeID, overtime.Date)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return apperrors.Conflict(apperrors.CodeOvertimeExists, "An overtime request already exists for this date")
+	}
+	if overtime.DayType, err = s.dayType(overtime.Date); err != nil {
+		return err
+	}
+	overtime.Status = models.LeaveStatusPending
+
+	err = repositories.Transaction(func(tx *gorm.DB) error {
+		if err := s.overtimeRepo.WithTx(tx).Create(overtime); err != nil {
+			return err
+		}
+		overtime.Employee = *employee
+		return appendEvent(s.outboxRepo.WithTx(tx), NewEvent(models.EventOvertimeSubmitted, AggregateOvertime, overtime.ID, overtime))
+	})
+	return err
+}
+
+// Get 獲取加班申請
+func (s *OvertimeService) Get(id uint) (*models.OvertimeRequest, error) {
+	return s.overtimeRepo.GetByID(id)
+}
+
+// List 獲取符合條件的加班申請
+func (s *OvertimeService) List(filter repositories.OvertimeFilter) ([]models.OvertimeRequest, error) {
+	return s.overtimeRepo.List(filter)
+}
+
+// UpdateStatus 審批待審批的加班申請，approverID 為執行審批的員工
+// 核准選擇補休的申請時，在同一事務中寫入補休額度
+func (s *OvertimeService) UpdateStatus(id uint, status, remark string, approverID uint) (*models.OvertimeRequest, error) {
+	overtime, err := s.overtimeRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if status != models.LeaveStatusApproved && status != models.LeaveStatusRejected {
+		return nil, apperrors.Validation(apperrors.CodeInvalidStatus, "Invalid status",
+			apperrors.Field("status", apperrors.CodeInvalidStatus, "Status must be approved or rejected"))
+	}
+	if overtime.Status != models.LeaveStatusPending {
+		return nil, errOvertimeNotPending("approved or rejected")
+	}
+
+	// 檢查審批人是否為直屬主管或當前的代理審批人，代理審批時記錄原審批人
+	onBehalfOf, err := s.approvals.AuthorizeEmployeeApproval(&overtime.Employee, approverID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	overtime.Status = status
+	overtime.ApproveRemark = remark
+	overtime.ApproverID = &approverID
+	overtime.OnBehalfOfID = onBehalfOf
+	overtime.ApproveTime = &now
+
+	var credit *models.LeaveLedgerEntry
+	if status == models.LeaveStatusApproved && overtime.Compensation == models.OvertimeCompensationCompTime {
+		if credit, err = s.compTimeCredit(overtime, &approverID); err != nil {
+			return nil, err
+		}
+		overtime.CompDays = credit.Days
+		overtime.CompExpiresAt = credit.ExpiresAt
+	}
+
+	eventType := models.EventOvertimeApproved
+	if status == models.LeaveStatusRejected {
+		eventType = models.EventOvertimeRejected
+	}
+	// 只更新仍待審批的申請，並發的審批只有一個成功，補休額度只寫入一次
+	err = repositories.Transaction(func(tx *gorm.DB) error {
+		decided, err := s.overtimeRepo.WithTx(tx).Decide(overtime)
+		if err != nil {
+			return err
+		}
+		if !decided {
+			return errOvertimeNotPending("approved or rejected")
+		}
+		if credit != nil {
+			if err := s.ledgerRepo.WithTx(tx).Create(credit); err != nil {
+				return err
+			}
+		}
+		return appendEvent(s.outboxRepo.WithTx(tx), NewEvent(eventType, AggregateOvertime, overtime.ID, overtime))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return overtime, nil
+}
+
+// Cancel 取消待審批的加班申請，已核准的補休額度須由 HR 調整
+func (s *OvertimeService) Cancel(id uint) error {
+	overtime, err := s.overtimeRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if overtime.Status != models.LeaveStatusPending {
+		return errOvertimeNotPending("cancelled")
+	}
+
+	overtime.Status = models.LeaveStatusCancelled
+	return repositories.Transaction(func(tx *gorm.DB) error {
+		cancelled, err := s.overtimeRepo.WithTx(tx).Cancel(overtime.ID)
+		if err != nil {
+			return err
+		}
+		if !cancelled {
+			return errOvertimeNotPending("cancelled")
+		}
+		return appendEvent(s.outboxRepo.WithTx(tx), NewEvent(models.EventOvertimeCancelled, AggregateOvertime, overtime.ID, overtime))
+	})
+}
+
+// compTimeCredit 生成補休額度流水，加班時數按 1:1 轉為補休，記入加班日所在年度，
+// 該年度已結算時記入之後第一個未結算的年度；額度在所記年度的年底到期
+func (s *OvertimeService) compTimeCredit(overtime *models.OvertimeRequest, actorID *uint) (*models.LeaveLedgerEntry, error) {
+	year := overtime.Date.Year()
+	for {
+		closed, err := s.closeRepo.Exists(year)
+		if err != nil {
+			return nil, err
+		}
+		if !closed {
+			break
+		}
+		year++
+	}
+	_, yearEnd := yearRange(year)
+	expiresAt := yearEnd.AddDate(0, 0, -1)
+	return &models.LeaveLedgerEntry{
+		EmployeeID: overtime.EmployeeID,
+		Year:       year,
+		LeaveType:  models.LeaveTypeCompensatory,
+		Kind:       models.LeaveLedgerOvertime,
+		Days:       overtime.Hours / float64(s.config.HoursPerDay),
+		OvertimeID: &overtime.ID,
+		ExpiresAt:  &expiresAt,
+		ActorID:    actorID,
+		Remark:     fmt.Sprintf("Overtime #%d on %s", overtime.ID, overtime.Date.Format("2006-01-02")),
+	}, nil
+}
+
+// dayType 判斷加班日類型，公司假日優先於週末
+func (s *OvertimeService) dayType(date time.Time) (string, error) {
+	holidays, err := s.holidayRepo.ListBetween(date, date.AddDate(0, 0, 1))
+	if err != nil {
+		return "", err
+	}
+	if len(holidays) > 0 {
+		return models.OvertimeDayHoliday, nil
+	}
+	if weekday := date.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+		return models.OvertimeDayRestDay, nil
+	}
+	return models.OvertimeDayWeekday, nil
+}
+
+func errOvertimeNotPending(action string) *apperrors.Error {
+	return apperrors.Conflict(apperrors.CodeOvertimeNotPending, "Only pending overtime requests can be "+action)
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"sync"
+	"testing"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+	"hr-system/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOvertimeService() *OvertimeService {
+	employeeRepo := repositories.NewEmployeeRepository()
+	leaveRepo := repositories.NewLeaveRepository()
+	approvals := NewApprovalService(repositories.NewDelegationRepository(), employeeRepo, leaveRepo)
+	return NewOvertimeService(repositories.NewOvertimeRepository(), employeeRepo, repositories.NewHolidayRepository(),
+		repositories.NewLeaveLedgerRepository(), repositories.NewYearEndCloseRepository(), repositories.NewOutboxRepository(),
+		approvals, config.OvertimeConfig{HoursPerDay: 8})
+}
+
+func TestUpdateOvertimeStatusAuthorizesApprover(t *testing.T) {
+	tests := []struct {
+		name     string
+		approver string
+		wantCode string
+	}{
+		{name: "直屬主管核准", approver: "主管"},
+		{name: "申請人不能核准自己的加班", approver: "員工", wantCode: apperrors.CodeNotApprover},
+		{name: "其他員工不能核准", approver: "同事", wantCode: apperrors.CodeNotApprover},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupTestDB(t)
+			service := newTestOvertimeService()
+			manager := seedEmployee(t, db, models.Employee{Name: "主管"})
+			employee := seedEmployee(t, db, models.Employee{Name: "員工", ManagerID: &manager.ID})
+			colleague := seedEmployee(t, db, models.Employee{Name: "同事", ManagerID: &manager.ID})
+			approvers := map[string]uint{"主管": manager.ID, "員工": employee.ID, "同事": colleague.ID}
+			overtime := models.OvertimeRequest{
+				EmployeeID:   employee.ID,
+				Date:         date(2024, 10, 5),
+				Hours:        4,
+				DayType:      models.OvertimeDayRestDay,
+				Compensation: models.OvertimeCompensationCompTime,
+				Status:       models.LeaveStatusPending,
+			}
+			require.NoError(t, db.Create(&overtime).Error)
+
+			_, err := service.UpdateStatus(overtime.ID, models.LeaveStatusApproved, "", approvers[tt.approver])
+
+			var stored models.OvertimeRequest
+			require.NoError(t, db.First(&stored, overtime.ID).Error)
+			var credits int64
+			db.Model(&models.LeaveLedgerEntry{}).Where("overtime_id = ?", overtime.ID).Count(&credits)
+			if tt.wantCode == "" {
+				require.NoError(t, err)
+				assert.Equal(t, models.LeaveStatusApproved, stored.Status)
+				require.NotNil(t, stored.ApproverID)
+				assert.Equal(t, manager.ID, *stored.ApproverID)
+				assert.Equal(t, int64(1), credits)
+				return
+			}
+			var appErr *apperrors.Error
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, tt.wantCode, appErr.Code)
+			assert.Equal(t, models.LeaveStatusPending, stored.Status)
+			assert.Zero(t, credits, "未授權的審批不應寫入補休額度")
+		})
+	}
+}
+
+func TestUpdateOvertimeStatusCreditsCompTimeOnce(t *testing.T) {
+	db := setupTestDB(t)
+	service := newTestOvertimeService()
+	manager := seedEmployee(t, db, models.Employee{Name: "主管"})
+	employee := seedEmployee(t, db, models.Employee{Name: "員工", ManagerID: &manager.ID})
+	overtime := models.OvertimeRequest{
+		EmployeeID:   employee.ID,
+		Date:         date(2024, 10, 5),
+		Hours:        4,
+		DayType:      models.OvertimeDayRestDay,
+		Compensation: models.OvertimeCompensationCompTime,
+		Status:       models.LeaveStatusPending,
+	}
+	require.NoError(t, db.Create(&overtime).Error)
+
+	// 並發核准只有一個成功，只寫入一筆補休額度與一個事件
+	errs := make([]error, 4)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = service.UpdateStatus(overtime.ID, models.LeaveStatusApproved, "", manager.ID)
+		}(i)
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		var appErr *apperrors.Error
+		require.ErrorAs(t, err, &appErr)
+		assert.Equal(t, apperrors.CodeOvertimeNotPending, appErr.Code)
+	}
+	assert.Equal(t, 1, succeeded)
+	var credits, events int64
+	db.Model(&models.LeaveLedgerEntry{}).Where("overtime_id = ?", overtime.ID).Count(&credits)
+	db.Model(&models.OutboxMessage{}).Count(&events)
+	assert.Equal(t, int64(1), credits)
+	assert.Equal(t, int64(1), events)
+
+	// 已核准的申請不能取消
+	err := service.Cancel(overtime.ID)
+	var appErr *apperrors.Error
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, apperrors.CodeOvertimeNotPending, appErr.Code)
+
+	// 同一加班申請的補休流水不能重複寫入
+	duplicate := models.LeaveLedgerEntry{
+		EmployeeID: employee.ID, Year: 2024, LeaveType: models.LeaveTypeCompensatory, Kind: models.LeaveLedgerOvertime,
+		Days: 0.5, OvertimeID: &overtime.ID,
+	}
+	assert.Error(t, db.Create(&duplicate).Error)
+}
@@ -0,0 +1,502 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+	"hr-system/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+// PayrollService 按月份計算薪資批次：底薪按在職天數折算，扣除不給薪的請假，加上加班費與配置的津貼、扣款，
+// 並按分級表扣除勞健保費與勞退自提
+// 批次在草稿狀態下可以重新計算，鎖定後不再變化
+type PayrollService struct {
+	payrollRepo  *repositories.PayrollRepository
+	employeeRepo *repositories.EmployeeRepository
+	leaveRepo    *repositories.LeaveRepository
+	overtimeRepo *repositories.OvertimeRepository
+	outboxRepo   *repositories.OutboxRepository
+	schedules    *ScheduleService
+	insurance    *InsuranceService
+	config       config.PayrollConfig
+	now          func() time.Time
+}
+
+func NewPayrollService(payrollRepo *repositories.PayrollRepository, employeeRepo *repositories.EmployeeRepository, leaveRepo *repositories.LeaveRepository, overtimeRepo *repositories.OvertimeRepository, outboxRepo *repositories.OutboxRepository, schedules *ScheduleService, insurance *InsuranceService, cfg config.PayrollConfig) *PayrollService {
+	return &PayrollService{
+		payrollRepo:  payrollRepo,
+		employeeRepo: employeeRepo,
+		leaveRepo:    leaveRepo,
+		overtimeRepo: overtimeRepo,
+		outboxRepo:   outboxRepo,
+		schedules:    schedules,
+		insurance:    insurance,
+		config:       cfg,
+		now:          time.Now,
+	}
+}
+
+// CreateComponent 創建薪資項目
+func (s *PayrollService) CreateComponent(component *models.PayComponent) error {
+	if err := s.validateComponent(component); err != nil {
+		return err
+	}
+	return s.payrollRepo.CreateComponent(component)
+}
+
+// GetComponent 獲取薪資項目
+func (s *PayrollService) GetComponent(id uint) (*models.PayComponent, error) {
+	return s.payrollRepo.GetComponent(id)
+}
+
+// ListComponents 獲取所有薪資項目
+func (s *PayrollService) ListComponents() ([]models.PayComponent, error) {
+	return s.payrollRepo.ListComponents(false)
+}
+
+// UpdateComponent 更新薪資項目，只影響之後計算或重新計算的薪資批次
+func (s *PayrollService) UpdateComponent(id uint, component *models.PayComponent) (*models.PayComponent, error) {
+	existing, err := s.payrollRepo.GetComponent(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateComponent(component); err != nil {
+		return nil, err
+	}
+	existing.Name = component.Name
+	existing.Kind = component.Kind
+	existing.Amount = component.Amount
+	existing.EmployeeID = component.EmployeeID
+	existing.Department = component.Department
+	existing.StartMonth = component.StartMonth
+	existing.EndMonth = component.EndMonth
+	existing.ProRate = component.ProRate
+	existing.Active = component.Active
+	existing.Description = component.Description
+	if err := s.payrollRepo.UpdateComponent(existing); err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// DeleteComponent 刪除薪資項目
+func (s *PayrollService) DeleteComponent(id uint) error {
+	return s.payrollRepo.DeleteComponent(id)
+}
+
+// validateComponent 薪資項目不能同時指定員工與部門，起始月份不能晚於結束月份
+func (s *PayrollService) validateComponent(component *models.PayComponent) error {
+	if component.EmployeeID != nil {
+		if component.Department != "" {
+			return errInvalidPayComponent("department", "Department must be empty when employee_id is set")
+		}
+		if _, err := s.employeeRepo.GetByID(*component.EmployeeID); err != nil {
+			if apperrors.IsNotFound(err) {
+				return apperrors.Validation(apperrors.CodeEmployeeNotFound, "Employee not found",
+					apperrors.Field("employee_id", apperrors.CodeEmployeeNotFound, "Employee not found"))
+			}
+			return err
+		}
+	}
+	if component.StartMonth != "" && component.EndMonth != "" && component.EndMonth < component.StartMonth {
+		return errInvalidPayComponent("end_month", "End month must not be before start month")
+	}
+	return nil
+}
+
+// CreateRun 創建某月份（YYYY-MM）的薪資批次並計算薪資單，未來的月份不能計算；該月份已有批次時返回衝突錯誤
+func (s *PayrollService) CreateRun(month string, actorID *uint) (*models.PayrollRun, error) {
+	from, to, err := s.payrollMonth(month)
+	if err != nil {
+		return nil, err
+	}
+	run := &models.PayrollRun{Month: month, Status: models.PayrollRunDraft, CreatedByID: actorID}
+	payslips, err := s.calculate(run, from, to)
+	if err != nil {
+		return nil, err
+	}
+	err = repositories.Transaction(func(tx *gorm.DB) error {
+		repo := s.payrollRepo.WithTx(tx)
+		if err := repo.CreateRun(run); err != nil {
+			return err
+		}
+		return repo.ReplacePayslips(run.ID, payslips)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.payrollRepo.GetRun(run.ID)
+}
+
+// Recalculate 按當前的員工、請假、加班與薪資項目重新計算草稿薪資批次
+func (s *PayrollService) Recalculate(id uint) (*models.PayrollRun, error) {
+	run, err := s.payrollRepo.GetRun(id)
+	if err != nil {
+		return nil, err
+	}
+	if run.Status != models.PayrollRunDraft {
+		return nil, errPayrollRunLocked()
+	}
+	from, to, err := parseMonth(run.Month)
+	if err != nil {
+		return nil, err
+	}
+	payslips, err := s.calculate(run, from, to)
+	if err != nil {
+		return nil, err
+	}
+	err = repositories.Transaction(func(tx *gorm.DB) error {
+		repo := s.payrollRepo.WithTx(tx)
+		updated, err := repo.UpdateDraftRun(run)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return errPayrollRunLocked()
+		}
+		return repo.ReplacePayslips(run.ID, payslips)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.payrollRepo.GetRun(run.ID)
+}
+
+// Lock 鎖定草稿薪資批次，鎖定後不能重新計算或刪除
+func (s *PayrollService) Lock(id uint, actorID *uint) (*models.PayrollRun, error) {
+	run, err := s.payrollRepo.GetRun(id)
+	if err != nil {
+		return nil, err
+	}
+	if run.Status != models.PayrollRunDraft {
+		return nil, errPayrollRunLocked()
+	}
+	now := s.now()
+	run.Status = models.PayrollRunLocked
+	run.LockedAt = &now
+	run.LockedByID = actorID
+	err = repositories.Transaction(func(tx *gorm.DB) error {
+		locked, err := s.payrollRepo.WithTx(tx).LockRun(run)
+		if err != nil {
+			return err
+		}
+		if !locked {
+			return errPayrollRunLocked()
+		}
+		summary := *run
+		summary.Payslips = nil
+		return appendEvent(s.outboxRepo.WithTx(tx), NewEvent(models.EventPayrollLocked, AggregatePayroll, run.ID, summary))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// DeleteRun 刪除草稿薪資批次
+func (s *PayrollService) DeleteRun(id uint) error {
+	run, err := s.payrollRepo.GetRun(id)
+	if err != nil {
+		return err
+	}
+	if run.Status != models.PayrollRunDraft {
+		return errPayrollRunLocked()
+	}
+	return repositories.Transaction(func(tx *gorm.DB) error {
+		deleted, err := s.payrollRepo.WithTx(tx).DeleteDraftRun(id)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			return errPayrollRunLocked()
+		}
+		return nil
+	})
+}
+
+// GetRun 獲取薪資批次，包括薪資單
+func (s *PayrollService) GetRun(id uint) (*models.PayrollRun, error) {
+	return s.payrollRepo.GetRun(id)
+}
+
+// ListRuns 獲取所有薪資批次
+func (s *PayrollService) ListRuns() ([]models.PayrollRun, error) {
+	return s.payrollRepo.ListRuns()
+}
+
+// GetPayslip 獲取員工在薪資批次中的薪資單，包括明細
+func (s *PayrollService) GetPayslip(runID, employeeID uint) (*models.Payslip, error) {
+	if _, err := s.payrollRepo.GetRun(runID); err != nil {
+		return nil, err
+	}
+	return s.payrollRepo.GetPayslip(runID, employeeID)
+}
+
+// ListEmployeePayslips 獲取員工在已鎖定薪資批次中的薪資單
+func (s *PayrollService) ListEmployeePayslips(employeeID uint) ([]models.Payslip, error) {
+	if _, err := s.employeeRepo.GetByID(employeeID); err != nil {
+		return nil, err
+	}
+	return s.payrollRepo.ListEmployeePayslips(employeeID)
+}
+
+// payrollMonth 解析薪資月份，未來的月份不能計算
+func (s *PayrollService) payrollMonth(month string) (time.Time, time.Time, error) {
+	from, to, err := parseMonth(month)
+	if err != nil {
+		return from, to, err
+	}
+	if from.After(s.now()) {
+		return from, to, errInvalidPayrollMonth("Month must not be in the future")
+	}
+	return from, to, nil
+}
+
+// calculate 計算薪資批次中每個員工的薪資單並更新批次的匯總金額
+// 計薪員工為當月在職的員工：入職日期不晚於月底，且未離職或離職日期不早於月初；沒有離職日期的離職員工不計薪
+func (s *PayrollService) calculate(run *models.PayrollRun, from, to time.Time) ([]models.Payslip, error) {
+	all, err := s.employeeRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	var employees []models.Employee
+	for _, employee := range all {
+		if start, end := employmentWithin(&employee, from, to); end.After(start) {
+			employees = append(employees, employee)
+		}
+	}
+	sort.Slice(employees, func(i, j int) bool { return employees[i].ID < employees[j].ID })
+
+	components, err := s.payrollRepo.ListComponents(true)
+	if err != nil {
+		return nil, err
+	}
+	shifts, err := s.schedules.scheduledShifts(employees, from, to)
+	if err != nil {
+		return nil, err
+	}
+	leaves := make(map[uint][]models.Leave)
+	if len(s.config.UnpaidLeaveRates) > 0 {
+		leaveTypes := make([]string, 0, len(s.config.UnpaidLeaveRates))
+		for leaveType := range s.config.UnpaidLeaveRates {
+			leaveTypes = append(leaveTypes, leaveType)
+		}
+		leaveList, err := s.leaveRepo.ListApprovedByTypes(leaveTypes, from, to)
+		if err != nil {
+			return nil, err
+		}
+		for _, leave := range leaveList {
+			leaves[leave.EmployeeID] = append(leaves[leave.EmployeeID], leave)
+		}
+	}
+	overtimeList, err := s.overtimeRepo.List(repositories.OvertimeFilter{Status: models.LeaveStatusApproved, From: from, To: to})
+	if err != nil {
+		return nil, err
+	}
+	tables, err := s.insurance.effectiveTables(from)
+	if err != nil {
+		return nil, err
+	}
+	overtimes := make(map[uint][]models.OvertimeRequest)
+	for i := len(overtimeList) - 1; i >= 0; i-- {
+		if overtimeList[i].Compensation == models.OvertimeCompensationPay {
+			overtimes[overtimeList[i].EmployeeID] = append(overtimes[overtimeList[i].EmployeeID], overtimeList[i])
+		}
+	}
+
+	run.CalculatedAt = s.now()
+	run.EmployeeCount = len(employees)
+	run.GrossTotal, run.DeductionTotal, run.NetTotal, run.EmployerContributionTotal = 0, 0, 0, 0
+	payslips := make([]models.Payslip, 0, len(employees))
+	for i := range employees {
+		employee := &employees[i]
+		payslip := s.payslip(employee, run.Month, from, to, shifts[employee.ID], leaves[employee.ID], overtimes[employee.ID], components, tables)
+		run.GrossTotal += payslip.Gross
+		run.DeductionTotal += payslip.Deductions
+		run.NetTotal += payslip.Net
+		run.EmployerContributionTotal += payslip.EmployerContributions
+		payslips = append(payslips, payslip)
+	}
+	return payslips, nil
+}
+
+// payslip 計算員工某月份的薪資單，金額四捨五入到元
+func (s *PayrollService) payslip(employee *models.Employee, month string, from, to time.Time, shifts map[string]models.ScheduledShift, leaves []models.Leave, overtimes []models.OvertimeRequest, components []models.PayComponent, tables map[string]*models.InsuranceGradeTable) models.Payslip {
+	start, end := employmentWithin(employee, from, to)
+	payslip := models.Payslip{
+		Month:        month,
+		EmployeeID:   employee.ID,
+		EmployeeName: employee.Name,
+		Department:   employee.Department,
+		BaseSalary:   employee.Salary,
+		PeriodDays:   daysBetween(from, to),
+		EmployedDays: daysBetween(start, end),
+		Lines:        []models.PayrollLine{},
+	}
+	prorate := float64(payslip.EmployedDays) / float64(payslip.PeriodDays)
+	dailyRate := employee.Salary / float64(s.config.DailyRateDivisor)
+	hourlyRate := dailyRate / s.config.HoursPerDay
+	add := func(line models.PayrollLine) {
+		if line.Amount <= 0 {
+			return
+		}
+		if line.Kind == models.PayrollLineEarning {
+			payslip.Gross += line.Amount
+		} else {
+			payslip.Deductions += line.Amount
+		}
+		payslip.Lines = append(payslip.Lines, line)
+	}
+
+	add(models.PayrollLine{
+		Code:        models.PayrollCodeBaseSalary,
+		Kind:        models.PayrollLineEarning,
+		Description: fmt.Sprintf("%d/%d days", payslip.EmployedDays, payslip.PeriodDays),
+		Quantity:    float64(payslip.EmployedDays),
+		Rate:        roundTo(employee.Salary/float64(payslip.PeriodDays), 2),
+		Amount:      roundTo(employee.Salary*prorate, 0),
+	})
+
+	// 不給薪的請假只計算在職期間內有班次的日子
+	for i := range leaves {
+		leave := &leaves[i]
+		rate := s.config.UnpaidLeaveRates[leave.LeaveType]
+		days := shiftDays(leave, shifts, start, end)
+		add(models.PayrollLine{
+			Code:        models.PayrollCodeUnpaidLeave,
+			Kind:        models.PayrollLineDeduction,
+			Description: leave.LeaveType,
+			Quantity:    float64(days),
+			Rate:        roundTo(dailyRate*rate, 2),
+			Amount:      roundTo(float64(days)*dailyRate*rate, 0),
+			LeaveID:     &leave.ID,
+		})
+	}
+
+	for i := range overtimes {
+		overtime := &overtimes[i]
+		weighted := overtimeWeightedHours(overtime.Hours, s.config.OvertimeRates[overtime.DayType])
+		add(models.PayrollLine{
+			Code:        models.PayrollCodeOvertime,
+			Kind:        models.PayrollLineEarning,
+			Description: fmt.Sprintf("%s %s", overtime.Date.Format("2006-01-02"), overtime.DayType),
+			Quantity:    overtime.Hours,
+			Rate:        roundTo(hourlyRate*weighted/overtime.Hours, 2),
+			Amount:      roundTo(hourlyRate*weighted, 0),
+			OvertimeID:  &overtime.ID,
+		})
+	}
+
+	for i := range components {
+		component := &components[i]
+		if !component.AppliesTo(employee, month) {
+			continue
+		}
+		line := models.PayrollLine{
+			Code:        models.PayrollCodeAllowance,
+			Kind:        models.PayrollLineEarning,
+			Description: component.Name,
+			Quantity:    1,
+			Rate:        component.Amount,
+			Amount:      component.Amount,
+			ComponentID: &component.ID,
+		}
+		if component.Kind == models.PayComponentDeduction {
+			line.Code = models.PayrollCodeDeduction
+			line.Kind = models.PayrollLineDeduction
+		}
+		if component.ProRate {
+			line.Quantity = roundTo(prorate, 4)
+			line.Amount = roundTo(component.Amount*prorate, 0)
+		}
+		add(line)
+	}
+
+	for _, contribution := range s.insurance.contributions(employee, tables, from, to) {
+		add(contributionLine(&contribution))
+		payslip.EmployerContributions += contribution.EmployerAmount
+		payslip.Contributions = append(payslip.Contributions, contribution)
+	}
+
+	payslip.Net = payslip.Gross - payslip.Deductions
+	return payslip
+}
+
+// overtimeWeightedHours 按加班費倍率將加班時數折算為平日時數，超過最後一段的時數按最後一段的倍率計算
+func overtimeWeightedHours(hours float64, tiers []config.OvertimeTier) float64 {
+	if len(tiers) == 0 {
+		return 0
+	}
+	var weighted, counted float64
+	for _, tier := range tiers {
+		if counted >= hours {
+			break
+		}
+		upTo := tier.UpToHours
+		if upTo > hours {
+			upTo = hours
+		}
+		if upTo > counted {
+			weighted += (upTo - counted) * tier.Multiplier
+			counted = upTo
+		}
+	}
+	if counted < hours {
+		weighted += (hours - counted) * tiers[len(tiers)-1].Multiplier
+	}
+	return weighted
+}
+
+// employmentWithin 返回員工在 [from, to) 內的在職期間 [start, end)，不在職時 end 不晚於 start
+func employmentWithin(employee *models.Employee, from, to time.Time) (time.Time, time.Time) {
+	start, end := from, to
+	if employee.Status == models.EmployeeStatusInactive && employee.TerminationDate == nil {
+		return start, start
+	}
+	if !employee.HireDate.IsZero() && startOfDay(employee.HireDate).After(start) {
+		start = startOfDay(employee.HireDate)
+	}
+	if employee.TerminationDate != nil {
+		if last := startOfDay(*employee.TerminationDate).AddDate(0, 0, 1); last.Before(end) {
+			end = last
+		}
+	}
+	return start, end
+}
+
+// daysBetween 計算 [from, to) 的天數
+func daysBetween(from, to time.Time) int {
+	if !to.After(from) {
+		return 0
+	}
+	return int(to.Sub(from).Round(24*time.Hour) / (24 * time.Hour))
+}
+
+// parseMonth 解析 YYYY-MM 格式的月份，返回該月的起止時間 [from, to)
+func parseMonth(month string) (time.Time, time.Time, error) {
+	from, err := time.ParseInLocation("2006-01", month, time.Local)
+	if err != nil {
+		return from, from, errInvalidPayrollMonth("Month must be in YYYY-MM format")
+	}
+	return from, from.AddDate(0, 1, 0), nil
+}
+
+func errPayrollRunLocked() *apperrors.Error {
+	return apperrors.Conflict(apperrors.CodePayrollRunLocked, "The payroll run has been locked")
+}
+
+func errInvalidPayrollMonth(msg string) *apperrors.Error {
+	return apperrors.Validation(apperrors.CodeInvalidPayrollMonth, msg,
+		apperrors.Field("month", apperrors.CodeInvalidPayrollMonth, msg))
+}
+
+func errInvalidPayComponent(field, msg string) *apperrors.Error {
+	return apperrors.Validation(apperrors.CodeInvalidPayComponent, msg,
+		apperrors.Field(field, apperrors.CodeInvalidPayComponent, msg))
+}
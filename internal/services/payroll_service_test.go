@@ -0,0 +1,275 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/dto"
+	"hr-system/internal/models"
+	"hr-system/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 與默認配置相同的加班費倍率
+var (
+	weekdayTiers = []config.OvertimeTier{{UpToHours: 2, Multiplier: 1.34}, {UpToHours: 4, Multiplier: 1.67}}
+	restDayTiers = []config.OvertimeTier{{UpToHours: 2, Multiplier: 1.34}, {UpToHours: 8, Multiplier: 1.67}, {UpToHours: 12, Multiplier: 2.67}}
+	holidayTiers = []config.OvertimeTier{{UpToHours: 8, Multiplier: 1}, {UpToHours: 12, Multiplier: 1.34}}
+)
+
+func newTestPayrollService() *PayrollService {
+	return NewPayrollService(repositories.NewPayrollRepository(), repositories.NewEmployeeRepository(), repositories.NewLeaveRepository(),
+		repositories.NewOvertimeRepository(), repositories.NewOutboxRepository(), newTestScheduleService(), newTestInsuranceService(),
+		config.PayrollConfig{
+			DailyRateDivisor: 30,
+			HoursPerDay:      8,
+			UnpaidLeaveRates: map[string]float64{models.LeaveTypePersonal: 1, models.LeaveTypeSick: 0.5},
+			OvertimeRates: map[string][]config.OvertimeTier{
+				models.OvertimeDayWeekday: weekdayTiers,
+				models.OvertimeDayRestDay: restDayTiers,
+				models.OvertimeDayHoliday: holidayTiers,
+			},
+		})
+}
+
+func TestEmploymentWithin(t *testing.T) {
+	from, to := date(2024, 10, 1), date(2024, 11, 1)
+	terminated := func(day time.Time) *time.Time { return &day }
+
+	tests := []struct {
+		name      string
+		employee  models.Employee
+		wantStart time.Time
+		wantEnd   time.Time
+		employed  bool
+	}{
+		{name: "整月在職", employee: models.Employee{HireDate: date(2020, 1, 1)}, wantStart: from, wantEnd: to, employed: true},
+		{name: "未填入職日期", employee: models.Employee{}, wantStart: from, wantEnd: to, employed: true},
+		{name: "月中入職", employee: models.Employee{HireDate: time.Date(2024, 10, 16, 14, 0, 0, 0, time.Local)}, wantStart: date(2024, 10, 16), wantEnd: to, employed: true},
+		{name: "月中離職", employee: models.Employee{Status: models.EmployeeStatusInactive, HireDate: date(2020, 1, 1), TerminationDate: terminated(date(2024, 10, 15))}, wantStart: from, wantEnd: date(2024, 10, 16), employed: true},
+		{name: "月中入職並離職", employee: models.Employee{HireDate: date(2024, 10, 10), TerminationDate: terminated(date(2024, 10, 20))}, wantStart: date(2024, 10, 10), wantEnd: date(2024, 10, 21), employed: true},
+		{name: "月底最後一天離職", employee: models.Employee{HireDate: date(2020, 1, 1), TerminationDate: terminated(date(2024, 10, 31))}, wantStart: from, wantEnd: to, employed: true},
+		{name: "下月入職", employee: models.Employee{HireDate: date(2024, 11, 1)}, employed: false},
+		{name: "上月離職", employee: models.Employee{HireDate: date(2020, 1, 1), TerminationDate: terminated(date(2024, 9, 30))}, employed: false},
+		{name: "沒有離職日期的離職員工", employee: models.Employee{Status: models.EmployeeStatusInactive, HireDate: date(2020, 1, 1)}, employed: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := employmentWithin(&tt.employee, from, to)
+			if !tt.employed {
+				assert.False(t, end.After(start), "不在職時 end 不應晚於 start")
+				return
+			}
+			assert.Equal(t, tt.wantStart, start)
+			assert.Equal(t, tt.wantEnd, end)
+		})
+	}
+}
+
+func TestOvertimeWeightedHours(t *testing.T) {
+	tests := []struct {
+		name  string
+		hours float64
+		tiers []config.OvertimeTier
+		want  float64
+	}{
+		{name: "平日未加班", hours: 0, tiers: weekdayTiers, want: 0},
+		{name: "平日第一段內", hours: 1, tiers: weekdayTiers, want: 1.34},
+		{name: "平日第一段上限", hours: 2, tiers: weekdayTiers, want: 2.68},
+		{name: "平日跨入第二段", hours: 3, tiers: weekdayTiers, want: 2.68 + 1.67},
+		{name: "平日第二段上限", hours: 4, tiers: weekdayTiers, want: 2.68 + 3.34},
+		{name: "平日超過最後一段按最後一段計算", hours: 5, tiers: weekdayTiers, want: 2.68 + 3.34 + 1.67},
+		{name: "休息日第一段上限", hours: 2, tiers: restDayTiers, want: 2.68},
+		{name: "休息日第二段上限", hours: 8, tiers: restDayTiers, want: 2.68 + 6*1.67},
+		{name: "休息日跨入第三段", hours: 9, tiers: restDayTiers, want: 2.68 + 6*1.67 + 2.67},
+		{name: "休息日第三段上限", hours: 12, tiers: restDayTiers, want: 2.68 + 6*1.67 + 4*2.67},
+		{name: "國定假日 8 小時內", hours: 8, tiers: holidayTiers, want: 8},
+		{name: "國定假日超過 8 小時", hours: 10, tiers: holidayTiers, want: 8 + 2*1.34},
+		{name: "未配置倍率", hours: 3, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, overtimeWeightedHours(tt.hours, tt.tiers), 1e-9)
+		})
+	}
+}
+
+// payrollLine 薪資明細中測試關心的欄位
+type payrollLine struct {
+	Code   string
+	Amount float64
+}
+
+func TestPayslip(t *testing.T) {
+	from, to := date(2024, 10, 1), date(2024, 11, 1)
+	terminated := func(day time.Time) *time.Time { return &day }
+	leave := func(leaveType string, start, end time.Time) models.Leave {
+		return models.Leave{LeaveType: leaveType, StartDate: start, EndDate: end, Status: models.LeaveStatusApproved}
+	}
+	overtime := func(day time.Time, dayType string, hours float64) models.OvertimeRequest {
+		return models.OvertimeRequest{Date: day, DayType: dayType, Hours: hours, Compensation: models.OvertimeCompensationPay}
+	}
+
+	// 月薪 30000，日薪 1000，時薪 125；2024-10 共 31 天，10-10 為國慶日
+	tests := []struct {
+		name           string
+		employee       models.Employee
+		leaves         []models.Leave
+		overtimes      []models.OvertimeRequest
+		components     []models.PayComponent
+		wantEmployed   int
+		wantLines      []payrollLine
+		wantGross      float64
+		wantDeductions float64
+	}{
+		{
+			name:         "整月在職",
+			employee:     models.Employee{},
+			wantEmployed: 31,
+			wantLines:    []payrollLine{{models.PayrollCodeBaseSalary, 30000}},
+			wantGross:    30000,
+		},
+		{
+			name:         "月中入職按在職天數折算",
+			employee:     models.Employee{HireDate: date(2024, 10, 16)},
+			wantEmployed: 16,
+			wantLines:    []payrollLine{{models.PayrollCodeBaseSalary, 15484}},
+			wantGross:    15484,
+		},
+		{
+			name:         "月中離職按在職天數折算",
+			employee:     models.Employee{Status: models.EmployeeStatusInactive, TerminationDate: terminated(date(2024, 10, 15))},
+			wantEmployed: 15,
+			wantLines:    []payrollLine{{models.PayrollCodeBaseSalary, 14516}},
+			wantGross:    14516,
+		},
+		{
+			name:           "跨週末的事假只扣有班次的日子",
+			leaves:         []models.Leave{leave(models.LeaveTypePersonal, date(2024, 10, 4), date(2024, 10, 7))},
+			wantEmployed:   31,
+			wantLines:      []payrollLine{{models.PayrollCodeBaseSalary, 30000}, {models.PayrollCodeUnpaidLeave, 2000}},
+			wantGross:      30000,
+			wantDeductions: 2000,
+		},
+		{
+			name: "休息日與國定假日的請假不扣薪",
+			leaves: []models.Leave{
+				leave(models.LeaveTypePersonal, date(2024, 10, 5), date(2024, 10, 6)),
+				leave(models.LeaveTypeSick, date(2024, 10, 10), date(2024, 10, 10)),
+			},
+			wantEmployed: 31,
+			wantLines:    []payrollLine{{models.PayrollCodeBaseSalary, 30000}},
+			wantGross:    30000,
+		},
+		{
+			name:           "病假半薪",
+			leaves:         []models.Leave{leave(models.LeaveTypeSick, date(2024, 10, 8), date(2024, 10, 9))},
+			wantEmployed:   31,
+			wantLines:      []payrollLine{{models.PayrollCodeBaseSalary, 30000}, {models.PayrollCodeUnpaidLeave, 1000}},
+			wantGross:      30000,
+			wantDeductions: 1000,
+		},
+		{
+			name:         "離職後的請假不扣薪",
+			employee:     models.Employee{Status: models.EmployeeStatusInactive, TerminationDate: terminated(date(2024, 10, 4))},
+			leaves:       []models.Leave{leave(models.LeaveTypePersonal, date(2024, 10, 7), date(2024, 10, 8))},
+			wantEmployed: 4,
+			wantLines:    []payrollLine{{models.PayrollCodeBaseSalary, 3871}},
+			wantGross:    3871,
+		},
+		{
+			name: "加班費按倍率計算",
+			overtimes: []models.OvertimeRequest{
+				overtime(date(2024, 10, 7), models.OvertimeDayWeekday, 3),
+				overtime(date(2024, 10, 5), models.OvertimeDayRestDay, 9),
+				overtime(date(2024, 10, 10), models.OvertimeDayHoliday, 8),
+			},
+			wantEmployed: 31,
+			wantLines: []payrollLine{
+				{models.PayrollCodeBaseSalary, 30000},
+				{models.PayrollCodeOvertime, 544},  // 125 × (2×1.34 + 1.67)
+				{models.PayrollCodeOvertime, 1921}, // 125 × (2×1.34 + 6×1.67 + 2.67)
+				{models.PayrollCodeOvertime, 1000}, // 125 × 8
+			},
+			wantGross: 33465,
+		},
+		{
+			name:     "金額為零或負數的明細不列出",
+			employee: models.Employee{HireDate: date(2024, 10, 16)},
+			components: []models.PayComponent{
+				{Name: "零元津貼", Kind: models.PayComponentAllowance, Amount: 0, Active: true},
+				{Name: "負數津貼", Kind: models.PayComponentAllowance, Amount: -100, Active: true},
+				{Name: "伙食津貼", Kind: models.PayComponentAllowance, Amount: 3100, ProRate: true, Active: true},
+				{Name: "福利金", Kind: models.PayComponentDeduction, Amount: 500, Active: true},
+			},
+			overtimes:    []models.OvertimeRequest{overtime(date(2024, 10, 21), models.OvertimeDayWeekday, 0)},
+			wantEmployed: 16,
+			wantLines: []payrollLine{
+				{models.PayrollCodeBaseSalary, 15484},
+				{models.PayrollCodeAllowance, 1600},
+				{models.PayrollCodeDeduction, 500},
+			},
+			wantGross:      17084,
+			wantDeductions: 500,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupTestDB(t)
+			service := newTestPayrollService()
+			seedHoliday(t, db, date(2024, 10, 10), "國慶日")
+			tt.employee.Name, tt.employee.Salary = "員工", 30000
+			employee := seedEmployee(t, db, tt.employee)
+
+			shifts, err := service.schedules.scheduledShifts([]models.Employee{*employee}, from, to)
+			require.NoError(t, err)
+			payslip := service.payslip(employee, "2024-10", from, to, shifts[employee.ID], tt.leaves, tt.overtimes, tt.components, nil)
+
+			var lines []payrollLine
+			for _, line := range payslip.Lines {
+				lines = append(lines, payrollLine{line.Code, line.Amount})
+			}
+			assert.Equal(t, 31, payslip.PeriodDays)
+			assert.Equal(t, tt.wantEmployed, payslip.EmployedDays)
+			assert.Equal(t, tt.wantLines, lines)
+			assert.Equal(t, tt.wantGross, payslip.Gross)
+			assert.Equal(t, tt.wantDeductions, payslip.Deductions)
+			assert.Equal(t, tt.wantGross-tt.wantDeductions, payslip.Net)
+		})
+	}
+}
+
+func TestCreateInactiveComponent(t *testing.T) {
+	db := setupTestDB(t)
+	service := newTestPayrollService()
+	employee := seedEmployee(t, db, models.Employee{Name: "王小明", Salary: 30000})
+	inactive := false
+	requests := []dto.PayComponentRequest{
+		{Name: "交通津貼", Kind: models.PayComponentAllowance, Amount: 1000},
+		{Name: "停發的伙食津貼", Kind: models.PayComponentAllowance, Amount: 2400, Active: &inactive},
+	}
+	components := make([]*models.PayComponent, len(requests))
+	for i := range requests {
+		components[i] = requests[i].ToModel()
+		require.NoError(t, service.CreateComponent(components[i]))
+	}
+
+	stored, err := service.GetComponent(components[1].ID)
+	require.NoError(t, err)
+	assert.False(t, stored.Active, "新增時指定停用的薪資項目應保存為停用")
+
+	run, err := service.CreateRun("2024-10", nil)
+	require.NoError(t, err)
+	payslip, err := service.GetPayslip(run.ID, employee.ID)
+	require.NoError(t, err)
+	var applied []uint
+	for _, line := range payslip.Lines {
+		if line.ComponentID != nil {
+			applied = append(applied, *line.ComponentID)
+		}
+	}
+	assert.Equal(t, []uint{components[0].ID}, applied)
+}
@@ -2,37 +2,113 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"log"
+	"os"
+	"sync"
 	"time"
 
+	"hr-system/config"
 	"hr-system/internal/repositories"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// 預熱模式
+const (
+	PrewarmModeFull        = "full"        // 全量預熱
+	PrewarmModeIncremental = "incremental" // 只刷新上次水位線之後有更新的數據
+)
+
+const (
+	prewarmLockName   = "cache_prewarm"
+	prewarmStateKey   = "prewarm:state"
+	prewarmLastRunKey = "prewarm:last_run"
+	// 水位線往前回退的時間，覆蓋預熱開始前已更新但尚未提交的事務
+	prewarmWatermarkLag = time.Minute
 )
 
+// PrewarmRun 單次預熱的統計
+type PrewarmRun struct {
+	Replica    string     `json:"replica"`
+	Mode       string     `json:"mode"`
+	Scope      string     `json:"scope"`
+	Since      *time.Time `json:"since,omitempty"` // 增量預熱的起始水位線
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt time.Time  `json:"finished_at"`
+	Employees  int        `json:"employees"`
+	Leaves     int        `json:"leaves"`
+	Batches    int        `json:"batches"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// prewarmState 預熱進度，共享緩存時存放在 Redis 中以便其他副本接手
+type prewarmState struct {
+	Watermark   time.Time `json:"watermark"`     // 下一次增量預熱的起點
+	LastFullRun time.Time `json:"last_full_run"` // 最近一次全量預熱的時間
+}
+
+// PrewarmStatus 預熱配置與最近一次執行情況
+type PrewarmStatus struct {
+	Interval     string      `json:"interval"`
+	FullInterval string      `json:"full_interval"`
+	Scope        string      `json:"scope"`
+	BatchSize    int         `json:"batch_size"`
+	Running      bool        `json:"running"`
+	LastRun      *PrewarmRun `json:"last_run,omitempty"` // 集群內最近一次完成的預熱（可能由其他副本執行）
+	LastSkip     string      `json:"last_skip,omitempty"`
+}
+
 type PrewarmService struct {
 	employeeRepo *repositories.EmployeeRepository
 	leaveRepo    *repositories.LeaveRepository
-	cacheService *CacheService
+	cacheService CacheService
+	lock         DistributedLock
+	cfg          config.PrewarmConfig
+	replica      string
+	trigger      chan string
+
+	mu         sync.Mutex
+	running    bool
+	lastRun    *PrewarmRun
+	lastSkip   string
+	localState prewarmState
 }
 
 func NewPrewarmService(
 	employeeRepo *repositories.EmployeeRepository,
 	leaveRepo *repositories.LeaveRepository,
-	cacheService *CacheService,
+	cacheService CacheService,
+	lock DistributedLock,
+	cfg config.PrewarmConfig,
 ) *PrewarmService {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+	replica, _ := os.Hostname()
 	return &PrewarmService{
 		employeeRepo: employeeRepo,
 		leaveRepo:    leaveRepo,
 		cacheService: cacheService,
+		lock:         lock,
+		cfg:          cfg,
+		replica:      replica,
+		trigger:      make(chan string, 1),
 	}
 }
 
 // StartPrewarming 開始預熱緩存
 func (s *PrewarmService) StartPrewarming(ctx context.Context) {
+	if s.cfg.Scope == config.PrewarmScopeNone {
+		log.Println("Cache prewarming disabled")
+		return
+	}
+
 	// 立即執行一次預熱
-	s.prewarmCache(ctx)
+	s.prewarmCache(ctx, "")
 
-	// 每30分鐘執行一次預熱
-	ticker := time.NewTicker(30 * time.Minute)
+	ticker := time.NewTicker(s.cfg.Interval)
 	go func() {
 		for {
 			select {
@@ -40,33 +116,218 @@ func (s *PrewarmService) StartPrewarming(ctx context.Context) {
 				ticker.Stop()
 				return
 			case <-ticker.C:
-				s.prewarmCache(ctx)
+				s.prewarmCache(ctx, "")
+			case mode := <-s.trigger:
+				s.prewarmCache(ctx, mode)
 			}
 		}
 	}()
 }
 
+// Trigger 請求立即執行一次預熱，mode 為空時自動選擇全量或增量
+// 已有待執行的請求時返回 false
+func (s *PrewarmService) Trigger(mode string) bool {
+	select {
+	case s.trigger <- mode:
+		return true
+	default:
+		return false
+	}
+}
+
+// Status 返回預熱配置與最近一次執行情況
+func (s *PrewarmService) Status(ctx context.Context) PrewarmStatus {
+	s.mu.Lock()
+	status := PrewarmStatus{
+		Interval:     s.cfg.Interval.String(),
+		FullInterval: s.cfg.FullInterval.String(),
+		Scope:        s.cfg.Scope,
+		BatchSize:    s.cfg.BatchSize,
+		Running:      s.running,
+		LastRun:      s.lastRun,
+		LastSkip:     s.lastSkip,
+	}
+	s.mu.Unlock()
+
+	// 共享緩存可用時，讀取集群內最近一次的執行結果
+	if s.sharedCache(ctx) {
+		data, err := config.RedisClient.Get(ctx, prewarmLastRunKey).Bytes()
+		if err == nil {
+			var run PrewarmRun
+			if json.Unmarshal(data, &run) == nil {
+				status.LastRun = &run
+			}
+		}
+	}
+	return status
+}
+
+// sharedCache 當前是否使用多副本共享的 Redis 緩存
+// 熔斷後各副本使用自己的進程內緩存，此時每個副本都需要各自預熱
+func (s *PrewarmService) sharedCache(ctx context.Context) bool {
+	return s.cacheService.Health(ctx).Backend == config.CacheBackendRedis
+}
+
 // prewarmCache 執行緩存預熱
-func (s *PrewarmService) prewarmCache(ctx context.Context) {
-	log.Println("Starting cache prewarming...")
+func (s *PrewarmService) prewarmCache(ctx context.Context, mode string) {
+	shared := s.sharedCache(ctx)
 
-	// 獲取所有員工數據
-	employees, err := s.employeeRepo.GetAll()
-	if err != nil {
-		log.Printf("Failed to get employees for prewarming: %v", err)
+	// 共享緩存只需要一個副本預熱
+	if shared {
+		unlock, ok, err := s.lock.TryLock(ctx, prewarmLockName, s.cfg.LockTTL)
+		if err != nil || !ok {
+			reason := "another replica holds the prewarm lock"
+			if err != nil {
+				reason = "failed to acquire prewarm lock: " + err.Error()
+			}
+			log.Printf("Skipping cache prewarming: %s", reason)
+			s.mu.Lock()
+			s.lastSkip = reason
+			s.mu.Unlock()
+			return
+		}
+		defer unlock()
+	}
+
+	s.mu.Lock()
+	if s.running {
+		s.mu.Unlock()
 		return
 	}
+	s.running = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.running = false
+		s.mu.Unlock()
+	}()
+
+	run := &PrewarmRun{
+		Replica:   s.replica,
+		Scope:     s.cfg.Scope,
+		StartedAt: time.Now(),
+	}
 
-	// 獲取所有請假記錄
-	leaves, err := s.leaveRepo.GetAll()
+	state, err := s.loadState(ctx, shared)
 	if err != nil {
-		log.Printf("Failed to get leaves for prewarming: %v", err)
-		return
+		log.Printf("Failed to load prewarm state, falling back to full prewarm: %v", err)
 	}
+	if mode == "" {
+		mode = PrewarmModeIncremental
+		if state.Watermark.IsZero() || time.Since(state.LastFullRun) >= s.cfg.FullInterval {
+			mode = PrewarmModeFull
+		}
+	}
+	since := state.Watermark
+	if mode == PrewarmModeFull {
+		since = time.Time{}
+	} else {
+		run.Since = &since
+	}
+	run.Mode = mode
 
-	// 執行預熱
-	if err := s.cacheService.PrewarmCache(ctx, employees, leaves); err != nil {
+	log.Printf("Starting %s cache prewarming (scope=%s)...", mode, s.cfg.Scope)
+	if err := s.prewarmBatches(ctx, since, run); err != nil {
 		log.Printf("Failed to prewarm cache: %v", err)
-		return
+		run.Error = err.Error()
+	} else {
+		// 下一次增量預熱從本次開始時間（回退一段時間）開始
+		state.Watermark = run.StartedAt.Add(-prewarmWatermarkLag)
+		if mode == PrewarmModeFull {
+			state.LastFullRun = run.StartedAt
+		}
+		if err := s.saveState(ctx, shared, state); err != nil {
+			log.Printf("Failed to save prewarm state: %v", err)
+		}
+	}
+	run.FinishedAt = time.Now()
+	log.Printf("Finished %s cache prewarming: %d employees, %d leaves in %d batches (%s)",
+		mode, run.Employees, run.Leaves, run.Batches, run.FinishedAt.Sub(run.StartedAt))
+
+	s.mu.Lock()
+	s.lastRun = run
+	s.lastSkip = ""
+	s.mu.Unlock()
+	if shared {
+		if data, err := json.Marshal(run); err == nil {
+			config.RedisClient.Set(ctx, prewarmLastRunKey, data, 0)
+		}
+	}
+}
+
+// prewarmBatches 分批讀取數據並寫入緩存
+func (s *PrewarmService) prewarmBatches(ctx context.Context, since time.Time, run *PrewarmRun) error {
+	if s.cfg.Scope == config.PrewarmScopeAll || s.cfg.Scope == config.PrewarmScopeEmployees {
+		var afterID uint
+		for {
+			employees, err := s.employeeRepo.GetUpdatedSince(since, afterID, s.cfg.BatchSize)
+			if err != nil {
+				return err
+			}
+			if len(employees) == 0 {
+				break
+			}
+			if err := s.cacheService.PrewarmCache(ctx, employees, nil); err != nil {
+				return err
+			}
+			run.Employees += len(employees)
+			run.Batches++
+			afterID = employees[len(employees)-1].ID
+		}
+	}
+
+	if s.cfg.Scope == config.PrewarmScopeAll || s.cfg.Scope == config.PrewarmScopeLeaves {
+		var afterID uint
+		for {
+			leaves, err := s.leaveRepo.GetUpdatedSince(since, afterID, s.cfg.BatchSize)
+			if err != nil {
+				return err
+			}
+			if len(leaves) == 0 {
+				break
+			}
+			if err := s.cacheService.PrewarmCache(ctx, nil, leaves); err != nil {
+				return err
+			}
+			run.Leaves += len(leaves)
+			run.Batches++
+			afterID = leaves[len(leaves)-1].ID
+		}
+	}
+	return nil
+}
+
+// loadState 讀取預熱進度
+func (s *PrewarmService) loadState(ctx context.Context, shared bool) (prewarmState, error) {
+	if !shared {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.localState, nil
+	}
+
+	var state prewarmState
+	data, err := config.RedisClient.Get(ctx, prewarmStateKey).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	err = json.Unmarshal(data, &state)
+	return state, err
+}
+
+func (s *PrewarmService) saveState(ctx context.Context, shared bool, state prewarmState) error {
+	if !shared {
+		s.mu.Lock()
+		s.localState = state
+		s.mu.Unlock()
+		return nil
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
 	}
+	return config.RedisClient.Set(ctx, prewarmStateKey, data, 0).Err()
 }
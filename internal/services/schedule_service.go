@@ -0,0 +1,579 @@
+package services
+
+import (
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+	"hr-system/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+// maxCycleDays 輪班模板的最長週期
+const maxCycleDays = 56
+
+// ScheduleService 管理班表模板、員工排班與換班申請，並計算員工每天排定的班次
+// 沒有排班的日子按配置的默認上下班時間於週一至週五上班，公司假日不上班
+type ScheduleService struct {
+	scheduleRepo *repositories.ScheduleRepository
+	employeeRepo *repositories.EmployeeRepository
+	holidayRepo  *repositories.HolidayRepository
+	approvals    *ApprovalService
+	config       config.ScheduleConfig
+	now          func() time.Time
+}
+
+func NewScheduleService(scheduleRepo *repositories.ScheduleRepository, employeeRepo *repositories.EmployeeRepository, holidayRepo *repositories.HolidayRepository, approvals *ApprovalService, cfg config.ScheduleConfig) *ScheduleService {
+	return &ScheduleService{
+		scheduleRepo: scheduleRepo,
+		employeeRepo: employeeRepo,
+		holidayRepo:  holidayRepo,
+		approvals:    approvals,
+		config:       cfg,
+		now:          time.Now,
+	}
+}
+
+// CreateTemplate 創建班表模板
+func (s *ScheduleService) CreateTemplate(template *models.WorkScheduleTemplate) error {
+	if err := validateTemplate(template); err != nil {
+		return err
+	}
+	return s.scheduleRepo.CreateTemplate(template)
+}
+
+// GetTemplate 獲取班表模板
+func (s *ScheduleService) GetTemplate(id uint) (*models.WorkScheduleTemplate, error) {
+	return s.scheduleRepo.GetTemplate(id)
+}
+
+// ListTemplates 獲取所有班表模板
+func (s *ScheduleService) ListTemplates() ([]models.WorkScheduleTemplate, error) {
+	return s.scheduleRepo.ListTemplates()
+}
+
+// UpdateTemplate 更新班表模板並替換其班次，已有的排班立即按新的班次計算
+func (s *ScheduleService) UpdateTemplate(template *models.WorkScheduleTemplate) error {
+	existing, err := s.scheduleRepo.GetTemplate(template.ID)
+	if err != nil {
+		return err
+	}
+	if err := validateTemplate(template); err != nil {
+		return err
+	}
+	template.CreatedAt = existing.CreatedAt
+	return repositories.Transaction(func(tx *gorm.DB) error {
+		return s.scheduleRepo.WithTx(tx).UpdateTemplate(template)
+	})
+}
+
+// DeleteTemplate 刪除沒有排班使用的班表模板
+func (s *ScheduleService) DeleteTemplate(id uint) error {
+	inUse, err := s.scheduleRepo.TemplateInUse(id)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return apperrors.Conflict(apperrors.CodeScheduleTemplateInUse, "The schedule template is used by roster assignments")
+	}
+	return repositories.Transaction(func(tx *gorm.DB) error {
+		return s.scheduleRepo.WithTx(tx).DeleteTemplate(id)
+	})
+}
+
+// validateTemplate 檢查班表模板，固定工時與兼職的週期固定為 7 天
+func validateTemplate(template *models.WorkScheduleTemplate) error {
+	invalid := func(field, msg string) error {
+		return apperrors.Validation(apperrors.CodeInvalidScheduleTemplate, msg,
+			apperrors.Field(field, apperrors.CodeInvalidScheduleTemplate, msg))
+	}
+	if template.Kind == models.ScheduleKindRotating {
+		if template.CycleDays < 1 || template.CycleDays > maxCycleDays {
+			return invalid("cycle_days", "Rotating schedules need a cycle of 1 to 56 days")
+		}
+	} else {
+		if template.CycleDays != 0 && template.CycleDays != 7 {
+			return invalid("cycle_days", "Fixed and part-time schedules repeat weekly")
+		}
+		template.CycleDays = 7
+	}
+	if len(template.Shifts) == 0 {
+		return invalid("shifts", "A schedule template needs at least one shift")
+	}
+
+	days := make(map[int]bool, len(template.Shifts))
+	for _, shift := range template.Shifts {
+		if shift.DayIndex < 0 || shift.DayIndex >= template.CycleDays || days[shift.DayIndex] {
+			return invalid("shifts", "Each shift needs a distinct day within the cycle")
+		}
+		days[shift.DayIndex] = true
+		start, err := config.ParseClock(shift.Start)
+		if err != nil {
+			return invalid("shifts", "Shift times must be in HH:MM format")
+		}
+		end, err := config.ParseClock(shift.End)
+		if err != nil {
+			return invalid("shifts", "Shift times must be in HH:MM format")
+		}
+		if start == end {
+			return invalid("shifts", "A shift must not start and end at the same time")
+		}
+		if end < start {
+			end += 24 * time.Hour
+		}
+		if shift.BreakMinutes < 0 || time.Duration(shift.BreakMinutes)*time.Minute >= end-start {
+			return invalid("shifts", "A shift's break must be shorter than the shift")
+		}
+	}
+	return nil
+}
+
+// AssignRoster 為員工排班，同一員工的排班期間不能重疊
+func (s *ScheduleService) AssignRoster(roster *models.RosterAssignment) error {
+	if _, err := s.employeeRepo.GetByID(roster.EmployeeID); err != nil {
+		if apperrors.IsNotFound(err) {
+			return apperrors.Validation(apperrors.CodeEmployeeNotFound, "Employee not found",
+				apperrors.Field("employee_id", apperrors.CodeEmployeeNotFound, "Employee not found"))
+		}
+		return err
+	}
+	template, err := s.scheduleRepo.GetTemplate(roster.TemplateID)
+	if err != nil {
+		if apperrors.IsNotFound(err) {
+			return apperrors.Validation(apperrors.CodeScheduleTemplateNotFound, "Schedule template not found",
+				apperrors.Field("template_id", apperrors.CodeScheduleTemplateNotFound, "Schedule template not found"))
+		}
+		return err
+	}
+	if roster.EndDate != nil && roster.EndDate.Before(roster.StartDate) {
+		return apperrors.Validation(apperrors.CodeInvalidDateRange, "Start date must be before end date",
+			apperrors.Field("end_date", apperrors.CodeInvalidDateRange, "Start date must be before end date"))
+	}
+	if template.Kind != models.ScheduleKindRotating {
+		roster.CycleOffset = 0
+	} else if roster.CycleOffset >= template.CycleDays {
+		return apperrors.Validation(apperrors.CodeInvalidScheduleTemplate, "Cycle offset must be within the template's cycle",
+			apperrors.Field("cycle_offset", apperrors.CodeInvalidScheduleTemplate, "Cycle offset must be within the template's cycle"))
+	}
+
+	overlap, err := s.scheduleRepo.HasRosterOverlap(roster.EmployeeID, roster.StartDate, roster.EndDate)
+	if err != nil {
+		return err
+	}
+	if overlap {
+		return apperrors.Conflict(apperrors.CodeRosterOverlap, "The employee already has a roster assignment in this period")
+	}
+	if err := s.scheduleRepo.CreateRoster(roster); err != nil {
+		return err
+	}
+	roster.Template = *template
+	return nil
+}
+
+// ListRosters 獲取員工的排班
+func (s *ScheduleService) ListRosters(employeeID uint) ([]models.RosterAssignment, error) {
+	if _, err := s.employeeRepo.GetByID(employeeID); err != nil {
+		return nil, err
+	}
+	return s.scheduleRepo.ListRosters(employeeID)
+}
+
+// DeleteRoster 刪除排班
+func (s *ScheduleService) DeleteRoster(id uint) error {
+	return s.scheduleRepo.DeleteRoster(id)
+}
+
+// Shifts 按日期獲取員工在 [from, to] 內排定的班次
+func (s *ScheduleService) Shifts(employeeID uint, from, to time.Time) ([]models.ScheduledShift, error) {
+	employee, err := s.employeeRepo.GetByID(employeeID)
+	if err != nil {
+		return nil, err
+	}
+	from, end := startOfDay(from), startOfDay(to).AddDate(0, 0, 1)
+	if !end.After(from) {
+		return nil, apperrors.Validation(apperrors.CodeInvalidDateRange, "Start date must be before end date",
+			apperrors.Field("to", apperrors.CodeInvalidDateRange, "Start date must be before end date"))
+	}
+	if end.Sub(from) > s.config.MaxRange {
+		return nil, apperrors.Validation(apperrors.CodeScheduleRangeTooLong, "Schedule range is too long")
+	}
+
+	shifts, err := s.scheduledShifts([]models.Employee{*employee}, from, end)
+	if err != nil {
+		return nil, err
+	}
+	result := []models.ScheduledShift{}
+	for day := from; day.Before(end); day = day.AddDate(0, 0, 1) {
+		if shift, ok := shifts[employee.ID][day.Format("2006-01-02")]; ok {
+			result = append(result, shift)
+		}
+	}
+	return result, nil
+}
+
+// ShiftAt 返回員工在 at 時正在進行的班次，不在班時返回空；跨夜班次在次日凌晨仍算在班
+func (s *ScheduleService) ShiftAt(employeeID uint, at time.Time) (*models.ScheduledShift, error) {
+	employee, err := s.employeeRepo.GetByID(employeeID)
+	if err != nil {
+		return nil, err
+	}
+	day := startOfDay(at)
+	shifts, err := s.scheduledShifts([]models.Employee{*employee}, day.AddDate(0, 0, -1), day.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	for _, date := range []time.Time{day, day.AddDate(0, 0, -1)} {
+		if shift, ok := shifts[employee.ID][date.Format("2006-01-02")]; ok && shift.Contains(at) {
+			return &shift, nil
+		}
+	}
+	return nil, nil
+}
+
+// scheduledShifts 計算員工在 [from, to) 每天開始的班次，按員工ID與 YYYY-MM-DD 索引；
+// 入職之前與離職日期之後的日子沒有班次
+func (s *ScheduleService) scheduledShifts(employees []models.Employee, from, to time.Time) (map[uint]map[string]models.ScheduledShift, error) {
+	result := make(map[uint]map[string]models.ScheduledShift, len(employees))
+	if len(employees) == 0 {
+		return result, nil
+	}
+	ids := make([]uint, len(employees))
+	for i, employee := range employees {
+		ids[i] = employee.ID
+	}
+
+	holidayList, err := s.holidayRepo.ListBetween(from, to)
+	if err != nil {
+		return nil, err
+	}
+	holidays := make(map[string]bool, len(holidayList))
+	for _, holiday := range holidayList {
+		holidays[holiday.Date.Format("2006-01-02")] = true
+	}
+	rosterList, err := s.scheduleRepo.ListRostersBetween(ids, from, to)
+	if err != nil {
+		return nil, err
+	}
+	rosters := make(map[uint][]models.RosterAssignment)
+	for _, roster := range rosterList {
+		rosters[roster.EmployeeID] = append(rosters[roster.EmployeeID], roster)
+	}
+	overrideList, err := s.scheduleRepo.ListOverrides(ids, from, to)
+	if err != nil {
+		return nil, err
+	}
+	// 同一員工同一天以最新的覆蓋為準
+	overrides := make(map[uint]map[string]models.ShiftOverride)
+	for _, override := range overrideList {
+		if overrides[override.EmployeeID] == nil {
+			overrides[override.EmployeeID] = make(map[string]models.ShiftOverride)
+		}
+		overrides[override.EmployeeID][override.Date.Format("2006-01-02")] = override
+	}
+
+	for i := range employees {
+		employee := &employees[i]
+		shifts := make(map[string]models.ScheduledShift)
+		result[employee.ID] = shifts
+		if employee.Status == models.EmployeeStatusInactive && employee.TerminationDate == nil {
+			continue
+		}
+		for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+			if !employee.HireDate.IsZero() && day.Before(startOfDay(employee.HireDate)) {
+				continue
+			}
+			if employee.TerminationDate != nil && day.After(startOfDay(*employee.TerminationDate)) {
+				continue
+			}
+			key := day.Format("2006-01-02")
+			if override, ok := overrides[employee.ID][key]; ok {
+				if !override.Off && override.Start != nil && override.End != nil {
+					shifts[key] = models.ScheduledShift{
+						EmployeeID:   employee.ID,
+						Date:         key,
+						Start:        *override.Start,
+						End:          *override.End,
+						BreakMinutes: override.BreakMinutes,
+						Source:       models.ShiftSourceSwap,
+						SwapID:       override.SwapID,
+					}
+				}
+				continue
+			}
+			if shift, ok := s.shiftOn(employee.ID, day, rosters[employee.ID], holidays[key]); ok {
+				shifts[key] = shift
+			}
+		}
+	}
+	return result, nil
+}
+
+// shiftOn 按排班或默認上下班時間計算員工某天的班次
+func (s *ScheduleService) shiftOn(employeeID uint, day time.Time, rosters []models.RosterAssignment, holiday bool) (models.ScheduledShift, bool) {
+	shift := models.ScheduledShift{EmployeeID: employeeID, Date: day.Format("2006-01-02")}
+	for _, roster := range rosters {
+		start := startOfDay(roster.StartDate)
+		if day.Before(start) || (roster.EndDate != nil && day.After(startOfDay(*roster.EndDate))) {
+			continue
+		}
+		template := &roster.Template
+		if holiday && template.SkipHolidays {
+			return shift, false
+		}
+		var index int
+		if template.Kind == models.ScheduleKindRotating {
+			elapsed := int(day.Sub(start).Round(24*time.Hour) / (24 * time.Hour))
+			index = (elapsed + roster.CycleOffset) % template.CycleDays
+		} else {
+			index = (int(day.Weekday()) + 6) % 7
+		}
+		for _, templateShift := range template.Shifts {
+			if templateShift.DayIndex != index {
+				continue
+			}
+			startClock, _ := config.ParseClock(templateShift.Start)
+			endClock, _ := config.ParseClock(templateShift.End)
+			if endClock <= startClock {
+				endClock += 24 * time.Hour
+			}
+			templateID := template.ID
+			shift.Start, shift.End = day.Add(startClock), day.Add(endClock)
+			shift.BreakMinutes = templateShift.BreakMinutes
+			shift.Source = models.ShiftSourceRoster
+			shift.TemplateID = &templateID
+			return shift, true
+		}
+		return shift, false
+	}
+
+	weekday := day.Weekday()
+	if holiday || weekday == time.Saturday || weekday == time.Sunday {
+		return shift, false
+	}
+	shift.Start, shift.End = day.Add(s.config.DefaultStart), day.Add(s.config.DefaultEnd)
+	shift.Source = models.ShiftSourceDefault
+	return shift, true
+}
+
+// CreateSwap 提交換班申請，申請人在 Date 與對方在 CounterpartDate 都必須有班次
+func (s *ScheduleService) CreateSwap(swap *models.ShiftSwapRequest) error {
+	if swap.CounterpartID == swap.RequesterID {
+		return errInvalidShiftSwap("counterpart_id", "Cannot swap shifts with yourself")
+	}
+	requester, err := s.employeeRepo.GetByID(swap.RequesterID)
+	if err != nil {
+		return err
+	}
+	counterpart, err := s.employeeRepo.GetByID(swap.CounterpartID)
+	if apperrors.IsNotFound(err) {
+		return errInvalidShiftSwap("counterpart_id", "The counterpart must be another active employee")
+	}
+	if err != nil {
+		return err
+	}
+	if counterpart.Status == models.EmployeeStatusInactive {
+		return errInvalidShiftSwap("counterpart_id", "The counterpart must be another active employee")
+	}
+	if swap.CounterpartDate.IsZero() {
+		swap.CounterpartDate = swap.Date
+	}
+	today := startOfDay(s.now())
+	if swap.Date.Before(today) || swap.CounterpartDate.Before(today) {
+		return errInvalidShiftSwap("date", "Only future shifts can be swapped")
+	}
+	if _, _, err := s.swapShifts(swap, requester, counterpart); err != nil {
+		return err
+	}
+
+	swap.Status = models.ShiftSwapPending
+	if err := s.scheduleRepo.CreateSwap(swap); err != nil {
+		return err
+	}
+	swap.Requester, swap.Counterpart = *requester, *counterpart
+	return nil
+}
+
+// swapShifts 返回換班雙方要交換的班次；日期不同時雙方在對方讓出班次的日期不能已有班次
+func (s *ScheduleService) swapShifts(swap *models.ShiftSwapRequest, requester, counterpart *models.Employee) (*models.ScheduledShift, *models.ScheduledShift, error) {
+	from, to := swap.Date, swap.CounterpartDate
+	if to.Before(from) {
+		from, to = to, from
+	}
+	shifts, err := s.scheduledShifts([]models.Employee{*requester, *counterpart}, startOfDay(from), startOfDay(to).AddDate(0, 0, 1))
+	if err != nil {
+		return nil, nil, err
+	}
+	date, counterpartDate := swap.Date.Format("2006-01-02"), swap.CounterpartDate.Format("2006-01-02")
+	requesterShift, ok := shifts[requester.ID][date]
+	if !ok {
+		return nil, nil, errInvalidShiftSwap("date", "You have no shift on this date")
+	}
+	counterpartShift, ok := shifts[counterpart.ID][counterpartDate]
+	if !ok {
+		return nil, nil, errInvalidShiftSwap("counterpart_date", "The counterpart has no shift on this date")
+	}
+	if date != counterpartDate {
+		if _, busy := shifts[counterpart.ID][date]; busy {
+			return nil, nil, errInvalidShiftSwap("date", "The counterpart already has a shift on this date")
+		}
+		if _, busy := shifts[requester.ID][counterpartDate]; busy {
+			return nil, nil, errInvalidShiftSwap("counterpart_date", "You already have a shift on this date")
+		}
+	}
+	return &requesterShift, &counterpartShift, nil
+}
+
+// GetSwap 獲取換班申請
+func (s *ScheduleService) GetSwap(id uint) (*models.ShiftSwapRequest, error) {
+	return s.scheduleRepo.GetSwap(id)
+}
+
+// ListSwaps 獲取換班申請，employeeID 為 0 或 status 為空時不限制
+func (s *ScheduleService) ListSwaps(employeeID uint, status string) ([]models.ShiftSwapRequest, error) {
+	return s.scheduleRepo.ListSwaps(employeeID, status)
+}
+
+// RespondSwap 對方同意或拒絕待同意的換班申請
+func (s *ScheduleService) RespondSwap(id, employeeID uint, accept bool) (*models.ShiftSwapRequest, error) {
+	swap, err := s.scheduleRepo.GetSwap(id)
+	if err != nil {
+		return nil, err
+	}
+	if swap.CounterpartID != employeeID {
+		return nil, apperrors.Forbidden(apperrors.CodeShiftSwapNotAllowed, "Only the counterpart can respond to this shift swap")
+	}
+	if swap.Status != models.ShiftSwapPending {
+		return nil, errShiftSwapStatus()
+	}
+
+	now := s.now()
+	swap.Status = models.ShiftSwapDeclined
+	if accept {
+		swap.Status = models.ShiftSwapAccepted
+	}
+	swap.RespondTime = &now
+	if err := s.updateSwapStatus(swap, models.ShiftSwapPending); err != nil {
+		return nil, err
+	}
+	return swap, nil
+}
+
+// CancelSwap 申請人取消尚未審批的換班申請
+func (s *ScheduleService) CancelSwap(id, employeeID uint) (*models.ShiftSwapRequest, error) {
+	swap, err := s.scheduleRepo.GetSwap(id)
+	if err != nil {
+		return nil, err
+	}
+	if swap.RequesterID != employeeID {
+		return nil, apperrors.Forbidden(apperrors.CodeShiftSwapNotAllowed, "Only the requester can cancel this shift swap")
+	}
+	if swap.Status != models.ShiftSwapPending && swap.Status != models.ShiftSwapAccepted {
+		return nil, errShiftSwapStatus()
+	}
+
+	current := swap.Status
+	swap.Status = models.ShiftSwapCancelled
+	if err := s.updateSwapStatus(swap, current); err != nil {
+		return nil, err
+	}
+	return swap, nil
+}
+
+// UpdateSwapStatus 審批對方已同意的換班申請，審批人規則與申請人請假相同；核准後雙方的班次立即交換
+func (s *ScheduleService) UpdateSwapStatus(id uint, status, remark string, approverID uint) (*models.ShiftSwapRequest, error) {
+	swap, err := s.scheduleRepo.GetSwap(id)
+	if err != nil {
+		return nil, err
+	}
+	if status != models.ShiftSwapApproved && status != models.ShiftSwapRejected {
+		return nil, apperrors.Validation(apperrors.CodeInvalidStatus, "Invalid status",
+			apperrors.Field("status", apperrors.CodeInvalidStatus, "Status must be approved or rejected"))
+	}
+	if swap.Status != models.ShiftSwapAccepted {
+		return nil, errShiftSwapStatus()
+	}
+
+	// 檢查審批人是否為申請人的直屬主管或當前的代理審批人，代理審批時記錄原審批人
+	onBehalfOf, err := s.approvals.AuthorizeEmployeeApproval(&swap.Requester, approverID)
+	if err != nil {
+		return nil, err
+	}
+
+	var overrides []models.ShiftOverride
+	if status == models.ShiftSwapApproved {
+		// 提交後排班可能已經變化，按核准時的班次交換
+		requesterShift, counterpartShift, err := s.swapShifts(swap, &swap.Requester, &swap.Counterpart)
+		if err != nil {
+			return nil, err
+		}
+		overrides = swapOverrides(swap, requesterShift, counterpartShift)
+	}
+
+	now := s.now()
+	swap.Status = status
+	swap.ApproveRemark = remark
+	swap.ApproverID = &approverID
+	swap.OnBehalfOfID = onBehalfOf
+	swap.ApproveTime = &now
+	err = repositories.Transaction(func(tx *gorm.DB) error {
+		updated, err := s.scheduleRepo.WithTx(tx).UpdateSwapStatus(swap, models.ShiftSwapAccepted)
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return errShiftSwapStatus()
+		}
+		return s.scheduleRepo.WithTx(tx).CreateOverrides(overrides)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return swap, nil
+}
+
+// updateSwapStatus 僅在換班申請仍為 from 狀態時更新，避免並發操作覆蓋彼此的結果
+func (s *ScheduleService) updateSwapStatus(swap *models.ShiftSwapRequest, from string) error {
+	updated, err := s.scheduleRepo.UpdateSwapStatus(swap, from)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return errShiftSwapStatus()
+	}
+	return nil
+}
+
+// swapOverrides 生成換班後雙方的單日班次：申請人在 Date 的班次給對方，對方在 CounterpartDate 的班次給申請人
+func swapOverrides(swap *models.ShiftSwapRequest, requesterShift, counterpartShift *models.ScheduledShift) []models.ShiftOverride {
+	assign := func(employeeID uint, date time.Time, shift *models.ScheduledShift) models.ShiftOverride {
+		override := models.ShiftOverride{EmployeeID: employeeID, Date: startOfDay(date), Off: shift == nil, SwapID: &swap.ID}
+		if shift != nil {
+			start, end := shift.Start, shift.End
+			override.Start, override.End = &start, &end
+			override.BreakMinutes = shift.BreakMinutes
+		}
+		return override
+	}
+	if swap.Date.Format("2006-01-02") == swap.CounterpartDate.Format("2006-01-02") {
+		return []models.ShiftOverride{
+			assign(swap.RequesterID, swap.Date, counterpartShift),
+			assign(swap.CounterpartID, swap.Date, requesterShift),
+		}
+	}
+	return []models.ShiftOverride{
+		assign(swap.RequesterID, swap.Date, nil),
+		assign(swap.CounterpartID, swap.Date, requesterShift),
+		assign(swap.CounterpartID, swap.CounterpartDate, nil),
+		assign(swap.RequesterID, swap.CounterpartDate, counterpartShift),
+	}
+}
+
+func errInvalidShiftSwap(field, msg string) *apperrors.Error {
+	return apperrors.Validation(apperrors.CodeInvalidShiftSwap, msg, apperrors.Field(field, apperrors.CodeInvalidShiftSwap, msg))
+}
+
+func errShiftSwapStatus() *apperrors.Error {
+	return apperrors.Conflict(apperrors.CodeShiftSwapStatus, "The shift swap can no longer be changed in its current status")
+}
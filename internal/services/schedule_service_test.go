@@ -0,0 +1,113 @@
+package services
+
+import (
+	"testing"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/dto"
+	"hr-system/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateSwapStatusAuthorizesApprover(t *testing.T) {
+	tests := []struct {
+		name     string
+		approver string
+		wantCode string
+	}{
+		{name: "申請人的直屬主管核准", approver: "主管"},
+		{name: "申請人不能核准自己的換班", approver: "申請人", wantCode: apperrors.CodeNotApprover},
+		{name: "對方不能核准", approver: "對方", wantCode: apperrors.CodeNotApprover},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupTestDB(t)
+			service := newTestScheduleService()
+			manager := seedEmployee(t, db, models.Employee{Name: "主管"})
+			requester := seedEmployee(t, db, models.Employee{Name: "申請人", ManagerID: &manager.ID})
+			counterpart := seedEmployee(t, db, models.Employee{Name: "對方", ManagerID: &manager.ID})
+			seedRoster(t, db, requester.ID, "早班", 0)
+			seedRoster(t, db, counterpart.ID, "晚班", 1)
+			approvers := map[string]uint{"主管": manager.ID, "申請人": requester.ID, "對方": counterpart.ID}
+			// 申請人讓出 10/7（週一）的班次，接手對方 10/8（週二）的班次
+			swap := models.ShiftSwapRequest{
+				RequesterID:     requester.ID,
+				CounterpartID:   counterpart.ID,
+				Date:            date(2024, 10, 7),
+				CounterpartDate: date(2024, 10, 8),
+				Status:          models.ShiftSwapAccepted,
+			}
+			require.NoError(t, db.Create(&swap).Error)
+
+			_, err := service.UpdateSwapStatus(swap.ID, models.ShiftSwapApproved, "", approvers[tt.approver])
+
+			var stored models.ShiftSwapRequest
+			require.NoError(t, db.First(&stored, swap.ID).Error)
+			var overrides int64
+			db.Model(&models.ShiftOverride{}).Count(&overrides)
+			if tt.wantCode == "" {
+				require.NoError(t, err)
+				assert.Equal(t, models.ShiftSwapApproved, stored.Status)
+				require.NotNil(t, stored.ApproverID)
+				assert.Equal(t, manager.ID, *stored.ApproverID)
+				assert.NotZero(t, overrides)
+				return
+			}
+			var appErr *apperrors.Error
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, tt.wantCode, appErr.Code)
+			assert.Equal(t, models.ShiftSwapAccepted, stored.Status)
+			assert.Zero(t, overrides, "未授權的審批不應交換班次")
+		})
+	}
+}
+
+func TestCreateTemplateSkipHolidays(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		name         string
+		kind         string
+		cycleDays    int
+		dayIndex     int
+		skipHolidays *bool
+		want         bool
+	}{
+		{name: "固定工時默認假日不上班", kind: models.ScheduleKindFixed, dayIndex: 3, want: true},
+		{name: "輪班默認假日照常上班", kind: models.ScheduleKindRotating, cycleDays: 1, want: false},
+		{name: "固定工時指定假日照常上班", kind: models.ScheduleKindFixed, dayIndex: 3, skipHolidays: &no, want: false},
+		{name: "輪班指定假日不上班", kind: models.ScheduleKindRotating, cycleDays: 1, skipHolidays: &yes, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := setupTestDB(t)
+			service := newTestScheduleService()
+			employee := seedEmployee(t, db, models.Employee{Name: "員工"})
+			seedHoliday(t, db, date(2024, 10, 10), "國慶日")
+			req := dto.ScheduleTemplateRequest{
+				Name:         "班表",
+				Kind:         tt.kind,
+				CycleDays:    tt.cycleDays,
+				SkipHolidays: tt.skipHolidays,
+				Shifts:       []dto.ScheduleShiftRequest{{DayIndex: tt.dayIndex, Start: "09:00", End: "18:00"}},
+			}
+			template := req.ToModel()
+			require.NoError(t, service.CreateTemplate(template))
+			require.NoError(t, db.Create(&models.RosterAssignment{EmployeeID: employee.ID, TemplateID: template.ID, StartDate: date(2024, 10, 7)}).Error)
+
+			stored, err := service.GetTemplate(template.ID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, stored.SkipHolidays)
+
+			// 班表在 10/10（週四）有班次，該天為公司假日
+			shifts, err := service.Shifts(employee.ID, date(2024, 10, 10), date(2024, 10, 10))
+			require.NoError(t, err)
+			if tt.want {
+				assert.Empty(t, shifts)
+			} else {
+				assert.Len(t, shifts, 1)
+			}
+		})
+	}
+}
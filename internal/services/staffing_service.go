@@ -0,0 +1,260 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+	"hr-system/internal/repositories"
+)
+
+// staffingLeaveStatuses 計入缺勤人數的請假狀態
+var staffingLeaveStatuses = []string{models.LeaveStatusApproved}
+
+// StaffingService 管理人力規則，並評估請假對部門與團隊在崗人數的影響
+// 規則只計算請假員工排定有班次的日子，每天的應在崗人數為當天排定有班次的成員；同一天有多筆請假的員工只計一次
+type StaffingService struct {
+	ruleRepo     *repositories.StaffingRuleRepository
+	employeeRepo *repositories.EmployeeRepository
+	leaveRepo    *repositories.LeaveRepository
+	schedules    *ScheduleService
+}
+
+func NewStaffingService(ruleRepo *repositories.StaffingRuleRepository, employeeRepo *repositories.EmployeeRepository, leaveRepo *repositories.LeaveRepository, schedules *ScheduleService) *StaffingService {
+	return &StaffingService{
+		ruleRepo:     ruleRepo,
+		employeeRepo: employeeRepo,
+		leaveRepo:    leaveRepo,
+		schedules:    schedules,
+	}
+}
+
+// CreateRule 創建人力規則
+func (s *StaffingService) CreateRule(rule *models.StaffingRule) error {
+	if err := s.validateRule(rule); err != nil {
+		return err
+	}
+	return s.ruleRepo.Create(rule)
+}
+
+// GetRule 獲取人力規則
+func (s *StaffingService) GetRule(id uint) (*models.StaffingRule, error) {
+	return s.ruleRepo.GetByID(id)
+}
+
+// ListRules 獲取所有人力規則
+func (s *StaffingService) ListRules() ([]models.StaffingRule, error) {
+	return s.ruleRepo.List()
+}
+
+// UpdateRule 更新人力規則
+func (s *StaffingService) UpdateRule(rule *models.StaffingRule) error {
+	existing, err := s.ruleRepo.GetByID(rule.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.validateRule(rule); err != nil {
+		return err
+	}
+	rule.CreatedAt = existing.CreatedAt
+	return s.ruleRepo.Update(rule)
+}
+
+// DeleteRule 刪除人力規則
+func (s *StaffingService) DeleteRule(id uint) error {
+	return s.ruleRepo.Delete(id)
+}
+
+// validateRule 檢查規則的適用範圍與限制條件
+func (s *StaffingService) validateRule(rule *models.StaffingRule) error {
+	if (rule.Department == "") == (rule.ManagerID == nil) {
+		return apperrors.Validation(apperrors.CodeInvalidStaffingRule, "Specify exactly one of department or manager_id")
+	}
+	if rule.MinPresent == 0 && rule.MaxAbsentRatio == 0 {
+		return apperrors.Validation(apperrors.CodeInvalidStaffingRule, "Specify min_present, max_absent_ratio or both")
+	}
+	if rule.StartDate != nil && rule.EndDate != nil && rule.StartDate.After(*rule.EndDate) {
+		return apperrors.Validation(apperrors.CodeInvalidDateRange, "Start date must be before end date",
+			apperrors.Field("end_date", apperrors.CodeInvalidDateRange, "Start date must be before end date"))
+	}
+	if rule.ManagerID != nil {
+		_, err := s.employeeRepo.GetByID(*rule.ManagerID)
+		if apperrors.IsNotFound(err) {
+			return apperrors.Validation(apperrors.CodeManagerNotFound, "Manager not found",
+				apperrors.Field("manager_id", apperrors.CodeManagerNotFound, "Manager not found"))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LeaveCoverage 獲取請假的人力情況，供審批人在審批前查看
+func (s *StaffingService) LeaveCoverage(leaveID uint) ([]models.StaffingCoverage, error) {
+	leave, err := s.leaveRepo.GetByID(leaveID)
+	if err != nil {
+		return nil, err
+	}
+	return s.Evaluate(leave)
+}
+
+// Check 評估請假是否滿足所有適用的人力規則
+// 不滿足 block 規則時返回衝突錯誤，不滿足 warn 規則時返回這些規則的人力情況作為警告
+func (s *StaffingService) Check(leave *models.Leave) ([]models.StaffingCoverage, error) {
+	coverage, err := s.Evaluate(leave)
+	if err != nil {
+		return nil, err
+	}
+	var warnings []models.StaffingCoverage
+	for _, rule := range coverage {
+		if rule.Satisfied {
+			continue
+		}
+		if rule.Enforcement == models.StaffingEnforcementBlock {
+			return nil, apperrors.Conflict(apperrors.CodeStaffingRuleViolated,
+				fmt.Sprintf("The leave would violate staffing rule %q", rule.RuleName))
+		}
+		warnings = append(warnings, rule)
+	}
+	return warnings, nil
+}
+
+// Evaluate 計算假設請假被核准後，每條適用規則在請假期間內每天的在崗人數
+// 請假本身不論當前狀態都計入缺勤，其他員工只計已核准的請假
+func (s *StaffingService) Evaluate(leave *models.Leave) ([]models.StaffingCoverage, error) {
+	employee := &leave.Employee
+	if employee.ID == 0 {
+		var err error
+		if employee, err = s.employeeRepo.GetByID(leave.EmployeeID); err != nil {
+			return nil, err
+		}
+	}
+	from, to := startOfDay(leave.StartDate), startOfDay(leave.EndDate).AddDate(0, 0, 1)
+
+	// 團隊規則適用於主管鏈中任一主管的團隊
+	chain, err := managerChain(s.employeeRepo, employee)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.ruleRepo.ListApplicable(employee.Department, chain, from, to)
+	if err != nil {
+		return nil, err
+	}
+	coverage := make([]models.StaffingCoverage, 0, len(rules))
+	if len(rules) == 0 {
+		return coverage, nil
+	}
+
+	// 請假員工沒有班次的日子不影響在崗人數
+	own, err := s.schedules.scheduledShifts([]models.Employee{*employee}, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rules {
+		result, err := s.evaluateRule(&rules[i], leave, from, to, own[employee.ID])
+		if err != nil {
+			return nil, err
+		}
+		if len(result.Days) > 0 {
+			coverage = append(coverage, *result)
+		}
+	}
+	return coverage, nil
+}
+
+// evaluateRule 計算單條規則在 [from, to) 內、規則生效且請假員工排定有班次的每一天的在崗人數
+// 只計算當天排定有班次的成員，沒有班次的成員不計入應在崗人數，其請假也不計入缺勤
+func (s *StaffingService) evaluateRule(rule *models.StaffingRule, leave *models.Leave, from, to time.Time, own map[string]models.ScheduledShift) (*models.StaffingCoverage, error) {
+	var members []models.Employee
+	var err error
+	if rule.ManagerID != nil {
+		members, err = teamMembers(s.employeeRepo, *rule.ManagerID)
+	} else {
+		members, err = s.employeeRepo.ListByDepartment(rule.Department)
+	}
+	if err != nil {
+		return nil, err
+	}
+	active := make([]models.Employee, 0, len(members))
+	ids := make([]uint, 0, len(members))
+	for _, member := range members {
+		if member.Status == models.EmployeeStatusInactive {
+			continue
+		}
+		active = append(active, member)
+		ids = append(ids, member.ID)
+	}
+
+	var leaves []models.Leave
+	if len(ids) > 0 {
+		if leaves, err = s.leaveRepo.ListOverlapping(ids, staffingLeaveStatuses, from, to); err != nil {
+			return nil, err
+		}
+	}
+
+	if rule.StartDate != nil && startOfDay(*rule.StartDate).After(from) {
+		from = startOfDay(*rule.StartDate)
+	}
+	if rule.EndDate != nil && startOfDay(*rule.EndDate).AddDate(0, 0, 1).Before(to) {
+		to = startOfDay(*rule.EndDate).AddDate(0, 0, 1)
+	}
+
+	result := &models.StaffingCoverage{
+		RuleID:      rule.ID,
+		RuleName:    rule.Name,
+		Enforcement: rule.Enforcement,
+		Satisfied:   true,
+		Days:        []models.StaffingDay{},
+	}
+	if !to.After(from) {
+		return result, nil
+	}
+	shifts, err := s.schedules.scheduledShifts(active, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		if _, ok := own[date]; !ok {
+			continue
+		}
+		rostered := make(map[uint]bool, len(ids))
+		for _, id := range ids {
+			if _, ok := shifts[id][date]; ok {
+				rostered[id] = true
+			}
+		}
+		next := day.AddDate(0, 0, 1)
+		absent := make(map[uint]bool)
+		if rostered[leave.EmployeeID] {
+			absent[leave.EmployeeID] = true
+		}
+		for _, other := range leaves {
+			if other.ID != leave.ID && rostered[other.EmployeeID] && other.StartDate.Before(next) && !startOfDay(other.EndDate).Before(day) {
+				absent[other.EmployeeID] = true
+			}
+		}
+
+		entry := models.StaffingDay{Date: date, Headcount: len(rostered), Absent: len(absent), Present: len(rostered) - len(absent)}
+		entry.Satisfied = staffingSatisfied(rule, entry)
+		if !entry.Satisfied {
+			result.Satisfied = false
+		}
+		result.Days = append(result.Days, entry)
+	}
+	return result, nil
+}
+
+// staffingSatisfied 判斷某一天的在崗情況是否滿足規則
+func staffingSatisfied(rule *models.StaffingRule, day models.StaffingDay) bool {
+	if rule.MinPresent > 0 && day.Present < rule.MinPresent {
+		return false
+	}
+	if rule.MaxAbsentRatio > 0 && day.Headcount > 0 && float64(day.Absent)/float64(day.Headcount) > rule.MaxAbsentRatio {
+		return false
+	}
+	return true
+}
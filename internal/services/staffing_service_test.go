@@ -0,0 +1,122 @@
+package services
+
+import (
+	"testing"
+
+	"hr-system/internal/models"
+	"hr-system/internal/repositories"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStaffingService() *StaffingService {
+	return NewStaffingService(repositories.NewStaffingRuleRepository(), repositories.NewEmployeeRepository(),
+		repositories.NewLeaveRepository(), newTestScheduleService())
+}
+
+func TestStaffingCountsRosteredEmployees(t *testing.T) {
+	db := setupTestDB(t)
+	service := newTestStaffingService()
+	department := "客服部"
+	applicant := seedEmployee(t, db, models.Employee{Name: "申請人", Department: department})
+	colleague := seedEmployee(t, db, models.Employee{Name: "同事", Department: department})
+	seedEmployee(t, db, models.Employee{Name: "值班", Department: department})
+	weekend := seedEmployee(t, db, models.Employee{Name: "週末班", Department: department})
+	seedRoster(t, db, weekend.ID, "週末班", 5, 6)
+	require.NoError(t, db.Create(&models.StaffingRule{
+		Name: "客服至少兩人", Department: department, MinPresent: 2, Enforcement: models.StaffingEnforcementWarn, Active: true,
+	}).Error)
+	seedHoliday(t, db, date(2024, 10, 10), "國慶日")
+	// 同事週五請假；週末班員工週五沒有班次，其請假不計入缺勤
+	seedLeave(t, db, colleague.ID, models.LeaveTypeAnnual, date(2024, 10, 4), date(2024, 10, 4))
+	seedLeave(t, db, weekend.ID, models.LeaveTypePersonal, date(2024, 10, 4), date(2024, 10, 4))
+
+	tests := []struct {
+		name     string
+		employee *models.Employee
+		start    int // 2024-10 的日期
+		end      int
+		wantDays []models.StaffingDay
+	}{
+		{
+			name:     "週五至週一跨週末，只計算申請人有班次的日子",
+			employee: applicant,
+			start:    4,
+			end:      7,
+			wantDays: []models.StaffingDay{
+				{Date: "2024-10-04", Headcount: 3, Absent: 2, Present: 1, Satisfied: false},
+				{Date: "2024-10-07", Headcount: 3, Absent: 1, Present: 2, Satisfied: true},
+			},
+		},
+		{
+			name:     "國慶日不計算",
+			employee: applicant,
+			start:    10,
+			end:      11,
+			wantDays: []models.StaffingDay{
+				{Date: "2024-10-11", Headcount: 3, Absent: 1, Present: 2, Satisfied: true},
+			},
+		},
+		{
+			name:     "週末只計算有班次的成員",
+			employee: weekend,
+			start:    5,
+			end:      6,
+			wantDays: []models.StaffingDay{
+				{Date: "2024-10-05", Headcount: 1, Absent: 1, Present: 0, Satisfied: false},
+				{Date: "2024-10-06", Headcount: 1, Absent: 1, Present: 0, Satisfied: false},
+			},
+		},
+		{name: "申請人休息日的請假不影響在崗人數", employee: weekend, start: 7, end: 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leave := &models.Leave{
+				EmployeeID: tt.employee.ID,
+				LeaveType:  models.LeaveTypeAnnual,
+				StartDate:  date(2024, 10, tt.start),
+				EndDate:    date(2024, 10, tt.end),
+				Status:     models.LeaveStatusPending,
+			}
+			require.NoError(t, db.Create(leave).Error)
+
+			coverage, err := service.Evaluate(leave)
+			require.NoError(t, err)
+			if len(tt.wantDays) == 0 {
+				assert.Empty(t, coverage)
+				warnings, err := service.Check(leave)
+				require.NoError(t, err)
+				assert.Empty(t, warnings)
+				return
+			}
+			require.Len(t, coverage, 1)
+			assert.Equal(t, tt.wantDays, coverage[0].Days)
+		})
+	}
+}
+
+func TestCreateInactiveStaffingRule(t *testing.T) {
+	db := setupTestDB(t)
+	service := newTestStaffingService()
+	employee := seedEmployee(t, db, models.Employee{Name: "員工", Department: "客服部"})
+
+	rule := &models.StaffingRule{
+		Name: "停用的規則", Department: "客服部", MinPresent: 5, Enforcement: models.StaffingEnforcementBlock, Active: false,
+	}
+	require.NoError(t, service.CreateRule(rule))
+	stored, err := service.GetRule(rule.ID)
+	require.NoError(t, err)
+	assert.False(t, stored.Active, "新增時指定停用的規則不應被保存為啟用")
+
+	// 停用的 block 規則不阻止提交
+	warnings, err := service.Check(&models.Leave{
+		EmployeeID: employee.ID,
+		LeaveType:  models.LeaveTypeAnnual,
+		StartDate:  date(2024, 10, 7),
+		EndDate:    date(2024, 10, 7),
+		Status:     models.LeaveStatusPending,
+	})
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+}
@@ -0,0 +1,311 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"hr-system/config"
+	"hr-system/internal/apperrors"
+	"hr-system/internal/models"
+	"hr-system/internal/repositories"
+)
+
+// Webhook 請求頭
+const (
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookEventIDHeader   = "X-Webhook-Event-ID"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+// maxWebhookErrorBody 失敗時記錄的響應體長度上限
+const maxWebhookErrorBody = 1024
+
+// WebhookService 管理 Webhook 訂閱，並將事件投遞給訂閱方
+// 事件先寫入投遞記錄表，再由後台輪詢投遞，失敗時按指數退避重試，重試用盡後進入死信列表
+type WebhookService struct {
+	repo   *repositories.WebhookRepository
+	cfg    config.WebhookConfig
+	client *http.Client
+	wake   chan struct{}
+}
+
+func NewWebhookService(repo *repositories.WebhookRepository, cfg config.WebhookConfig) *WebhookService {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 50
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 1
+	}
+	return &WebhookService{
+		repo:   repo,
+		cfg:    cfg,
+		client: &http.Client{Timeout: cfg.Timeout},
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// CreateSubscription 創建訂閱，未指定密鑰時自動生成
+func (s *WebhookService) CreateSubscription(sub *models.WebhookSubscription) error {
+	if sub.Secret == "" {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			return apperrors.Internal(err)
+		}
+		sub.Secret = secret
+	}
+	return s.repo.CreateSubscription(sub)
+}
+
+// GetSubscription 獲取訂閱
+func (s *WebhookService) GetSubscription(id uint) (*models.WebhookSubscription, error) {
+	return s.repo.GetSubscription(id)
+}
+
+// ListSubscriptions 獲取所有訂閱
+func (s *WebhookService) ListSubscriptions() ([]models.WebhookSubscription, error) {
+	return s.repo.ListSubscriptions()
+}
+
+// UpdateSubscription 更新訂閱，未指定密鑰時保留原密鑰
+func (s *WebhookService) UpdateSubscription(sub *models.WebhookSubscription) error {
+	existing, err := s.repo.GetSubscription(sub.ID)
+	if err != nil {
+		return err
+	}
+	if sub.Secret == "" {
+		sub.Secret = existing.Secret
+	}
+	sub.CreatedAt = existing.CreatedAt
+	return s.repo.UpdateSubscription(sub)
+}
+
+// DeleteSubscription 刪除訂閱，尚未投遞的記錄會在投遞時進入死信列表
+func (s *WebhookService) DeleteSubscription(id uint) error {
+	return s.repo.DeleteSubscription(id)
+}
+
+// ListDeliveries 按狀態與訂閱查詢投遞記錄
+func (s *WebhookService) ListDeliveries(status string, subscriptionID uint, limit int) ([]models.WebhookDelivery, error) {
+	return s.repo.ListDeliveries(status, subscriptionID, limit)
+}
+
+// Redeliver 重新投遞一條已完成（成功或進入死信列表）的記錄，重新計算重試次數
+func (s *WebhookService) Redeliver(id uint) (*models.WebhookDelivery, error) {
+	delivery, err := s.repo.GetDelivery(id)
+	if err != nil {
+		return nil, err
+	}
+	if delivery.Status == models.DeliveryStatusPending || delivery.Status == models.DeliveryStatusRetrying {
+		return nil, apperrors.Conflict(apperrors.CodeDeliveryInFlight, "Delivery is already scheduled")
+	}
+
+	delivery.Status = models.DeliveryStatusPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	delivery.LastError = ""
+	delivery.LastStatusCode = 0
+	if err := s.repo.UpdateDelivery(delivery); err != nil {
+		return nil, err
+	}
+	s.notify()
+	return delivery, nil
+}
+
+// Name 事件出口名稱
+func (s *WebhookService) Name() string {
+	return config.OutboxSinkWebhook
+}
+
+// Publish 為所有匹配的啟用訂閱創建投遞記錄，實際投遞由後台完成
+// 同一事件對同一訂閱只會創建一條投遞記錄，事件中繼重複發佈時不會重複投遞
+func (s *WebhookService) Publish(ctx context.Context, msg *models.OutboxMessage) error {
+	subs, err := s.repo.ListActiveSubscriptions()
+	if err != nil {
+		return err
+	}
+
+	var deliveries []models.WebhookDelivery
+	for _, sub := range subs {
+		if !sub.Matches(msg.EventType) {
+			continue
+		}
+		deliveries = append(deliveries, models.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			EventID:        msg.EventID,
+			EventType:      msg.EventType,
+			Payload:        msg.Payload,
+			Status:         models.DeliveryStatusPending,
+			NextAttemptAt:  time.Now(),
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if err := s.repo.CreateDeliveries(deliveries); err != nil {
+		return err
+	}
+	s.notify()
+	return nil
+}
+
+// notify 喚醒後台投遞，已有待處理的喚醒時忽略
+func (s *WebhookService) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// StartDispatching 開始後台投遞
+func (s *WebhookService) StartDispatching(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-s.wake:
+			}
+			s.dispatchDue(ctx)
+		}
+	}()
+}
+
+// dispatchDue 投遞所有到期的記錄
+func (s *WebhookService) dispatchDue(ctx context.Context) {
+	for ctx.Err() == nil {
+		now := time.Now()
+		deliveries, err := s.repo.GetDueDeliveries(now, s.cfg.BatchSize)
+		if err != nil {
+			log.Printf("Failed to load due webhook deliveries: %v", err)
+			return
+		}
+
+		// 租約需長於單次投遞耗時，避免投遞中的記錄被其他副本重複佔用
+		leaseUntil := now.Add(2*s.cfg.Timeout + time.Minute)
+		var wg sync.WaitGroup
+		for i := range deliveries {
+			delivery := &deliveries[i]
+			claimed, err := s.repo.ClaimDelivery(delivery.ID, now, leaseUntil)
+			if err != nil {
+				log.Printf("Failed to claim webhook delivery %d: %v", delivery.ID, err)
+				continue
+			}
+			if !claimed {
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.attempt(ctx, delivery)
+			}()
+		}
+		wg.Wait()
+
+		if len(deliveries) < s.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+// attempt 執行一次投遞並記錄結果
+func (s *WebhookService) attempt(ctx context.Context, delivery *models.WebhookDelivery) {
+	sub := delivery.Subscription
+	delivery.Attempts++
+
+	var statusCode int
+	var err error
+	if sub.ID == 0 || sub.DeletedAt.Valid || !sub.Active {
+		// 訂閱已刪除或停用，不再重試
+		delivery.Attempts = s.cfg.MaxAttempts
+		err = fmt.Errorf("subscription %d is deleted or inactive", delivery.SubscriptionID)
+	} else {
+		statusCode, err = s.send(ctx, &sub, delivery)
+	}
+
+	now := time.Now()
+	delivery.LastStatusCode = statusCode
+	switch {
+	case err == nil:
+		delivery.Status = models.DeliveryStatusSucceeded
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+	case delivery.Attempts >= s.cfg.MaxAttempts:
+		delivery.Status = models.DeliveryStatusDead
+		delivery.LastError = err.Error()
+		log.Printf("Webhook delivery %d (%s) moved to dead letters after %d attempts: %v",
+			delivery.ID, delivery.EventType, delivery.Attempts, err)
+	default:
+		delivery.Status = models.DeliveryStatusRetrying
+		delivery.NextAttemptAt = now.Add(exponentialBackoff(s.cfg.BackoffBase, s.cfg.BackoffMax, delivery.Attempts))
+		delivery.LastError = err.Error()
+	}
+
+	if err := s.repo.UpdateDelivery(delivery); err != nil {
+		log.Printf("Failed to update webhook delivery %d: %v", delivery.ID, err)
+	}
+}
+
+// send 發送簽名後的請求，2xx 視為成功
+func (s *WebhookService) send(ctx context.Context, sub *models.WebhookSubscription, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "hr-system-webhooks/1.0")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookEventIDHeader, delivery.EventID)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(sub.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookErrorBody))
+		return resp.StatusCode, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, maxWebhookErrorBody))
+	return resp.StatusCode, nil
+}
+
+// SignWebhookPayload 計算請求簽名：sha256=HEX(HMAC-SHA256(secret, "{timestamp}.{body}"))
+// 接收方應使用相同方式計算並以常數時間比較，同時拒絕時間戳過舊的請求以防重放
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func newWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,36 @@
+// Package storage 保存請假附件等上傳文件，支持本地磁盤與 S3 兼容的對象存儲
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"hr-system/config"
+)
+
+// ErrNotFound 對象不存在
+var ErrNotFound = errors.New("storage: object not found")
+
+// Store 定義文件存儲接口，key 為以 / 分隔的相對路徑
+type Store interface {
+	// Put 寫入對象，已存在時覆蓋
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// Open 讀取對象，不存在時返回 ErrNotFound
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete 刪除對象，不存在時不報錯
+	Delete(ctx context.Context, key string) error
+}
+
+// NewStore 依配置創建附件存儲
+func NewStore(cfg config.AttachmentConfig) (Store, error) {
+	switch cfg.Storage {
+	case config.AttachmentStorageLocal:
+		return NewLocal(cfg.Dir)
+	case config.AttachmentStorageS3:
+		return NewS3(cfg.S3)
+	default:
+		return nil, fmt.Errorf("storage: unknown backend %q", cfg.Storage)
+	}
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"hr-system/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocal(t *testing.T) {
+	store, err := NewLocal(t.TempDir())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, "leaves/1/a.pdf", strings.NewReader("%PDF-1.4"), 8, "application/pdf"))
+	r, err := store.Open(ctx, "leaves/1/a.pdf")
+	require.NoError(t, err)
+	content, _ := io.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "%PDF-1.4", string(content))
+
+	require.NoError(t, store.Delete(ctx, "leaves/1/a.pdf"))
+	_, err = store.Open(ctx, "leaves/1/a.pdf")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, store.Delete(ctx, "leaves/1/a.pdf"))
+
+	for _, key := range []string{"../escape", "/etc/passwd", "leaves/../../escape", ""} {
+		assert.Error(t, store.Put(ctx, key, strings.NewReader("x"), 1, "text/plain"), key)
+	}
+}
+
+// fakeS3 以內存模擬 S3 的 PUT/GET/DELETE，記錄收到的簽名
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]string
+	auth    []string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.auth = append(f.auth, r.Header.Get("Authorization"))
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = string(body)
+	case http.MethodGet:
+		body, ok := f.objects[r.URL.Path]
+		if !ok {
+			http.Error(w, "<Error><Code>NoSuchKey</Code></Error>", http.StatusNotFound)
+			return
+		}
+		io.WriteString(w, body)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3(t *testing.T) {
+	fake := &fakeS3{objects: map[string]string{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	store, err := NewS3(config.S3Config{Endpoint: server.URL, Region: "ap-northeast-1", Bucket: "hr", AccessKey: "AKID", SecretKey: "secret", Timeout: time.Second})
+	require.NoError(t, err)
+	store.now = func() time.Time { return time.Date(2024, 6, 10, 8, 0, 0, 0, time.UTC) }
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, "leaves/1/a.png", strings.NewReader("png"), 3, "image/png"))
+	assert.Contains(t, fake.objects, "/hr/leaves/1/a.png")
+	assert.True(t, strings.HasPrefix(fake.auth[0], "AWS4-HMAC-SHA256 Credential=AKID/20240610/ap-northeast-1/s3/aws4_request, SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date, Signature="))
+
+	r, err := store.Open(ctx, "leaves/1/a.png")
+	require.NoError(t, err)
+	content, _ := io.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "png", string(content))
+
+	require.NoError(t, store.Delete(ctx, "leaves/1/a.png"))
+	_, err = store.Open(ctx, "leaves/1/a.png")
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	_, err = NewS3(config.S3Config{Endpoint: server.URL})
+	assert.Error(t, err)
+}
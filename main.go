@@ -3,42 +3,151 @@ package main
 import (
 	"context"
 	"log"
+	"os"
 
 	"hr-system/config"
 	"hr-system/internal/handlers"
+	"hr-system/internal/middleware"
+	"hr-system/internal/notifications"
 	"hr-system/internal/repositories"
 	"hr-system/internal/services"
+	"hr-system/internal/storage"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
 )
 
 func main() {
 	// 初始化數據庫連接
 	config.InitDB()
 
-	// 初始化 Redis 連接
-	config.InitRedis()
+	// 初始化 Redis 連接，失敗時緩存層會使用備援後端
+	redisErr := config.InitRedis()
 
 	// 初始化依賴
 	employeeRepo := repositories.NewEmployeeRepository()
 	leaveRepo := repositories.NewLeaveRepository()
-	cacheService := services.NewCacheService()
+	cacheConfig := config.LoadCacheConfig()
+	cacheService := services.NewCacheService(cacheConfig, redisErr == nil)
 
-	employeeService := services.NewEmployeeService(employeeRepo, cacheService)
-	leaveService := services.NewLeaveService(leaveRepo, employeeRepo, cacheService)
+	// 員工與請假的變更和事件在同一事務中寫入 outbox
+	outboxRepo := repositories.NewOutboxRepository()
+	employeeService := services.NewEmployeeService(employeeRepo, cacheService, outboxRepo)
+	approvalService := services.NewApprovalService(repositories.NewDelegationRepository(), employeeRepo, leaveRepo)
+	leaveHistoryRepo := repositories.NewLeaveHistoryRepository()
+	holidayRepo := repositories.NewHolidayRepository()
+	// 排班決定員工每天的班次，沒有排班時按默認上下班時間於週一至週五上班
+	scheduleService := services.NewScheduleService(repositories.NewScheduleRepository(), employeeRepo, holidayRepo,
+		approvalService, config.LoadScheduleConfig())
+	// 提交與核准請假時檢查部門與團隊的人力規則，只計算排定有班次的成員
+	staffingService := services.NewStaffingService(repositories.NewStaffingRuleRepository(), employeeRepo, leaveRepo, scheduleService)
+	// 請假附件保存在本地磁盤或 S3 兼容的對象存儲
+	attachmentConfig := config.LoadAttachmentConfig()
+	attachmentStore, err := storage.NewStore(attachmentConfig)
+	if err != nil {
+		log.Fatal("Failed to configure attachment storage:", err)
+	}
+	attachmentService := services.NewAttachmentService(leaveRepo, repositories.NewLeaveAttachmentRepository(), leaveHistoryRepo,
+		employeeRepo, approvalService, attachmentStore, cacheService, attachmentConfig)
+	leaveService := services.NewLeaveService(leaveRepo, employeeRepo, cacheService, outboxRepo, leaveHistoryRepo, approvalService,
+		staffingService, attachmentService)
+	// 颱風停班、公司停工等情況由 HR 為一批員工直接創建已核准的請假
+	bulkLeaveService := services.NewBulkLeaveService(repositories.NewBulkLeaveRepository(), leaveRepo, employeeRepo,
+		leaveHistoryRepo, outboxRepo)
+	// 假期額度流水與年終的遞延、作廢、折發工資，請假只扣除排定有班次的日子
+	leaveLedgerRepo := repositories.NewLeaveLedgerRepository()
+	yearEndCloseRepo := repositories.NewYearEndCloseRepository()
+	leaveBalanceService := services.NewLeaveBalanceService(leaveLedgerRepo, yearEndCloseRepo, employeeRepo, leaveRepo,
+		scheduleService, config.LoadYearEndConfig())
+	// 加班申請沿用請假的審批人規則，選擇補休的加班核准後轉為補休額度
+	overtimeRepo := repositories.NewOvertimeRepository()
+	overtimeService := services.NewOvertimeService(overtimeRepo, employeeRepo, holidayRepo,
+		leaveLedgerRepo, yearEndCloseRepo, outboxRepo, approvalService, config.LoadOvertimeConfig())
+	// 考勤按排定的班次計算每天的摘要，已核准請假的日子不標記異常
+	attendanceService := services.NewAttendanceService(repositories.NewAttendanceRepository(), employeeRepo, leaveRepo,
+		scheduleService, approvalService, config.LoadAttendanceConfig())
+	// 薪資按月份計算，不給薪的請假只扣除排定有班次的日子，勞健保費與勞退按當月生效的分級表計算
+	payrollRepo := repositories.NewPayrollRepository()
+	insuranceService := services.NewInsuranceService(repositories.NewInsuranceRepository(), payrollRepo, employeeRepo,
+		config.LoadInsuranceConfig())
+	payrollService := services.NewPayrollService(payrollRepo, employeeRepo, leaveRepo,
+		overtimeRepo, outboxRepo, scheduleService, insuranceService, config.LoadPayrollConfig())
+
+	// 多副本共享 Redis 時通過分佈式鎖保證後台任務只有一個副本執行
+	// 啟動時 Redis 不可用也使用分佈式鎖，搶鎖失敗的輪次跳過，客戶端會自動重連，恢復後仍只有一個副本執行
+	var lock services.DistributedLock = services.NewLocalLock()
+	if cacheConfig.Backend == config.CacheBackendRedis {
+		lock = services.NewRedisLock(config.RedisClient)
+	}
+	prewarmService := services.NewPrewarmService(employeeRepo, leaveRepo, cacheService, lock, config.LoadPrewarmConfig())
 
-	// 初始化緩存預熱服務
-	prewarmService := services.NewPrewarmService(employeeRepo, leaveRepo, cacheService)
+	// 事件中繼將 outbox 中的事件發佈到 Webhook、郵件通知、Redis Stream 等出口
+	var redisClient *redis.Client // Redis 不可用時為空
+	if redisErr == nil {
+		redisClient = config.RedisClient
+	}
+	webhookService := services.NewWebhookService(repositories.NewWebhookRepository(), config.LoadWebhookConfig())
+	notificationConfig := config.LoadNotificationConfig()
+	// 站內通知經 Redis pub/sub 廣播，推送給連接在任一副本上的客戶端
+	notificationHub := services.NewNotificationHub(redisClient, notificationConfig.PubSubChannel)
+	auditService := services.NewAuditService(repositories.NewAuditRepository())
+	// 審批郵件中附帶簽名的一次性核准/駁回連結
+	leaveActionService := services.NewLeaveActionService(repositories.NewLeaveActionRepository(), leaveRepo, leaveService,
+		auditService, config.LoadLeaveActionConfig())
+	notificationService := services.NewNotificationService(repositories.NewNotificationRepository(), employeeRepo,
+		notifications.NewMailer(notificationConfig.SMTP), notificationHub, approvalService, leaveActionService, notificationConfig)
+	outboxConfig := config.LoadOutboxConfig()
+	sinks, err := services.NewEventSinks(outboxConfig, webhookService, notificationService, redisClient, os.Stdout)
+	if err != nil {
+		log.Fatal("Failed to configure outbox sinks:", err)
+	}
+	outboxRelay := services.NewOutboxRelay(outboxRepo, sinks, lock, outboxConfig)
+	// 超過審批時限的請假提醒審批人、升級給上一級主管，按配置自動核准已開始的請假
+	approvalSLAService := services.NewApprovalSLAService(leaveRepo, leaveHistoryRepo, outboxRepo, approvalService,
+		attachmentService, cacheService, lock, config.LoadApprovalSLAConfig())
 	// 創建一個後台context用於緩存預熱
 	ctx := context.Background()
 	// 啟動緩存預熱
 	prewarmService.StartPrewarming(ctx)
+	// 啟動事件中繼、Webhook 投遞與郵件通知發送
+	outboxRelay.StartRelaying(ctx)
+	webhookService.StartDispatching(ctx)
+	notificationService.StartSending(ctx)
+	notificationHub.Start(ctx)
+	approvalSLAService.StartChecking(ctx)
+
+	// 創建類接口的冪等鍵存儲，與緩存共用 Redis
+	idempotency := middleware.Idempotency(
+		services.NewIdempotencyStore(redisErr == nil, config.RedisClient),
+		config.LoadIdempotencyConfig(),
+	)
 
 	employeeHandler := handlers.NewEmployeeHandler(employeeService)
 	leaveHandler := handlers.NewLeaveHandler(leaveService)
+	healthHandler := handlers.NewHealthHandler(services.NewHealthService(cacheService))
+	prewarmHandler := handlers.NewPrewarmHandler(prewarmService)
+	metaHandler := handlers.NewMetaHandler()
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
+	outboxHandler := handlers.NewOutboxHandler(outboxRelay)
+	notificationHandler := handlers.NewNotificationHandler(notificationService, notificationConfig.StreamHeartbeat)
+	leaveActionHandler := handlers.NewLeaveActionHandler(leaveActionService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	approvalHandler := handlers.NewApprovalHandler(approvalService)
+	calendarHandler := handlers.NewCalendarHandler(services.NewCalendarService(leaveRepo, employeeRepo,
+		holidayRepo, repositories.NewCalendarFeedRepository(), config.LoadCalendarConfig()))
+	staffingHandler := handlers.NewStaffingHandler(staffingService)
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentService, attachmentConfig.MaxSize)
+	bulkLeaveHandler := handlers.NewBulkLeaveHandler(bulkLeaveService)
+	leaveBalanceHandler := handlers.NewLeaveBalanceHandler(leaveBalanceService)
+	overtimeHandler := handlers.NewOvertimeHandler(overtimeService)
+	attendanceHandler := handlers.NewAttendanceHandler(attendanceService)
+	scheduleHandler := handlers.NewScheduleHandler(scheduleService)
+	payrollHandler := handlers.NewPayrollHandler(payrollService)
+	insuranceHandler := handlers.NewInsuranceHandler(insuranceService)
 
 	// 創建 Gin 路由
-	r := gin.Default()
+	r := gin.New()
+	r.Use(middleware.RequestID(), middleware.Locale(), gin.Logger(), middleware.Recovery(), middleware.ErrorHandler(), middleware.Identity())
 
 	// 健康檢查
 	r.GET("/ping", func(c *gin.Context) {
@@ -46,6 +155,14 @@ func main() {
 			"message": "pong",
 		})
 	})
+	r.GET("/health", healthHandler.GetHealth)
+
+	// 郵件審批連結的確認頁與提交
+	r.GET("/leave-actions", leaveActionHandler.ShowLeaveAction)
+	r.POST("/leave-actions", leaveActionHandler.SubmitLeaveAction)
+
+	// 日曆客戶端訂閱的 iCalendar 內容，以連結中的令牌認證
+	r.GET("/calendar/feeds/:file", calendarHandler.GetFeed)
 
 	// API 路由組
 	api := r.Group("/api")
@@ -53,22 +170,182 @@ func main() {
 		// 員工相關路由
 		employees := api.Group("/employees")
 		{
-			employees.POST("", employeeHandler.CreateEmployee)
+			employees.POST("", idempotency, employeeHandler.CreateEmployee)
 			employees.GET("", employeeHandler.ListEmployees)
 			employees.GET("/:id", employeeHandler.GetEmployee)
 			employees.PUT("/:id", employeeHandler.UpdateEmployee)
 			employees.DELETE("/:id", employeeHandler.DeleteEmployee)
+			employees.GET("/:id/notification-preferences", notificationHandler.GetPreferences)
+			employees.PUT("/:id/notification-preferences", notificationHandler.UpdatePreferences)
+			employees.POST("/:id/delegations", middleware.RequireIdentity(), approvalHandler.CreateDelegation)
+			employees.GET("/:id/delegations", approvalHandler.ListDelegations)
+			employees.DELETE("/:id/delegations/:delegation_id", middleware.RequireIdentity(), approvalHandler.DeleteDelegation)
+			employees.GET("/:id/leave-balances", leaveBalanceHandler.GetBalances)
+			employees.GET("/:id/leave-ledger", leaveBalanceHandler.ListEntries)
+			employees.GET("/:id/attendance", attendanceHandler.GetEmployeeAttendance)
+			employees.GET("/:id/rosters", scheduleHandler.ListRosters)
+			employees.GET("/:id/shifts", scheduleHandler.GetShifts)
+			employees.GET("/:id/scheduled", scheduleHandler.GetScheduled)
+			employees.GET("/:id/payslips", payrollHandler.ListEmployeePayslips)
+			employees.GET("/:id/insurance-grades", insuranceHandler.GetEmployeeGrades)
 		}
 
 		// 請假相關路由
 		leaves := api.Group("/leaves")
 		{
-			leaves.POST("", leaveHandler.CreateLeave)
+			leaves.POST("", idempotency, leaveHandler.CreateLeave)
 			leaves.GET("", leaveHandler.ListLeaves)
 			leaves.GET("/:id", leaveHandler.GetLeave)
-			leaves.PUT("/:id/status", leaveHandler.UpdateLeaveStatus)
+			leaves.GET("/:id/approver", approvalHandler.GetLeaveApprover)
+			leaves.PUT("/:id/status", middleware.RequireIdentity(), leaveHandler.UpdateLeaveStatus)
+			leaves.PUT("/:id/cancel", middleware.RequireIdentity(), leaveHandler.CancelLeave)
+			leaves.GET("/:id/history", leaveHandler.GetLeaveHistory)
+			leaves.GET("/:id/coverage", staffingHandler.GetLeaveCoverage)
+			leaves.POST("/:id/attachments", middleware.RequireIdentity(), attachmentHandler.UploadAttachment)
+			leaves.GET("/:id/attachments", middleware.RequireIdentity(), attachmentHandler.ListAttachments)
+			leaves.GET("/:id/attachments/:attachment_id", middleware.RequireIdentity(), attachmentHandler.DownloadAttachment)
+			leaves.DELETE("/:id/attachments/:attachment_id", middleware.RequireIdentity(), attachmentHandler.DeleteAttachment)
 			leaves.DELETE("/:id", leaveHandler.DeleteLeave)
 		}
+
+		// 加班相關路由
+		overtime := api.Group("/overtime")
+		{
+			overtime.POST("", idempotency, overtimeHandler.CreateOvertime)
+			overtime.GET("", overtimeHandler.ListOvertime)
+			overtime.GET("/:id", overtimeHandler.GetOvertime)
+			overtime.PUT("/:id/status", middleware.RequireIdentity(), overtimeHandler.UpdateOvertimeStatus)
+			overtime.PUT("/:id/cancel", overtimeHandler.CancelOvertime)
+		}
+
+		// 考勤相關路由
+		attendance := api.Group("/attendance")
+		{
+			attendance.POST("/punches", middleware.RequireIdentity(), attendanceHandler.Punch)
+			attendance.GET("/anomalies", attendanceHandler.ListAnomalies)
+			attendance.POST("/corrections", middleware.RequireIdentity(), idempotency, attendanceHandler.CreateCorrection)
+			attendance.GET("/corrections", attendanceHandler.ListCorrections)
+			attendance.GET("/corrections/:id", attendanceHandler.GetCorrection)
+			attendance.PUT("/corrections/:id/status", middleware.RequireIdentity(), attendanceHandler.UpdateCorrectionStatus)
+		}
+
+		// 換班相關路由
+		shiftSwaps := api.Group("/shift-swaps")
+		{
+			shiftSwaps.POST("", middleware.RequireIdentity(), idempotency, scheduleHandler.CreateSwap)
+			shiftSwaps.GET("", scheduleHandler.ListSwaps)
+			shiftSwaps.GET("/:id", scheduleHandler.GetSwap)
+			shiftSwaps.PUT("/:id/respond", middleware.RequireIdentity(), scheduleHandler.RespondSwap)
+			shiftSwaps.PUT("/:id/cancel", middleware.RequireIdentity(), scheduleHandler.CancelSwap)
+			shiftSwaps.PUT("/:id/status", middleware.RequireIdentity(), scheduleHandler.UpdateSwapStatus)
+		}
+
+		// 當前員工相關路由
+		me := api.Group("/me", middleware.RequireIdentity())
+		{
+			me.GET("/approvals", approvalHandler.ListMyApprovals)
+			me.GET("/notifications", notificationHandler.ListMyNotifications)
+			me.GET("/notifications/stream", notificationHandler.StreamNotifications)
+			me.PUT("/notifications/read-all", notificationHandler.MarkAllNotificationsRead)
+			me.PUT("/notifications/:id/read", notificationHandler.MarkNotificationRead)
+			me.POST("/calendar-feeds", calendarHandler.CreateMyFeed)
+			me.GET("/calendar-feeds", calendarHandler.ListMyFeeds)
+			me.DELETE("/calendar-feeds/:id", calendarHandler.DeleteMyFeed)
+		}
+
+		// 團隊請假行事曆
+		api.GET("/calendar", calendarHandler.GetTeamCalendar)
+
+		// 本地化枚舉
+		api.GET("/meta/enums", metaHandler.GetEnums)
+
+		// 管理相關路由
+		admin := api.Group("/admin")
+		{
+			admin.GET("/cache/prewarm", prewarmHandler.GetPrewarmStatus)
+			admin.POST("/cache/prewarm", prewarmHandler.TriggerPrewarm)
+			admin.GET("/outbox", outboxHandler.GetOutboxStatus)
+			admin.GET("/notifications/logs", notificationHandler.ListLogs)
+			admin.GET("/audit-logs", auditHandler.ListAuditLogs)
+			admin.POST("/holidays", calendarHandler.CreateHoliday)
+			admin.GET("/holidays", calendarHandler.ListHolidays)
+			admin.DELETE("/holidays/:id", calendarHandler.DeleteHoliday)
+
+			staffingRules := admin.Group("/staffing-rules")
+			{
+				staffingRules.POST("", staffingHandler.CreateRule)
+				staffingRules.GET("", staffingHandler.ListRules)
+				staffingRules.GET("/:id", staffingHandler.GetRule)
+				staffingRules.PUT("/:id", staffingHandler.UpdateRule)
+				staffingRules.DELETE("/:id", staffingHandler.DeleteRule)
+			}
+
+			bulkLeaves := admin.Group("/bulk-leaves")
+			{
+				bulkLeaves.POST("", idempotency, bulkLeaveHandler.CreateBulkLeave)
+				bulkLeaves.GET("", bulkLeaveHandler.ListBulkLeaves)
+				bulkLeaves.GET("/:id", bulkLeaveHandler.GetBulkLeave)
+			}
+
+			admin.POST("/leave-ledger", leaveBalanceHandler.AddEntry)
+			admin.POST("/attendance/punches", idempotency, attendanceHandler.ImportPunch)
+
+			scheduleTemplates := admin.Group("/schedule-templates")
+			{
+				scheduleTemplates.POST("", scheduleHandler.CreateTemplate)
+				scheduleTemplates.GET("", scheduleHandler.ListTemplates)
+				scheduleTemplates.GET("/:id", scheduleHandler.GetTemplate)
+				scheduleTemplates.PUT("/:id", scheduleHandler.UpdateTemplate)
+				scheduleTemplates.DELETE("/:id", scheduleHandler.DeleteTemplate)
+			}
+			admin.POST("/rosters", scheduleHandler.AssignRoster)
+			admin.DELETE("/rosters/:id", scheduleHandler.DeleteRoster)
+			yearEndCloses := admin.Group("/year-end-closes")
+			{
+				yearEndCloses.POST("", idempotency, leaveBalanceHandler.CloseYear)
+				yearEndCloses.GET("", leaveBalanceHandler.ListCloses)
+				yearEndCloses.GET("/:year", leaveBalanceHandler.GetClose)
+				yearEndCloses.GET("/:year/payouts", leaveBalanceHandler.GetPayoutReport)
+			}
+			payComponents := admin.Group("/pay-components")
+			{
+				payComponents.POST("", payrollHandler.CreateComponent)
+				payComponents.GET("", payrollHandler.ListComponents)
+				payComponents.GET("/:id", payrollHandler.GetComponent)
+				payComponents.PUT("/:id", payrollHandler.UpdateComponent)
+				payComponents.DELETE("/:id", payrollHandler.DeleteComponent)
+			}
+			payrollRuns := admin.Group("/payroll-runs")
+			{
+				payrollRuns.POST("", idempotency, payrollHandler.CreateRun)
+				payrollRuns.GET("", payrollHandler.ListRuns)
+				payrollRuns.GET("/:id", payrollHandler.GetRun)
+				payrollRuns.DELETE("/:id", payrollHandler.DeleteRun)
+				payrollRuns.POST("/:id/recalculate", payrollHandler.RecalculateRun)
+				payrollRuns.POST("/:id/lock", payrollHandler.LockRun)
+				payrollRuns.GET("/:id/payslips/:employee_id", payrollHandler.GetPayslip)
+				payrollRuns.GET("/:id/insurance-reconciliation", insuranceHandler.Reconcile)
+			}
+			gradeTables := admin.Group("/insurance-grade-tables")
+			{
+				gradeTables.POST("", insuranceHandler.ImportTable)
+				gradeTables.GET("", insuranceHandler.ListTables)
+				gradeTables.GET("/:id", insuranceHandler.GetTable)
+				gradeTables.DELETE("/:id", insuranceHandler.DeleteTable)
+			}
+
+			webhooks := admin.Group("/webhooks")
+			{
+				webhooks.POST("", webhookHandler.CreateSubscription)
+				webhooks.GET("", webhookHandler.ListSubscriptions)
+				webhooks.GET("/dead-letters", webhookHandler.ListDeadLetters)
+				webhooks.POST("/deliveries/:id/redeliver", webhookHandler.Redeliver)
+				webhooks.GET("/:id", webhookHandler.GetSubscription)
+				webhooks.PUT("/:id", webhookHandler.UpdateSubscription)
+				webhooks.DELETE("/:id", webhookHandler.DeleteSubscription)
+				webhooks.GET("/:id/deliveries", webhookHandler.ListSubscriptionDeliveries)
+			}
+		}
 	}
 
 	// 啟動服務器